@@ -0,0 +1,202 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/config"
+	"github.com/keenetic29/vk-internship/pkg/database"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(args []string) error
+}
+
+var commands = []command{
+	{"serve", "запустить HTTP-сервер (по умолчанию)", runServe},
+	{"migrate", "управление схемой базы данных", runMigrate},
+	{"seed", "заполнить базу демонстрационными данными", runSeed},
+	{"create-admin", "создать пользователя с ролью администратора", runCreateAdmin},
+	{"reset-password", "задать пользователю новый пароль", runResetPassword},
+	{"ban-user", "заблокировать или разблокировать пользователя", runBanUser},
+	{"export-ads", "выгрузить объявления в CSV или JSON", runExportAds},
+}
+
+// run разбирает подкоманду и возвращает код выхода: 0 - успех, 1 - ошибка выполнения, 2 - ошибка в аргументах
+func run(args []string) int {
+	name := "serve"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	} else if len(args) > 0 && (args[0] == "-h" || args[0] == "--help") {
+		name = "help"
+	}
+
+	if name == "help" {
+		printUsage()
+		return 0
+	}
+
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+		err := cmd.run(args)
+		var usageErr usageError
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.As(err, &usageErr):
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			return 2
+		default:
+			fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	printUsage()
+	return 2
+}
+
+func printUsage() {
+	fmt.Fprintln(os.Stderr, "usage: marketplace <command> [flags]")
+	fmt.Fprintln(os.Stderr, "\nкоманды:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-16s%s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(os.Stderr, "\nсправка по команде: marketplace <command> --help")
+}
+
+// usageError - ошибка в аргументах команды (код выхода 2)
+type usageError struct {
+	msg string
+}
+
+func (e usageError) Error() string {
+	return e.msg
+}
+
+func usageErrorf(format string, args ...any) error {
+	return usageError{msg: fmt.Sprintf(format, args...)}
+}
+
+// newFlagSet создаёт набор флагов подкоманды; ошибки разбора возвращаются как usageError
+func newFlagSet(name, argsUsage, description string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: marketplace %s [flags]", name)
+		if argsUsage != "" {
+			fmt.Fprint(flags.Output(), " "+argsUsage)
+		}
+		fmt.Fprintf(flags.Output(), "\n\n%s\n", description)
+		hasFlags := false
+		flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+		if hasFlags {
+			fmt.Fprintln(flags.Output(), "\nфлаги:")
+			flags.PrintDefaults()
+		}
+	}
+	return flags
+}
+
+// parseFlags разбирает флаги и оборачивает ошибку разбора в usageError
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return usageError{msg: err.Error()}
+	}
+	return nil
+}
+
+// requireFlags проверяет, что перечисленные строковые флаги заданы
+func requireFlags(flags *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if f := flags.Lookup(name); f == nil || f.Value.String() == "" {
+			return usageErrorf("flag --%s is required", name)
+		}
+	}
+	return nil
+}
+
+type environment struct {
+	cfg *config.Config
+	db  *gorm.DB
+}
+
+// close закрывает соединения с базой, открытые в setup
+func (e *environment) close() {
+	if sqlDB, err := e.db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+// loadEnvironment загружает конфигурацию и логгер, пишущий кроме файла в console;
+// логгер закрывает main через logger.Sync
+func loadEnvironment(console io.Writer) (*config.Config, error) {
+	cfg, err := config.LoadConfig(".env")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := logger.InitWithConsole(cfg.LogDebug, cfg.LogFile, "marketplace.go", console); err != nil {
+		return nil, fmt.Errorf("failed to initialize logger: %w", err)
+	}
+	return cfg, nil
+}
+
+// setup загружает окружение и подключается к базе. Служебные команды работают
+// только с базой: в режиме STORAGE=memory данные живут внутри процесса serve.
+func setup() (*environment, error) {
+	// stdout занят результатом команды (например, выгрузкой export-ads), поэтому лог - в stderr
+	cfg, err := loadEnvironment(os.Stderr)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Storage == config.StorageMemory {
+		return nil, errors.New("STORAGE=memory keeps data only inside the serve process, this command needs STORAGE=database")
+	}
+
+	db, err := openDatabase(cfg)
+	if err != nil {
+		return nil, err
+	}
+	// SQL-лог GORM по умолчанию тоже пишется в stdout
+	db.Logger = gormlogger.New(log.New(os.Stderr, "\r\n", log.LstdFlags), gormlogger.Config{
+		SlowThreshold: 200 * time.Millisecond,
+		LogLevel:      gormlogger.Warn,
+		Colorful:      true,
+	})
+
+	return &environment{cfg: cfg, db: db}, nil
+}
+
+// openDatabase подключается к базе, выбранной в DB_DRIVER
+func openDatabase(cfg *config.Config) (*gorm.DB, error) {
+	db, err := database.InitDB(database.Options{
+		Driver:   cfg.DBDriver,
+		AdminDSN: cfg.GetBDCreateString(),
+		DSN:      cfg.GetDBConnectionString(),
+		Name:     cfg.DBName,
+		Path:     cfg.DBPath,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	return db, nil
+}
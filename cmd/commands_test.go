@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+// проверяются только пути, которые завершаются до подключения к базе
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want int
+	}{
+		{"help", []string{"help"}, 0},
+		{"top-level help flag", []string{"--help"}, 0},
+		{"command help", []string{"export-ads", "--help"}, 0},
+		{"unknown command", []string{"bogus"}, 2},
+		{"unknown flag", []string{"seed", "--bogus"}, 2},
+		{"missing required flag", []string{"ban-user"}, 2},
+		{"invalid role", []string{"create-admin", "--username", "root", "--password", "secret1", "--role", "owner"}, 2},
+		{"invalid format", []string{"export-ads", "--format", "xml"}, 2},
+		{"missing migrate subcommand", []string{"migrate"}, 2},
+		{"migrate to without version", []string{"migrate", "to"}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := run(tt.args); got != tt.want {
+				t.Errorf("run(%q) = %d, want %d", tt.args, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/internal/repository"
+	"github.com/keenetic29/vk-internship/internal/services"
+	"gorm.io/gorm"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+var exportCSVHeader = []string{"id", "title", "description", "price", "status", "category_id", "author", "image_url", "created_at"}
+
+type exportedAd struct {
+	ID          uint            `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	Price       float64         `json:"price"`
+	Status      domain.AdStatus `json:"status"`
+	CategoryID  uint            `json:"category_id"`
+	Author      string          `json:"author"`
+	ImageURL    string          `json:"image_url"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+func runExportAds(args []string) error {
+	flags := newFlagSet("export-ads", "", "Выгружает неудалённые объявления от старых к новым.\n"+
+		"CSV пишется с заголовком, JSON - по одному объекту на строку (JSON Lines).")
+	format := flags.String("format", "csv", "формат: csv или json")
+	status := flags.String("status", "", "выгрузить только объявления в этом статусе")
+	output := flags.String("output", "", "файл для выгрузки (по умолчанию stdout)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *format != "csv" && *format != "json" {
+		return usageErrorf("unknown format %q", *format)
+	}
+	if *status != "" && !domain.AdStatus(*status).Valid() {
+		return usageErrorf("unknown status %q", *status)
+	}
+
+	env, err := setup()
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	db := env.db
+	if *output == "" {
+		// предупреждения GORM (например, о медленных запросах) идут в stdout и испортили бы выгрузку
+		db = db.Session(&gorm.Session{Logger: gormlogger.Discard})
+	}
+	adminService := services.NewAdminService(repository.NewUserRepository(db), repository.NewTokenRepository(db), repository.NewAdvertisementRepository(db))
+
+	var out io.Writer = os.Stdout
+	var file *os.File
+	if *output != "" {
+		if file, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer file.Close()
+		out = file
+	}
+
+	var exporter adExporter = newJSONExporter(out)
+	if *format == "csv" {
+		exporter = newCSVExporter(out)
+	}
+
+	count := 0
+	err = adminService.ExportAds(domain.AdStatus(*status), func(ad domain.Advertisement) error {
+		count++
+		return exporter.Write(exportedAd{
+			ID:          ad.ID,
+			Title:       ad.Title,
+			Description: ad.Description,
+			Price:       ad.Price,
+			Status:      ad.Status,
+			CategoryID:  ad.CategoryID,
+			Author:      ad.User.Username,
+			ImageURL:    ad.ImageURL,
+			CreatedAt:   ad.CreatedAt,
+		})
+	})
+	if err != nil {
+		return err
+	}
+	if err := exporter.Flush(); err != nil {
+		return err
+	}
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "exported %d ads\n", count)
+	return nil
+}
+
+type adExporter interface {
+	Write(ad exportedAd) error
+	Flush() error
+}
+
+type csvExporter struct {
+	writer        *csv.Writer
+	headerWritten bool
+}
+
+func newCSVExporter(w io.Writer) *csvExporter {
+	return &csvExporter{writer: csv.NewWriter(w)}
+}
+
+func (e *csvExporter) writeHeader() error {
+	if e.headerWritten {
+		return nil
+	}
+	e.headerWritten = true
+	return e.writer.Write(exportCSVHeader)
+}
+
+func (e *csvExporter) Write(ad exportedAd) error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	return e.writer.Write([]string{
+		strconv.FormatUint(uint64(ad.ID), 10),
+		ad.Title,
+		ad.Description,
+		strconv.FormatFloat(ad.Price, 'f', 2, 64),
+		string(ad.Status),
+		strconv.FormatUint(uint64(ad.CategoryID), 10),
+		ad.Author,
+		ad.ImageURL,
+		ad.CreatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// Flush дописывает заголовок, если объявлений не было, и сбрасывает буфер
+func (e *csvExporter) Flush() error {
+	if err := e.writeHeader(); err != nil {
+		return err
+	}
+	e.writer.Flush()
+	return e.writer.Error()
+}
+
+// jsonExporter пишет объявления в формате JSON Lines
+type jsonExporter struct {
+	out     *bufio.Writer
+	encoder *json.Encoder
+}
+
+func newJSONExporter(w io.Writer) *jsonExporter {
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	return &jsonExporter{out: out, encoder: encoder}
+}
+
+func (e *jsonExporter) Write(ad exportedAd) error {
+	return e.encoder.Encode(ad)
+}
+
+func (e *jsonExporter) Flush() error {
+	return e.out.Flush()
+}
@@ -1,49 +1,73 @@
 package main
 
 import (
-	"github.com/keenetic29/vk-internship/internal/api"
+	"context"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
 	"github.com/keenetic29/vk-internship/internal/config"
-	"github.com/keenetic29/vk-internship/internal/repository"
-	"github.com/keenetic29/vk-internship/internal/services"
-	"github.com/keenetic29/vk-internship/pkg/database"
+	"github.com/keenetic29/vk-internship/pkg/jwt"
 	"github.com/keenetic29/vk-internship/pkg/logger"
-	"log"
+	"github.com/keenetic29/vk-internship/pkg/storage"
+	"os"
+	"time"
 )
 
 func main() {
-	cfg, err := config.LoadConfig(".env")
+	code := run(os.Args[1:])
+	logger.Sync()
+	os.Exit(code)
+}
+
+// loadKeyring собирает связку ключей: HMAC из JWT_SECRET и PEM-ключи из JWT_KEYS
+func loadKeyring(cfg *config.Config) (*jwt.Keyring, error) {
+	files, err := cfg.GetJWTKeyFiles()
 	if err != nil {
-		log.Fatal("Failed to load config", err)
+		return nil, err
 	}
 
-	if err := logger.Init(cfg.LogDebug, cfg.LogFile, "marketplace.go"); err != nil {
-		log.Fatal("Failed to initialize logger:", err)
+	var keys []*jwt.Key
+	if cfg.JWTSecret != "" {
+		keys = append(keys, jwt.NewHMACKey(jwt.DefaultHMACKeyID, []byte(cfg.JWTSecret)))
 	}
-	defer logger.Sync()
-
-	logger.Log.Info("Starting application", 
-		"version", "1.0.0",
-		"debug", cfg.LogDebug,
-	)
-
-	db, err := database.InitDB(cfg.GetBDCreateString(), cfg.GetDBConnectionString(), cfg.DBName)
-	if err != nil {
-		log.Fatal("Failed to connect to database", err)
+	for _, file := range files {
+		key, err := jwt.LoadKeyFile(file.ID, file.Path)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
 	}
 
-	if err := database.RunMigrations(db); err != nil {
-		log.Fatal("Failed to run migrations", err)
+	keyring, err := jwt.NewKeyring(cfg.GetJWTActiveKey(files, jwt.DefaultHMACKeyID), keys...)
+	if err != nil {
+		return nil, err
 	}
 
-	userRepo := repository.NewUserRepository(db)
-	adRepo := repository.NewAdvertisementRepository(db)
+	logger.Log.Info("JWT keyring loaded", "keys", len(keys), "active_kid", keyring.ActiveKeyID())
+	return keyring, nil
+}
 
-	authService := services.NewAuthService(userRepo, cfg.JWTSecret)
-	adService := services.NewAdvertisementService(adRepo)
+func newImageStore(cfg *config.Config) (handlers.ImageStore, error) {
+	if cfg.ImageStore != "s3" {
+		logger.Log.Info("Using local image store", "dir", cfg.ImageDir)
+		return storage.NewLocalStore(cfg.ImageDir)
+	}
 
-	router := api.SetupRouter(authService, adService, cfg.JWTSecret)
+	store, err := storage.NewS3Store(storage.S3Config{
+		Endpoint:  cfg.S3Endpoint,
+		Bucket:    cfg.S3Bucket,
+		Region:    cfg.S3Region,
+		AccessKey: cfg.S3AccessKey,
+		SecretKey: cfg.S3SecretKey,
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	if err := router.Run(":"+cfg.ServerAddr); err != nil {
-		log.Fatal("Failed to start server", err)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := store.EnsureBucket(ctx); err != nil {
+		return nil, err
 	}
-}
\ No newline at end of file
+
+	logger.Log.Info("Using S3 image store", "endpoint", cfg.S3Endpoint, "bucket", cfg.S3Bucket)
+	return store, nil
+}
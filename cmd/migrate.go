@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/keenetic29/vk-internship/pkg/database"
+)
+
+// runMigrate выполняет команду migrate: up - применить все миграции, down - откатить последнюю,
+// to N - привести схему к версии N (0 - откатить всё), status - список миграций
+func runMigrate(args []string) error {
+	flags := newFlagSet("migrate", "up|down|status|to N",
+		"Управляет схемой базы данных: up - применить все миграции, down - откатить последнюю,\n"+
+			"to N - привести схему к версии N (0 - откатить всё), status - список миграций.")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	args = flags.Args()
+	if len(args) == 0 {
+		return usageErrorf("subcommand is required: up|down|status|to N")
+	}
+	if args[0] == "to" && len(args) != 2 {
+		return usageErrorf("usage: migrate to N")
+	}
+
+	env, err := setup()
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	migrator, err := database.NewMigrator(env.db)
+	if err != nil {
+		return err
+	}
+
+	var steps []database.MigrationStep
+	switch args[0] {
+	case "up":
+		steps, err = migrator.Up()
+	case "down":
+		steps, err = migrator.Down()
+	case "to":
+		version, convErr := strconv.Atoi(args[1])
+		if convErr != nil || version < 0 {
+			return usageErrorf("invalid version %q", args[1])
+		}
+		steps, err = migrator.To(version)
+	case "status":
+		return printMigrationStatus(migrator)
+	default:
+		return usageErrorf("unknown migrate subcommand %q", args[0])
+	}
+
+	for _, step := range steps {
+		fmt.Println(step)
+	}
+	if err != nil {
+		return err
+	}
+	if len(steps) == 0 {
+		fmt.Println("nothing to do")
+	}
+	return nil
+}
+
+func printMigrationStatus(migrator *database.Migrator) error {
+	statuses, err := migrator.Status()
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+		}
+		fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"github.com/keenetic29/vk-internship/internal/repository"
+	"github.com/keenetic29/vk-internship/internal/repository/memory"
+	"github.com/keenetic29/vk-internship/internal/services"
+	"gorm.io/gorm"
+)
+
+// Репозиторий пользователей обслуживает сразу несколько сервисов, поэтому
+// в repositories хранится объединение нужных им интерфейсов
+type userStore interface {
+	services.UserRepository
+	services.AdminUserRepository
+	services.UserLookup
+	services.ProfileLookup
+	services.ProfileRepository
+}
+
+type adStore interface {
+	services.AdvertisementRepository
+	services.AdLookup
+	services.AdMatcher
+}
+
+type tokenStore interface {
+	services.TokenRepository
+	services.SessionRevoker
+}
+
+type offerStore interface {
+	services.OfferRepository
+	services.OfferLookup
+}
+
+// repositories - набор репозиториев одного хранилища
+type repositories struct {
+	users         userStore
+	ads           adStore
+	categories    services.CategoryRepository
+	tokens        tokenStore
+	favorites     services.FavoriteRepository
+	savedSearches services.SavedSearchRepository
+	notifications services.NotificationRepository
+	conversations services.ConversationRepository
+	offers        offerStore
+	reviews       services.ReviewRepository
+	blocks        services.BlockRepository
+}
+
+// gormRepositories собирает репозитории поверх базы из DB_DRIVER: PostgreSQL или SQLite
+func gormRepositories(db *gorm.DB) *repositories {
+	return &repositories{
+		users:         repository.NewUserRepository(db),
+		ads:           repository.NewAdvertisementRepository(db),
+		categories:    repository.NewCategoryRepository(db),
+		tokens:        repository.NewTokenRepository(db),
+		favorites:     repository.NewFavoriteRepository(db),
+		savedSearches: repository.NewSavedSearchRepository(db),
+		notifications: repository.NewNotificationRepository(db),
+		conversations: repository.NewConversationRepository(db),
+		offers:        repository.NewOfferRepository(db),
+		reviews:       repository.NewReviewRepository(db),
+		blocks:        repository.NewBlockRepository(db),
+	}
+}
+
+func memoryRepositories() *repositories {
+	store := memory.NewStore()
+	return &repositories{
+		users:         memory.NewUserRepository(store),
+		ads:           memory.NewAdvertisementRepository(store),
+		categories:    memory.NewCategoryRepository(store),
+		tokens:        memory.NewTokenRepository(store),
+		favorites:     memory.NewFavoriteRepository(store),
+		savedSearches: memory.NewSavedSearchRepository(store),
+		notifications: memory.NewNotificationRepository(store),
+		conversations: memory.NewConversationRepository(store),
+		offers:        memory.NewOfferRepository(store),
+		reviews:       memory.NewReviewRepository(store),
+		blocks:        memory.NewBlockRepository(store),
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/repository"
+	"github.com/keenetic29/vk-internship/internal/seed"
+	"github.com/keenetic29/vk-internship/internal/services"
+)
+
+func runSeed(args []string) error {
+	flags := newFlagSet("seed", "", "Создаёт демонстрационных пользователей и объявления, детерминированно по значению --seed.\n"+
+		"Повторный запуск с тем же seed ничего не дублирует, а с большими --users/--ads дописывает недостающее.")
+	seedValue := flags.Int64("seed", 1, "значение, от которого зависят все сгенерированные данные")
+	users := flags.Int("users", 20, fmt.Sprintf("число пользователей (до %d)", seed.MaxUsers))
+	ads := flags.Int("ads", 200, "число объявлений")
+	password := flags.String("password", "demo-password", "пароль демонстрационных пользователей")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if *users < 0 || *users > seed.MaxUsers || *ads < 0 {
+		return usageErrorf("--users must be between 0 and %d, --ads must not be negative", seed.MaxUsers)
+	}
+	if *ads > 0 && *users == 0 {
+		return usageErrorf("--ads needs at least one user")
+	}
+
+	env, err := setup()
+	if err != nil {
+		return err
+	}
+	defer env.close()
+	keyring, err := loadKeyring(env.cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	userRepo := repository.NewUserRepository(env.db)
+	adRepo := repository.NewAdvertisementRepository(env.db)
+	categoryRepo := repository.NewCategoryRepository(env.db)
+	authService := services.NewAuthService(userRepo, repository.NewTokenRepository(env.db), keyring)
+	adService := services.NewAdvertisementService(adRepo, categoryRepo, repository.NewFavoriteRepository(env.db))
+
+	clock := &seed.Clock{}
+	adService.SetClock(clock.Now)
+
+	seeder := seed.NewSeeder(authService, adService, userRepo, adRepo, categoryRepo, clock)
+	result, err := seeder.Run(seed.Options{Seed: *seedValue, Users: *users, Ads: *ads, Password: *password})
+	if result != nil {
+		fmt.Printf("users: %d created, %d existing; ads: %d created, %d existing\n",
+			result.UsersCreated, result.UsersExisting, result.AdsCreated, result.AdsExisting)
+	}
+	return err
+}
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/api"
+	"github.com/keenetic29/vk-internship/internal/config"
+	"github.com/keenetic29/vk-internship/internal/services"
+	"github.com/keenetic29/vk-internship/pkg/database"
+	"github.com/keenetic29/vk-internship/pkg/diskcache"
+	"github.com/keenetic29/vk-internship/pkg/events"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+	"gorm.io/gorm"
+)
+
+// runServe применяет миграции и запускает HTTP-сервер до SIGINT/SIGTERM
+func runServe(args []string) error {
+	flags := newFlagSet("serve", "", "Применяет миграции и запускает HTTP-сервер (команда по умолчанию).")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	cfg, err := loadEnvironment(os.Stdout)
+	if err != nil {
+		return err
+	}
+
+	logger.Log.Info("Starting application",
+		"version", "1.0.0",
+		"debug", cfg.LogDebug,
+		"storage", cfg.Storage,
+		"db_driver", cfg.DBDriver,
+	)
+
+	var (
+		db    *gorm.DB
+		repos *repositories
+	)
+	if cfg.Storage == config.StorageMemory {
+		logger.Log.Warn("Using in-memory storage, data will be lost on restart")
+		repos = memoryRepositories()
+	} else {
+		db, err = openDatabase(cfg)
+		if err != nil {
+			return err
+		}
+		steps, err := database.RunMigrations(db)
+		if err != nil {
+			return fmt.Errorf("failed to run migrations: %w", err)
+		}
+		for _, step := range steps {
+			logger.Log.Info("Migration applied", "migration", step.String())
+		}
+		repos = gormRepositories(db)
+	}
+
+	keyring, err := loadKeyring(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to load JWT keys: %w", err)
+	}
+
+	imageStore, err := newImageStore(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to initialize image store: %w", err)
+	}
+
+	thumbnailCacheSize, err := cfg.GetThumbnailCacheBytes()
+	if err != nil {
+		return fmt.Errorf("invalid thumbnail cache size: %w", err)
+	}
+	thumbnailCache, err := diskcache.New(cfg.ThumbnailCacheDir, thumbnailCacheSize)
+	if err != nil {
+		return fmt.Errorf("failed to initialize thumbnail cache: %w", err)
+	}
+
+	authService := services.NewAuthService(repos.users, repos.tokens, keyring)
+	adService := services.NewAdvertisementService(repos.ads, repos.categories, repos.favorites)
+	categoryService := services.NewCategoryService(repos.categories)
+	adminService := services.NewAdminService(repos.users, repos.tokens, repos.ads)
+	savedSearchService := services.NewSavedSearchService(repos.savedSearches, repos.ads, repos.categories)
+	notificationService := services.NewNotificationService(repos.notifications)
+	messagingService := services.NewMessagingService(repos.conversations, repos.ads, repos.users, repos.blocks)
+	offerService := services.NewOfferService(repos.offers, repos.ads)
+	reviewService := services.NewReviewService(repos.reviews, repos.offers, repos.users)
+	profileService := services.NewProfileService(repos.users)
+
+	// события между экземплярами идут через LISTEN/NOTIFY PostgreSQL; с памятью и SQLite
+	// экземпляр один, и хаб получает события напрямую
+	eventHub := events.NewHub(events.DefaultBuffer)
+	var (
+		publisher   services.EventPublisher = eventHub
+		eventBroker *events.PGBroker
+	)
+	if db != nil && cfg.DBDriver == database.DriverPostgres {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return fmt.Errorf("failed to get database handle: %w", err)
+		}
+		eventBroker = events.NewPGBroker(sqlDB, cfg.GetDBConnectionString(), events.DefaultChannel, eventHub)
+		publisher = eventBroker
+	}
+	adService.SetEventPublisher(publisher)
+	messagingService.SetEventPublisher(publisher)
+	savedSearchService.SetEventPublisher(publisher)
+	offerService.SetEventPublisher(publisher)
+
+	savedSearchInterval, err := cfg.GetSavedSearchInterval()
+	if err != nil {
+		return fmt.Errorf("invalid saved search interval: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if eventBroker != nil {
+		go eventBroker.Run(ctx, func(err error) {
+			logger.Log.Error("Event listener disconnected", "error", err)
+		})
+	}
+	go savedSearchService.Run(ctx, savedSearchInterval)
+
+	router := api.SetupRouter(api.Dependencies{
+		AuthService:         authService,
+		AdService:           adService,
+		CategoryService:     categoryService,
+		AdminService:        adminService,
+		SavedSearchService:  savedSearchService,
+		NotificationService: notificationService,
+		MessagingService:    messagingService,
+		OfferService:        offerService,
+		ReviewService:       reviewService,
+		ProfileService:      profileService,
+		Events:              eventHub,
+		KeySet:              keyring,
+		ImageStore:          imageStore,
+		ThumbnailCache:      thumbnailCache,
+		MediaBaseURL:        cfg.MediaBaseURL,
+	})
+
+	server := &http.Server{
+		Addr:    ":" + cfg.ServerAddr,
+		Handler: router,
+	}
+	serverErr := make(chan error, 1)
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+	case err := <-serverErr:
+		eventHub.Close()
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+	logger.Log.Info("Shutting down")
+
+	// потоки /stream живут бесконечно, поэтому их закрываем до Shutdown, иначе он будет их ждать
+	eventHub.Close()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		logger.Log.Error("Failed to shut down server gracefully", "error", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/internal/repository"
+	"github.com/keenetic29/vk-internship/internal/services"
+)
+
+const passwordFlagUsage = "пароль; \"-\" - прочитать первую строку из stdin, чтобы пароль не попал в историю shell"
+
+func runCreateAdmin(args []string) error {
+	flags := newFlagSet("create-admin", "", "Создаёт пользователя и назначает ему роль (по умолчанию admin).")
+	username := flags.String("username", "", "имя пользователя (обязательно)")
+	password := flags.String("password", "", passwordFlagUsage+" (обязательно)")
+	role := flags.String("role", string(domain.RoleAdmin), "роль: user, moderator или admin")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlags(flags, "username", "password"); err != nil {
+		return err
+	}
+	if !domain.Role(*role).Valid() {
+		return usageErrorf("unknown role %q", *role)
+	}
+	pwd, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	env, err := setup()
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	// команда не выдаёт токенов, связка ключей ей не нужна
+	authService := services.NewAuthService(repository.NewUserRepository(env.db), repository.NewTokenRepository(env.db), nil)
+
+	// пользователь создаётся сразу с ролью: без отдельного SetRole не останется
+	// пользователя с ролью user, если вторая запись не пройдёт
+	user, err := authService.CreateUser(*username, pwd, domain.Role(*role))
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("created user %s (id %d, role %s)\n", user.Username, user.ID, user.Role)
+	return nil
+}
+
+func runResetPassword(args []string) error {
+	flags := newFlagSet("reset-password", "", "Задаёт пользователю новый пароль и отзывает его refresh-токены; выданные access-токены истекают сами.")
+	username := flags.String("username", "", "имя пользователя (обязательно)")
+	password := flags.String("password", "", passwordFlagUsage+" (обязательно)")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlags(flags, "username", "password"); err != nil {
+		return err
+	}
+	pwd, err := readPassword(*password)
+	if err != nil {
+		return err
+	}
+
+	env, err := setup()
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	userRepo := repository.NewUserRepository(env.db)
+	authService := services.NewAuthService(userRepo, repository.NewTokenRepository(env.db), nil)
+
+	user, err := userRepo.GetByUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %q: %w", *username, err)
+	}
+	if err := authService.ResetPassword(user.ID, pwd); err != nil {
+		return err
+	}
+
+	fmt.Printf("password of %s updated, refresh tokens revoked (access tokens expire within %s)\n", user.Username, services.AccessTokenTTL)
+	return nil
+}
+
+// runBanUser действует от имени администратора, поэтому, как и в API,
+// заблокировать другого администратора нельзя
+func runBanUser(args []string) error {
+	flags := newFlagSet("ban-user", "", "Блокирует пользователя и завершает его сессии; с --unban снимает блокировку.\n"+
+		"Администраторов блокировать нельзя.")
+	username := flags.String("username", "", "имя пользователя (обязательно)")
+	unban := flags.Bool("unban", false, "снять блокировку")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if err := requireFlags(flags, "username"); err != nil {
+		return err
+	}
+
+	env, err := setup()
+	if err != nil {
+		return err
+	}
+	defer env.close()
+
+	userRepo := repository.NewUserRepository(env.db)
+	adminService := services.NewAdminService(userRepo, repository.NewTokenRepository(env.db), repository.NewAdvertisementRepository(env.db))
+
+	user, err := userRepo.GetByUsername(*username)
+	if err != nil {
+		return fmt.Errorf("user %q: %w", *username, err)
+	}
+	if user, err = adminService.SetBanned(0, domain.RoleAdmin, user.ID, !*unban); err != nil {
+		return err
+	}
+
+	state := "banned"
+	if !user.Banned {
+		state = "unbanned"
+	}
+	fmt.Printf("user %s %s\n", user.Username, state)
+	return nil
+}
+
+// readPassword возвращает значение флага --password или, если это "-", первую строку stdin
+func readPassword(value string) (string, error) {
+	if value != "-" {
+		return value, nil
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil && line == "" {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
@@ -0,0 +1,160 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type AdminService interface {
+	ListUsers(filter domain.UserFilter) (*domain.UserPage, error)
+	SetBanned(actorID uint, actorRole domain.Role, userID uint, banned bool) (*domain.User, error)
+	ArchiveAd(adID uint) (*domain.Advertisement, error)
+}
+
+type AdminHandler struct {
+	adminService AdminService
+}
+
+func NewAdminHandler(adminService AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+type responseUser struct {
+	ID        uint        `json:"id"`
+	Username  string      `json:"username"`
+	Role      domain.Role `json:"role"`
+	Banned    bool        `json:"banned"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func newResponseUser(user domain.User) responseUser {
+	return responseUser{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Banned:    user.Banned,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+type responseUserPage struct {
+	Items []responseUser `json:"items"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+func currentRole(c *gin.Context) domain.Role {
+	if role, exists := c.Get("role"); exists {
+		if r, ok := role.(domain.Role); ok {
+			return r
+		}
+	}
+	return ""
+}
+
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	filter := domain.UserFilter{
+		Page:  page,
+		Limit: limit,
+		Role:  domain.Role(c.Query("role")),
+	}
+
+	if raw := c.Query("banned"); raw != "" {
+		banned, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid banned"})
+			return
+		}
+		filter.Banned = &banned
+	}
+
+	result, err := h.adminService.ListUsers(filter)
+	if err != nil {
+		logger.Log.Error("Failed to list users",
+			"error", err,
+			"user_id", currentUserID(c),
+		)
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	items := make([]responseUser, 0, len(result.Items))
+	for _, user := range result.Items {
+		items = append(items, newResponseUser(user))
+	}
+
+	c.JSON(http.StatusOK, responseUserPage{
+		Items: items,
+		Total: result.Total,
+		Page:  result.Page,
+		Limit: result.Limit,
+	})
+}
+
+func (h *AdminHandler) SetBanned(banned bool) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		actorID := currentUserID(c)
+
+		userID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		user, err := h.adminService.SetBanned(actorID, currentRole(c), userID, banned)
+		if err != nil {
+			logger.Log.Warn("Failed to change ban status",
+				"error", err,
+				"actor_id", actorID,
+				"target_id", userID,
+				"banned", banned,
+			)
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		logger.Log.Info("User ban status changed",
+			"actor_id", actorID,
+			"target_id", user.ID,
+			"banned", banned,
+		)
+
+		c.JSON(http.StatusOK, newResponseUser(*user))
+	}
+}
+
+func (h *AdminHandler) ArchiveAd(c *gin.Context) {
+	actorID := currentUserID(c)
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ad, err := h.adminService.ArchiveAd(adID)
+	if err != nil {
+		logger.Log.Warn("Failed to force-archive advertisement",
+			"error", err,
+			"actor_id", actorID,
+			"ad_id", adID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Advertisement archived by moderator",
+		"actor_id", actorID,
+		"ad_id", ad.ID,
+		"author_id", ad.UserID,
+	)
+
+	c.JSON(http.StatusOK, newResponseAd(*ad, actorID))
+}
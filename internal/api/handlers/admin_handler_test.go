@@ -0,0 +1,223 @@
+package handlers_test
+
+import (
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockAdminService struct {
+	mock.Mock
+}
+
+func (m *MockAdminService) ListUsers(filter domain.UserFilter) (*domain.UserPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*domain.UserPage), args.Error(1)
+}
+
+func (m *MockAdminService) SetBanned(actorID uint, actorRole domain.Role, userID uint, banned bool) (*domain.User, error) {
+	args := m.Called(actorID, actorRole, userID, banned)
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func (m *MockAdminService) ArchiveAd(adID uint) (*domain.Advertisement, error) {
+	args := m.Called(adID)
+	return args.Get(0).(*domain.Advertisement), args.Error(1)
+}
+
+func setAdminContext(c *gin.Context) {
+	c.Set("userID", uint(1))
+	c.Set("role", domain.RoleModerator)
+}
+
+func TestAdminHandler_ListUsers(t *testing.T) {
+	banned := true
+
+	tests := []struct {
+		name         string
+		query        string
+		mockSetup    func(*MockAdminService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:  "Successful list",
+			query: "?page=1&limit=20&banned=true",
+			mockSetup: func(m *MockAdminService) {
+				m.On("ListUsers", domain.UserFilter{Page: 1, Limit: 20, Banned: &banned}).Return(&domain.UserPage{
+					Items: []domain.User{{ID: 2, Username: "spammer", Password: "hash", Role: domain.RoleUser, Banned: true}},
+					Total: 1, Page: 1, Limit: 20,
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[{"id":2,"username":"spammer","role":"user","banned":true,"created_at":"0001-01-01T00:00:00Z"}],"total":1,"page":1,"limit":20}`,
+		},
+		{
+			name:         "Invalid banned flag",
+			query:        "?banned=maybe",
+			mockSetup:    func(m *MockAdminService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Unknown role",
+			query: "?role=root",
+			mockSetup: func(m *MockAdminService) {
+				m.On("ListUsers", mock.Anything).Return((*domain.UserPage)(nil), domain.ErrInvalidInput)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdminService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAdminHandler(mockService)
+			router := setupTestRouter()
+			router.GET("/admin/users", func(c *gin.Context) {
+				setAdminContext(c)
+				handler.ListUsers(c)
+			})
+
+			req, _ := http.NewRequest("GET", "/admin/users"+tt.query, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_SetBanned(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		mockSetup    func(*MockAdminService)
+		expectedCode int
+	}{
+		{
+			name: "Successful ban",
+			path: "/admin/users/2/ban",
+			mockSetup: func(m *MockAdminService) {
+				m.On("SetBanned", uint(1), domain.RoleModerator, uint(2), true).
+					Return(&domain.User{ID: 2, Username: "spammer", Banned: true}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Successful unban",
+			path: "/admin/users/2/unban",
+			mockSetup: func(m *MockAdminService) {
+				m.On("SetBanned", uint(1), domain.RoleModerator, uint(2), false).
+					Return(&domain.User{ID: 2, Username: "spammer"}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Insufficient role",
+			path: "/admin/users/3/ban",
+			mockSetup: func(m *MockAdminService) {
+				m.On("SetBanned", uint(1), domain.RoleModerator, uint(3), true).
+					Return((*domain.User)(nil), domain.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name: "User not found",
+			path: "/admin/users/99/ban",
+			mockSetup: func(m *MockAdminService) {
+				m.On("SetBanned", uint(1), domain.RoleModerator, uint(99), true).
+					Return((*domain.User)(nil), domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid ID",
+			path:         "/admin/users/abc/ban",
+			mockSetup:    func(m *MockAdminService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdminService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAdminHandler(mockService)
+			router := setupTestRouter()
+			router.POST("/admin/users/:id/ban", func(c *gin.Context) {
+				setAdminContext(c)
+				handler.SetBanned(true)(c)
+			})
+			router.POST("/admin/users/:id/unban", func(c *gin.Context) {
+				setAdminContext(c)
+				handler.SetBanned(false)(c)
+			})
+
+			req, _ := http.NewRequest("POST", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdminHandler_ArchiveAd(t *testing.T) {
+	tests := []struct {
+		name         string
+		mockSetup    func(*MockAdminService)
+		expectedCode int
+	}{
+		{
+			name: "Successful archive",
+			mockSetup: func(m *MockAdminService) {
+				m.On("ArchiveAd", uint(5)).
+					Return(&domain.Advertisement{ID: 5, UserID: 7, Status: domain.AdStatusArchived}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Already archived",
+			mockSetup: func(m *MockAdminService) {
+				m.On("ArchiveAd", uint(5)).
+					Return((*domain.Advertisement)(nil), domain.ErrInvalidStatusTransition)
+			},
+			expectedCode: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdminService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAdminHandler(mockService)
+			router := setupTestRouter()
+			router.POST("/admin/ads/:id/archive", func(c *gin.Context) {
+				setAdminContext(c)
+				handler.ArchiveAd(c)
+			})
+
+			req, _ := http.NewRequest("POST", "/admin/ads/5/archive", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
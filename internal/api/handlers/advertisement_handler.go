@@ -2,7 +2,10 @@ package handlers
 
 import (
 	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/imagecheck"
 	"github.com/keenetic29/vk-internship/pkg/logger"
+	"github.com/keenetic29/vk-internship/pkg/safehttp"
+	"context"
 	"errors"
 	"strings"
 	"time"
@@ -16,16 +19,32 @@ import (
 const (
 	MaxImageSize       = 10 * 1024 * 1024 // 10MB
 	ImageCheckTimeout  = 2 * time.Second
+	ImageMaxRedirects  = 3
 	AllowedImageTypes  = "image/jpeg,image/png,image/webp"
+	MinImageWidth      = 100
+	MinImageHeight     = 100
+	MaxImageWidth      = 10000
+	MaxImageHeight     = 10000
 )
 
 type HTTPClient interface {
-    Head(url string) (*http.Response, error)
+	Do(req *http.Request) (*http.Response, error)
 }
 
 type AdvertisementService interface {
-	CreateAd(userID uint, title, description, imageURL string, price float64) (*domain.Advertisement, error)
-	GetAds(page, limit int, sortBy, order string, minPrice, maxPrice float64) ([]domain.Advertisement, error)
+	CreateAd(userID uint, title, description string, imageURLs []string, price float64, categoryID uint, status domain.AdStatus) (*domain.Advertisement, error)
+	GetAd(id, viewerID uint) (*domain.Advertisement, error)
+	UpdateAd(userID, adID uint, update domain.AdvertisementUpdate) (*domain.Advertisement, error)
+	DeleteAd(userID, adID uint) error
+	ChangeStatus(userID, adID uint, status domain.AdStatus) (*domain.Advertisement, error)
+	GetAds(filter domain.AdFilter) (*domain.AdPage, error)
+	AddImage(userID, adID uint, url string) (*domain.Advertisement, error)
+	RemoveImage(userID, adID, imageID uint) (*domain.Advertisement, *domain.AdImage, error)
+	ReorderImages(userID, adID uint, imageIDs []uint) (*domain.Advertisement, error)
+	SetCoverImage(userID, adID, imageID uint) (*domain.Advertisement, error)
+	AddFavorite(userID, adID uint) error
+	RemoveFavorite(userID, adID uint) error
+	GetFavorites(userID uint, filter domain.AdFilter) (*domain.AdPage, error)
 }
 
 type AdvertisementHandler struct {
@@ -36,7 +55,11 @@ type AdvertisementHandler struct {
 func NewAdvertisementHandler(adService AdvertisementService) *AdvertisementHandler {
 	return &AdvertisementHandler{
         adService: adService,
-        httpClient: &http.Client{Timeout: ImageCheckTimeout},
+        // ссылки присылают пользователи, поэтому внутренние адреса недоступны (защита от SSRF)
+        httpClient: safehttp.NewClient(safehttp.Options{
+            Timeout:      ImageCheckTimeout,
+            MaxRedirects: ImageMaxRedirects,
+        }),
     }
 }
 
@@ -48,41 +71,208 @@ func (h *AdvertisementHandler) SetHTTPClient(client HTTPClient) {
 type CreateAdRequest struct {
 	Title       string  `json:"title" binding:"required"`
 	Description string  `json:"description" binding:"required"`
-	ImageURL    string  `json:"image_url" binding:"required,url"`
+	// необязательно: изображение можно загрузить позже через POST /ads/{id}/images
+	ImageURL    string  `json:"image_url" binding:"omitempty,url"`
+	// галерея, не больше domain.MaxAdImages; image_url, если задан, идёт первым и становится обложкой
+	Images      []string `json:"images" binding:"omitempty,max=10,dive,url"`
 	Price       float64 `json:"price" binding:"required"`
+	CategoryID  uint    `json:"category_id" binding:"required"`
+	// draft или published (по умолчанию)
+	Status domain.AdStatus `json:"status"`
+}
+
+type UpdateAdRequest struct {
+	Title       *string  `json:"title"`
+	Description *string  `json:"description"`
+	ImageURL    *string  `json:"image_url" binding:"omitempty,url"`
+	Price       *float64 `json:"price"`
+	CategoryID  *uint    `json:"category_id"`
+}
+
+type responseAd struct {
+	ID          uint            `json:"id"`
+	Title       string          `json:"title"`
+	Description string          `json:"description"`
+	ImageURL    string          `json:"image_url"`
+	Price       float64         `json:"price"`
+	AuthorLogin string          `json:"author_login"`
+	// средняя оценка автора по отзывам, null - отзывов ещё нет
+	SellerRating *float64 `json:"seller_rating"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Status      domain.AdStatus `json:"status"`
+	CategoryID  uint            `json:"category_id"`
+	IsOwner     *bool           `json:"is_owner,omitempty"`
+	IsFavorite  *bool           `json:"is_favorite,omitempty"`
+	// причина, по которой объявление больше недоступно: deleted или archived
+	Unavailable string `json:"unavailable,omitempty"`
+	// галерея отдаётся только в карточке объявления, в списках - только обложка (image_url)
+	Images []responseAdImage `json:"images,omitempty"`
+	// миниатюры обложки по размерам: small, medium, large
+	Thumbnails map[string]string `json:"thumbnails,omitempty"`
+}
+
+type responseAdImage struct {
+	ID       uint   `json:"id"`
+	URL      string `json:"url"`
+	Position int    `json:"position"`
+	IsCover  bool   `json:"is_cover"`
+}
+
+func newResponseAd(ad domain.Advertisement, currentUserID uint) responseAd {
+	item := responseAd{
+		ID:          ad.ID,
+		Title:       ad.Title,
+		Description: ad.Description,
+		ImageURL:    ad.ImageURL,
+		Price:       ad.Price,
+		AuthorLogin: ad.User.Username,
+		SellerRating: ad.User.Rating(),
+		CreatedAt:   ad.CreatedAt,
+		Status:      ad.Status,
+		CategoryID:  ad.CategoryID,
+		Unavailable: ad.UnavailableReason(),
+		Thumbnails:  thumbnailURLs(ad),
+	}
+
+	if currentUserID != 0 {
+		isOwner := ad.UserID == currentUserID
+		item.IsOwner = &isOwner
+		isFavorite := ad.IsFavorite
+		item.IsFavorite = &isFavorite
+	}
+
+	for _, image := range ad.Images {
+		item.Images = append(item.Images, responseAdImage{
+			ID:       image.ID,
+			URL:      image.URL,
+			Position: image.Position,
+			IsCover:  image.IsCover,
+		})
+	}
+
+	return item
+}
+
+// Конверт ответа со списком объявлений
+type responseAdPage struct {
+	Items      []responseAd `json:"items"`
+	Total      int64        `json:"total"`
+	Page       int          `json:"page"`
+	Limit      int          `json:"limit"`
+	NextCursor *string      `json:"next_cursor"`
+}
+
+func newResponseAdPage(page *domain.AdPage, currentUserID uint) responseAdPage {
+	response := responseAdPage{
+		Items: make([]responseAd, 0, len(page.Items)),
+		Total: page.Total,
+		Page:  page.Page,
+		Limit: page.Limit,
+	}
+
+	for _, ad := range page.Items {
+		response.Items = append(response.Items, newResponseAd(ad, currentUserID))
+	}
+
+	if page.NextCursor != "" {
+		response.NextCursor = &page.NextCursor
+	}
+
+	return response
+}
+
+// currentUserID возвращает ID пользователя, выставленный middleware, или 0 для анонима
+func currentUserID(c *gin.Context) uint {
+	if userID, exists := c.Get("userID"); exists {
+		if uid, ok := userID.(uint); ok {
+			return uid
+		}
+	}
+	return 0
+}
+
+func parseIDParam(c *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(name), 10, 64)
+	if err != nil || id == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid " + name})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// errorStatus переводит ошибки сервисов в HTTP статус
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	case errors.Is(err, domain.ErrInvalidStatusTransition), errors.Is(err, domain.ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// listErrorStatus - для выборок: всё, кроме ошибок параметров и доступа, считается ошибкой сервера
+func listErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidInput):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrForbidden):
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// imageCheckMessages - ответы клиенту на ошибки проверки изображения
+var imageCheckMessages = []struct {
+	err     error
+	message string
+}{
+	{imagecheck.ErrUnsupportedType, "only JPEG, PNG and WEBP images are allowed"},
+	{imagecheck.ErrTypeMismatch, "image content does not match its content type"},
+	{imagecheck.ErrTooLarge, "image size exceeds maximum limit"},
+	{imagecheck.ErrDimensions, "image dimensions must be between 100x100 and 10000x10000 pixels"},
+	{imagecheck.ErrMalformed, "unable to read image"},
 }
 
 func (h *AdvertisementHandler) validateImageURL(imageURL string) error {
 	logger.Log.Debug("Validating image URL", "url", imageURL)
 
-	resp, err := h.httpClient.Head(imageURL)
+	// HEAD и GET делят общий бюджет времени
+	ctx, cancel := context.WithTimeout(context.Background(), 2*ImageCheckTimeout)
+	defer cancel()
+
+	result, err := imagecheck.Check(ctx, h.httpClient, imageURL, imagecheck.Limits{
+		AllowedTypes: strings.Split(AllowedImageTypes, ","),
+		MaxSize:      MaxImageSize,
+		MinWidth:     MinImageWidth,
+		MinHeight:    MinImageHeight,
+		MaxWidth:     MaxImageWidth,
+		MaxHeight:    MaxImageHeight,
+	})
 	if err != nil {
-		logger.Log.Warn("Image URL validation failed", 
+		logger.Log.Warn("Image URL validation failed",
 			"error", err,
 			"url", imageURL,
 		)
+		for _, m := range imageCheckMessages {
+			if errors.Is(err, m.err) {
+				return errors.New(m.message)
+			}
+		}
 		return errors.New("invalid image URL or unable to verify")
 	}
-	defer resp.Body.Close()
 
-	contentType := resp.Header.Get("Content-Type")
-	if !strings.Contains(AllowedImageTypes, contentType) {
-		logger.Log.Warn("Unsupported image format", 
-			"content_type", contentType,
-			"allowed_types", AllowedImageTypes,
-		)
-		return errors.New("only JPEG, PNG and WEBP images are allowed")
-	}
-
-	if size := resp.ContentLength; size > MaxImageSize {
-		logger.Log.Warn("Image size exceeds limit",
-			"size", size,
-			"max_allowed", MaxImageSize,
-		)
-		return errors.New("image size exceeds maximum limit")
-	}
-
-	logger.Log.Debug("Image URL validation successful")
+	logger.Log.Debug("Image URL validation successful",
+		"content_type", result.ContentType,
+		"width", result.Width,
+		"height", result.Height,
+	)
 
 	return nil
 }
@@ -116,25 +306,39 @@ func (h *AdvertisementHandler) CreateAd(c *gin.Context) {
 		"title_length", len(req.Title),
 		"description_length", len(req.Description),
 		"price", req.Price,
+		"category_id", req.CategoryID,
 	)
 
-	if err := h.validateImageURL(req.ImageURL); err != nil {
-		logger.Log.Warn("Image validation failed",
-			"error", err,
-			"user_id", userID,
-			"image_url", req.ImageURL,
-		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	var imageURLs []string
+	if req.ImageURL != "" {
+		imageURLs = append(imageURLs, req.ImageURL)
+	}
+	imageURLs = append(imageURLs, req.Images...)
+
+	if len(imageURLs) > domain.MaxAdImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many images"})
 		return
 	}
 
-	ad, err := h.adService.CreateAd(userID.(uint), req.Title, req.Description, req.ImageURL, req.Price)
+	for _, imageURL := range imageURLs {
+		if err := h.validateImageURL(imageURL); err != nil {
+			logger.Log.Warn("Image validation failed",
+				"error", err,
+				"user_id", userID,
+				"image_url", imageURL,
+			)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ad, err := h.adService.CreateAd(userID.(uint), req.Title, req.Description, imageURLs, req.Price, req.CategoryID, req.Status)
 	if err != nil {
 		logger.Log.Error("Failed to create advertisement",
 			"error", err,
 			"user_id", userID,
 		)
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
@@ -144,87 +348,283 @@ func (h *AdvertisementHandler) CreateAd(c *gin.Context) {
 		"title", ad.Title,
 	)
 
-	ad.IsOwner = true
-	c.JSON(http.StatusCreated, ad)
+	c.JSON(http.StatusCreated, newResponseAd(*ad, userID.(uint)))
 }
 
-func (h *AdvertisementHandler) GetAds(c *gin.Context) {
-	logger.Log.Info("GetAds request started",
-		"path", c.Request.URL.Path,
-		"query", c.Request.URL.RawQuery,
-	)
-
+// parseAdFilter разбирает общие для списков объявлений параметры: пагинацию, сортировку,
+// поиск и фильтры. При ошибке ответ уже отправлен.
+func parseAdFilter(c *gin.Context) (domain.AdFilter, bool) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
-	sortBy := c.DefaultQuery("sort_by", "created_at")
+	query := c.Query("q")
+	sortBy := c.Query("sort_by")
+	if sortBy == "" {
+		// при поиске по умолчанию сортируем по релевантности
+		sortBy = "created_at"
+		if query != "" {
+			sortBy = "relevance"
+		}
+	}
 	order := c.DefaultQuery("order", "desc")
+	cursor := c.Query("cursor")
+	author := c.Query("author")
 	minPrice, _ := strconv.ParseFloat(c.Query("min_price"), 64)
 	maxPrice, _ := strconv.ParseFloat(c.Query("max_price"), 64)
 
-	logger.Log.Debug("GetAds query parameters",
+	var categoryID uint
+	if raw := c.Query("category_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid category_id"})
+			return domain.AdFilter{}, false
+		}
+		categoryID = uint(id)
+	}
+
+	logger.Log.Debug("Advertisement list query parameters",
 		"page", page,
 		"limit", limit,
 		"sort_by", sortBy,
 		"order", order,
 		"min_price", minPrice,
 		"max_price", maxPrice,
+		"q", query,
+		"category_id", categoryID,
+		"cursor", cursor,
+		"author", author,
+	)
+
+	return domain.AdFilter{
+		Page:     page,
+		Limit:    limit,
+		SortBy:   sortBy,
+		Order:    order,
+		MinPrice: minPrice,
+		MaxPrice: maxPrice,
+		Query:    query,
+		Author:   author,
+
+		CategoryID: categoryID,
+		Cursor:     cursor,
+		ViewerID:   currentUserID(c),
+	}, true
+}
+
+func (h *AdvertisementHandler) GetAds(c *gin.Context) {
+	logger.Log.Info("GetAds request started",
+		"path", c.Request.URL.Path,
+		"query", c.Request.URL.RawQuery,
 	)
 
-	ads, err := h.adService.GetAds(page, limit, sortBy, order, minPrice, maxPrice)
+	filter, ok := parseAdFilter(c)
+	if !ok {
+		return
+	}
+	status := domain.AdStatus(c.Query("status"))
+
+	userID := currentUserID(c)
+	if userID != 0 {
+		logger.Log.Debug("User authenticated",
+			"user_id", userID,
+		)
+	}
+
+	// фильтр по статусу показывает только собственные объявления
+	if status != "" {
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "status filter requires authorization"})
+			return
+		}
+		if !status.Valid() {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown status"})
+			return
+		}
+		filter.Status = status
+		filter.UserID = userID
+	}
+
+	result, err := h.adService.GetAds(filter)
 	if err != nil {
 		logger.Log.Error("Failed to get advertisements",
 			"error", err,
 			"query", c.Request.URL.RawQuery,
 		)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := newResponseAdPage(result, userID)
+
+	logger.Log.Info("GetAds request completed",
+		"ads_count", len(response.Items),
+		"total", response.Total,
+		"page", response.Page,
+	)
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetUserAds - опубликованные объявления одного автора, с теми же параметрами, что и GetAds (кроме status)
+func (h *AdvertisementHandler) GetUserAds(c *gin.Context) {
+	filter, ok := parseAdFilter(c)
+	if !ok {
+		return
+	}
+	filter.Author = c.Param("username")
+
+	result, err := h.adService.GetAds(filter)
+	if err != nil {
+		logger.Log.Error("Failed to get user advertisements",
+			"error", err,
+			"author", filter.Author,
+		)
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseAdPage(result, currentUserID(c)))
+}
+
+func (h *AdvertisementHandler) GetAd(c *gin.Context) {
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	ad, err := h.adService.GetAd(adID, currentUserID(c))
+	if err != nil {
+		logger.Log.Warn("Failed to get advertisement",
+			"error", err,
+			"ad_id", adID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseAd(*ad, currentUserID(c)))
+}
+
+func (h *AdvertisementHandler) UpdateAd(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req UpdateAdRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Log.Error("Invalid request body",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-    var currentUserID uint
-    if userID, exists := c.Get("userID"); exists {
-        if uid, ok := userID.(uint); ok {
-			logger.Log.Debug("User authenticated",
-				"user_id", currentUserID,
+	if req.ImageURL != nil {
+		if err := h.validateImageURL(*req.ImageURL); err != nil {
+			logger.Log.Warn("Image validation failed",
+				"error", err,
+				"user_id", userID,
+				"image_url", *req.ImageURL,
 			)
-            currentUserID = uid
-        }
-    }
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
 
-    type responseAd struct {
-        ID          uint      `json:"id"`
-        Title       string    `json:"title"`
-        Description string    `json:"description"`
-        ImageURL    string    `json:"image_url"`
-        Price       float64   `json:"price"`
-        AuthorLogin string    `json:"author_login"`
-        CreatedAt   time.Time `json:"created_at"`
-        IsOwner     *bool     `json:"is_owner,omitempty"` 
-    }
+	ad, err := h.adService.UpdateAd(userID, adID, domain.AdvertisementUpdate{
+		Title:       req.Title,
+		Description: req.Description,
+		ImageURL:    req.ImageURL,
+		Price:       req.Price,
+		CategoryID:  req.CategoryID,
+	})
+	if err != nil {
+		logger.Log.Warn("Failed to update advertisement",
+			"error", err,
+			"ad_id", adID,
+			"user_id", userID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
 
-    var response []responseAd
-    for _, ad := range ads {
-        item := responseAd{
-            ID:          ad.ID,
-            Title:       ad.Title,
-            Description: ad.Description,
-            ImageURL:    ad.ImageURL,
-            Price:       ad.Price,
-            AuthorLogin: ad.User.Username,
-            CreatedAt:   ad.CreatedAt,
-        }
-
-        if currentUserID != 0 {
-            isOwner := ad.UserID == currentUserID
-            item.IsOwner = &isOwner 
-        }
-
-        response = append(response, item)
-    }
+	logger.Log.Info("Advertisement updated successfully",
+		"ad_id", ad.ID,
+		"user_id", userID,
+	)
 
-	logger.Log.Info("GetAds request completed",
-		"ads_count", len(response),
-		"page", page,
+	c.JSON(http.StatusOK, newResponseAd(*ad, userID))
+}
+
+// ChangeStatus возвращает обработчик перехода объявления в указанный статус
+func (h *AdvertisementHandler) ChangeStatus(status domain.AdStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID := currentUserID(c)
+		if userID == 0 {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		adID, ok := parseIDParam(c, "id")
+		if !ok {
+			return
+		}
+
+		ad, err := h.adService.ChangeStatus(userID, adID, status)
+		if err != nil {
+			logger.Log.Warn("Failed to change advertisement status",
+				"error", err,
+				"ad_id", adID,
+				"user_id", userID,
+				"status", status,
+			)
+			c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+
+		logger.Log.Info("Advertisement status changed",
+			"ad_id", ad.ID,
+			"user_id", userID,
+			"status", ad.Status,
+		)
+
+		c.JSON(http.StatusOK, newResponseAd(*ad, userID))
+	}
+}
+
+func (h *AdvertisementHandler) DeleteAd(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.adService.DeleteAd(userID, adID); err != nil {
+		logger.Log.Warn("Failed to delete advertisement",
+			"error", err,
+			"ad_id", adID,
+			"user_id", userID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Advertisement deleted successfully",
+		"ad_id", adID,
+		"user_id", userID,
 	)
-	
-    c.JSON(http.StatusOK, response)
+
+	c.Status(http.StatusNoContent)
 }
\ No newline at end of file
@@ -4,8 +4,11 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"github.com/keenetic29/vk-internship/internal/api/handlers"
 	"github.com/keenetic29/vk-internship/internal/domain"
+	"image"
+	"image/jpeg"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -23,8 +26,8 @@ type MockHTTPClient struct {
 	mock.Mock
 }
 
-func (m *MockHTTPClient) Head(url string) (*http.Response, error) {
-	args := m.Called(url)
+func (m *MockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	args := m.Called(req.Method, req.URL.String())
 	return args.Get(0).(*http.Response), args.Error(1)
 }
 
@@ -32,14 +35,78 @@ type MockAdvertisementService struct {
 	mock.Mock
 }
 
-func (m *MockAdvertisementService) CreateAd(userID uint, title, description, imageURL string, price float64) (*domain.Advertisement, error) {
-	args := m.Called(userID, title, description, imageURL, price)
+func (m *MockAdvertisementService) CreateAd(userID uint, title, description string, imageURLs []string, price float64, categoryID uint, status domain.AdStatus) (*domain.Advertisement, error) {
+	args := m.Called(userID, title, description, imageURLs, price, categoryID, status)
 	return args.Get(0).(*domain.Advertisement), args.Error(1)
 }
 
-func (m *MockAdvertisementService) GetAds(page, limit int, sortBy, order string, minPrice, maxPrice float64) ([]domain.Advertisement, error) {
-	args := m.Called(page, limit, sortBy, order, minPrice, maxPrice)
-	return args.Get(0).([]domain.Advertisement), args.Error(1)
+func (m *MockAdvertisementService) GetAd(id, viewerID uint) (*domain.Advertisement, error) {
+	args := m.Called(id, viewerID)
+	return args.Get(0).(*domain.Advertisement), args.Error(1)
+}
+
+func (m *MockAdvertisementService) UpdateAd(userID, adID uint, update domain.AdvertisementUpdate) (*domain.Advertisement, error) {
+	args := m.Called(userID, adID, update)
+	return args.Get(0).(*domain.Advertisement), args.Error(1)
+}
+
+func (m *MockAdvertisementService) DeleteAd(userID, adID uint) error {
+	args := m.Called(userID, adID)
+	return args.Error(0)
+}
+
+func (m *MockAdvertisementService) ChangeStatus(userID, adID uint, status domain.AdStatus) (*domain.Advertisement, error) {
+	args := m.Called(userID, adID, status)
+	return args.Get(0).(*domain.Advertisement), args.Error(1)
+}
+
+func (m *MockAdvertisementService) GetAds(filter domain.AdFilter) (*domain.AdPage, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*domain.AdPage), args.Error(1)
+}
+
+func (m *MockAdvertisementService) AddImage(userID, adID uint, url string) (*domain.Advertisement, error) {
+	args := m.Called(userID, adID, url)
+	return args.Get(0).(*domain.Advertisement), args.Error(1)
+}
+
+func (m *MockAdvertisementService) RemoveImage(userID, adID, imageID uint) (*domain.Advertisement, *domain.AdImage, error) {
+	args := m.Called(userID, adID, imageID)
+	return args.Get(0).(*domain.Advertisement), args.Get(1).(*domain.AdImage), args.Error(2)
+}
+
+func (m *MockAdvertisementService) ReorderImages(userID, adID uint, imageIDs []uint) (*domain.Advertisement, error) {
+	args := m.Called(userID, adID, imageIDs)
+	return args.Get(0).(*domain.Advertisement), args.Error(1)
+}
+
+func (m *MockAdvertisementService) SetCoverImage(userID, adID, imageID uint) (*domain.Advertisement, error) {
+	args := m.Called(userID, adID, imageID)
+	return args.Get(0).(*domain.Advertisement), args.Error(1)
+}
+
+func (m *MockAdvertisementService) AddFavorite(userID, adID uint) error {
+	args := m.Called(userID, adID)
+	return args.Error(0)
+}
+
+func (m *MockAdvertisementService) RemoveFavorite(userID, adID uint) error {
+	args := m.Called(userID, adID)
+	return args.Error(0)
+}
+
+func (m *MockAdvertisementService) GetFavorites(userID uint, filter domain.AdFilter) (*domain.AdPage, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).(*domain.AdPage), args.Error(1)
+}
+
+// Вспомогательная функция для создания JPEG-изображения заданного размера
+func jpegData(width, height int) []byte {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)), nil); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
 }
 
 // Вспомогательная функция для создания валидного HTTP ответа для изображения
@@ -55,6 +122,19 @@ func createValidImageResponse() *http.Response {
 	}
 }
 
+// Вспомогательная функция для создания ответа на ranged GET с данными изображения
+func createImageDataResponse(contentType string, data []byte) *http.Response {
+	return &http.Response{
+		StatusCode: http.StatusPartialContent,
+		Header: http.Header{
+			"Content-Type":  []string{contentType},
+			"Content-Range": []string{fmt.Sprintf("bytes 0-%d/%d", len(data)-1, len(data))},
+		},
+		ContentLength: int64(len(data)),
+		Body:          io.NopCloser(bytes.NewReader(data)),
+	}
+}
+
 // Вспомогательная функция для создания невалидного HTTP ответа
 func createInvalidImageResponse() *http.Response {
 	return &http.Response{
@@ -68,6 +148,12 @@ func createInvalidImageResponse() *http.Response {
 	}
 }
 
+// mockValidImage настраивает HEAD и GET для ссылки на корректное изображение
+func mockValidImage(hc *MockHTTPClient, url string) {
+	hc.On("Do", http.MethodHead, url).Return(createValidImageResponse(), nil)
+	hc.On("Do", http.MethodGet, url).Return(createImageDataResponse("image/jpeg", jpegData(200, 200)), nil)
+}
+
 func TestAdvertisementHandler_CreateAd(t *testing.T) {
 	tests := []struct {
 		name         string
@@ -75,6 +161,7 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 		setupContext func(*gin.Context)
 		mockSetup    func(*MockAdvertisementService, *MockHTTPClient)
 		expectedCode int
+		expectedBody string
 	}{
 		{
 			name: "Successful ad creation",
@@ -83,13 +170,14 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 				"description": "Test Description",
 				"image_url":   "http://valid.com/image.jpg",
 				"price":       100.50,
+				"category_id": 3,
 			},
 			setupContext: func(c *gin.Context) {
 				c.Set("userID", uint(1))
 			},
 			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
-				hc.On("Head", "http://valid.com/image.jpg").Return(createValidImageResponse(), nil)
-				as.On("CreateAd", uint(1), "Test Ad", "Test Description", "http://valid.com/image.jpg", 100.50).
+				mockValidImage(hc, "http://valid.com/image.jpg")
+				as.On("CreateAd", uint(1), "Test Ad", "Test Description", []string{"http://valid.com/image.jpg"}, 100.50, uint(3), domain.AdStatus("")).
 					Return(&domain.Advertisement{
 						ID:          1,
 						Title:       "Test Ad",
@@ -100,6 +188,45 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 					}, nil)
 			},
 			expectedCode: http.StatusCreated,
+			expectedBody: `"is_owner":true`,
+		},
+		{
+			name: "Ad with gallery",
+			requestBody: map[string]interface{}{
+				"title":       "Test Ad",
+				"description": "Test Description",
+				"image_url":   "http://valid.com/image.jpg",
+				"images":      []string{"http://valid.com/second.jpg"},
+				"price":       100.50,
+				"category_id": 3,
+			},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				mockValidImage(hc, "http://valid.com/image.jpg")
+				mockValidImage(hc, "http://valid.com/second.jpg")
+				as.On("CreateAd", uint(1), "Test Ad", "Test Description", []string{"http://valid.com/image.jpg", "http://valid.com/second.jpg"}, 100.50, uint(3), domain.AdStatus("")).
+					Return(&domain.Advertisement{ID: 1, Title: "Test Ad", Description: "Test Description", Price: 100.50, UserID: 1}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "Ad without image",
+			requestBody: map[string]interface{}{
+				"title":       "Test Ad",
+				"description": "Test Description",
+				"price":       100.50,
+				"category_id": 3,
+			},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("CreateAd", uint(1), "Test Ad", "Test Description", []string(nil), 100.50, uint(3), domain.AdStatus("")).
+					Return(&domain.Advertisement{ID: 1, Title: "Test Ad", Description: "Test Description", Price: 100.50, UserID: 1}, nil)
+			},
+			expectedCode: http.StatusCreated,
 		},
 		{
 			name: "Unauthorized request",
@@ -108,11 +235,26 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 				"description": "Test Description",
 				"image_url":   "http://example.com/image.jpg",
 				"price":       100.50,
+				"category_id": 3,
 			},
 			setupContext: func(c *gin.Context) {},
 			mockSetup:    func(as *MockAdvertisementService, hc *MockHTTPClient) {},
 			expectedCode: http.StatusUnauthorized,
 		},
+		{
+			name: "Missing category",
+			requestBody: map[string]interface{}{
+				"title":       "Test Ad",
+				"description": "Test Description",
+				"image_url":   "http://valid.com/image.jpg",
+				"price":       100.50,
+			},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup:    func(as *MockAdvertisementService, hc *MockHTTPClient) {},
+			expectedCode: http.StatusBadRequest,
+		},
 		{
 			name: "Invalid request body",
 			requestBody: map[string]interface{}{
@@ -120,6 +262,7 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 				"description": "Test Description",
 				"image_url":   "http://example.com/image.jpg",
 				"price":       100.50,
+				"category_id": 3,
 			},
 			setupContext: func(c *gin.Context) {
 				c.Set("userID", uint(1))
@@ -134,12 +277,73 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 				"description": "Test Description",
 				"image_url":   "http://invalid.com/image.jpg",
 				"price":       100.50,
+				"category_id": 3,
+			},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				hc.On("Do", http.MethodHead, "http://invalid.com/image.jpg").Return(createInvalidImageResponse(), nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Host without HEAD support",
+			requestBody: map[string]interface{}{
+				"title":       "Test Ad",
+				"description": "Test Description",
+				"image_url":   "http://nohead.com/image.jpg",
+				"price":       100.50,
+				"category_id": 3,
+			},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				hc.On("Do", http.MethodHead, "http://nohead.com/image.jpg").
+					Return(&http.Response{StatusCode: http.StatusMethodNotAllowed, Body: http.NoBody}, nil)
+				hc.On("Do", http.MethodGet, "http://nohead.com/image.jpg").
+					Return(createImageDataResponse("image/jpeg", jpegData(200, 200)), nil)
+				as.On("CreateAd", uint(1), "Test Ad", "Test Description", []string{"http://nohead.com/image.jpg"}, 100.50, uint(3), domain.AdStatus("")).
+					Return(&domain.Advertisement{ID: 1, Title: "Test Ad", UserID: 1}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name: "Image validation failed - content does not match declared type",
+			requestBody: map[string]interface{}{
+				"title":       "Test Ad",
+				"description": "Test Description",
+				"image_url":   "http://liar.com/image.jpg",
+				"price":       100.50,
+				"category_id": 3,
+			},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				hc.On("Do", http.MethodHead, "http://liar.com/image.jpg").Return(createValidImageResponse(), nil)
+				hc.On("Do", http.MethodGet, "http://liar.com/image.jpg").
+					Return(createImageDataResponse("image/jpeg", []byte("<html><body>not an image</body></html>")), nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Image validation failed - dimensions too small",
+			requestBody: map[string]interface{}{
+				"title":       "Test Ad",
+				"description": "Test Description",
+				"image_url":   "http://small.com/image.jpg",
+				"price":       100.50,
+				"category_id": 3,
 			},
 			setupContext: func(c *gin.Context) {
 				c.Set("userID", uint(1))
 			},
 			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
-				hc.On("Head", "http://invalid.com/image.jpg").Return(createInvalidImageResponse(), nil)
+				hc.On("Do", http.MethodHead, "http://small.com/image.jpg").Return(createValidImageResponse(), nil)
+				hc.On("Do", http.MethodGet, "http://small.com/image.jpg").
+					Return(createImageDataResponse("image/jpeg", jpegData(16, 16)), nil)
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -150,12 +354,14 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 				"description": "Test Description",
 				"image_url":   "http://error.com/image.jpg",
 				"price":       100.50,
+				"category_id": 3,
 			},
 			setupContext: func(c *gin.Context) {
 				c.Set("userID", uint(1))
 			},
 			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
-				hc.On("Head", "http://error.com/image.jpg").Return((*http.Response)(nil), errors.New("connection error"))
+				hc.On("Do", http.MethodHead, "http://error.com/image.jpg").Return((*http.Response)(nil), errors.New("connection error"))
+				hc.On("Do", http.MethodGet, "http://error.com/image.jpg").Return((*http.Response)(nil), errors.New("connection error"))
 			},
 			expectedCode: http.StatusBadRequest,
 		},
@@ -166,15 +372,33 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 				"description": "Test Description",
 				"image_url":   "http://valid.com/image.jpg",
 				"price":       100.50,
+				"category_id": 3,
 			},
 			setupContext: func(c *gin.Context) {
 				c.Set("userID", uint(1))
 			},
 			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
-				hc.On("Head", "http://valid.com/image.jpg").Return(createValidImageResponse(), nil)
-				as.On("CreateAd", uint(1), "Test Ad", "Test Description", "http://valid.com/image.jpg", 100.50).
+				mockValidImage(hc, "http://valid.com/image.jpg")
+				as.On("CreateAd", uint(1), "Test Ad", "Test Description", []string{"http://valid.com/image.jpg"}, 100.50, uint(3), domain.AdStatus("")).
 					Return((*domain.Advertisement)(nil), errors.New("service error"))
 			},
+			expectedCode: http.StatusInternalServerError,
+		},
+		{
+			name: "Unknown category",
+			requestBody: map[string]interface{}{
+				"title":       "Test Ad",
+				"description": "Test Description",
+				"price":       100.50,
+				"category_id": 99,
+			},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("CreateAd", uint(1), "Test Ad", "Test Description", []string(nil), 100.50, uint(99), domain.AdStatus("")).
+					Return((*domain.Advertisement)(nil), fmt.Errorf("%w: category not found", domain.ErrInvalidInput))
+			},
 			expectedCode: http.StatusBadRequest,
 		},
 	}
@@ -202,6 +426,9 @@ func TestAdvertisementHandler_CreateAd(t *testing.T) {
 			router.ServeHTTP(w, req)
 
 			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
 			mockService.AssertExpectations(t)
 			mockHTTPClient.AssertExpectations(t)
 		})
@@ -219,8 +446,10 @@ func TestAdvertisementHandler_GetAds(t *testing.T) {
 			Price:       100.50,
 			UserID:      1,
 			User: domain.User{
-				ID:       1,
-				Username: "user1",
+				ID:          1,
+				Username:    "user1",
+				RatingSum:   9,
+				RatingCount: 2,
 			},
 			CreatedAt: now,
 		},
@@ -239,6 +468,8 @@ func TestAdvertisementHandler_GetAds(t *testing.T) {
 		},
 	}
 
+	testPage := &domain.AdPage{Items: testAds, Total: 2, Page: 1, Limit: 10}
+
 	tests := []struct {
 		name         string
 		queryParams  string
@@ -252,10 +483,10 @@ func TestAdvertisementHandler_GetAds(t *testing.T) {
 			queryParams: "",
 			setupContext: func(c *gin.Context) {},
 			mockSetup: func(m *MockAdvertisementService) {
-				m.On("GetAds", 1, 10, "created_at", "desc", 0.0, 0.0).Return(testAds, nil)
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc"}).Return(testPage, nil)
 			},
 			expectedCode: http.StatusOK,
-			expectedBody: `[{"id":1,"title":"Ad 1","description":"Description 1","image_url":"http://example.com/image1.jpg","price":100.5,"author_login":"user1","created_at":"`,
+			expectedBody: `{"items":[{"id":1,"title":"Ad 1","description":"Description 1","image_url":"http://example.com/image1.jpg","price":100.5,"author_login":"user1","seller_rating":4.5,"created_at":"`,
 		},
 		{
 			name:        "Successful get ads with auth",
@@ -264,11 +495,30 @@ func TestAdvertisementHandler_GetAds(t *testing.T) {
 				c.Set("userID", uint(1))
 			},
 			mockSetup: func(m *MockAdvertisementService) {
-				m.On("GetAds", 1, 10, "created_at", "desc", 0.0, 0.0).Return(testAds, nil)
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc", ViewerID: 1}).Return(testPage, nil)
 			},
 			expectedCode: http.StatusOK,
 			expectedBody: `"is_owner":true`,
 		},
+		{
+			name:         "Thumbnail links next to image_url",
+			queryParams:  "",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc"}).Return(testPage, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"thumbnails":{"large":"/images/1/large","medium":"/images/1/medium","small":"/images/1/small"}`,
+		},
+		{
+			name:         "Author filter",
+			queryParams:  "?author=user1",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc", Author: "user1"}).Return(testPage, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
 		{
 			name:        "With query parameters",
 			queryParams: "?page=2&limit=5&sort_by=price&order=asc&min_price=100&max_price=300",
@@ -276,7 +526,7 @@ func TestAdvertisementHandler_GetAds(t *testing.T) {
 				c.Set("userID", uint(1))
 			},
 			mockSetup: func(m *MockAdvertisementService) {
-				m.On("GetAds", 2, 5, "price", "asc", 100.0, 300.0).Return(testAds, nil)
+				m.On("GetAds", domain.AdFilter{Page: 2, Limit: 5, SortBy: "price", Order: "asc", MinPrice: 100, MaxPrice: 300, ViewerID: 1}).Return(testPage, nil)
 			},
 			expectedCode: http.StatusOK,
 			expectedBody: `"is_owner":true`,
@@ -288,12 +538,99 @@ func TestAdvertisementHandler_GetAds(t *testing.T) {
 				c.Set("userID", uint(1))
 			},
 			mockSetup: func(m *MockAdvertisementService) {
-				m.On("GetAds", 1, 10, "created_at", "desc", 0.0, 0.0).
-					Return([]domain.Advertisement{}, errors.New("service error"))
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc", ViewerID: 1}).
+					Return((*domain.AdPage)(nil), errors.New("service error"))
 			},
 			expectedCode: http.StatusInternalServerError,
 			expectedBody: `{"error":"service error"}`,
 		},
+		{
+			name:        "Status filter applies to own ads",
+			queryParams: "?status=sold",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc", Status: domain.AdStatusSold, UserID: 1, ViewerID: 1}).
+					Return(&domain.AdPage{Items: testAds[:1], Total: 1, Page: 1, Limit: 10}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Search defaults to relevance sort",
+			queryParams:  "?q=%D0%B2%D0%B5%D0%BB%D0%BE%D1%81%D0%B8%D0%BF%D0%B5%D0%B4",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "relevance", Order: "desc", Query: "велосипед"}).
+					Return(testPage, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid search query",
+			queryParams:  "?q=iphone",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "relevance", Order: "desc", Query: "iphone"}).
+					Return((*domain.AdPage)(nil), domain.ErrInvalidInput)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Envelope with next cursor",
+			queryParams:  "?limit=1&cursor=abc",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 1, SortBy: "created_at", Order: "desc", Cursor: "abc"}).
+					Return(&domain.AdPage{Items: testAds[:1], Total: 2, Limit: 1, NextCursor: "next"}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"total":2,"page":0,"limit":1,"next_cursor":"next"}`,
+		},
+		{
+			name:         "Empty page",
+			queryParams:  "?page=5",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 5, Limit: 10, SortBy: "created_at", Order: "desc"}).
+					Return(&domain.AdPage{Total: 2, Page: 5, Limit: 10}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[],"total":2,"page":5,"limit":10,"next_cursor":null}`,
+		},
+		{
+			name:         "Category filter",
+			queryParams:  "?category_id=2",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc", CategoryID: 2}).
+					Return(testPage, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Invalid category filter",
+			queryParams:  "?category_id=abc",
+			setupContext: func(c *gin.Context) {},
+			mockSetup:    func(m *MockAdvertisementService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Status filter requires auth",
+			queryParams:  "?status=sold",
+			setupContext: func(c *gin.Context) {},
+			mockSetup:    func(m *MockAdvertisementService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "Unknown status",
+			queryParams: "?status=deleted",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup:    func(m *MockAdvertisementService) {},
+			expectedCode: http.StatusBadRequest,
+		},
 	}
 
 	for _, tt := range tests {
@@ -324,4 +661,396 @@ func TestAdvertisementHandler_GetAds(t *testing.T) {
 			mockService.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAdvertisementHandler_GetAd(t *testing.T) {
+	ad := &domain.Advertisement{
+		ID:          1,
+		Title:       "Ad 1",
+		Description: "Description 1",
+		ImageURL:    "http://example.com/image1.jpg",
+		Price:       100.50,
+		UserID:      1,
+		User:        domain.User{ID: 1, Username: "user1"},
+	}
+
+	tests := []struct {
+		name         string
+		path         string
+		setupContext func(*gin.Context)
+		mockSetup    func(*MockAdvertisementService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "Successful get ad",
+			path:         "/ads/1",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(0)).Return(ad, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"author_login":"user1","seller_rating":null`,
+		},
+		{
+			name: "Owner sees is_owner",
+			path: "/ads/1",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return(ad, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"is_owner":true`,
+		},
+		{
+			name:         "Not found",
+			path:         "/ads/2",
+			setupContext: func(c *gin.Context) {},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(2), uint(0)).Return((*domain.Advertisement)(nil), domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid id",
+			path:         "/ads/abc",
+			setupContext: func(c *gin.Context) {},
+			mockSetup:    func(m *MockAdvertisementService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAdvertisementHandler(mockService)
+
+			router := setupTestRouter()
+			router.GET("/ads/:id", func(c *gin.Context) {
+				tt.setupContext(c)
+				handler.GetAd(c)
+			})
+
+			req, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdvertisementHandler_UpdateAd(t *testing.T) {
+	newTitle := "New title"
+	newImage := "http://valid.com/new.jpg"
+
+	tests := []struct {
+		name         string
+		requestBody  interface{}
+		setupContext func(*gin.Context)
+		mockSetup    func(*MockAdvertisementService, *MockHTTPClient)
+		expectedCode int
+	}{
+		{
+			name:        "Successful update",
+			requestBody: map[string]interface{}{"title": newTitle},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("UpdateAd", uint(1), uint(1), domain.AdvertisementUpdate{Title: &newTitle}).
+					Return(&domain.Advertisement{ID: 1, Title: newTitle, UserID: 1}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:        "New image URL is validated",
+			requestBody: map[string]interface{}{"image_url": newImage},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				hc.On("Do", http.MethodHead, newImage).Return(createInvalidImageResponse(), nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Unauthorized request",
+			requestBody:  map[string]interface{}{"title": newTitle},
+			setupContext: func(c *gin.Context) {},
+			mockSetup:    func(as *MockAdvertisementService, hc *MockHTTPClient) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:        "Not the owner",
+			requestBody: map[string]interface{}{"title": newTitle},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(2))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("UpdateAd", uint(2), uint(1), domain.AdvertisementUpdate{Title: &newTitle}).
+					Return((*domain.Advertisement)(nil), domain.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:        "Validation error",
+			requestBody: map[string]interface{}{"title": newTitle},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("UpdateAd", uint(1), uint(1), domain.AdvertisementUpdate{Title: &newTitle}).
+					Return((*domain.Advertisement)(nil), fmt.Errorf("%w: title must be between 5 and 100 characters", domain.ErrInvalidInput))
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:        "Internal error",
+			requestBody: map[string]interface{}{"title": newTitle},
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("UpdateAd", uint(1), uint(1), domain.AdvertisementUpdate{Title: &newTitle}).
+					Return((*domain.Advertisement)(nil), errors.New("connection refused"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			mockHTTPClient := new(MockHTTPClient)
+			tt.mockSetup(mockService, mockHTTPClient)
+
+			handler := handlers.NewAdvertisementHandler(mockService)
+			handler.SetHTTPClient(mockHTTPClient)
+
+			router := setupTestRouter()
+			router.PATCH("/ads/:id", func(c *gin.Context) {
+				tt.setupContext(c)
+				handler.UpdateAd(c)
+			})
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("PATCH", "/ads/1", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+			mockHTTPClient.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdvertisementHandler_DeleteAd(t *testing.T) {
+	tests := []struct {
+		name         string
+		setupContext func(*gin.Context)
+		mockSetup    func(*MockAdvertisementService)
+		expectedCode int
+	}{
+		{
+			name: "Successful delete",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("DeleteAd", uint(1), uint(1)).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name: "Not the owner",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(2))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("DeleteAd", uint(2), uint(1)).Return(domain.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name: "Not found",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("DeleteAd", uint(1), uint(1)).Return(domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Unauthorized request",
+			setupContext: func(c *gin.Context) {},
+			mockSetup:    func(m *MockAdvertisementService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAdvertisementHandler(mockService)
+
+			router := setupTestRouter()
+			router.DELETE("/ads/:id", func(c *gin.Context) {
+				tt.setupContext(c)
+				handler.DeleteAd(c)
+			})
+
+			req, _ := http.NewRequest("DELETE", "/ads/1", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdvertisementHandler_ChangeStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		setupContext func(*gin.Context)
+		mockSetup    func(*MockAdvertisementService)
+		expectedCode int
+	}{
+		{
+			name: "Successful transition",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("ChangeStatus", uint(1), uint(1), domain.AdStatusSold).
+					Return(&domain.Advertisement{ID: 1, UserID: 1, Status: domain.AdStatusSold}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Transition not allowed",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(1))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("ChangeStatus", uint(1), uint(1), domain.AdStatusSold).
+					Return((*domain.Advertisement)(nil), domain.ErrInvalidStatusTransition)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name: "Not the owner",
+			setupContext: func(c *gin.Context) {
+				c.Set("userID", uint(2))
+			},
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("ChangeStatus", uint(2), uint(1), domain.AdStatusSold).
+					Return((*domain.Advertisement)(nil), domain.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAdvertisementHandler(mockService)
+
+			router := setupTestRouter()
+			router.POST("/ads/:id/sell", func(c *gin.Context) {
+				tt.setupContext(c)
+				handler.ChangeStatus(domain.AdStatusSold)(c)
+			})
+
+			req, _ := http.NewRequest("POST", "/ads/1/sell", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAdvertisementHandler_CreateAd_BlocksInternalImageURL(t *testing.T) {
+	// сервер с "изображением" во внутренней сети: обращаться к нему валидатор не должен
+	var requested bool
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = true
+		w.Header().Set("Content-Type", "image/jpeg")
+	}))
+	defer internal.Close()
+
+	mockService := new(MockAdvertisementService)
+	handler := handlers.NewAdvertisementHandler(mockService)
+
+	router := setupTestRouter()
+	router.POST("/ads", func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		handler.CreateAd(c)
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"title":       "Test Ad",
+		"description": "Test Description",
+		"image_url":   internal.URL + "/image.jpg",
+		"price":       100.50,
+		"category_id": 3,
+	})
+	req, _ := http.NewRequest("POST", "/ads", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.False(t, requested)
+	mockService.AssertExpectations(t)
+}
+
+func TestAdvertisementHandler_GetUserAds(t *testing.T) {
+	page := &domain.AdPage{Items: []domain.Advertisement{
+		{ID: 1, Title: "Ad 1", UserID: 1, User: domain.User{ID: 1, Username: "user1"}},
+	}, Total: 1, Page: 1, Limit: 10}
+
+	mockService := new(MockAdvertisementService)
+	// status из запроса игнорируется: в публичном списке только опубликованные объявления
+	mockService.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "price", Order: "desc", Author: "user1"}).Return(page, nil)
+	mockService.On("GetAds", domain.AdFilter{Page: 1, Limit: 10, SortBy: "created_at", Order: "desc", Author: "nobody"}).
+		Return(&domain.AdPage{Page: 1, Limit: 10}, nil)
+
+	handler := handlers.NewAdvertisementHandler(mockService)
+	router := setupTestRouter()
+	router.GET("/users/:username/ads", handler.GetUserAds)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/users/user1/ads?sort_by=price&status=draft", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"author_login":"user1"`)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/users/nobody/ads", nil)
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"items":[]`)
+
+	mockService.AssertExpectations(t)
+}
@@ -1,17 +1,22 @@
 package handlers
 
 import (
+	"errors"
 	"github.com/keenetic29/vk-internship/internal/domain"
 	"github.com/keenetic29/vk-internship/pkg/logger"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AuthService interface {
     Register(username, password string) (*domain.User, error)
-    Login(username, password string) (string, error)
-    ValidateToken(token string) (uint, error)
+    Login(username, password string) (*domain.TokenPair, error)
+    Refresh(refreshToken string) (*domain.TokenPair, error)
+    Logout(accessToken, refreshToken string) error
+    ValidateToken(token string) (uint, domain.Role, error)
+    TokenExpiresAt(token string) (time.Time, error)
 }
 
 type AuthHandler struct {
@@ -92,21 +97,96 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		"username", req.Username,
 	)
 
-	token, err := h.authService.Login(req.Username, req.Password)
+	tokens, err := h.authService.Login(req.Username, req.Password)
 	if err != nil {
 		logger.Log.Warn("Login failed",
 			"error", err.Error(),
 			"username", req.Username,
 		)
-		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		c.JSON(authErrorStatus(err), gin.H{"error": err.Error()})
 		return
 	}
 
 	logger.Log.Info("User logged in successfully",
 		"username", req.Username,
-		"token_prefix", token[:10]+"...", // логирую только префикс токена
+		"token_prefix", tokens.AccessToken[:10]+"...", // логирую только префикс токена
 	)
 
-	c.Header("Authorization", token)
-	c.JSON(http.StatusOK, gin.H{"token": token})
+	c.Header("Authorization", tokens.AccessToken)
+	c.JSON(http.StatusOK, newTokenResponse(tokens))
+}
+
+func authErrorStatus(err error) int {
+	if errors.Is(err, domain.ErrUserBanned) {
+		return http.StatusForbidden
+	}
+	return http.StatusUnauthorized
+}
+
+func newTokenResponse(tokens *domain.TokenPair) gin.H {
+	return gin.H{
+		"token":         tokens.AccessToken,
+		"refresh_token": tokens.RefreshToken,
+		"expires_in":    int(tokens.ExpiresIn.Seconds()),
+	}
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	logger.Log.Info("Refresh request received",
+		"path", c.Request.URL.Path,
+		"client_ip", c.ClientIP(),
+	)
+
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tokens, err := h.authService.Refresh(req.RefreshToken)
+	if err != nil {
+		logger.Log.Warn("Token refresh failed",
+			"error", err.Error(),
+			"client_ip", c.ClientIP(),
+		)
+		c.JSON(authErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Authorization", tokens.AccessToken)
+	c.JSON(http.StatusOK, newTokenResponse(tokens))
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	// тело необязательно: без refresh-токена отзывается только текущий access-токен
+	var req LogoutRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if err := h.authService.Logout(c.GetHeader("Authorization"), req.RefreshToken); err != nil {
+		logger.Log.Error("Logout failed",
+			"error", err.Error(),
+			"user_id", currentUserID(c),
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("User logged out",
+		"user_id", currentUserID(c),
+	)
+
+	c.Status(http.StatusNoContent)
 }
\ No newline at end of file
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,14 +23,29 @@ func (m *MockAuthService) Register(username, password string) (*domain.User, err
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
-func (m *MockAuthService) Login(username, password string) (string, error) {
+func (m *MockAuthService) Login(username, password string) (*domain.TokenPair, error) {
 	args := m.Called(username, password)
-	return args.String(0), args.Error(1)
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
 }
 
-func (m *MockAuthService) ValidateToken(token string) (uint, error) {
+func (m *MockAuthService) Refresh(refreshToken string) (*domain.TokenPair, error) {
+	args := m.Called(refreshToken)
+	return args.Get(0).(*domain.TokenPair), args.Error(1)
+}
+
+func (m *MockAuthService) Logout(accessToken, refreshToken string) error {
+	args := m.Called(accessToken, refreshToken)
+	return args.Error(0)
+}
+
+func (m *MockAuthService) ValidateToken(token string) (uint, domain.Role, error) {
+	args := m.Called(token)
+	return args.Get(0).(uint), args.Get(1).(domain.Role), args.Error(2)
+}
+
+func (m *MockAuthService) TokenExpiresAt(token string) (time.Time, error) {
 	args := m.Called(token)
-	return args.Get(0).(uint), args.Error(1)
+	return args.Get(0).(time.Time), args.Error(1)
 }
 
 
@@ -116,7 +132,11 @@ func TestAuthHandler_Login(t *testing.T) {
 				"password": "testpass",
 			},
 			mockSetup: func(m *MockAuthService) {
-				m.On("Login", "testuser", "testpass").Return("testtoken12345", nil)
+				m.On("Login", "testuser", "testpass").Return(&domain.TokenPair{
+					AccessToken:  "testtoken12345",
+					RefreshToken: "refresh12345",
+					ExpiresIn:    15 * time.Minute,
+				}, nil)
 			},
 			expectedCode: http.StatusOK,
 		},
@@ -127,10 +147,21 @@ func TestAuthHandler_Login(t *testing.T) {
 				"password": "wrongpass",
 			},
 			mockSetup: func(m *MockAuthService) {
-				m.On("Login", "testuser", "wrongpass").Return("", assert.AnError)
+				m.On("Login", "testuser", "wrongpass").Return((*domain.TokenPair)(nil), assert.AnError)
 			},
 			expectedCode: http.StatusUnauthorized,
 		},
+		{
+			name: "Banned user",
+			requestBody: map[string]string{
+				"username": "banned",
+				"password": "testpass",
+			},
+			mockSetup: func(m *MockAuthService) {
+				m.On("Login", "banned", "testpass").Return((*domain.TokenPair)(nil), domain.ErrUserBanned)
+			},
+			expectedCode: http.StatusForbidden,
+		},
 		{
 			name: "Invalid request body",
 			requestBody: map[string]string{
@@ -162,4 +193,121 @@ func TestAuthHandler_Login(t *testing.T) {
 			mockService.AssertExpectations(t)
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestAuthHandler_Refresh(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  interface{}
+		mockSetup    func(*MockAuthService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:        "Successful refresh",
+			requestBody: map[string]string{"refresh_token": "old"},
+			mockSetup: func(m *MockAuthService) {
+				m.On("Refresh", "old").Return(&domain.TokenPair{
+					AccessToken:  "newaccess123",
+					RefreshToken: "new",
+					ExpiresIn:    15 * time.Minute,
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"expires_in":900,"refresh_token":"new","token":"newaccess123"}`,
+		},
+		{
+			name:        "Revoked refresh token",
+			requestBody: map[string]string{"refresh_token": "old"},
+			mockSetup: func(m *MockAuthService) {
+				m.On("Refresh", "old").Return((*domain.TokenPair)(nil), assert.AnError)
+			},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Missing refresh token",
+			requestBody:  map[string]string{},
+			mockSetup:    func(m *MockAuthService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAuthHandler(mockService)
+			router := setupTestRouter()
+			router.POST("/refresh", handler.Refresh)
+
+			body, _ := json.Marshal(tt.requestBody)
+			req, _ := http.NewRequest("POST", "/refresh", bytes.NewBuffer(body))
+			req.Header.Set("Content-Type", "application/json")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestAuthHandler_Logout(t *testing.T) {
+	tests := []struct {
+		name         string
+		requestBody  string
+		mockSetup    func(*MockAuthService)
+		expectedCode int
+	}{
+		{
+			name:        "Logout with refresh token",
+			requestBody: `{"refresh_token":"refresh"}`,
+			mockSetup: func(m *MockAuthService) {
+				m.On("Logout", "access", "refresh").Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:        "Logout without body",
+			requestBody: "",
+			mockSetup: func(m *MockAuthService) {
+				m.On("Logout", "access", "").Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:        "Service error",
+			requestBody: "",
+			mockSetup: func(m *MockAuthService) {
+				m.On("Logout", "access", "").Return(assert.AnError)
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAuthService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewAuthHandler(mockService)
+			router := setupTestRouter()
+			router.POST("/logout", handler.Logout)
+
+			req, _ := http.NewRequest("POST", "/logout", bytes.NewBufferString(tt.requestBody))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "access")
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type CategoryService interface {
+	GetTree() ([]domain.Category, error)
+}
+
+type CategoryHandler struct {
+	categoryService CategoryService
+}
+
+func NewCategoryHandler(categoryService CategoryService) *CategoryHandler {
+	return &CategoryHandler{categoryService: categoryService}
+}
+
+type responseCategory struct {
+	ID       uint               `json:"id"`
+	Name     string             `json:"name"`
+	Slug     string             `json:"slug"`
+	Children []responseCategory `json:"children"`
+}
+
+func newResponseCategories(categories []domain.Category) []responseCategory {
+	response := make([]responseCategory, 0, len(categories))
+	for _, category := range categories {
+		response = append(response, responseCategory{
+			ID:       category.ID,
+			Name:     category.Name,
+			Slug:     category.Slug,
+			Children: newResponseCategories(category.Children),
+		})
+	}
+	return response
+}
+
+func (h *CategoryHandler) GetCategories(c *gin.Context) {
+	tree, err := h.categoryService.GetTree()
+	if err != nil {
+		logger.Log.Error("Failed to get categories",
+			"error", err,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseCategories(tree))
+}
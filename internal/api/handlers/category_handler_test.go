@@ -0,0 +1,75 @@
+package handlers_test
+
+import (
+	"errors"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockCategoryService struct {
+	mock.Mock
+}
+
+func (m *MockCategoryService) GetTree() ([]domain.Category, error) {
+	args := m.Called()
+	return args.Get(0).([]domain.Category), args.Error(1)
+}
+
+func TestCategoryHandler_GetCategories(t *testing.T) {
+	parentID := uint(1)
+	tree := []domain.Category{
+		{ID: 1, Name: "Электроника", Slug: "electronics", Children: []domain.Category{
+			{ID: 2, Name: "Телефоны", Slug: "phones", ParentID: &parentID},
+		}},
+	}
+
+	tests := []struct {
+		name         string
+		mockSetup    func(*MockCategoryService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "Successful get tree",
+			mockSetup: func(m *MockCategoryService) {
+				m.On("GetTree").Return(tree, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `[{"id":1,"name":"Электроника","slug":"electronics","children":[{"id":2,"name":"Телефоны","slug":"phones","children":[]}]}]`,
+		},
+		{
+			name: "Service error",
+			mockSetup: func(m *MockCategoryService) {
+				m.On("GetTree").Return([]domain.Category(nil), errors.New("db error"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockCategoryService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewCategoryHandler(mockService)
+			router := setupTestRouter()
+			router.GET("/categories", handler.GetCategories)
+
+			req, _ := http.NewRequest("GET", "/categories", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type FavoriteService interface {
+	AddFavorite(userID, adID uint) error
+	RemoveFavorite(userID, adID uint) error
+	GetFavorites(userID uint, filter domain.AdFilter) (*domain.AdPage, error)
+}
+
+type FavoriteHandler struct {
+	favoriteService FavoriteService
+}
+
+func NewFavoriteHandler(favoriteService FavoriteService) *FavoriteHandler {
+	return &FavoriteHandler{favoriteService: favoriteService}
+}
+
+func (h *FavoriteHandler) AddFavorite(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.favoriteService.AddFavorite(userID, adID); err != nil {
+		logger.Log.Warn("Failed to add favorite",
+			"error", err,
+			"ad_id", adID,
+			"user_id", userID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RemoveFavorite идемпотентен и работает в том числе для удалённых объявлений
+func (h *FavoriteHandler) RemoveFavorite(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.favoriteService.RemoveFavorite(userID, adID); err != nil {
+		logger.Log.Error("Failed to remove favorite",
+			"error", err,
+			"ad_id", adID,
+			"user_id", userID,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove favorite"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetFavorites - избранное текущего пользователя с параметрами списка GET /ads
+func (h *FavoriteHandler) GetFavorites(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	filter, ok := parseAdFilter(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.favoriteService.GetFavorites(userID, filter)
+	if err != nil {
+		logger.Log.Error("Failed to get favorites",
+			"error", err,
+			"user_id", userID,
+			"query", c.Request.URL.RawQuery,
+		)
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseAdPage(result, userID))
+}
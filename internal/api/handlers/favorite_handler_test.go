@@ -0,0 +1,152 @@
+package handlers_test
+
+import (
+	"errors"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func TestFavoriteHandler_AddRemove(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		setupContext func(*gin.Context)
+		mockSetup    func(*MockAdvertisementService)
+		expectedCode int
+	}{
+		{
+			name:         "Add favorite",
+			method:       "POST",
+			path:         "/ads/1/favorite",
+			setupContext: func(c *gin.Context) { c.Set("userID", uint(2)) },
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("AddFavorite", uint(2), uint(1)).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Add unknown ad",
+			method:       "POST",
+			path:         "/ads/42/favorite",
+			setupContext: func(c *gin.Context) { c.Set("userID", uint(2)) },
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("AddFavorite", uint(2), uint(42)).Return(domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Add own ad",
+			method:       "POST",
+			path:         "/ads/1/favorite",
+			setupContext: func(c *gin.Context) { c.Set("userID", uint(1)) },
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("AddFavorite", uint(1), uint(1)).Return(domain.ErrInvalidInput)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Add without auth",
+			method:       "POST",
+			path:         "/ads/1/favorite",
+			setupContext: func(c *gin.Context) {},
+			mockSetup:    func(m *MockAdvertisementService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:         "Remove favorite",
+			method:       "DELETE",
+			path:         "/ads/1/favorite",
+			setupContext: func(c *gin.Context) { c.Set("userID", uint(2)) },
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("RemoveFavorite", uint(2), uint(1)).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:         "Remove fails",
+			method:       "DELETE",
+			path:         "/ads/1/favorite",
+			setupContext: func(c *gin.Context) { c.Set("userID", uint(2)) },
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("RemoveFavorite", uint(2), uint(1)).Return(errors.New("db error"))
+			},
+			expectedCode: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewFavoriteHandler(mockService)
+			router := setupTestRouter()
+			router.POST("/ads/:id/favorite", func(c *gin.Context) {
+				tt.setupContext(c)
+				handler.AddFavorite(c)
+			})
+			router.DELETE("/ads/:id/favorite", func(c *gin.Context) {
+				tt.setupContext(c)
+				handler.RemoveFavorite(c)
+			})
+
+			req, _ := http.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestFavoriteHandler_GetFavorites(t *testing.T) {
+	page := &domain.AdPage{
+		Items: []domain.Advertisement{
+			{ID: 1, Title: "Велосипед", UserID: 1, Status: domain.AdStatusPublished, ImageURL: "http://a.jpg", IsFavorite: true},
+			{ID: 2, Title: "Самокат", UserID: 1, Status: domain.AdStatusArchived, IsFavorite: true},
+			{ID: 3, Title: "Ролики", UserID: 1, Status: domain.AdStatusPublished, ImageURL: "http://b.jpg", IsFavorite: true,
+				DeletedAt: gorm.DeletedAt{Time: time.Now(), Valid: true}},
+		},
+		Total: 3, Page: 1, Limit: 10,
+	}
+
+	mockService := new(MockAdvertisementService)
+	mockService.On("GetFavorites", uint(2), domain.AdFilter{Page: 1, Limit: 10, SortBy: "price", Order: "asc", ViewerID: 2}).
+		Return(page, nil)
+
+	handler := handlers.NewFavoriteHandler(mockService)
+	router := setupTestRouter()
+	router.GET("/me/favorites", func(c *gin.Context) {
+		if c.Query("anonymous") == "" {
+			c.Set("userID", uint(2))
+		}
+		handler.GetFavorites(c)
+	})
+
+	req, _ := http.NewRequest("GET", "/me/favorites?sort_by=price&order=asc", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	body := w.Body.String()
+	assert.Contains(t, body, `"is_owner":false,"is_favorite":true,"thumbnails"`)
+	assert.Contains(t, body, `"is_favorite":true,"unavailable":"archived"`)
+	// у удалённого объявления нет миниатюр
+	assert.Contains(t, body, `"is_favorite":true,"unavailable":"deleted"}`)
+	mockService.AssertExpectations(t)
+
+	req, _ = http.NewRequest("GET", "/me/favorites?anonymous=1", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
@@ -0,0 +1,356 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+	"github.com/keenetic29/vk-internship/pkg/storage"
+)
+
+// запас на заголовки и границы multipart-формы сверх самого файла
+const multipartOverhead = 1 << 20
+
+// MediaPath - префикс стабильных URL загруженных изображений
+const MediaPath = "/media/"
+
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+}
+
+type ImageStore interface {
+	Put(ctx context.Context, key, contentType string, data []byte) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *storage.ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+}
+
+type AdImageService interface {
+	GetAd(id, viewerID uint) (*domain.Advertisement, error)
+	AddImage(userID, adID uint, url string) (*domain.Advertisement, error)
+	RemoveImage(userID, adID, imageID uint) (*domain.Advertisement, *domain.AdImage, error)
+	ReorderImages(userID, adID uint, imageIDs []uint) (*domain.Advertisement, error)
+	SetCoverImage(userID, adID, imageID uint) (*domain.Advertisement, error)
+}
+
+type ImageHandler struct {
+	adService AdImageService
+	store     ImageStore
+	baseURL   string
+}
+
+// NewImageHandler: baseURL - внешний адрес сервиса (например https://market.example.com),
+// пустой baseURL даёт относительные ссылки вида /media/ads/1/....jpg
+func NewImageHandler(adService AdImageService, store ImageStore, baseURL string) *ImageHandler {
+	return &ImageHandler{
+		adService: adService,
+		store:     store,
+		baseURL:   strings.TrimRight(baseURL, "/"),
+	}
+}
+
+func isAllowedImageType(contentType string) bool {
+	for _, allowed := range strings.Split(AllowedImageTypes, ",") {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// detectImageType определяет тип по сигнатуре файла, а не по заявленному клиентом заголовку
+func detectImageType(data []byte) (string, error) {
+	contentType := http.DetectContentType(data)
+	if !isAllowedImageType(contentType) {
+		return "", fmt.Errorf("only JPEG, PNG and WEBP images are allowed, got %s", contentType)
+	}
+	return contentType, nil
+}
+
+func newImageKey(adID uint, contentType string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("ads/%d/%s%s", adID, hex.EncodeToString(b), imageExtensions[contentType]), nil
+}
+
+func (h *ImageHandler) mediaURL(key string) string {
+	return h.baseURL + MediaPath + key
+}
+
+// mediaKey возвращает ключ объекта, если url указывает на наше хранилище
+func (h *ImageHandler) mediaKey(url string) (string, bool) {
+	prefix := h.baseURL + MediaPath
+	if !strings.HasPrefix(url, prefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(url, prefix), true
+}
+
+// readUpload читает файл из поля image с ограничением по размеру
+func readUpload(c *gin.Context) ([]byte, int, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, MaxImageSize+multipartOverhead)
+
+	file, header, err := c.Request.FormFile("image")
+	if err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return nil, http.StatusRequestEntityTooLarge, errors.New("image size exceeds maximum limit")
+		}
+		return nil, http.StatusBadRequest, errors.New("multipart field \"image\" is required")
+	}
+	defer file.Close()
+
+	if header.Size > MaxImageSize {
+		return nil, http.StatusRequestEntityTooLarge, errors.New("image size exceeds maximum limit")
+	}
+
+	data, err := io.ReadAll(io.LimitReader(file, MaxImageSize+1))
+	if err != nil {
+		return nil, http.StatusBadRequest, err
+	}
+	if len(data) > MaxImageSize {
+		return nil, http.StatusRequestEntityTooLarge, errors.New("image size exceeds maximum limit")
+	}
+	if len(data) == 0 {
+		return nil, http.StatusBadRequest, errors.New("image is empty")
+	}
+
+	return data, http.StatusOK, nil
+}
+
+// UploadImage принимает изображение (multipart, поле image) и добавляет его в конец галереи
+func (h *ImageHandler) UploadImage(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	// права проверяем до загрузки в хранилище, чтобы не плодить ничейные файлы
+	ad, err := h.adService.GetAd(adID, userID)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if ad.UserID != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": domain.ErrForbidden.Error()})
+		return
+	}
+	if len(ad.Images) >= domain.MaxAdImages {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "too many images"})
+		return
+	}
+
+	data, status, err := readUpload(c)
+	if err != nil {
+		logger.Log.Warn("Invalid image upload",
+			"error", err,
+			"user_id", userID,
+			"ad_id", adID,
+		)
+		c.JSON(status, gin.H{"error": err.Error()})
+		return
+	}
+
+	contentType, err := detectImageType(data)
+	if err != nil {
+		logger.Log.Warn("Unsupported image format",
+			"error", err,
+			"user_id", userID,
+			"ad_id", adID,
+		)
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	}
+
+	key, err := newImageKey(adID, contentType)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store image"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := h.store.Put(ctx, key, contentType, data); err != nil {
+		logger.Log.Error("Failed to store image",
+			"error", err,
+			"key", key,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store image"})
+		return
+	}
+
+	updated, err := h.adService.AddImage(userID, adID, h.mediaURL(key))
+	if err != nil {
+		h.deleteObject(ctx, key)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Advertisement image uploaded",
+		"ad_id", adID,
+		"user_id", userID,
+		"key", key,
+		"content_type", contentType,
+		"size", len(data),
+	)
+
+	c.JSON(http.StatusOK, newResponseAd(*updated, userID))
+}
+
+func (h *ImageHandler) deleteObject(ctx context.Context, key string) {
+	if err := h.store.Delete(ctx, key); err != nil {
+		logger.Log.Warn("Failed to remove stored image", "error", err, "key", key)
+	}
+}
+
+// RemoveImage удаляет изображение из галереи; загруженный к нам файл удаляется из хранилища
+func (h *ImageHandler) RemoveImage(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	imageID, ok := parseIDParam(c, "image_id")
+	if !ok {
+		return
+	}
+
+	ad, removed, err := h.adService.RemoveImage(userID, adID, imageID)
+	if err != nil {
+		logger.Log.Warn("Failed to remove image",
+			"error", err,
+			"ad_id", adID,
+			"image_id", imageID,
+			"user_id", userID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	if key, ok := h.mediaKey(removed.URL); ok {
+		h.deleteObject(c.Request.Context(), key)
+	}
+
+	logger.Log.Info("Advertisement image removed",
+		"ad_id", adID,
+		"image_id", imageID,
+		"user_id", userID,
+	)
+
+	c.JSON(http.StatusOK, newResponseAd(*ad, userID))
+}
+
+type ReorderImagesRequest struct {
+	ImageIDs []uint `json:"image_ids" binding:"required"`
+}
+
+// ReorderImages задаёт порядок галереи списком id всех её изображений
+func (h *ImageHandler) ReorderImages(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ReorderImagesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ad, err := h.adService.ReorderImages(userID, adID, req.ImageIDs)
+	if err != nil {
+		logger.Log.Warn("Failed to reorder images",
+			"error", err,
+			"ad_id", adID,
+			"user_id", userID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseAd(*ad, userID))
+}
+
+func (h *ImageHandler) SetCover(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+	imageID, ok := parseIDParam(c, "image_id")
+	if !ok {
+		return
+	}
+
+	ad, err := h.adService.SetCoverImage(userID, adID, imageID)
+	if err != nil {
+		logger.Log.Warn("Failed to set cover image",
+			"error", err,
+			"ad_id", adID,
+			"image_id", imageID,
+			"user_id", userID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseAd(*ad, userID))
+}
+
+// ServeMedia отдаёт загруженные изображения по стабильному URL /media/{key}
+func (h *ImageHandler) ServeMedia(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	body, info, err := h.store.Get(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) || errors.Is(err, storage.ErrInvalidKey) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+			return
+		}
+		logger.Log.Error("Failed to read image",
+			"error", err,
+			"key", key,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to read image"})
+		return
+	}
+	defer body.Close()
+
+	// ключи случайные и не переиспользуются, поэтому содержимое по URL не меняется
+	c.DataFromReader(http.StatusOK, info.Size, info.ContentType, body, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
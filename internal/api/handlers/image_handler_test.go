@@ -0,0 +1,368 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/storage"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// memoryImageStore - хранилище изображений в памяти для тестов
+type memoryImageStore struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func newMemoryImageStore() *memoryImageStore {
+	return &memoryImageStore{objects: make(map[string][]byte), types: make(map[string]string)}
+}
+
+func (s *memoryImageStore) Put(ctx context.Context, key, contentType string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = data
+	s.types[key] = contentType
+	return nil
+}
+
+func (s *memoryImageStore) Get(ctx context.Context, key string) (io.ReadCloser, *storage.ObjectInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, ok := s.objects[key]
+	if !ok {
+		return nil, nil, storage.ErrNotFound
+	}
+	return io.NopCloser(bytes.NewReader(data)), &storage.ObjectInfo{ContentType: s.types[key], Size: int64(len(data))}, nil
+}
+
+func (s *memoryImageStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// сигнатура PNG, дополненная до размера, достаточного для определения типа
+var pngData = append([]byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), make([]byte, 64)...)
+
+func multipartImage(t *testing.T, field string, data []byte) (*bytes.Buffer, string) {
+	t.Helper()
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	part, err := writer.CreateFormFile(field, "photo.png")
+	if err != nil {
+		t.Fatalf("CreateFormFile failed: %v", err)
+	}
+	part.Write(data)
+	writer.Close()
+	return body, writer.FormDataContentType()
+}
+
+func TestImageHandler_UploadImage(t *testing.T) {
+	ownAd := &domain.Advertisement{ID: 1, UserID: 1, ImageURL: "/media/ads/1/old.png"}
+
+	tests := []struct {
+		name         string
+		field        string
+		data         []byte
+		mockSetup    func(*MockAdvertisementService)
+		expectedCode int
+		expectStored bool
+	}{
+		{
+			name:  "Successful upload",
+			field: "image",
+			data:  pngData,
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return(ownAd, nil)
+				m.On("AddImage", uint(1), uint(1), mock.MatchedBy(func(url string) bool {
+					return strings.HasPrefix(url, "/media/ads/1/") && strings.HasSuffix(url, ".png")
+				})).Return(&domain.Advertisement{ID: 1, UserID: 1, ImageURL: "/media/ads/1/old.png"}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectStored: true,
+		},
+		{
+			name:  "Gallery is full",
+			field: "image",
+			data:  pngData,
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return(&domain.Advertisement{ID: 1, UserID: 1, Images: make([]domain.AdImage, domain.MaxAdImages)}, nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Not an image",
+			field: "image",
+			data:  []byte("<html><script>alert(1)</script></html>"),
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return(ownAd, nil)
+			},
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		{
+			name:  "Image too large",
+			field: "image",
+			data:  append(append([]byte{}, pngData...), make([]byte, handlers.MaxImageSize)...),
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return(ownAd, nil)
+			},
+			expectedCode: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:  "Missing file field",
+			field: "file",
+			data:  pngData,
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return(ownAd, nil)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:  "Not the owner",
+			field: "image",
+			data:  pngData,
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return(&domain.Advertisement{ID: 1, UserID: 2}, nil)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:  "Ad not found",
+			field: "image",
+			data:  pngData,
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("GetAd", uint(1), uint(1)).Return((*domain.Advertisement)(nil), domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			tt.mockSetup(mockService)
+
+			store := newMemoryImageStore()
+			store.objects["ads/1/old.png"] = pngData
+
+			handler := handlers.NewImageHandler(mockService, store, "")
+			router := setupTestRouter()
+			router.POST("/ads/:id/images", func(c *gin.Context) {
+				c.Set("userID", uint(1))
+				handler.UploadImage(c)
+			})
+
+			body, contentType := multipartImage(t, tt.field, tt.data)
+			req, _ := http.NewRequest("POST", "/ads/1/images", body)
+			req.Header.Set("Content-Type", contentType)
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			// новое изображение добавляется в хранилище, прежние остаются в галерее
+			if tt.expectStored {
+				assert.Len(t, store.objects, 2)
+			} else {
+				assert.Len(t, store.objects, 1)
+			}
+			assert.Contains(t, store.objects, "ads/1/old.png")
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImageHandler_ServeMedia(t *testing.T) {
+	store := newMemoryImageStore()
+	store.Put(context.Background(), "ads/1/photo.png", "image/png", pngData)
+
+	handler := handlers.NewImageHandler(new(MockAdvertisementService), store, "")
+	router := setupTestRouter()
+	router.GET(handlers.MediaPath+"*key", handler.ServeMedia)
+
+	req, _ := http.NewRequest("GET", "/media/ads/1/photo.png", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+	assert.Equal(t, "nosniff", w.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, pngData, w.Body.Bytes())
+
+	req, _ = http.NewRequest("GET", "/media/ads/1/missing.png", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestImageHandler_RemoveImage(t *testing.T) {
+	tests := []struct {
+		name          string
+		mockSetup     func(*MockAdvertisementService)
+		expectedCode  int
+		expectDeleted bool
+	}{
+		{
+			name: "Uploaded image is deleted from store",
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("RemoveImage", uint(1), uint(1), uint(7)).
+					Return(&domain.Advertisement{ID: 1, UserID: 1}, &domain.AdImage{ID: 7, URL: "/media/ads/1/photo.png"}, nil)
+			},
+			expectedCode:  http.StatusOK,
+			expectDeleted: true,
+		},
+		{
+			name: "External image is only unlinked",
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("RemoveImage", uint(1), uint(1), uint(7)).
+					Return(&domain.Advertisement{ID: 1, UserID: 1}, &domain.AdImage{ID: 7, URL: "http://example.com/photo.png"}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name: "Image not found",
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("RemoveImage", uint(1), uint(1), uint(7)).
+					Return((*domain.Advertisement)(nil), (*domain.AdImage)(nil), domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			tt.mockSetup(mockService)
+
+			store := newMemoryImageStore()
+			store.objects["ads/1/photo.png"] = pngData
+
+			handler := handlers.NewImageHandler(mockService, store, "")
+			router := setupTestRouter()
+			router.DELETE("/ads/:id/images/:image_id", func(c *gin.Context) {
+				c.Set("userID", uint(1))
+				handler.RemoveImage(c)
+			})
+
+			req, _ := http.NewRequest("DELETE", "/ads/1/images/7", nil)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectDeleted {
+				assert.NotContains(t, store.objects, "ads/1/photo.png")
+			} else {
+				assert.Contains(t, store.objects, "ads/1/photo.png")
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImageHandler_ReorderImages(t *testing.T) {
+	tests := []struct {
+		name         string
+		body         string
+		mockSetup    func(*MockAdvertisementService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name: "Successful reorder",
+			body: `{"image_ids":[2,1]}`,
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("ReorderImages", uint(1), uint(1), []uint{2, 1}).Return(&domain.Advertisement{
+					ID: 1, UserID: 1, ImageURL: "http://a.jpg",
+					Images: []domain.AdImage{
+						{ID: 2, URL: "http://b.jpg", Position: 0},
+						{ID: 1, URL: "http://a.jpg", Position: 1, IsCover: true},
+					},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `"images":[{"id":2,"url":"http://b.jpg","position":0,"is_cover":false},{"id":1,"url":"http://a.jpg","position":1,"is_cover":true}]`,
+		},
+		{
+			name: "Incomplete order",
+			body: `{"image_ids":[2]}`,
+			mockSetup: func(m *MockAdvertisementService) {
+				m.On("ReorderImages", uint(1), uint(1), []uint{2}).Return((*domain.Advertisement)(nil), domain.ErrInvalidInput)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Missing image_ids",
+			body:         `{}`,
+			mockSetup:    func(m *MockAdvertisementService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewImageHandler(mockService, newMemoryImageStore(), "")
+			router := setupTestRouter()
+			router.PUT("/ads/:id/images", func(c *gin.Context) {
+				c.Set("userID", uint(1))
+				handler.ReorderImages(c)
+			})
+
+			req, _ := http.NewRequest("PUT", "/ads/1/images", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.Contains(t, w.Body.String(), tt.expectedBody)
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestImageHandler_SetCover(t *testing.T) {
+	mockService := new(MockAdvertisementService)
+	mockService.On("SetCoverImage", uint(1), uint(1), uint(2)).
+		Return(&domain.Advertisement{ID: 1, UserID: 1, ImageURL: "http://b.jpg"}, nil)
+	mockService.On("SetCoverImage", uint(1), uint(1), uint(9)).
+		Return((*domain.Advertisement)(nil), domain.ErrNotFound)
+
+	handler := handlers.NewImageHandler(mockService, newMemoryImageStore(), "")
+	router := setupTestRouter()
+	router.POST("/ads/:id/images/:image_id/cover", func(c *gin.Context) {
+		c.Set("userID", uint(1))
+		handler.SetCover(c)
+	})
+
+	req, _ := http.NewRequest("POST", "/ads/1/images/2/cover", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"image_url":"http://b.jpg"`)
+
+	req, _ = http.NewRequest("POST", "/ads/1/images/9/cover", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	mockService.AssertExpectations(t)
+}
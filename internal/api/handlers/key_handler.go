@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/pkg/jwt"
+)
+
+type KeySetProvider interface {
+	JWKS() jwt.JWKSet
+}
+
+type KeyHandler struct {
+	keySet KeySetProvider
+}
+
+func NewKeyHandler(keySet KeySetProvider) *KeyHandler {
+	return &KeyHandler{keySet: keySet}
+}
+
+// GetJWKS публикует публичные ключи для проверки access-токенов сторонними сервисами
+func (h *KeyHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keySet.JWKS())
+}
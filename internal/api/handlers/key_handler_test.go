@@ -0,0 +1,37 @@
+package handlers_test
+
+import (
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/pkg/jwt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type stubKeySet struct {
+	set jwt.JWKSet
+}
+
+func (s stubKeySet) JWKS() jwt.JWKSet {
+	return s.set
+}
+
+func TestKeyHandler_GetJWKS(t *testing.T) {
+	keySet := stubKeySet{set: jwt.JWKSet{Keys: []jwt.JWK{
+		{Kty: "OKP", Kid: "ed-1", Use: "sig", Alg: "EdDSA", Crv: "Ed25519", X: "abc"},
+	}}}
+
+	router := setupTestRouter()
+	handler := handlers.NewKeyHandler(keySet)
+	router.GET("/.well-known/jwks.json", handler.GetJWKS)
+
+	req, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"keys":[{"kty":"OKP","kid":"ed-1","use":"sig","alg":"EdDSA","crv":"Ed25519","x":"abc"}]}`, w.Body.String())
+}
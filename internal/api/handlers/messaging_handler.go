@@ -0,0 +1,246 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type MessagingService interface {
+	SendToAd(senderID, adID uint, body string) (*domain.Message, error)
+	Reply(senderID, conversationID uint, body string) (*domain.Message, error)
+	ListConversations(userID uint, page, limit int) (*domain.ConversationPage, error)
+	GetMessages(userID, conversationID uint, cursor string, limit int) (*domain.MessagePage, error)
+	BlockUser(userID uint, username string) error
+	UnblockUser(userID uint, username string) error
+}
+
+type MessagingHandler struct {
+	messagingService MessagingService
+}
+
+func NewMessagingHandler(messagingService MessagingService) *MessagingHandler {
+	return &MessagingHandler{messagingService: messagingService}
+}
+
+type SendMessageRequest struct {
+	Text string `json:"text" binding:"required"`
+}
+
+type responseMessage struct {
+	ID             uint      `json:"id"`
+	ConversationID uint      `json:"conversation_id"`
+	SenderID       uint      `json:"sender_id"`
+	IsMine         bool      `json:"is_mine"`
+	Text           string    `json:"text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func newResponseMessage(msg domain.Message, currentUserID uint) responseMessage {
+	return responseMessage{
+		ID:             msg.ID,
+		ConversationID: msg.ConversationID,
+		SenderID:       msg.SenderID,
+		IsMine:         msg.SenderID == currentUserID,
+		Text:           msg.Body,
+		CreatedAt:      msg.CreatedAt,
+	}
+}
+
+type responseConversation struct {
+	ID            uint   `json:"id"`
+	AdID          uint   `json:"ad_id"`
+	AdTitle       string `json:"ad_title"`
+	AdUnavailable string `json:"ad_unavailable,omitempty"`
+	// роль текущего пользователя в переписке: buyer или seller
+	Role             string    `json:"role"`
+	CounterpartLogin string    `json:"counterpart_login"`
+	Unread           int       `json:"unread"`
+	LastMessageAt    time.Time `json:"last_message_at"`
+}
+
+type responseConversationPage struct {
+	Items  []responseConversation `json:"items"`
+	Total  int64                  `json:"total"`
+	Unread int64                  `json:"unread"`
+	Page   int                    `json:"page"`
+	Limit  int                    `json:"limit"`
+}
+
+type responseMessagePage struct {
+	Items      []responseMessage `json:"items"`
+	NextCursor *string           `json:"next_cursor"`
+}
+
+func newResponseConversation(conv domain.Conversation, currentUserID uint) responseConversation {
+	item := responseConversation{
+		ID:               conv.ID,
+		AdID:             conv.AdID,
+		AdTitle:          conv.Ad.Title,
+		AdUnavailable:    conv.Ad.UnavailableReason(),
+		Role:             "buyer",
+		CounterpartLogin: conv.Seller.Username,
+		Unread:           conv.UnreadFor(currentUserID),
+		LastMessageAt:    conv.LastMessageAt,
+	}
+	if currentUserID == conv.SellerID {
+		item.Role = "seller"
+		item.CounterpartLogin = conv.Buyer.Username
+	}
+	return item
+}
+
+// send - общая часть SendToAd и Reply; id из пути - объявление или переписка
+func (h *MessagingHandler) send(c *gin.Context, send func(senderID, id uint, body string) (*domain.Message, error)) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	id, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req SendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	msg, err := send(userID, id, req.Text)
+	if err != nil {
+		logger.Log.Warn("Failed to send message",
+			"error", err,
+			"user_id", userID,
+			"id", id,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Message sent",
+		"message_id", msg.ID,
+		"conversation_id", msg.ConversationID,
+		"user_id", userID,
+	)
+
+	c.JSON(http.StatusCreated, newResponseMessage(*msg, userID))
+}
+
+// SendToAd - написать автору объявления; переписка создаётся при первом сообщении
+func (h *MessagingHandler) SendToAd(c *gin.Context) {
+	h.send(c, h.messagingService.SendToAd)
+}
+
+// Reply - ответ в существующей переписке (в том числе продавцом)
+func (h *MessagingHandler) Reply(c *gin.Context) {
+	h.send(c, h.messagingService.Reply)
+}
+
+func (h *MessagingHandler) ListConversations(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.messagingService.ListConversations(userID, page, limit)
+	if err != nil {
+		logger.Log.Error("Failed to list conversations",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := responseConversationPage{
+		Items:  make([]responseConversation, 0, len(result.Items)),
+		Total:  result.Total,
+		Unread: result.Unread,
+		Page:   result.Page,
+		Limit:  result.Limit,
+	}
+	for _, conv := range result.Items {
+		response.Items = append(response.Items, newResponseConversation(conv, userID))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// GetMessages - сообщения переписки, новые первыми; просмотр отмечает переписку прочитанной
+func (h *MessagingHandler) GetMessages(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	conversationID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	result, err := h.messagingService.GetMessages(userID, conversationID, c.Query("cursor"), limit)
+	if err != nil {
+		logger.Log.Warn("Failed to get messages",
+			"error", err,
+			"user_id", userID,
+			"conversation_id", conversationID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := responseMessagePage{Items: make([]responseMessage, 0, len(result.Items))}
+	for _, msg := range result.Items {
+		response.Items = append(response.Items, newResponseMessage(msg, userID))
+	}
+	if result.NextCursor != "" {
+		response.NextCursor = &result.NextCursor
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// BlockUser запрещает переписку с пользователем; повторная блокировка не ошибка
+func (h *MessagingHandler) BlockUser(c *gin.Context) {
+	h.setBlocked(c, h.messagingService.BlockUser)
+}
+
+// UnblockUser снимает блокировку; идемпотентен
+func (h *MessagingHandler) UnblockUser(c *gin.Context) {
+	h.setBlocked(c, h.messagingService.UnblockUser)
+}
+
+func (h *MessagingHandler) setBlocked(c *gin.Context, apply func(userID uint, username string) error) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	username := c.Param("username")
+	if err := apply(userID, username); err != nil {
+		logger.Log.Warn("Failed to change user block",
+			"error", err,
+			"user_id", userID,
+			"username", username,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
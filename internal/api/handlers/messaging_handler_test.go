@@ -0,0 +1,256 @@
+package handlers_test
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gorm.io/gorm"
+)
+
+type MockMessagingService struct {
+	mock.Mock
+}
+
+func (m *MockMessagingService) SendToAd(senderID, adID uint, body string) (*domain.Message, error) {
+	args := m.Called(senderID, adID, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessagingService) Reply(senderID, conversationID uint, body string) (*domain.Message, error) {
+	args := m.Called(senderID, conversationID, body)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Message), args.Error(1)
+}
+
+func (m *MockMessagingService) ListConversations(userID uint, page, limit int) (*domain.ConversationPage, error) {
+	args := m.Called(userID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.ConversationPage), args.Error(1)
+}
+
+func (m *MockMessagingService) GetMessages(userID, conversationID uint, cursor string, limit int) (*domain.MessagePage, error) {
+	args := m.Called(userID, conversationID, cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.MessagePage), args.Error(1)
+}
+
+func (m *MockMessagingService) BlockUser(userID uint, username string) error {
+	return m.Called(userID, username).Error(0)
+}
+
+func (m *MockMessagingService) UnblockUser(userID uint, username string) error {
+	return m.Called(userID, username).Error(0)
+}
+
+func TestMessagingHandler(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		userID       uint
+		mockSetup    func(*MockMessagingService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:   "Start conversation",
+			method: "POST",
+			path:   "/ads/1/messages",
+			body:   `{"text":"Ещё продаёте?"}`,
+			userID: 2,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("SendToAd", uint(2), uint(1), "Ещё продаёте?").
+					Return(&domain.Message{ID: 10, ConversationID: 3, SenderID: 2, Body: "Ещё продаёте?", CreatedAt: createdAt}, nil)
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":10,"conversation_id":3,"sender_id":2,"is_mine":true,"text":"Ещё продаёте?","created_at":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:   "Message own ad",
+			method: "POST",
+			path:   "/ads/1/messages",
+			body:   `{"text":"Привет"}`,
+			userID: 1,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("SendToAd", uint(1), uint(1), "Привет").Return(nil, domain.ErrInvalidInput)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Empty body",
+			method:       "POST",
+			path:         "/ads/1/messages",
+			body:         `{}`,
+			userID:       2,
+			mockSetup:    func(m *MockMessagingService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Without auth",
+			method:       "POST",
+			path:         "/ads/1/messages",
+			body:         `{"text":"Привет"}`,
+			mockSetup:    func(m *MockMessagingService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "Reply to banned user",
+			method: "POST",
+			path:   "/conversations/3/messages",
+			body:   `{"text":"Привет"}`,
+			userID: 1,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("Reply", uint(1), uint(3), "Привет").Return(nil, domain.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "Seller reply",
+			method: "POST",
+			path:   "/conversations/3/messages",
+			body:   `{"text":"Да"}`,
+			userID: 1,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("Reply", uint(1), uint(3), "Да").
+					Return(&domain.Message{ID: 11, ConversationID: 3, SenderID: 1, Body: "Да", CreatedAt: createdAt}, nil)
+			},
+			expectedCode: http.StatusCreated,
+		},
+		{
+			name:   "List conversations as seller",
+			method: "GET",
+			path:   "/me/conversations",
+			userID: 1,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("ListConversations", uint(1), 1, 20).Return(&domain.ConversationPage{
+					Items: []domain.Conversation{
+						{ID: 3, AdID: 1, Ad: domain.Advertisement{Title: "Велосипед"}, BuyerID: 2, Buyer: domain.User{Username: "buyer"},
+							SellerID: 1, Seller: domain.User{Username: "seller"}, BuyerUnread: 1, SellerUnread: 2, LastMessageAt: createdAt},
+						{ID: 4, AdID: 5, Ad: domain.Advertisement{Title: "Диван", DeletedAt: gorm.DeletedAt{Time: createdAt, Valid: true}},
+							BuyerID: 1, Buyer: domain.User{Username: "seller"}, SellerID: 7, Seller: domain.User{Username: "shop"}, LastMessageAt: createdAt},
+					},
+					Total: 2, Unread: 2, Page: 1, Limit: 20,
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[
+				{"id":3,"ad_id":1,"ad_title":"Велосипед","role":"seller","counterpart_login":"buyer","unread":2,"last_message_at":"2025-01-01T12:00:00Z"},
+				{"id":4,"ad_id":5,"ad_title":"Диван","ad_unavailable":"deleted","role":"buyer","counterpart_login":"shop","unread":0,"last_message_at":"2025-01-01T12:00:00Z"}
+			],"total":2,"unread":2,"page":1,"limit":20}`,
+		},
+		{
+			name:   "Get messages with cursor",
+			method: "GET",
+			path:   "/conversations/3/messages?cursor=abc&limit=1",
+			userID: 1,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("GetMessages", uint(1), uint(3), "abc", 1).Return(&domain.MessagePage{
+					Items:      []domain.Message{{ID: 10, ConversationID: 3, SenderID: 2, Body: "Ещё продаёте?", CreatedAt: createdAt}},
+					NextCursor: "next",
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[{"id":10,"conversation_id":3,"sender_id":2,"is_mine":false,"text":"Ещё продаёте?","created_at":"2025-01-01T12:00:00Z"}],"next_cursor":"next"}`,
+		},
+		{
+			name:   "Foreign conversation",
+			method: "GET",
+			path:   "/conversations/3/messages",
+			userID: 5,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("GetMessages", uint(5), uint(3), "", 50).Return(nil, domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "Block user",
+			method: "POST",
+			path:   "/users/spammer/block",
+			userID: 2,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("BlockUser", uint(2), "spammer").Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+		{
+			name:   "Block yourself",
+			method: "POST",
+			path:   "/users/buyer/block",
+			userID: 2,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("BlockUser", uint(2), "buyer").Return(fmt.Errorf("%w: cannot block yourself", domain.ErrInvalidInput))
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Unblock unknown user",
+			method: "DELETE",
+			path:   "/users/ghost/block",
+			userID: 2,
+			mockSetup: func(m *MockMessagingService) {
+				m.On("UnblockUser", uint(2), "ghost").Return(domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Block without auth",
+			method:       "POST",
+			path:         "/users/spammer/block",
+			mockSetup:    func(m *MockMessagingService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockMessagingService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewMessagingHandler(mockService)
+			router := setupTestRouter()
+			router.Use(func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("userID", tt.userID)
+				}
+			})
+			router.POST("/ads/:id/messages", handler.SendToAd)
+			router.GET("/me/conversations", handler.ListConversations)
+			router.GET("/conversations/:id/messages", handler.GetMessages)
+			router.POST("/conversations/:id/messages", handler.Reply)
+			router.POST("/users/:username/block", handler.BlockUser)
+			router.DELETE("/users/:username/block", handler.UnblockUser)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type NotificationService interface {
+	ListNotifications(userID uint, filter domain.NotificationFilter) (*domain.NotificationPage, error)
+	MarkRead(userID uint, ids []uint) (int64, error)
+}
+
+type NotificationHandler struct {
+	notificationService NotificationService
+}
+
+func NewNotificationHandler(notificationService NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+type responseNotification struct {
+	ID            uint                    `json:"id"`
+	Type          domain.NotificationType `json:"type"`
+	Title         string                  `json:"title"`
+	AdID          *uint                   `json:"ad_id,omitempty"`
+	SavedSearchID *uint                   `json:"saved_search_id,omitempty"`
+	Read          bool                    `json:"read"`
+	CreatedAt     time.Time               `json:"created_at"`
+}
+
+type responseNotificationPage struct {
+	Items  []responseNotification `json:"items"`
+	Total  int64                  `json:"total"`
+	Unread int64                  `json:"unread"`
+	Page   int                    `json:"page"`
+	Limit  int                    `json:"limit"`
+}
+
+func newResponseNotificationPage(page *domain.NotificationPage) responseNotificationPage {
+	response := responseNotificationPage{
+		Items:  make([]responseNotification, 0, len(page.Items)),
+		Total:  page.Total,
+		Unread: page.Unread,
+		Page:   page.Page,
+		Limit:  page.Limit,
+	}
+	for _, n := range page.Items {
+		response.Items = append(response.Items, responseNotification{
+			ID:            n.ID,
+			Type:          n.Type,
+			Title:         n.Title,
+			AdID:          n.AdID,
+			SavedSearchID: n.SavedSearchID,
+			Read:          n.ReadAt != nil,
+			CreatedAt:     n.CreatedAt,
+		})
+	}
+	return response
+}
+
+// ListNotifications - входящие уведомления, новые первыми; unread=true оставляет только непрочитанные
+func (h *NotificationHandler) ListNotifications(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	filter := domain.NotificationFilter{Page: page, Limit: limit}
+
+	if raw := c.Query("unread"); raw != "" {
+		unread, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid unread"})
+			return
+		}
+		filter.UnreadOnly = unread
+	}
+
+	result, err := h.notificationService.ListNotifications(userID, filter)
+	if err != nil {
+		logger.Log.Error("Failed to list notifications",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseNotificationPage(result))
+}
+
+type MarkReadRequest struct {
+	// пустой список - прочитать все
+	IDs []uint `json:"ids"`
+}
+
+func (h *NotificationHandler) MarkRead(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req MarkReadRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	updated, err := h.notificationService.MarkRead(userID, req.IDs)
+	if err != nil {
+		logger.Log.Error("Failed to mark notifications as read",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark notifications as read"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
@@ -0,0 +1,123 @@
+package handlers_test
+
+import (
+	"bytes"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockNotificationService struct {
+	mock.Mock
+}
+
+func (m *MockNotificationService) ListNotifications(userID uint, filter domain.NotificationFilter) (*domain.NotificationPage, error) {
+	args := m.Called(userID, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.NotificationPage), args.Error(1)
+}
+
+func (m *MockNotificationService) MarkRead(userID uint, ids []uint) (int64, error) {
+	args := m.Called(userID, ids)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func TestNotificationHandler(t *testing.T) {
+	adID, searchID := uint(7), uint(3)
+	readAt := time.Date(2025, 1, 2, 0, 0, 0, 0, time.UTC)
+	createdAt := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		mockSetup    func(*MockNotificationService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:   "List notifications",
+			method: "GET",
+			path:   "/me/notifications?unread=false&limit=5",
+			mockSetup: func(m *MockNotificationService) {
+				m.On("ListNotifications", uint(1), domain.NotificationFilter{Page: 1, Limit: 5}).
+					Return(&domain.NotificationPage{
+						Items: []domain.Notification{
+							{ID: 2, Type: domain.NotificationSavedSearchMatch, Title: "Телефоны: Смартфон",
+								AdID: &adID, SavedSearchID: &searchID, CreatedAt: createdAt},
+							{ID: 1, Type: domain.NotificationSavedSearchMatch, Title: "Телефоны: Чехол",
+								ReadAt: &readAt, CreatedAt: createdAt},
+						},
+						Total: 2, Unread: 1, Page: 1, Limit: 5,
+					}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[
+				{"id":2,"type":"saved_search_match","title":"Телефоны: Смартфон","ad_id":7,"saved_search_id":3,"read":false,"created_at":"2025-01-01T00:00:00Z"},
+				{"id":1,"type":"saved_search_match","title":"Телефоны: Чехол","read":true,"created_at":"2025-01-01T00:00:00Z"}
+			],"total":2,"unread":1,"page":1,"limit":5}`,
+		},
+		{
+			name:         "Invalid unread flag",
+			method:       "GET",
+			path:         "/me/notifications?unread=maybe",
+			mockSetup:    func(m *MockNotificationService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Mark selected as read",
+			method: "POST",
+			path:   "/me/notifications/read",
+			body:   `{"ids":[1,2]}`,
+			mockSetup: func(m *MockNotificationService) {
+				m.On("MarkRead", uint(1), []uint{1, 2}).Return(int64(1), nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"updated":1}`,
+		},
+		{
+			name:   "Mark all as read without body",
+			method: "POST",
+			path:   "/me/notifications/read",
+			mockSetup: func(m *MockNotificationService) {
+				m.On("MarkRead", uint(1), []uint(nil)).Return(int64(4), nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"updated":4}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockNotificationService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewNotificationHandler(mockService)
+			router := setupTestRouter()
+			router.Use(func(c *gin.Context) { c.Set("userID", uint(1)) })
+			router.GET("/me/notifications", handler.ListNotifications)
+			router.POST("/me/notifications/read", handler.MarkRead)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
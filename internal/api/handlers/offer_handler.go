@@ -0,0 +1,225 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type OfferService interface {
+	MakeOffer(buyerID, adID uint, amount float64) (*domain.Offer, error)
+	ListAdOffers(sellerID, adID uint, page, limit int) (*domain.OfferPage, error)
+	ListMyOffers(userID uint, role string, page, limit int) (*domain.OfferPage, error)
+	Accept(userID, offerID uint) (*domain.Offer, error)
+	Reject(userID, offerID uint) (*domain.Offer, error)
+	Counter(userID, offerID uint, amount float64) (*domain.Offer, error)
+	Withdraw(userID, offerID uint) (*domain.Offer, error)
+}
+
+type OfferHandler struct {
+	offerService OfferService
+}
+
+func NewOfferHandler(offerService OfferService) *OfferHandler {
+	return &OfferHandler{offerService: offerService}
+}
+
+type OfferAmountRequest struct {
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+type responseOffer struct {
+	ID             uint               `json:"id"`
+	AdID           uint               `json:"ad_id"`
+	AdTitle        string             `json:"ad_title,omitempty"`
+	BuyerLogin     string             `json:"buyer_login,omitempty"`
+	Amount         float64            `json:"amount"`
+	CounterAmount  *float64           `json:"counter_amount,omitempty"`
+	AcceptedAmount *float64           `json:"accepted_amount,omitempty"`
+	Status         domain.OfferStatus `json:"status"`
+	CreatedAt      time.Time          `json:"created_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+func newResponseOffer(offer domain.Offer) responseOffer {
+	return responseOffer{
+		ID:             offer.ID,
+		AdID:           offer.AdID,
+		AdTitle:        offer.Ad.Title,
+		BuyerLogin:     offer.Buyer.Username,
+		Amount:         offer.Amount,
+		CounterAmount:  offer.CounterAmount,
+		AcceptedAmount: offer.AcceptedAmount,
+		Status:         offer.Status,
+		CreatedAt:      offer.CreatedAt,
+		UpdatedAt:      offer.UpdatedAt,
+	}
+}
+
+type responseOfferPage struct {
+	Items []responseOffer `json:"items"`
+	Total int64           `json:"total"`
+	Page  int             `json:"page"`
+	Limit int             `json:"limit"`
+}
+
+func newResponseOfferPage(page *domain.OfferPage) responseOfferPage {
+	response := responseOfferPage{
+		Items: make([]responseOffer, 0, len(page.Items)),
+		Total: page.Total,
+		Page:  page.Page,
+		Limit: page.Limit,
+	}
+	for _, offer := range page.Items {
+		response.Items = append(response.Items, newResponseOffer(offer))
+	}
+	return response
+}
+
+func (h *OfferHandler) MakeOffer(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req OfferAmountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	offer, err := h.offerService.MakeOffer(userID, adID, req.Amount)
+	if err != nil {
+		logger.Log.Warn("Failed to make offer",
+			"error", err,
+			"user_id", userID,
+			"ad_id", adID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Offer made",
+		"offer_id", offer.ID,
+		"ad_id", adID,
+		"user_id", userID,
+	)
+
+	c.JSON(http.StatusCreated, newResponseOffer(*offer))
+}
+
+// ListAdOffers - предложения по своему объявлению
+func (h *OfferHandler) ListAdOffers(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	adID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.offerService.ListAdOffers(userID, adID, page, limit)
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseOfferPage(result))
+}
+
+// ListMyOffers - сделанные (role=buyer, по умолчанию) или полученные (role=seller) предложения
+func (h *OfferHandler) ListMyOffers(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+
+	result, err := h.offerService.ListMyOffers(userID, c.Query("role"), page, limit)
+	if err != nil {
+		logger.Log.Error("Failed to list offers",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, newResponseOfferPage(result))
+}
+
+// respond - общая часть ответов на предложение: action получает пользователя и id предложения
+func (h *OfferHandler) respond(c *gin.Context, action func(userID, offerID uint) (*domain.Offer, error)) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	offerID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	offer, err := action(userID, offerID)
+	if err != nil {
+		logger.Log.Warn("Failed to respond to offer",
+			"error", err,
+			"user_id", userID,
+			"offer_id", offerID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Offer status changed",
+		"offer_id", offerID,
+		"status", offer.Status,
+		"user_id", userID,
+	)
+
+	c.JSON(http.StatusOK, newResponseOffer(*offer))
+}
+
+func (h *OfferHandler) Accept(c *gin.Context) {
+	h.respond(c, h.offerService.Accept)
+}
+
+func (h *OfferHandler) Reject(c *gin.Context) {
+	h.respond(c, h.offerService.Reject)
+}
+
+func (h *OfferHandler) Withdraw(c *gin.Context) {
+	h.respond(c, h.offerService.Withdraw)
+}
+
+// Counter - встречная цена продавца
+func (h *OfferHandler) Counter(c *gin.Context) {
+	h.respond(c, func(userID, offerID uint) (*domain.Offer, error) {
+		var req OfferAmountRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return nil, fmt.Errorf("%w: %s", domain.ErrInvalidInput, err.Error())
+		}
+		return h.offerService.Counter(userID, offerID, req.Amount)
+	})
+}
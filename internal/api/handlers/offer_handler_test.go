@@ -0,0 +1,239 @@
+package handlers_test
+
+import (
+	"bytes"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockOfferService struct {
+	mock.Mock
+}
+
+func (m *MockOfferService) offer(args mock.Arguments) (*domain.Offer, error) {
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Offer), args.Error(1)
+}
+
+func (m *MockOfferService) MakeOffer(buyerID, adID uint, amount float64) (*domain.Offer, error) {
+	return m.offer(m.Called(buyerID, adID, amount))
+}
+
+func (m *MockOfferService) ListAdOffers(sellerID, adID uint, page, limit int) (*domain.OfferPage, error) {
+	args := m.Called(sellerID, adID, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OfferPage), args.Error(1)
+}
+
+func (m *MockOfferService) ListMyOffers(userID uint, role string, page, limit int) (*domain.OfferPage, error) {
+	args := m.Called(userID, role, page, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.OfferPage), args.Error(1)
+}
+
+func (m *MockOfferService) Accept(userID, offerID uint) (*domain.Offer, error) {
+	return m.offer(m.Called(userID, offerID))
+}
+
+func (m *MockOfferService) Reject(userID, offerID uint) (*domain.Offer, error) {
+	return m.offer(m.Called(userID, offerID))
+}
+
+func (m *MockOfferService) Counter(userID, offerID uint, amount float64) (*domain.Offer, error) {
+	return m.offer(m.Called(userID, offerID, amount))
+}
+
+func (m *MockOfferService) Withdraw(userID, offerID uint) (*domain.Offer, error) {
+	return m.offer(m.Called(userID, offerID))
+}
+
+func TestOfferHandler(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	counter := 900.0
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		userID       uint
+		mockSetup    func(*MockOfferService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:   "Make offer",
+			method: "POST",
+			path:   "/ads/1/offers",
+			body:   `{"amount":800}`,
+			userID: 2,
+			mockSetup: func(m *MockOfferService) {
+				m.On("MakeOffer", uint(2), uint(1), 800.0).Return(&domain.Offer{ID: 5, AdID: 1, BuyerID: 2, SellerID: 1,
+					Amount: 800, Status: domain.OfferStatusPending, CreatedAt: createdAt, UpdatedAt: createdAt}, nil)
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":5,"ad_id":1,"amount":800,"status":"pending","created_at":"2025-01-01T12:00:00Z","updated_at":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:         "Negative amount",
+			method:       "POST",
+			path:         "/ads/1/offers",
+			body:         `{"amount":-1}`,
+			userID:       2,
+			mockSetup:    func(m *MockOfferService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Offer on own ad",
+			method: "POST",
+			path:   "/ads/1/offers",
+			body:   `{"amount":800}`,
+			userID: 1,
+			mockSetup: func(m *MockOfferService) {
+				m.On("MakeOffer", uint(1), uint(1), 800.0).Return(nil, domain.ErrForbidden)
+			},
+			expectedCode: http.StatusForbidden,
+		},
+		{
+			name:   "Offer on reserved ad",
+			method: "POST",
+			path:   "/ads/1/offers",
+			body:   `{"amount":800}`,
+			userID: 2,
+			mockSetup: func(m *MockOfferService) {
+				m.On("MakeOffer", uint(2), uint(1), 800.0).Return(nil, domain.ErrInvalidStatusTransition)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "List ad offers",
+			method: "GET",
+			path:   "/ads/1/offers",
+			userID: 1,
+			mockSetup: func(m *MockOfferService) {
+				m.On("ListAdOffers", uint(1), uint(1), 1, 20).Return(&domain.OfferPage{
+					Items: []domain.Offer{{ID: 5, AdID: 1, Ad: domain.Advertisement{Title: "Велосипед"}, Buyer: domain.User{Username: "buyer"},
+						Amount: 800, CounterAmount: &counter, Status: domain.OfferStatusCountered, CreatedAt: createdAt, UpdatedAt: createdAt}},
+					Total: 1, Page: 1, Limit: 20,
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[{"id":5,"ad_id":1,"ad_title":"Велосипед","buyer_login":"buyer","amount":800,"counter_amount":900,"status":"countered","created_at":"2025-01-01T12:00:00Z","updated_at":"2025-01-01T12:00:00Z"}],"total":1,"page":1,"limit":20}`,
+		},
+		{
+			name:   "List received offers",
+			method: "GET",
+			path:   "/me/offers?role=seller&limit=5",
+			userID: 1,
+			mockSetup: func(m *MockOfferService) {
+				m.On("ListMyOffers", uint(1), "seller", 1, 5).Return(&domain.OfferPage{Page: 1, Limit: 5}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[],"total":0,"page":1,"limit":5}`,
+		},
+		{
+			name:   "Accept offer",
+			method: "POST",
+			path:   "/offers/5/accept",
+			userID: 1,
+			mockSetup: func(m *MockOfferService) {
+				m.On("Accept", uint(1), uint(5)).Return(&domain.Offer{ID: 5, Status: domain.OfferStatusAccepted}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:   "Accept out of turn",
+			method: "POST",
+			path:   "/offers/5/accept",
+			userID: 2,
+			mockSetup: func(m *MockOfferService) {
+				m.On("Accept", uint(2), uint(5)).Return(nil, domain.ErrInvalidStatusTransition)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "Counter offer",
+			method: "POST",
+			path:   "/offers/5/counter",
+			body:   `{"amount":900}`,
+			userID: 1,
+			mockSetup: func(m *MockOfferService) {
+				m.On("Counter", uint(1), uint(5), 900.0).Return(&domain.Offer{ID: 5, Status: domain.OfferStatusCountered, CounterAmount: &counter}, nil)
+			},
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:         "Counter without amount",
+			method:       "POST",
+			path:         "/offers/5/counter",
+			body:         `{}`,
+			userID:       1,
+			mockSetup:    func(m *MockOfferService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Withdraw foreign offer",
+			method: "POST",
+			path:   "/offers/5/withdraw",
+			userID: 7,
+			mockSetup: func(m *MockOfferService) {
+				m.On("Withdraw", uint(7), uint(5)).Return(nil, domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Reject without auth",
+			method:       "POST",
+			path:         "/offers/5/reject",
+			mockSetup:    func(m *MockOfferService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockOfferService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewOfferHandler(mockService)
+			router := setupTestRouter()
+			router.Use(func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("userID", tt.userID)
+				}
+			})
+			router.POST("/ads/:id/offers", handler.MakeOffer)
+			router.GET("/ads/:id/offers", handler.ListAdOffers)
+			router.GET("/me/offers", handler.ListMyOffers)
+			router.POST("/offers/:id/accept", handler.Accept)
+			router.POST("/offers/:id/reject", handler.Reject)
+			router.POST("/offers/:id/counter", handler.Counter)
+			router.POST("/offers/:id/withdraw", handler.Withdraw)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type ProfileService interface {
+	UpdateProfile(userID uint, update domain.ProfileUpdate) (*domain.User, error)
+}
+
+type ProfileHandler struct {
+	profileService ProfileService
+}
+
+func NewProfileHandler(profileService ProfileService) *ProfileHandler {
+	return &ProfileHandler{profileService: profileService}
+}
+
+// Пустая строка очищает поле, отсутствующее поле не меняется
+type UpdateProfileRequest struct {
+	DisplayName *string `json:"display_name"`
+	AvatarURL   *string `json:"avatar_url"`
+	City        *string `json:"city"`
+	About       *string `json:"about"`
+}
+
+// Публичные поля пользователя
+type responseUserProfile struct {
+	Username    string    `json:"username"`
+	DisplayName string    `json:"display_name"`
+	AvatarURL   string    `json:"avatar_url"`
+	City        string    `json:"city"`
+	About       string    `json:"about"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func newResponseUserProfile(user domain.User) responseUserProfile {
+	return responseUserProfile{
+		Username:    user.Username,
+		DisplayName: user.DisplayName,
+		AvatarURL:   user.AvatarURL,
+		City:        user.City,
+		About:       user.About,
+		CreatedAt:   user.CreatedAt,
+	}
+}
+
+// UpdateProfile - PATCH /me, изменение своего публичного профиля
+func (h *ProfileHandler) UpdateProfile(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req UpdateProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	user, err := h.profileService.UpdateProfile(userID, domain.ProfileUpdate{
+		DisplayName: req.DisplayName,
+		AvatarURL:   req.AvatarURL,
+		City:        req.City,
+		About:       req.About,
+	})
+	if err != nil {
+		logger.Log.Warn("Failed to update profile",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Profile updated", "user_id", userID)
+
+	c.JSON(http.StatusOK, newResponseUserProfile(*user))
+}
@@ -0,0 +1,102 @@
+package handlers_test
+
+import (
+	"bytes"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockProfileService struct {
+	mock.Mock
+}
+
+func (m *MockProfileService) UpdateProfile(userID uint, update domain.ProfileUpdate) (*domain.User, error) {
+	args := m.Called(userID, update)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
+func TestProfileHandler_UpdateProfile(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	name, empty := "Иван", ""
+
+	tests := []struct {
+		name         string
+		body         string
+		userID       uint
+		mockSetup    func(*MockProfileService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:   "Update",
+			body:   `{"display_name":"Иван","city":""}`,
+			userID: 1,
+			mockSetup: func(m *MockProfileService) {
+				m.On("UpdateProfile", uint(1), domain.ProfileUpdate{DisplayName: &name, City: &empty}).
+					Return(&domain.User{ID: 1, Username: "ivan", DisplayName: "Иван", About: "Привет", CreatedAt: createdAt}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"username":"ivan","display_name":"Иван","avatar_url":"","city":"","about":"Привет","created_at":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:   "Invalid avatar",
+			body:   `{"avatar_url":"ftp://example.com/a.png"}`,
+			userID: 1,
+			mockSetup: func(m *MockProfileService) {
+				m.On("UpdateProfile", uint(1), mock.Anything).Return(nil, domain.ErrInvalidInput)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Malformed body",
+			body:         `{"city":1}`,
+			userID:       1,
+			mockSetup:    func(m *MockProfileService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Unauthorized",
+			body:         `{}`,
+			mockSetup:    func(m *MockProfileService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProfileService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewProfileHandler(mockService)
+			router := setupTestRouter()
+			router.Use(func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("userID", tt.userID)
+				}
+			})
+			router.PATCH("/me", handler.UpdateProfile)
+
+			req, _ := http.NewRequest("PATCH", "/me", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type ReviewService interface {
+	LeaveReview(buyerID, offerID uint, rating int, text string) (*domain.Review, error)
+	GetProfile(username string) (*domain.SellerProfile, error)
+}
+
+type ReviewHandler struct {
+	reviewService ReviewService
+}
+
+func NewReviewHandler(reviewService ReviewService) *ReviewHandler {
+	return &ReviewHandler{reviewService: reviewService}
+}
+
+type ReviewRequest struct {
+	Rating int    `json:"rating" binding:"required"`
+	Text   string `json:"text"`
+}
+
+type responseReview struct {
+	ID         uint      `json:"id"`
+	AdID       uint      `json:"ad_id"`
+	AdTitle    string    `json:"ad_title,omitempty"`
+	BuyerLogin string    `json:"buyer_login,omitempty"`
+	Rating     int       `json:"rating"`
+	Text       string    `json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newResponseReview(review domain.Review) responseReview {
+	return responseReview{
+		ID:         review.ID,
+		AdID:       review.AdID,
+		AdTitle:    review.Ad.Title,
+		BuyerLogin: review.Buyer.Username,
+		Rating:     review.Rating,
+		Text:       review.Text,
+		CreatedAt:  review.CreatedAt,
+	}
+}
+
+type responseProfile struct {
+	responseUserProfile
+	Rating      *float64         `json:"rating"`
+	ReviewCount int              `json:"review_count"`
+	Reviews     []responseReview `json:"reviews"`
+}
+
+// LeaveReview - отзыв покупателя о продавце по завершённой сделке
+func (h *ReviewHandler) LeaveReview(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	offerID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	var req ReviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	review, err := h.reviewService.LeaveReview(userID, offerID, req.Rating, req.Text)
+	if err != nil {
+		logger.Log.Warn("Failed to leave review",
+			"error", err,
+			"user_id", userID,
+			"offer_id", offerID,
+		)
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Review left",
+		"review_id", review.ID,
+		"offer_id", offerID,
+		"seller_id", review.SellerID,
+		"rating", review.Rating,
+	)
+
+	c.JSON(http.StatusCreated, newResponseReview(*review))
+}
+
+// GetProfile - публичный профиль: рейтинг продавца и последние отзывы
+func (h *ReviewHandler) GetProfile(c *gin.Context) {
+	profile, err := h.reviewService.GetProfile(c.Param("username"))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	response := responseProfile{
+		responseUserProfile: newResponseUserProfile(profile.User),
+		Rating:              profile.User.Rating(),
+		ReviewCount:         profile.User.RatingCount,
+		Reviews:             make([]responseReview, 0, len(profile.Reviews)),
+	}
+	for _, review := range profile.Reviews {
+		response.Reviews = append(response.Reviews, newResponseReview(review))
+	}
+
+	c.JSON(http.StatusOK, response)
+}
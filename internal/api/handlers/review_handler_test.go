@@ -0,0 +1,169 @@
+package handlers_test
+
+import (
+	"bytes"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockReviewService struct {
+	mock.Mock
+}
+
+func (m *MockReviewService) LeaveReview(buyerID, offerID uint, rating int, text string) (*domain.Review, error) {
+	args := m.Called(buyerID, offerID, rating, text)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Review), args.Error(1)
+}
+
+func (m *MockReviewService) GetProfile(username string) (*domain.SellerProfile, error) {
+	args := m.Called(username)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SellerProfile), args.Error(1)
+}
+
+func TestReviewHandler(t *testing.T) {
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	review := domain.Review{ID: 3, OfferID: 7, AdID: 1, Ad: domain.Advertisement{Title: "Bike"}, SellerID: 1, BuyerID: 2,
+		Buyer: domain.User{Username: "buyer"}, Rating: 5, Text: "Отлично", CreatedAt: createdAt}
+
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		userID       uint
+		mockSetup    func(*MockReviewService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:   "Leave review",
+			method: "POST",
+			path:   "/offers/7/review",
+			body:   `{"rating":5,"text":"Отлично"}`,
+			userID: 2,
+			mockSetup: func(m *MockReviewService) {
+				m.On("LeaveReview", uint(2), uint(7), 5, "Отлично").Return(&review, nil)
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":3,"ad_id":1,"ad_title":"Bike","buyer_login":"buyer","rating":5,"text":"Отлично","created_at":"2025-01-01T12:00:00Z"}`,
+		},
+		{
+			name:         "Missing rating",
+			method:       "POST",
+			path:         "/offers/7/review",
+			body:         `{"text":"Отлично"}`,
+			userID:       2,
+			mockSetup:    func(m *MockReviewService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Deal not completed",
+			method: "POST",
+			path:   "/offers/7/review",
+			body:   `{"rating":4}`,
+			userID: 2,
+			mockSetup: func(m *MockReviewService) {
+				m.On("LeaveReview", uint(2), uint(7), 4, "").Return(nil, domain.ErrInvalidStatusTransition)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:   "Already reviewed",
+			method: "POST",
+			path:   "/offers/7/review",
+			body:   `{"rating":4}`,
+			userID: 2,
+			mockSetup: func(m *MockReviewService) {
+				m.On("LeaveReview", uint(2), uint(7), 4, "").Return(nil, domain.ErrConflict)
+			},
+			expectedCode: http.StatusConflict,
+		},
+		{
+			name:         "Unauthorized",
+			method:       "POST",
+			path:         "/offers/7/review",
+			body:         `{"rating":4}`,
+			mockSetup:    func(m *MockReviewService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "Profile",
+			method: "GET",
+			path:   "/users/seller",
+			mockSetup: func(m *MockReviewService) {
+				m.On("GetProfile", "seller").Return(&domain.SellerProfile{
+					User: domain.User{ID: 1, Username: "seller", DisplayName: "Иван", City: "Москва", RatingSum: 9, RatingCount: 2,
+						CreatedAt: createdAt},
+					Reviews: []domain.Review{review},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"username":"seller","display_name":"Иван","avatar_url":"","city":"Москва","about":"",
+				"created_at":"2025-01-01T12:00:00Z","rating":4.5,"review_count":2,"reviews":[
+				{"id":3,"ad_id":1,"ad_title":"Bike","buyer_login":"buyer","rating":5,"text":"Отлично","created_at":"2025-01-01T12:00:00Z"}]}`,
+		},
+		{
+			name:   "Profile without reviews",
+			method: "GET",
+			path:   "/users/newbie",
+			mockSetup: func(m *MockReviewService) {
+				m.On("GetProfile", "newbie").Return(&domain.SellerProfile{
+					User: domain.User{ID: 4, Username: "newbie", CreatedAt: createdAt},
+				}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"username":"newbie","display_name":"","avatar_url":"","city":"","about":"","created_at":"2025-01-01T12:00:00Z","rating":null,"review_count":0,"reviews":[]}`,
+		},
+		{
+			name:   "Unknown user",
+			method: "GET",
+			path:   "/users/nobody",
+			mockSetup: func(m *MockReviewService) {
+				m.On("GetProfile", "nobody").Return(nil, domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockReviewService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewReviewHandler(mockService)
+			router := setupTestRouter()
+			router.Use(func(c *gin.Context) {
+				if tt.userID != 0 {
+					c.Set("userID", tt.userID)
+				}
+			})
+			router.POST("/offers/:id/review", handler.LeaveReview)
+			router.GET("/users/:username", handler.GetProfile)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
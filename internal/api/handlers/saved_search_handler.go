@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+type SavedSearchService interface {
+	CreateSavedSearch(userID uint, name string, filter domain.AdFilter) (*domain.SavedSearch, error)
+	ListSavedSearches(userID uint) ([]domain.SavedSearch, error)
+	DeleteSavedSearch(userID, searchID uint) error
+}
+
+type SavedSearchHandler struct {
+	searchService SavedSearchService
+}
+
+func NewSavedSearchHandler(searchService SavedSearchService) *SavedSearchHandler {
+	return &SavedSearchHandler{searchService: searchService}
+}
+
+// CreateSavedSearchRequest - фильтры GET /ads с теми же именами параметров
+type CreateSavedSearchRequest struct {
+	Name       string  `json:"name" binding:"required,max=100"`
+	Query      string  `json:"q"`
+	MinPrice   float64 `json:"min_price" binding:"min=0"`
+	MaxPrice   float64 `json:"max_price" binding:"min=0"`
+	CategoryID uint    `json:"category_id"`
+	SortBy     string  `json:"sort_by" binding:"omitempty,oneof=created_at price relevance"`
+	Order      string  `json:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+type responseSavedSearch struct {
+	ID         uint      `json:"id"`
+	Name       string    `json:"name"`
+	Query      string    `json:"q"`
+	MinPrice   float64   `json:"min_price"`
+	MaxPrice   float64   `json:"max_price"`
+	CategoryID uint      `json:"category_id"`
+	SortBy     string    `json:"sort_by"`
+	Order      string    `json:"order"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func newResponseSavedSearch(search domain.SavedSearch) responseSavedSearch {
+	return responseSavedSearch{
+		ID:         search.ID,
+		Name:       search.Name,
+		Query:      search.Query,
+		MinPrice:   search.MinPrice,
+		MaxPrice:   search.MaxPrice,
+		CategoryID: search.CategoryID,
+		SortBy:     search.SortBy,
+		Order:      search.Order,
+		CreatedAt:  search.CreatedAt,
+	}
+}
+
+func (h *SavedSearchHandler) CreateSavedSearch(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	var req CreateSavedSearchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	search, err := h.searchService.CreateSavedSearch(userID, req.Name, domain.AdFilter{
+		Query:      req.Query,
+		MinPrice:   req.MinPrice,
+		MaxPrice:   req.MaxPrice,
+		CategoryID: req.CategoryID,
+		SortBy:     req.SortBy,
+		Order:      req.Order,
+	})
+	if err != nil {
+		logger.Log.Warn("Failed to create saved search",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(listErrorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	logger.Log.Info("Saved search created",
+		"saved_search_id", search.ID,
+		"user_id", userID,
+	)
+
+	c.JSON(http.StatusCreated, newResponseSavedSearch(*search))
+}
+
+func (h *SavedSearchHandler) ListSavedSearches(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	searches, err := h.searchService.ListSavedSearches(userID)
+	if err != nil {
+		logger.Log.Error("Failed to list saved searches",
+			"error", err,
+			"user_id", userID,
+		)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to list saved searches"})
+		return
+	}
+
+	response := make([]responseSavedSearch, 0, len(searches))
+	for _, search := range searches {
+		response = append(response, newResponseSavedSearch(search))
+	}
+
+	c.JSON(http.StatusOK, gin.H{"items": response})
+}
+
+func (h *SavedSearchHandler) DeleteSavedSearch(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	searchID, ok := parseIDParam(c, "id")
+	if !ok {
+		return
+	}
+
+	if err := h.searchService.DeleteSavedSearch(userID, searchID); err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,148 @@
+package handlers_test
+
+import (
+	"bytes"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockSavedSearchService struct {
+	mock.Mock
+}
+
+func (m *MockSavedSearchService) CreateSavedSearch(userID uint, name string, filter domain.AdFilter) (*domain.SavedSearch, error) {
+	args := m.Called(userID, name, filter)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchService) ListSavedSearches(userID uint) ([]domain.SavedSearch, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]domain.SavedSearch), args.Error(1)
+}
+
+func (m *MockSavedSearchService) DeleteSavedSearch(userID, searchID uint) error {
+	args := m.Called(userID, searchID)
+	return args.Error(0)
+}
+
+func TestSavedSearchHandler(t *testing.T) {
+	tests := []struct {
+		name         string
+		method       string
+		path         string
+		body         string
+		anonymous    bool
+		mockSetup    func(*MockSavedSearchService)
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:   "Create saved search",
+			method: "POST",
+			path:   "/me/searches",
+			body:   `{"name":"Телефоны","q":"iphone","max_price":1000,"category_id":2}`,
+			mockSetup: func(m *MockSavedSearchService) {
+				m.On("CreateSavedSearch", uint(1), "Телефоны", domain.AdFilter{Query: "iphone", MaxPrice: 1000, CategoryID: 2}).
+					Return(&domain.SavedSearch{ID: 3, Name: "Телефоны", Query: "iphone", MaxPrice: 1000, CategoryID: 2,
+						SortBy: "created_at", Order: "desc", CreatedAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)}, nil)
+			},
+			expectedCode: http.StatusCreated,
+			expectedBody: `{"id":3,"name":"Телефоны","q":"iphone","min_price":0,"max_price":1000,"category_id":2,"sort_by":"created_at","order":"desc","created_at":"2025-01-01T00:00:00Z"}`,
+		},
+		{
+			name:         "Create without name",
+			method:       "POST",
+			path:         "/me/searches",
+			body:         `{"q":"iphone"}`,
+			mockSetup:    func(m *MockSavedSearchService) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:   "Create over limit",
+			method: "POST",
+			path:   "/me/searches",
+			body:   `{"name":"Ещё один"}`,
+			mockSetup: func(m *MockSavedSearchService) {
+				m.On("CreateSavedSearch", uint(1), "Ещё один", domain.AdFilter{}).Return(nil, domain.ErrInvalidInput)
+			},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "Create without auth",
+			method:       "POST",
+			path:         "/me/searches",
+			body:         `{"name":"Телефоны"}`,
+			anonymous:    true,
+			mockSetup:    func(m *MockSavedSearchService) {},
+			expectedCode: http.StatusUnauthorized,
+		},
+		{
+			name:   "List saved searches",
+			method: "GET",
+			path:   "/me/searches",
+			mockSetup: func(m *MockSavedSearchService) {
+				m.On("ListSavedSearches", uint(1)).Return([]domain.SavedSearch{}, nil)
+			},
+			expectedCode: http.StatusOK,
+			expectedBody: `{"items":[]}`,
+		},
+		{
+			name:   "Delete foreign saved search",
+			method: "DELETE",
+			path:   "/me/searches/5",
+			mockSetup: func(m *MockSavedSearchService) {
+				m.On("DeleteSavedSearch", uint(1), uint(5)).Return(domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:   "Delete saved search",
+			method: "DELETE",
+			path:   "/me/searches/3",
+			mockSetup: func(m *MockSavedSearchService) {
+				m.On("DeleteSavedSearch", uint(1), uint(3)).Return(nil)
+			},
+			expectedCode: http.StatusNoContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockSavedSearchService)
+			tt.mockSetup(mockService)
+
+			handler := handlers.NewSavedSearchHandler(mockService)
+			router := setupTestRouter()
+			router.Use(func(c *gin.Context) {
+				if !tt.anonymous {
+					c.Set("userID", uint(1))
+				}
+			})
+			router.POST("/me/searches", handler.CreateSavedSearch)
+			router.GET("/me/searches", handler.ListSavedSearches)
+			router.DELETE("/me/searches/:id", handler.DeleteSavedSearch)
+
+			req, _ := http.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			if tt.expectedBody != "" {
+				assert.JSONEq(t, tt.expectedBody, w.Body.String())
+			}
+			mockService.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/events"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+// StreamHeartbeatInterval - период комментариев-пингов, которые не дают прокси закрыть соединение
+const StreamHeartbeatInterval = 25 * time.Second
+
+// Пауза перед переподключением, которую сервер предлагает клиенту (поле retry в SSE)
+const streamRetry = 3 * time.Second
+
+type EventSubscriber interface {
+	Subscribe(userID uint) (*events.Subscription, error)
+	Unsubscribe(sub *events.Subscription)
+}
+
+// StreamTokenValidator проверяет токен открытого потока: поток живёт дольше одного запроса
+type StreamTokenValidator interface {
+	ValidateToken(token string) (uint, domain.Role, error)
+	TokenExpiresAt(token string) (time.Time, error)
+}
+
+type StreamHandler struct {
+	events    EventSubscriber
+	tokens    StreamTokenValidator
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(events EventSubscriber, tokens StreamTokenValidator, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{events: events, tokens: tokens, heartbeat: heartbeat}
+}
+
+// Stream - поток событий пользователя в формате Server-Sent Events. Соединение закрывается,
+// если клиент не успевает читать события или сервер останавливается; клиент переподключается сам.
+// Поток также закрывается по истечении access-токена, а на каждом пинге токен проверяется заново,
+// так что выход из системы и блокировка обрывают поток не позже чем через период пинга.
+func (h *StreamHandler) Stream(c *gin.Context) {
+	userID := currentUserID(c)
+	if userID == 0 {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+		return
+	}
+
+	token := c.GetHeader("Authorization")
+	expiresAt, err := h.tokens.TokenExpiresAt(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+		return
+	}
+
+	sub, err := h.events.Subscribe(userID)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "event stream is unavailable"})
+		return
+	}
+	defer h.events.Unsubscribe(sub)
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", "text/event-stream")
+	header.Set("Cache-Control", "no-cache")
+	header.Set("Connection", "keep-alive")
+	// nginx не должен буферизовать поток
+	header.Set("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", streamRetry.Milliseconds())
+	c.Writer.Flush()
+
+	logger.Log.Debug("Event stream opened", "user_id", userID)
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+	expiry := time.NewTimer(time.Until(expiresAt))
+	defer expiry.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			logger.Log.Debug("Event stream closed by client", "user_id", userID)
+			return
+
+		case event, ok := <-sub.Events():
+			if !ok {
+				if errors.Is(sub.Err(), events.ErrSlowSubscriber) {
+					logger.Log.Warn("Event stream dropped: client is too slow", "user_id", userID)
+				}
+				return
+			}
+			fmt.Fprintf(c.Writer, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+			c.Writer.Flush()
+
+		case <-expiry.C:
+			logger.Log.Debug("Event stream closed: token expired", "user_id", userID)
+			return
+
+		case <-heartbeat.C:
+			if _, _, err := h.tokens.ValidateToken(token); err != nil {
+				logger.Log.Info("Event stream closed: token is no longer valid",
+					"user_id", userID,
+					"error", err.Error(),
+				)
+				return
+			}
+			fmt.Fprint(c.Writer, ": ping\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
@@ -0,0 +1,208 @@
+package handlers_test
+
+import (
+	"bufio"
+	"errors"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/events"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// streamTokens - токен потока, который тест может отозвать или сделать истекающим
+type streamTokens struct {
+	mu        sync.Mutex
+	expiresAt time.Time
+	err       error
+}
+
+func validStreamTokens() *streamTokens {
+	return &streamTokens{expiresAt: time.Now().Add(time.Hour)}
+}
+
+func (s *streamTokens) ValidateToken(token string) (uint, domain.Role, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return 0, "", s.err
+	}
+	return 1, domain.RoleUser, nil
+}
+
+func (s *streamTokens) TokenExpiresAt(token string) (time.Time, error) {
+	if token != "valid" {
+		return time.Time{}, errors.New("invalid token")
+	}
+	return s.expiresAt, nil
+}
+
+func (s *streamTokens) revoke(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+func startStreamServer(t *testing.T, hub *events.Hub, tokens *streamTokens, heartbeat time.Duration) *httptest.Server {
+	handler := handlers.NewStreamHandler(hub, tokens, heartbeat)
+	router := setupTestRouter()
+	router.GET("/stream", func(c *gin.Context) {
+		if c.Query("anonymous") == "" {
+			c.Set("userID", uint(1))
+		}
+		handler.Stream(c)
+	})
+
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// readFrame читает один кадр SSE (строки до пустой)
+func readFrame(t *testing.T, reader *bufio.Reader) string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return strings.Join(lines, "\n")
+		}
+		lines = append(lines, line)
+	}
+}
+
+func openStream(t *testing.T, server *httptest.Server, token string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, server.URL+"/stream", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", token)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// waitClosed читает поток, пока сервер его не закроет
+func waitClosed(t *testing.T, reader *bufio.Reader) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			if _, err := reader.ReadString('\n'); err != nil {
+				return
+			}
+		}
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("stream was not closed")
+	}
+}
+
+func waitSubscribers(t *testing.T, hub *events.Hub, n int) {
+	require.Eventually(t, func() bool { return hub.Len() == n }, time.Second, 5*time.Millisecond)
+}
+
+func TestStreamHandler_Events(t *testing.T) {
+	hub := events.NewHub(events.DefaultBuffer)
+	server := startStreamServer(t, hub, validStreamTokens(), time.Hour)
+
+	resp := openStream(t, server, "valid")
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	assert.Equal(t, "retry: 3000", readFrame(t, reader))
+	waitSubscribers(t, hub, 1)
+
+	hub.Publish(events.New("message", 2, map[string]string{"text": "чужое"}))
+	hub.Publish(events.New("message", 1, map[string]string{"text": "<привет>"}))
+	hub.Publish(events.New("ad_created", 0, map[string]int{"id": 7}))
+
+	assert.Equal(t, "event: message\ndata: {\"text\":\"<привет>\"}", readFrame(t, reader))
+	assert.Equal(t, "event: ad_created\ndata: {\"id\":7}", readFrame(t, reader))
+
+	// остановка сервера закрывает поток
+	hub.Close()
+	_, err := reader.ReadString('\n')
+	assert.Error(t, err)
+}
+
+func TestStreamHandler_Heartbeat(t *testing.T) {
+	hub := events.NewHub(events.DefaultBuffer)
+	server := startStreamServer(t, hub, validStreamTokens(), 10*time.Millisecond)
+
+	resp := openStream(t, server, "valid")
+
+	reader := bufio.NewReader(resp.Body)
+	readFrame(t, reader)
+	assert.Equal(t, ": ping", readFrame(t, reader))
+
+	// после отключения клиента подписка снимается
+	resp.Body.Close()
+	waitSubscribers(t, hub, 0)
+}
+
+func TestStreamHandler_Unavailable(t *testing.T) {
+	hub := events.NewHub(events.DefaultBuffer)
+	server := startStreamServer(t, hub, validStreamTokens(), time.Hour)
+
+	resp, err := http.Get(server.URL + "/stream?anonymous=1")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	resp = openStream(t, server, "forged")
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	hub.Close()
+	resp = openStream(t, server, "valid")
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+}
+
+func TestStreamHandler_TokenExpired(t *testing.T) {
+	hub := events.NewHub(events.DefaultBuffer)
+	tokens := validStreamTokens()
+	tokens.expiresAt = time.Now().Add(50 * time.Millisecond)
+	server := startStreamServer(t, hub, tokens, time.Hour)
+
+	resp := openStream(t, server, "valid")
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// поток закрывается вместе с истечением токена, даже без пингов
+	waitClosed(t, bufio.NewReader(resp.Body))
+	waitSubscribers(t, hub, 0)
+}
+
+func TestStreamHandler_TokenRevoked(t *testing.T) {
+	for name, err := range map[string]error{
+		"logout": errors.New("token revoked"),
+		"ban":    domain.ErrUserBanned,
+	} {
+		t.Run(name, func(t *testing.T) {
+			hub := events.NewHub(events.DefaultBuffer)
+			tokens := validStreamTokens()
+			server := startStreamServer(t, hub, tokens, 10*time.Millisecond)
+
+			resp := openStream(t, server, "valid")
+			reader := bufio.NewReader(resp.Body)
+			readFrame(t, reader)
+			assert.Equal(t, ": ping", readFrame(t, reader))
+
+			// после отзыва токена ближайший пинг закрывает поток
+			tokens.revoke(err)
+			waitClosed(t, reader)
+			waitSubscribers(t, hub, 0)
+		})
+	}
+}
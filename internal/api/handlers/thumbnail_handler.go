@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+	"github.com/keenetic29/vk-internship/pkg/safehttp"
+	"github.com/keenetic29/vk-internship/pkg/thumbnail"
+)
+
+// ThumbnailPath - префикс ссылок на миниатюры: /images/{ad_id}/{size}
+const ThumbnailPath = "/images/"
+
+const ThumbnailFetchTimeout = 10 * time.Second
+
+var errSourceUnavailable = errors.New("source image is unavailable")
+
+type ThumbnailCache interface {
+	Get(key string) ([]byte, string, bool)
+	Put(key string, data []byte) (string, error)
+}
+
+type ThumbnailAdService interface {
+	GetAd(id, viewerID uint) (*domain.Advertisement, error)
+}
+
+// thumbnailCall - генерация миниатюры, которую ждут параллельные запросы того же ключа
+type thumbnailCall struct {
+	done chan struct{}
+	data []byte
+	etag string
+	err  error
+}
+
+type ThumbnailHandler struct {
+	adService  ThumbnailAdService
+	store      ImageStore
+	cache      ThumbnailCache
+	httpClient HTTPClient
+	baseURL    string
+
+	mu       sync.Mutex
+	inflight map[string]*thumbnailCall
+}
+
+func NewThumbnailHandler(adService ThumbnailAdService, store ImageStore, cache ThumbnailCache, baseURL string) *ThumbnailHandler {
+	return &ThumbnailHandler{
+		adService: adService,
+		store:     store,
+		cache:     cache,
+		// исходники лежат на сторонних серверах, поэтому внутренние адреса недоступны (защита от SSRF)
+		httpClient: safehttp.NewClient(safehttp.Options{
+			Timeout:      ThumbnailFetchTimeout,
+			MaxRedirects: ImageMaxRedirects,
+		}),
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		inflight: make(map[string]*thumbnailCall),
+	}
+}
+
+// устанавливает HTTPClient (для тестов)
+func (h *ThumbnailHandler) SetHTTPClient(client HTTPClient) {
+	h.httpClient = client
+}
+
+// thumbnailURLs - ссылки на миниатюры обложки объявления по размерам
+func thumbnailURLs(ad domain.Advertisement) map[string]string {
+	// у удалённого объявления миниатюр нет
+	if ad.ImageURL == "" || ad.DeletedAt.Valid {
+		return nil
+	}
+	urls := make(map[string]string, len(thumbnail.Sizes))
+	for name := range thumbnail.Sizes {
+		urls[name] = ThumbnailPath + strconv.FormatUint(uint64(ad.ID), 10) + "/" + name
+	}
+	return urls
+}
+
+// thumbnailKey зависит от ссылки на исходник, поэтому смена обложки не отдаёт старую миниатюру
+func thumbnailKey(sourceURL, size string) string {
+	sum := sha256.Sum256([]byte(size + "\x00" + sourceURL))
+	return size + "-" + hex.EncodeToString(sum[:16])
+}
+
+// etagMatches проверяет If-None-Match (слабое сравнение, как требует RFC 9110)
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// readSource читает исходное изображение: загруженное к нам - из хранилища, остальные - по ссылке
+func (h *ThumbnailHandler) readSource(ctx context.Context, sourceURL string) ([]byte, error) {
+	if key, ok := strings.CutPrefix(sourceURL, h.baseURL+MediaPath); ok {
+		body, _, err := h.store.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		defer body.Close()
+		return io.ReadAll(io.LimitReader(body, MaxImageSize))
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, ThumbnailFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := h.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxImageSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxImageSize {
+		return nil, errors.New("image size exceeds maximum limit")
+	}
+	return data, nil
+}
+
+// generate строит миниатюру и кладёт её в кэш; параллельные запросы одного ключа
+// ждут первую генерацию, так что исходник скачивается один раз
+func (h *ThumbnailHandler) generate(key, sourceURL string, size thumbnail.Size) ([]byte, string, error) {
+	h.mu.Lock()
+	if call, ok := h.inflight[key]; ok {
+		h.mu.Unlock()
+		<-call.done
+		return call.data, call.etag, call.err
+	}
+	call := &thumbnailCall{done: make(chan struct{})}
+	h.inflight[key] = call
+	h.mu.Unlock()
+
+	defer func() {
+		h.mu.Lock()
+		delete(h.inflight, key)
+		h.mu.Unlock()
+		close(call.done)
+	}()
+
+	// запрос клиента может оборваться, а результат нужен и остальным ожидающим
+	source, err := h.readSource(context.Background(), sourceURL)
+	if err != nil {
+		call.err = fmt.Errorf("%w: %v", errSourceUnavailable, err)
+		return nil, "", call.err
+	}
+
+	call.data, call.err = thumbnail.Generate(source, size)
+	if call.err != nil {
+		return nil, "", call.err
+	}
+
+	call.etag, call.err = h.cache.Put(key, call.data)
+	return call.data, call.etag, call.err
+}
+
+// GetThumbnail отдаёт миниатюру обложки объявления; ETag - хэш содержимого
+func (h *ThumbnailHandler) GetThumbnail(c *gin.Context) {
+	adID, ok := parseIDParam(c, "ad_id")
+	if !ok {
+		return
+	}
+
+	sizeName := c.Param("size")
+	size, err := thumbnail.Lookup(sizeName)
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	ad, err := h.adService.GetAd(adID, currentUserID(c))
+	if err != nil {
+		c.JSON(errorStatus(err), gin.H{"error": err.Error()})
+		return
+	}
+	if ad.ImageURL == "" {
+		c.JSON(http.StatusNotFound, gin.H{"error": "advertisement has no image"})
+		return
+	}
+
+	key := thumbnailKey(ad.ImageURL, sizeName)
+	data, etag, ok := h.cache.Get(key)
+	if !ok {
+		data, etag, err = h.generate(key, ad.ImageURL, size)
+		if err != nil {
+			logger.Log.Warn("Failed to generate thumbnail",
+				"error", err,
+				"ad_id", adID,
+				"size", sizeName,
+				"source", ad.ImageURL,
+			)
+			status := http.StatusInternalServerError
+			if errors.Is(err, errSourceUnavailable) || errors.Is(err, thumbnail.ErrInvalidSource) ||
+				errors.Is(err, thumbnail.ErrSourceTooBig) {
+				status = http.StatusBadGateway
+			}
+			c.JSON(status, gin.H{"error": "unable to load image"})
+			return
+		}
+	}
+
+	etag = `"` + etag + `"`
+	c.Header("ETag", etag)
+	// обложка может смениться, поэтому кэшируем ненадолго и перепроверяем по ETag;
+	// черновик виден только владельцу и в общие кэши попадать не должен
+	if ad.Status == domain.AdStatusDraft {
+		c.Header("Cache-Control", "private, max-age=300")
+	} else {
+		c.Header("Cache-Control", "public, max-age=300")
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	c.Data(http.StatusOK, thumbnail.ContentType, data)
+}
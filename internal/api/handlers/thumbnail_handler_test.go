@@ -0,0 +1,175 @@
+package handlers_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/keenetic29/vk-internship/internal/api/handlers"
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/diskcache"
+	"image/jpeg"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupThumbnailRouter(t *testing.T, as *MockAdvertisementService, hc *MockHTTPClient, store *memoryImageStore) *gin.Engine {
+	cache, err := diskcache.New(t.TempDir(), 1<<20)
+	require.NoError(t, err)
+
+	handler := handlers.NewThumbnailHandler(as, store, cache, "")
+	handler.SetHTTPClient(hc)
+
+	router := setupTestRouter()
+	router.GET("/images/:ad_id/:size", handler.GetThumbnail)
+	return router
+}
+
+func getThumbnail(router *gin.Engine, path, etag string) *httptest.ResponseRecorder {
+	req, _ := http.NewRequest("GET", path, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestThumbnailHandler_UploadedImage(t *testing.T) {
+	store := newMemoryImageStore()
+	store.Put(context.Background(), "ads/1/photo.jpg", "image/jpeg", jpegData(800, 600))
+
+	mockService := new(MockAdvertisementService)
+	mockService.On("GetAd", uint(1), uint(0)).
+		Return(&domain.Advertisement{ID: 1, Status: domain.AdStatusPublished, ImageURL: "/media/ads/1/photo.jpg"}, nil)
+
+	router := setupThumbnailRouter(t, mockService, new(MockHTTPClient), store)
+
+	w := getThumbnail(router, "/images/1/small", "")
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+
+	config, err := jpeg.DecodeConfig(bytes.NewReader(w.Body.Bytes()))
+	require.NoError(t, err)
+	assert.Equal(t, 160, config.Width)
+	assert.Equal(t, 120, config.Height)
+
+	etag := w.Header().Get("ETag")
+	assert.Regexp(t, `^"[0-9a-f]+"$`, etag)
+
+	// повторный запрос с тем же ETag
+	w = getThumbnail(router, "/images/1/small", etag)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Empty(t, w.Body.Bytes())
+
+	// другой размер - другое содержимое
+	w = getThumbnail(router, "/images/1/medium", etag)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotEqual(t, etag, w.Header().Get("ETag"))
+}
+
+func TestThumbnailHandler_RemoteImageFetchedOnce(t *testing.T) {
+	const source = "http://example.com/photo.jpg"
+
+	mockService := new(MockAdvertisementService)
+	mockService.On("GetAd", uint(1), uint(0)).
+		Return(&domain.Advertisement{ID: 1, Status: domain.AdStatusPublished, ImageURL: source}, nil)
+
+	mockHTTPClient := new(MockHTTPClient)
+	mockHTTPClient.On("Do", http.MethodGet, source).Return(&http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{"Content-Type": []string{"image/jpeg"}},
+		Body:       io.NopCloser(bytes.NewReader(jpegData(300, 300))),
+	}, nil).Once()
+
+	router := setupThumbnailRouter(t, mockService, mockHTTPClient, newMemoryImageStore())
+
+	first := getThumbnail(router, "/images/1/small", "")
+	second := getThumbnail(router, "/images/1/small", "")
+
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, http.StatusOK, second.Code)
+	assert.Equal(t, first.Header().Get("ETag"), second.Header().Get("ETag"))
+	assert.Equal(t, first.Body.Bytes(), second.Body.Bytes())
+	mockHTTPClient.AssertExpectations(t)
+}
+
+func TestThumbnailHandler_Errors(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		mockSetup    func(*MockAdvertisementService, *MockHTTPClient)
+		expectedCode int
+	}{
+		{
+			name:         "Unknown size",
+			path:         "/images/1/huge",
+			mockSetup:    func(as *MockAdvertisementService, hc *MockHTTPClient) {},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name:         "Invalid ad id",
+			path:         "/images/abc/small",
+			mockSetup:    func(as *MockAdvertisementService, hc *MockHTTPClient) {},
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name: "Hidden draft",
+			path: "/images/1/small",
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("GetAd", uint(1), uint(0)).Return((*domain.Advertisement)(nil), domain.ErrNotFound)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Ad without image",
+			path: "/images/1/small",
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("GetAd", uint(1), uint(0)).Return(&domain.Advertisement{ID: 1}, nil)
+			},
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Source unavailable",
+			path: "/images/1/small",
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("GetAd", uint(1), uint(0)).Return(&domain.Advertisement{ID: 1, ImageURL: "http://gone.com/a.jpg"}, nil)
+				hc.On("Do", http.MethodGet, "http://gone.com/a.jpg").Return((*http.Response)(nil), errors.New("connection error"))
+			},
+			expectedCode: http.StatusBadGateway,
+		},
+		{
+			name: "Source is not an image",
+			path: "/images/1/small",
+			mockSetup: func(as *MockAdvertisementService, hc *MockHTTPClient) {
+				as.On("GetAd", uint(1), uint(0)).Return(&domain.Advertisement{ID: 1, ImageURL: "http://html.com/a.jpg"}, nil)
+				hc.On("Do", http.MethodGet, "http://html.com/a.jpg").Return(&http.Response{
+					StatusCode: http.StatusOK,
+					Body:       io.NopCloser(bytes.NewReader([]byte("<html></html>"))),
+				}, nil)
+			},
+			expectedCode: http.StatusBadGateway,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockAdvertisementService)
+			mockHTTPClient := new(MockHTTPClient)
+			tt.mockSetup(mockService, mockHTTPClient)
+
+			router := setupThumbnailRouter(t, mockService, mockHTTPClient, newMemoryImageStore())
+			w := getThumbnail(router, tt.path, "")
+
+			assert.Equal(t, tt.expectedCode, w.Code)
+			mockService.AssertExpectations(t)
+			mockHTTPClient.AssertExpectations(t)
+		})
+	}
+}
@@ -1,39 +1,147 @@
 package api
 
 import (
+	"errors"
 	"github.com/keenetic29/vk-internship/internal/api/handlers"
-	"github.com/keenetic29/vk-internship/internal/services"
+	"github.com/keenetic29/vk-internship/internal/domain"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(
-	authService handlers.AuthService,
-	adService handlers.AdvertisementService,
-	jwtSecret string,
-) *gin.Engine {
+// Dependencies - сервисы и хранилища, из которых собирается роутер
+type Dependencies struct {
+	AuthService         handlers.AuthService
+	AdService           handlers.AdvertisementService
+	CategoryService     handlers.CategoryService
+	AdminService        handlers.AdminService
+	SavedSearchService  handlers.SavedSearchService
+	NotificationService handlers.NotificationService
+	MessagingService    handlers.MessagingService
+	OfferService        handlers.OfferService
+	ReviewService       handlers.ReviewService
+	ProfileService      handlers.ProfileService
+	Events              handlers.EventSubscriber
+	KeySet              handlers.KeySetProvider
+	ImageStore          handlers.ImageStore
+	ThumbnailCache      handlers.ThumbnailCache
+	MediaBaseURL        string
+}
+
+func SetupRouter(deps Dependencies) *gin.Engine {
 	router := gin.Default()
 
-	authHandler := handlers.NewAuthHandler(authService)
-	adHandler := handlers.NewAdvertisementHandler(adService)
+	authHandler := handlers.NewAuthHandler(deps.AuthService)
+	adHandler := handlers.NewAdvertisementHandler(deps.AdService)
+	categoryHandler := handlers.NewCategoryHandler(deps.CategoryService)
+	keyHandler := handlers.NewKeyHandler(deps.KeySet)
+	adminHandler := handlers.NewAdminHandler(deps.AdminService)
+	imageHandler := handlers.NewImageHandler(deps.AdService, deps.ImageStore, deps.MediaBaseURL)
+	favoriteHandler := handlers.NewFavoriteHandler(deps.AdService)
+	savedSearchHandler := handlers.NewSavedSearchHandler(deps.SavedSearchService)
+	notificationHandler := handlers.NewNotificationHandler(deps.NotificationService)
+	messagingHandler := handlers.NewMessagingHandler(deps.MessagingService)
+	offerHandler := handlers.NewOfferHandler(deps.OfferService)
+	reviewHandler := handlers.NewReviewHandler(deps.ReviewService)
+	profileHandler := handlers.NewProfileHandler(deps.ProfileService)
+	streamHandler := handlers.NewStreamHandler(deps.Events, deps.AuthService, handlers.StreamHeartbeatInterval)
+	thumbnailHandler := handlers.NewThumbnailHandler(deps.AdService, deps.ImageStore, deps.ThumbnailCache, deps.MediaBaseURL)
 
 	authGroup := router.Group("/auth")
 	{
 		authGroup.POST("/register", authHandler.Register)
 		authGroup.POST("/login", authHandler.Login)
+		authGroup.POST("/refresh", authHandler.Refresh)
+		authGroup.POST("/logout", JWTMiddleware(deps.AuthService), authHandler.Logout)
 	}
 
 	apiGroup := router.Group("/ads")
 	{
-		apiGroup.GET("", Middleware(jwtSecret), adHandler.GetAds)
-		apiGroup.POST("", JWTMiddleware(jwtSecret), adHandler.CreateAd)
+		apiGroup.GET("", Middleware(deps.AuthService), adHandler.GetAds)
+		apiGroup.POST("", JWTMiddleware(deps.AuthService), adHandler.CreateAd)
+		apiGroup.GET("/:id", Middleware(deps.AuthService), adHandler.GetAd)
+		apiGroup.PATCH("/:id", JWTMiddleware(deps.AuthService), adHandler.UpdateAd)
+		apiGroup.DELETE("/:id", JWTMiddleware(deps.AuthService), adHandler.DeleteAd)
+		apiGroup.POST("/:id/images", JWTMiddleware(deps.AuthService), imageHandler.UploadImage)
+		apiGroup.PUT("/:id/images", JWTMiddleware(deps.AuthService), imageHandler.ReorderImages)
+		apiGroup.DELETE("/:id/images/:image_id", JWTMiddleware(deps.AuthService), imageHandler.RemoveImage)
+		apiGroup.POST("/:id/images/:image_id/cover", JWTMiddleware(deps.AuthService), imageHandler.SetCover)
+		apiGroup.POST("/:id/favorite", JWTMiddleware(deps.AuthService), favoriteHandler.AddFavorite)
+		apiGroup.DELETE("/:id/favorite", JWTMiddleware(deps.AuthService), favoriteHandler.RemoveFavorite)
+		apiGroup.POST("/:id/messages", JWTMiddleware(deps.AuthService), messagingHandler.SendToAd)
+		apiGroup.POST("/:id/offers", JWTMiddleware(deps.AuthService), offerHandler.MakeOffer)
+		apiGroup.GET("/:id/offers", JWTMiddleware(deps.AuthService), offerHandler.ListAdOffers)
+		apiGroup.POST("/:id/publish", JWTMiddleware(deps.AuthService), adHandler.ChangeStatus(domain.AdStatusPublished))
+		apiGroup.POST("/:id/reserve", JWTMiddleware(deps.AuthService), adHandler.ChangeStatus(domain.AdStatusReserved))
+		apiGroup.POST("/:id/sell", JWTMiddleware(deps.AuthService), adHandler.ChangeStatus(domain.AdStatusSold))
+		apiGroup.POST("/:id/archive", JWTMiddleware(deps.AuthService), adHandler.ChangeStatus(domain.AdStatusArchived))
+	}
+
+	meGroup := router.Group("/me", JWTMiddleware(deps.AuthService))
+	{
+		meGroup.PATCH("", profileHandler.UpdateProfile)
+		meGroup.GET("/favorites", favoriteHandler.GetFavorites)
+		meGroup.GET("/searches", savedSearchHandler.ListSavedSearches)
+		meGroup.POST("/searches", savedSearchHandler.CreateSavedSearch)
+		meGroup.DELETE("/searches/:id", savedSearchHandler.DeleteSavedSearch)
+		meGroup.GET("/notifications", notificationHandler.ListNotifications)
+		meGroup.GET("/conversations", messagingHandler.ListConversations)
+		meGroup.GET("/offers", offerHandler.ListMyOffers)
+		meGroup.POST("/notifications/read", notificationHandler.MarkRead)
+	}
+
+	conversationGroup := router.Group("/conversations", JWTMiddleware(deps.AuthService))
+	{
+		conversationGroup.GET("/:id/messages", messagingHandler.GetMessages)
+		conversationGroup.POST("/:id/messages", messagingHandler.Reply)
+	}
+
+	offerGroup := router.Group("/offers", JWTMiddleware(deps.AuthService))
+	{
+		offerGroup.POST("/:id/accept", offerHandler.Accept)
+		offerGroup.POST("/:id/reject", offerHandler.Reject)
+		offerGroup.POST("/:id/counter", offerHandler.Counter)
+		offerGroup.POST("/:id/withdraw", offerHandler.Withdraw)
+		offerGroup.POST("/:id/review", reviewHandler.LeaveReview)
+	}
+
+	router.GET("/stream", JWTMiddleware(deps.AuthService), streamHandler.Stream)
+	router.GET("/users/:username", reviewHandler.GetProfile)
+	router.GET("/users/:username/ads", Middleware(deps.AuthService), adHandler.GetUserAds)
+	router.POST("/users/:username/block", JWTMiddleware(deps.AuthService), messagingHandler.BlockUser)
+	router.DELETE("/users/:username/block", JWTMiddleware(deps.AuthService), messagingHandler.UnblockUser)
+	router.GET("/categories", categoryHandler.GetCategories)
+	router.GET("/.well-known/jwks.json", keyHandler.GetJWKS)
+	router.GET(handlers.MediaPath+"*key", imageHandler.ServeMedia)
+	router.GET(handlers.ThumbnailPath+":ad_id/:size", Middleware(deps.AuthService), thumbnailHandler.GetThumbnail)
+
+	adminGroup := router.Group("/admin", JWTMiddleware(deps.AuthService), RequireRole(domain.RoleModerator))
+	{
+		adminGroup.GET("/users", adminHandler.ListUsers)
+		adminGroup.POST("/users/:id/ban", adminHandler.SetBanned(true))
+		adminGroup.POST("/users/:id/unban", adminHandler.SetBanned(false))
+		adminGroup.POST("/ads/:id/archive", adminHandler.ArchiveAd)
 	}
 
 	return router
 }
 
-func JWTMiddleware(jwtSecret string) gin.HandlerFunc {
+// TokenValidator проверяет access-токен (подпись, срок действия и отзыв)
+type TokenValidator interface {
+	ValidateToken(token string) (uint, domain.Role, error)
+}
+
+// abortInvalidToken отвечает 403 заблокированным пользователям и 401 в остальных случаях
+func abortInvalidToken(c *gin.Context, err error) {
+	if errors.Is(err, domain.ErrUserBanned) {
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+	} else {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
+	}
+	c.Abort()
+}
+
+func JWTMiddleware(validator TokenValidator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if token == "" {
@@ -42,19 +150,19 @@ func JWTMiddleware(jwtSecret string) gin.HandlerFunc {
 			return
 		}
 
-		userID, err := services.NewAuthService(nil, jwtSecret).ValidateToken(token)
+		userID, role, err := validator.ValidateToken(token)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-			c.Abort()
+			abortInvalidToken(c, err)
 			return
 		}
 
 		c.Set("userID", userID)
+		c.Set("role", role)
 		c.Next()
 	}
 }
 
-func Middleware(jwtSecret string) gin.HandlerFunc {
+func Middleware(validator TokenValidator) gin.HandlerFunc {
     return func(c *gin.Context) {
         token := c.GetHeader("Authorization")
         
@@ -63,14 +171,28 @@ func Middleware(jwtSecret string) gin.HandlerFunc {
             return
         }
 
-        userID, err := services.NewAuthService(nil, jwtSecret).ValidateToken(token)
+        userID, role, err := validator.ValidateToken(token)
         if err != nil {
-            c.JSON(http.StatusUnauthorized, gin.H{"error": "invalid token"})
-            c.Abort()
+            abortInvalidToken(c, err)
             return
         }
 
         c.Set("userID", userID)
+        c.Set("role", role)
         c.Next()
     }
+}
+
+// RequireRole пропускает пользователей с ролью не ниже min; ставится после JWTMiddleware
+func RequireRole(min domain.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, _ := c.Get("role")
+		if r, ok := role.(domain.Role); !ok || !r.AtLeast(min) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "insufficient permissions"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
 }
\ No newline at end of file
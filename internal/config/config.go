@@ -4,40 +4,122 @@ import (
 	"bufio"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 )
 
 type Config struct {
+	// STORAGE: database (PostgreSQL) или memory (данные в памяти процесса, только для разработки)
+	Storage    string
+	// DB_DRIVER: postgres или sqlite; для sqlite база - файл DB_PATH, параметры DB_HOST и др. не нужны
+	DBDriver   string
+	DBPath     string
 	DBHost     string
 	DBPort     string
 	DBUser     string
 	DBPassword string
 	DBName     string
 	JWTSecret  string
+	// JWT_KEYS: список "kid=путь/к/ключу.pem" через запятую (RS256 или Ed25519)
+	JWTKeys      string
+	JWTActiveKey string
 	ServerAddr string
+	// IMAGE_STORE: local (каталог IMAGE_DIR) или s3 (S3/MinIO)
+	ImageStore   string
+	ImageDir     string
+	MediaBaseURL string
+	S3Endpoint   string
+	S3Bucket     string
+	S3Region     string
+	S3AccessKey  string
+	S3SecretKey  string
+	// THUMBNAIL_CACHE_SIZE - лимит кэша миниатюр в мегабайтах
+	ThumbnailCacheDir  string
+	ThumbnailCacheSize string
+	// SAVED_SEARCH_INTERVAL - период проверки сохранённых поисков (например 1m)
+	SavedSearchInterval string
 	LogFile    string
 	LogDebug   string
 }
 
+// Значения STORAGE
+const (
+	StorageDatabase = "database"
+	StorageMemory   = "memory"
+)
+
 func LoadConfig(filename string) (*Config, error) {
 	if err := loadEnvFile(filename); err != nil {
 		return nil, fmt.Errorf("error loading config file: %w", err)
 	}
 
 	cfg := &Config{
+		Storage:    getEnv("STORAGE", StorageDatabase),
+		DBDriver:   getEnv("DB_DRIVER", "postgres"),
+		DBPath:     getEnv("DB_PATH", "marketplace.db"),
 		DBHost:     getEnv("DB_HOST", "localhost"),
 		DBPort:     getEnv("DB_PORT", "5432"),
 		DBUser:     getEnv("DB_USER", "postgres"),
 		DBPassword: getEnv("DB_PASSWORD", "postgres"),
 		DBName:     getEnv("DB_NAME", "marketplace"),
 		JWTSecret:  getEnv("JWT_SECRET", ""),
+		JWTKeys:      getEnv("JWT_KEYS", ""),
+		JWTActiveKey: getEnv("JWT_ACTIVE_KEY", ""),
 		ServerAddr: getEnv("SERVER_ADDRESS", ":8080"),
+		ImageStore:   getEnv("IMAGE_STORE", "local"),
+		ImageDir:     getEnv("IMAGE_DIR", "uploads"),
+		MediaBaseURL: getEnv("MEDIA_BASE_URL", ""),
+		S3Endpoint:   getEnv("S3_ENDPOINT", ""),
+		S3Bucket:     getEnv("S3_BUCKET", ""),
+		S3Region:     getEnv("S3_REGION", "us-east-1"),
+		S3AccessKey:  getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:  getEnv("S3_SECRET_KEY", ""),
+		ThumbnailCacheDir:  getEnv("THUMBNAIL_CACHE_DIR", "cache/thumbnails"),
+		ThumbnailCacheSize: getEnv("THUMBNAIL_CACHE_SIZE", "256"),
+		SavedSearchInterval: getEnv("SAVED_SEARCH_INTERVAL", "1m"),
 		LogDebug:	getEnv("LOG_DEBUG", "true"),
 		LogFile:    getEnv("LOG_FILE", "marketplace.log"),
 	}
 
-	if cfg.JWTSecret == "" {
-		return nil, fmt.Errorf("JWT_SECRET is required")
+	if cfg.JWTSecret == "" && cfg.JWTKeys == "" {
+		return nil, fmt.Errorf("JWT_SECRET or JWT_KEYS is required")
+	}
+
+	if _, err := cfg.GetJWTKeyFiles(); err != nil {
+		return nil, err
+	}
+
+	if cfg.Storage != StorageDatabase && cfg.Storage != StorageMemory {
+		return nil, fmt.Errorf("unknown STORAGE %q, expected %s or %s", cfg.Storage, StorageDatabase, StorageMemory)
+	}
+
+	switch cfg.DBDriver {
+	case "postgres":
+	case "sqlite":
+		if cfg.DBPath == "" {
+			return nil, fmt.Errorf("DB_PATH is required for DB_DRIVER=sqlite")
+		}
+	default:
+		return nil, fmt.Errorf("unknown DB_DRIVER %q, expected postgres or sqlite", cfg.DBDriver)
+	}
+
+	switch cfg.ImageStore {
+	case "local":
+	case "s3":
+		if cfg.S3Endpoint == "" || cfg.S3Bucket == "" {
+			return nil, fmt.Errorf("S3_ENDPOINT and S3_BUCKET are required for IMAGE_STORE=s3")
+		}
+	default:
+		return nil, fmt.Errorf("unknown IMAGE_STORE %q, expected local or s3", cfg.ImageStore)
+	}
+
+	if _, err := cfg.GetThumbnailCacheBytes(); err != nil {
+		return nil, err
+	}
+
+	if _, err := cfg.GetSavedSearchInterval(); err != nil {
+		return nil, err
 	}
 
 	return cfg, nil
@@ -60,6 +142,60 @@ func (c *Config) GetBDCreateString() string {
 		c.DBPort)
 }
 
+type KeyFile struct {
+	ID   string
+	Path string
+}
+
+// GetJWTKeyFiles разбирает JWT_KEYS с сохранением порядка ключей
+func (c *Config) GetJWTKeyFiles() ([]KeyFile, error) {
+	var files []KeyFile
+	for _, item := range strings.Split(c.JWTKeys, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		parts := strings.SplitN(item, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" || strings.TrimSpace(parts[1]) == "" {
+			return nil, fmt.Errorf("invalid JWT_KEYS entry %q, expected kid=path", item)
+		}
+
+		files = append(files, KeyFile{ID: strings.TrimSpace(parts[0]), Path: strings.TrimSpace(parts[1])})
+	}
+	return files, nil
+}
+
+// GetJWTActiveKey возвращает kid ключа подписи: JWT_ACTIVE_KEY, иначе первый ключ из JWT_KEYS,
+// иначе HMAC-ключ из JWT_SECRET
+func (c *Config) GetJWTActiveKey(files []KeyFile, defaultID string) string {
+	if c.JWTActiveKey != "" {
+		return c.JWTActiveKey
+	}
+	if len(files) > 0 {
+		return files[0].ID
+	}
+	return defaultID
+}
+
+// GetThumbnailCacheBytes возвращает лимит кэша миниатюр в байтах
+func (c *Config) GetThumbnailCacheBytes() (int64, error) {
+	size, err := strconv.ParseInt(c.ThumbnailCacheSize, 10, 64)
+	if err != nil || size <= 0 {
+		return 0, fmt.Errorf("invalid THUMBNAIL_CACHE_SIZE %q, expected a positive number of megabytes", c.ThumbnailCacheSize)
+	}
+	return size << 20, nil
+}
+
+// GetSavedSearchInterval возвращает период работы воркера сохранённых поисков
+func (c *Config) GetSavedSearchInterval() (time.Duration, error) {
+	interval, err := time.ParseDuration(c.SavedSearchInterval)
+	if err != nil || interval <= 0 {
+		return 0, fmt.Errorf("invalid SAVED_SEARCH_INTERVAL %q, expected a positive duration like 1m", c.SavedSearchInterval)
+	}
+	return interval, nil
+}
+
 func loadEnvFile(filename string) error {
 	file, err := os.Open(filename)
 	if err != nil {
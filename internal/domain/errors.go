@@ -0,0 +1,16 @@
+package domain
+
+import "errors"
+
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrForbidden = errors.New("forbidden")
+	// некорректные параметры запроса (фильтры, сортировка и т.п.)
+	ErrInvalidInput = errors.New("invalid input")
+
+	ErrInvalidStatusTransition = errors.New("invalid status transition")
+	// действие уже выполнено и не может быть повторено
+	ErrConflict = errors.New("conflict")
+
+	ErrUserBanned = errors.New("user is banned")
+)
@@ -0,0 +1,51 @@
+package domain
+
+import "time"
+
+// Типы событий потока GET /stream
+const (
+	EventAdCreated    = "ad_created"
+	EventMessage      = "message"
+	EventNotification = "notification"
+	EventOffer        = "offer"
+)
+
+// Длина текста сообщения в событии; полный текст - в GET /conversations/:id/messages
+const MessageEventPreviewLength = 1000
+
+// Объявление стало доступно всем: опубликовано сразу или из черновика
+type AdCreatedEvent struct {
+	ID         uint      `json:"id"`
+	Title      string    `json:"title"`
+	Price      float64   `json:"price"`
+	CategoryID uint      `json:"category_id"`
+	ImageURL   string    `json:"image_url"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type MessageEvent struct {
+	ID             uint      `json:"id"`
+	ConversationID uint      `json:"conversation_id"`
+	AdID           uint      `json:"ad_id"`
+	SenderID       uint      `json:"sender_id"`
+	Text           string    `json:"text"`
+	Truncated      bool      `json:"truncated,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type NotificationEvent struct {
+	Type          NotificationType `json:"type"`
+	Title         string           `json:"title"`
+	AdID          *uint            `json:"ad_id,omitempty"`
+	SavedSearchID *uint            `json:"saved_search_id,omitempty"`
+}
+
+// Изменение предложения цены; приходит второй стороне сделки
+type OfferEvent struct {
+	ID             uint        `json:"id"`
+	AdID           uint        `json:"ad_id"`
+	Status         OfferStatus `json:"status"`
+	Amount         float64     `json:"amount"`
+	CounterAmount  *float64    `json:"counter_amount,omitempty"`
+	AcceptedAmount *float64    `json:"accepted_amount,omitempty"`
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Объявление в избранном пользователя. Запись переживает удаление объявления,
+// чтобы в списке избранного оно отображалось как недоступное.
+type Favorite struct {
+	UserID    uint `gorm:"primaryKey;autoIncrement:false"`
+	AdID      uint `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
+
+// Причины, по которым объявление из избранного больше недоступно
+const (
+	UnavailableDeleted  = "deleted"
+	UnavailableArchived = "archived"
+)
+
+// UnavailableReason возвращает причину недоступности объявления или пустую строку
+func (a *Advertisement) UnavailableReason() string {
+	switch {
+	case a.DeletedAt.Valid:
+		return UnavailableDeleted
+	case a.Status == AdStatusArchived:
+		return UnavailableArchived
+	}
+	return ""
+}
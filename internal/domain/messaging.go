@@ -0,0 +1,73 @@
+package domain
+
+import "time"
+
+// Переписка покупателя с автором объявления. На пару (объявление, покупатель)
+// приходится одна переписка; счётчики непрочитанных ведутся для каждого участника.
+type Conversation struct {
+	ID            uint          `gorm:"primaryKey"`
+	AdID          uint          `gorm:"not null;uniqueIndex:idx_conversation_ad_buyer"`
+	Ad            Advertisement `gorm:"foreignKey:AdID"`
+	BuyerID       uint          `gorm:"not null;uniqueIndex:idx_conversation_ad_buyer;index"`
+	Buyer         User          `gorm:"foreignKey:BuyerID"`
+	SellerID      uint          `gorm:"not null;index"`
+	Seller        User          `gorm:"foreignKey:SellerID"`
+	BuyerUnread   int           `gorm:"not null;default:0"`
+	SellerUnread  int           `gorm:"not null;default:0"`
+	LastMessageAt time.Time     `gorm:"index"`
+	CreatedAt     time.Time
+}
+
+// IsParticipant сообщает, участвует ли пользователь в переписке
+func (c *Conversation) IsParticipant(userID uint) bool {
+	return userID != 0 && (userID == c.BuyerID || userID == c.SellerID)
+}
+
+// CounterpartID возвращает второго участника переписки
+func (c *Conversation) CounterpartID(userID uint) uint {
+	if userID == c.BuyerID {
+		return c.SellerID
+	}
+	return c.BuyerID
+}
+
+// UnreadFor возвращает число непрочитанных сообщений участника
+func (c *Conversation) UnreadFor(userID uint) int {
+	if userID == c.BuyerID {
+		return c.BuyerUnread
+	}
+	return c.SellerUnread
+}
+
+type Message struct {
+	ID             uint   `gorm:"primaryKey"`
+	ConversationID uint   `gorm:"not null;index"`
+	SenderID       uint   `gorm:"not null"`
+	Body           string `gorm:"not null;size:2000"`
+	CreatedAt      time.Time
+}
+
+// Максимальная длина сообщения в символах
+const MaxMessageLength = 2000
+
+type ConversationPage struct {
+	Items  []Conversation
+	Total  int64
+	Unread int64 // непрочитанные сообщения во всех переписках пользователя
+	Page   int
+	Limit  int
+}
+
+// Страница сообщений, новые первыми
+type MessagePage struct {
+	Items      []Message
+	NextCursor string // пустой, если более старых сообщений нет
+}
+
+// Блокировка одного пользователя другим: пока она есть, писать друг другу они не могут.
+// В отличие от бана администратора действует только на эту пару.
+type UserBlock struct {
+	BlockerID uint `gorm:"primaryKey;autoIncrement:false"`
+	BlockedID uint `gorm:"primaryKey;autoIncrement:false;index"`
+	CreatedAt time.Time
+}
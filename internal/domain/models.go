@@ -2,23 +2,187 @@ package domain
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 type User struct {
 	ID       	uint   	`gorm:"primaryKey"`
 	Username 	string 	`gorm:"unique;not null"`
 	Password 	string 	`gorm:"type:varchar(100);not null"`
+	Role     	Role   	`gorm:"not null;size:20;default:user"`
+	Banned   	bool   	`gorm:"not null;default:false"`
+	// публичный профиль, заполняется пользователем через PATCH /me
+	DisplayName 	string 	`gorm:"size:50"`
+	AvatarURL 	string 	`gorm:"size:500"`
+	City 	string 	`gorm:"size:100"`
+	About 	string 	`gorm:"type:text"`
+	// сумма и число оценок из отзывов; обновляются вместе с добавлением отзыва
+	RatingSum 	int 	`gorm:"not null;default:0"`
+	RatingCount 	int 	`gorm:"not null;default:0"`
 	CreatedAt 	time.Time
 }
 
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRank = map[Role]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+func (r Role) Valid() bool {
+	_, ok := roleRank[r]
+	return ok
+}
+
+// AtLeast сообщает, что роль не ниже min (неизвестная роль не удовлетворяет ничему)
+func (r Role) AtLeast(min Role) bool {
+	return roleRank[r] > 0 && roleRank[r] >= roleRank[min]
+}
+
+type UserFilter struct {
+	Page   int
+	Limit  int
+	Role   Role
+	Banned *bool
+	Offset int
+}
+
+type UserPage struct {
+	Items []User
+	Total int64
+	Page  int
+	Limit int
+}
+
+// Ограничения полей профиля в символах
+const (
+	MaxDisplayNameLength = 50
+	MaxCityLength        = 100
+	MaxAboutLength       = 1000
+	MaxAvatarURLLength   = 500
+)
+
+// Изменение профиля: nil - поле не меняется, пустая строка - очистить
+type ProfileUpdate struct {
+	DisplayName *string
+	AvatarURL   *string
+	City        *string
+	About       *string
+}
+
+type Category struct {
+	ID       uint       `gorm:"primaryKey"`
+	Name     string     `gorm:"not null;size:100"`
+	Slug     string     `gorm:"unique;not null;size:100"`
+	ParentID *uint      `gorm:"index"`
+	Children []Category `gorm:"foreignKey:ParentID"`
+}
+
+type AdStatus string
+
+// Жизненный цикл объявления
+const (
+	AdStatusDraft     AdStatus = "draft"
+	AdStatusPublished AdStatus = "published"
+	AdStatusReserved  AdStatus = "reserved"
+	AdStatusSold      AdStatus = "sold"
+	AdStatusArchived  AdStatus = "archived"
+)
+
+func (s AdStatus) Valid() bool {
+	switch s {
+	case AdStatusDraft, AdStatusPublished, AdStatusReserved, AdStatusSold, AdStatusArchived:
+		return true
+	}
+	return false
+}
+
 type Advertisement struct {
 	ID          uint   	`gorm:"primaryKey"`
 	Title       string 	`gorm:"not null;size:100"`
 	Description string 	`gorm:"not null;size:1000"`
-	ImageURL    string 	`gorm:"not null"`
+	ImageURL    string 	`gorm:"not null"` // URL обложки (дублирует AdImage с IsCover для списков)
 	Price       float64 `gorm:"not null"`
+	Status      AdStatus `gorm:"not null;size:20;default:published;index"`
+	CategoryID  uint    `gorm:"index"`
 	UserID      uint    `gorm:"not null"`
 	User        User    `gorm:"foreignKey:UserID"`
+	Images      []AdImage `gorm:"foreignKey:AdID;constraint:OnDelete:CASCADE"`
 	IsOwner     bool    `gorm:"-" json:"is_owner"` 
+	IsFavorite  bool    `gorm:"-" json:"is_favorite"`
 	CreatedAt   time.Time
-}
\ No newline at end of file
+	PublishedAt *time.Time `gorm:"index"` // когда объявление впервые опубликовано; nil у черновика
+	// удалённые объявления остаются в базе для избранного и не попадают в выборки
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+// Максимальное число изображений в галерее объявления
+const MaxAdImages = 10
+
+// Изображение из галереи объявления; ровно одно изображение объявления является обложкой
+type AdImage struct {
+	ID        uint   `gorm:"primaryKey"`
+	AdID      uint   `gorm:"not null;index"`
+	URL       string `gorm:"not null"`
+	Position  int    `gorm:"not null"`
+	IsCover   bool   `gorm:"not null;default:false"`
+	CreatedAt time.Time
+}
+
+// Частичное обновление объявления: nil означает "поле не меняется"
+type AdvertisementUpdate struct {
+	Title       *string
+	Description *string
+	ImageURL    *string
+	Price       *float64
+	CategoryID  *uint
+}
+
+// Параметры выборки списка объявлений
+type AdFilter struct {
+	Page     int
+	Limit    int
+	SortBy   string
+	Order    string
+	MinPrice float64
+	MaxPrice float64
+	Status   AdStatus
+	UserID   uint   // если задан, выбираются только объявления этого пользователя
+	Author   string // логин автора объявлений
+	ViewerID uint   // пользователь, для которого отмечается is_favorite
+	// избранное пользователя: объявления в любом статусе, включая удалённые
+	FavoritesOf uint
+	Query    string // полнотекстовый поиск по заголовку и описанию
+
+	CategoryID  uint   // категория вместе со всеми подкатегориями
+	CategoryIDs []uint // развёрнутый сервисом список категорий для репозитория
+
+	Cursor string    // непрозрачный курсор клиента (keyset-пагинация вместо page)
+	After  *AdCursor // разобранный сервисом курсор для репозитория
+	Offset int       // вычисляется сервисом из Page и Limit
+}
+
+// Позиция последнего отданного объявления в текущем порядке сортировки
+type AdCursor struct {
+	SortBy    string    `json:"s"`
+	Order     string    `json:"o"`
+	CreatedAt time.Time `json:"c,omitempty"`
+	Price     float64   `json:"p,omitempty"`
+	ID        uint      `json:"i"`
+}
+
+// Страница списка объявлений
+type AdPage struct {
+	Items      []Advertisement
+	Total      int64
+	Page       int
+	Limit      int
+	NextCursor string // пустой, если следующей страницы нет
+}
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Сохранённый поиск: набор фильтров GET /ads. CheckedUntil - водяной знак воркера:
+// объявления, опубликованные не позже него, уже проверены на совпадение. Знак идёт по
+// времени публикации, а не по id, иначе черновик, опубликованный позже, не нашёлся бы.
+type SavedSearch struct {
+	ID           uint      `gorm:"primaryKey"`
+	UserID       uint      `gorm:"not null;index"`
+	Name         string    `gorm:"not null;size:100"`
+	Query        string    `gorm:"not null;size:200"`
+	MinPrice     float64   `gorm:"not null;default:0"`
+	MaxPrice     float64   `gorm:"not null;default:0"`
+	CategoryID   uint      `gorm:"not null;default:0"`
+	SortBy       string    `gorm:"not null;size:20"`
+	Order        string    `gorm:"not null;size:4"`
+	CheckedUntil time.Time `gorm:"not null"`
+	CreatedAt    time.Time
+}
+
+// Максимальное число сохранённых поисков у пользователя
+const MaxSavedSearches = 20
+
+// Filter возвращает параметры выборки GET /ads, соответствующие сохранённому поиску
+func (s *SavedSearch) Filter() AdFilter {
+	return AdFilter{
+		Query:      s.Query,
+		MinPrice:   s.MinPrice,
+		MaxPrice:   s.MaxPrice,
+		CategoryID: s.CategoryID,
+		SortBy:     s.SortBy,
+		Order:      s.Order,
+	}
+}
+
+type NotificationType string
+
+const NotificationSavedSearchMatch NotificationType = "saved_search_match"
+
+// Уведомление во внутреннем ящике пользователя. DedupKey уникален,
+// поэтому повторная обработка одного события не создаёт дубль.
+type Notification struct {
+	ID            uint             `gorm:"primaryKey"`
+	UserID        uint             `gorm:"not null;index"`
+	Type          NotificationType `gorm:"not null;size:50"`
+	Title         string           `gorm:"not null;size:200"`
+	AdID          *uint
+	SavedSearchID *uint
+	DedupKey      string `gorm:"not null;uniqueIndex;size:100"`
+	ReadAt        *time.Time
+	CreatedAt     time.Time
+}
+
+type NotificationFilter struct {
+	Page       int
+	Limit      int
+	UnreadOnly bool
+	Offset     int
+}
+
+type NotificationPage struct {
+	Items  []Notification
+	Total  int64
+	Unread int64
+	Page   int
+	Limit  int
+}
@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+type OfferStatus string
+
+const (
+	// ждёт ответа продавца
+	OfferStatusPending OfferStatus = "pending"
+	// продавец предложил свою цену, ждёт ответа покупателя
+	OfferStatusCountered OfferStatus = "countered"
+	OfferStatusAccepted  OfferStatus = "accepted"
+	OfferStatusRejected  OfferStatus = "rejected"
+	// отклонено автоматически: продавец принял другое предложение
+	OfferStatusDeclined  OfferStatus = "declined"
+	OfferStatusWithdrawn OfferStatus = "withdrawn"
+)
+
+// Active сообщает, что по предложению ещё ждут ответа
+func (s OfferStatus) Active() bool {
+	return s == OfferStatusPending || s == OfferStatusCountered
+}
+
+// Предложение цены покупателем. SellerID копируется из объявления при создании.
+type Offer struct {
+	ID             uint          `gorm:"primaryKey"`
+	AdID           uint          `gorm:"not null;index"`
+	Ad             Advertisement `gorm:"foreignKey:AdID"`
+	BuyerID        uint          `gorm:"not null;index"`
+	Buyer          User          `gorm:"foreignKey:BuyerID"`
+	SellerID       uint          `gorm:"not null;index"`
+	Amount         float64       `gorm:"not null"`
+	CounterAmount  *float64
+	AcceptedAmount *float64
+	Status         OfferStatus `gorm:"not null;size:20;default:pending;index"`
+	CreatedAt      time.Time
+	UpdatedAt      time.Time
+}
+
+type OfferFilter struct {
+	AdID     uint
+	BuyerID  uint
+	SellerID uint
+	Page     int
+	Limit    int
+	Offset   int
+}
+
+type OfferPage struct {
+	Items []Offer
+	Total int64
+	Page  int
+	Limit int
+}
@@ -0,0 +1,40 @@
+package domain
+
+import "time"
+
+// MaxReviewLength - максимальная длина текста отзыва в символах
+const MaxReviewLength = 1000
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+// Отзыв покупателя о продавце по завершённой сделке: одна сделка (принятое предложение) - один отзыв
+type Review struct {
+	ID        uint          `gorm:"primaryKey"`
+	OfferID   uint          `gorm:"not null;uniqueIndex"`
+	AdID      uint          `gorm:"not null"`
+	Ad        Advertisement `gorm:"foreignKey:AdID"`
+	SellerID  uint          `gorm:"not null;index"`
+	BuyerID   uint          `gorm:"not null"`
+	Buyer     User          `gorm:"foreignKey:BuyerID"`
+	Rating    int           `gorm:"not null"`
+	Text      string        `gorm:"type:text"`
+	CreatedAt time.Time
+}
+
+// Rating - средняя оценка пользователя как продавца; nil, если отзывов ещё нет
+func (u User) Rating() *float64 {
+	if u.RatingCount == 0 {
+		return nil
+	}
+	rating := float64(u.RatingSum) / float64(u.RatingCount)
+	return &rating
+}
+
+// Публичный профиль пользователя с последними отзывами о нём
+type SellerProfile struct {
+	User    User
+	Reviews []Review
+}
@@ -0,0 +1,27 @@
+package domain
+
+import "time"
+
+// Refresh-токен хранится только в виде хэша. Токены одной сессии объединены FamilyID:
+// при повторном использовании уже заменённого токена отзывается вся цепочка.
+type RefreshToken struct {
+	ID        uint      `gorm:"primaryKey"`
+	UserID    uint      `gorm:"not null;index"`
+	TokenHash string    `gorm:"uniqueIndex;not null;size:64"`
+	FamilyID  string    `gorm:"not null;index;size:32"`
+	ExpiresAt time.Time `gorm:"not null"`
+	RevokedAt *time.Time
+	CreatedAt time.Time
+}
+
+// Отозванный access-токен (по claim jti). Запись нужна только до истечения срока токена.
+type RevokedToken struct {
+	JTI       string    `gorm:"primaryKey;size:64"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
+
+type TokenPair struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    time.Duration
+}
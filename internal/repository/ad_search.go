@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"strings"
+	"unicode"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// adSearch - полнотекстовый поиск объявлений средствами конкретной СУБД
+type adSearch interface {
+	// match оставляет объявления, подходящие под строку поиска
+	match(query *gorm.DB, search string) *gorm.DB
+	// orderByRank сортирует от более релевантных к менее, при равенстве - от новых к старым
+	orderByRank(query *gorm.DB, search string) *gorm.DB
+}
+
+func newAdSearch(db *gorm.DB) adSearch {
+	if db.Dialector.Name() == "sqlite" {
+		return sqliteSearch{}
+	}
+	return postgresSearch{}
+}
+
+// Запрос объединяет разбор строки поиска по русской и английской конфигурациям
+const searchTSQuery = "(plainto_tsquery('russian', ?) || plainto_tsquery('english', ?))"
+
+// postgresSearch ищет по столбцу search_vector (миграция 0002) с учётом словоформ
+type postgresSearch struct{}
+
+func (postgresSearch) match(query *gorm.DB, search string) *gorm.DB {
+	return query.Where("advertisements.search_vector @@ "+searchTSQuery, search, search)
+}
+
+func (postgresSearch) orderByRank(query *gorm.DB, search string) *gorm.DB {
+	return query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                "ts_rank(advertisements.search_vector, " + searchTSQuery + ") DESC, advertisements.id DESC",
+		Vars:               []interface{}{search, search},
+		WithoutParentheses: true,
+	}})
+}
+
+// sqliteSearch ищет по таблице FTS5 advertisements_fts. Морфологии в SQLite нет, поэтому
+// каждое слово запроса ищется как начало слова: "велосипед" найдёт "велосипеды", но не наоборот.
+// Релевантность - bm25, где совпадения в заголовке весят вдвое больше, чем в описании.
+type sqliteSearch struct{}
+
+const ftsRank = "(SELECT bm25(advertisements_fts, 2.0, 1.0) FROM advertisements_fts" +
+	" WHERE advertisements_fts MATCH ? AND advertisements_fts.rowid = advertisements.id)"
+
+func (sqliteSearch) match(query *gorm.DB, search string) *gorm.DB {
+	expr := ftsQuery(search)
+	if expr == "" {
+		// как plainto_tsquery без слов: ничего не находится
+		return query.Where("1 = 0")
+	}
+	return query.Where("advertisements.id IN (SELECT rowid FROM advertisements_fts WHERE advertisements_fts MATCH ?)", expr)
+}
+
+func (sqliteSearch) orderByRank(query *gorm.DB, search string) *gorm.DB {
+	// bm25 тем меньше, чем документ релевантнее
+	return query.Order(clause.OrderBy{Expression: clause.Expr{
+		SQL:                ftsRank + " ASC, advertisements.id DESC",
+		Vars:               []interface{}{ftsQuery(search)},
+		WithoutParentheses: true,
+	}})
+}
+
+// ftsQuery превращает строку поиска в запрос FTS5: все слова обязательны, каждое ищется как префикс.
+// Слова берутся в кавычки, поэтому операторы FTS5 (OR, NOT, NEAR, *) из строки поиска не действуют.
+func ftsQuery(search string) string {
+	words := strings.FieldsFunc(search, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for i, word := range words {
+		words[i] = `"` + word + `"*`
+	}
+	return strings.Join(words, " ")
+}
@@ -2,41 +2,199 @@ package repository
 
 import (
 	"github.com/keenetic29/vk-internship/internal/domain"
+	"errors"
+	"fmt"
+	"time"
+
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type advertisementRepository struct {
-	db *gorm.DB
+	db     *gorm.DB
+	search adSearch
 }
 
 func NewAdvertisementRepository(db *gorm.DB) *advertisementRepository {
-	return &advertisementRepository{db: db}
+	return &advertisementRepository{db: db, search: newAdSearch(db)}
 }
 
 func (r *advertisementRepository) Create(ad *domain.Advertisement) error {
 	return r.db.Create(ad).Error
 }
 
-func (r *advertisementRepository) GetAll(page, limit int, sortBy, order string, minPrice, maxPrice float64) ([]domain.Advertisement, error) {
-	var ads []domain.Advertisement
+func (r *advertisementRepository) GetByID(id uint) (*domain.Advertisement, error) {
+	var ad domain.Advertisement
+	err := r.db.Preload("User").
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position, id")
+		}).
+		First(&ad, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &ad, nil
+}
+
+// Update сохраняет содержимое объявления. Статус и время публикации не пишутся:
+// их меняет только UpdateStatus, иначе устаревшая копия затёрла бы бронь от Accept.
+func (r *advertisementRepository) Update(ad *domain.Advertisement) error {
+	// связанного пользователя не трогаем, сохраняем только само объявление
+	return r.db.Omit(clause.Associations, "status", "published_at").Save(ad).Error
+}
+
+// UpdateStatus переводит объявление из статуса from в ad.Status вместе со временем публикации.
+// Если статус уже изменился (например, объявление забронировано принятием предложения),
+// возвращает ErrConflict.
+func (r *advertisementRepository) UpdateStatus(ad *domain.Advertisement, from domain.AdStatus) error {
+	result := r.db.Model(&domain.Advertisement{}).
+		Where("id = ? AND status = ?", ad.ID, from).
+		Updates(map[string]interface{}{
+			"status":       ad.Status,
+			"published_at": ad.PublishedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: advertisement is no longer %s", domain.ErrConflict, from)
+	}
+	return nil
+}
+
+// UpdateImages сохраняет галерею целиком: изображения, которых нет в images, удаляются,
+// новые (ID == 0) добавляются, а image_url объявления заменяется на URL обложки
+func (r *advertisementRepository) UpdateImages(adID uint, images []domain.AdImage, coverURL string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var keep []uint
+		for _, image := range images {
+			if image.ID != 0 {
+				keep = append(keep, image.ID)
+			}
+		}
+
+		query := tx.Where("ad_id = ?", adID)
+		if len(keep) > 0 {
+			query = query.Where("id NOT IN ?", keep)
+		}
+		if err := query.Delete(&domain.AdImage{}).Error; err != nil {
+			return err
+		}
+
+		for i := range images {
+			images[i].AdID = adID
+			if err := tx.Save(&images[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&domain.Advertisement{}).
+			Where("id = ?", adID).
+			Update("image_url", coverURL).Error
+	})
+}
 
-	query := r.db.Model(&domain.Advertisement{}).Preload("User")
+func (r *advertisementRepository) Delete(id uint) error {
+	result := r.db.Delete(&domain.Advertisement{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
 
-	if minPrice > 0 {
-		query = query.Where("price >= ?", minPrice)
+// applyFilter накладывает условия выборки без сортировки и пагинации
+func (r *advertisementRepository) applyFilter(query *gorm.DB, filter domain.AdFilter) *gorm.DB {
+	if filter.FavoritesOf != 0 {
+		// удалённые объявления остаются в избранном
+		query = query.Unscoped().
+			Joins("JOIN favorites ON favorites.ad_id = advertisements.id AND favorites.user_id = ?", filter.FavoritesOf)
+	}
+	if filter.Status != "" {
+		query = query.Where("advertisements.status = ?", filter.Status)
 	}
-	if maxPrice > 0 {
-		query = query.Where("price <= ?", maxPrice)
+	if filter.UserID != 0 {
+		query = query.Where("advertisements.user_id = ?", filter.UserID)
+	}
+	if filter.Author != "" {
+		query = query.Where("advertisements.user_id = (SELECT id FROM users WHERE username = ?)", filter.Author)
+	}
+	if filter.MinPrice > 0 {
+		query = query.Where("advertisements.price >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		query = query.Where("advertisements.price <= ?", filter.MaxPrice)
+	}
+	if len(filter.CategoryIDs) > 0 {
+		query = query.Where("advertisements.category_id IN ?", filter.CategoryIDs)
+	}
+	if filter.Query != "" {
+		query = r.search.match(query, filter.Query)
+	}
+	return query
+}
+
+func (r *advertisementRepository) GetAll(filter domain.AdFilter) ([]domain.Advertisement, error) {
+	var ads []domain.Advertisement
+
+	query := r.applyFilter(r.db.Model(&domain.Advertisement{}).Preload("User"), filter)
+
+	direction := "DESC"
+	if filter.Order == "asc" {
+		direction = "ASC"
 	}
 
-	if sortBy != "" {
-		query = query.Order(sortBy + " " + order)
+	if filter.SortBy == "relevance" && filter.Query != "" {
+		query = r.search.orderByRank(query, filter.Query)
 	} else {
-		query = query.Order("created_at DESC")
+		column := "advertisements.created_at"
+		var after interface{}
+		if filter.After != nil {
+			after = filter.After.CreatedAt
+		}
+		if filter.SortBy == "price" {
+			column = "advertisements.price"
+			if filter.After != nil {
+				after = filter.After.Price
+			}
+		}
+
+		// keyset: продолжаем строго после последней отданной строки, id разрешает равенство ключа
+		if filter.After != nil {
+			operator := "<"
+			if direction == "ASC" {
+				operator = ">"
+			}
+			query = query.Where("("+column+", advertisements.id) "+operator+" (?, ?)", after, filter.After.ID)
+		}
+
+		query = query.Order(column + " " + direction + ", advertisements.id " + direction)
 	}
 
-	offset := (page - 1) * limit
-	err := query.Offset(offset).Limit(limit).Find(&ads).Error
+	err := query.Offset(filter.Offset).Limit(filter.Limit).Find(&ads).Error
+
+	return ads, err
+}
+
+func (r *advertisementRepository) Count(filter domain.AdFilter) (int64, error) {
+	var count int64
+	err := r.applyFilter(r.db.Model(&domain.Advertisement{}), filter).Count(&count).Error
+	return count, err
+}
 
+// MatchNew выбирает объявления, опубликованные в (after, upTo], подходящие под фильтр.
+// Заполнены только id, user_id и title.
+func (r *advertisementRepository) MatchNew(filter domain.AdFilter, after, upTo time.Time) ([]domain.Advertisement, error) {
+	var ads []domain.Advertisement
+	err := r.applyFilter(r.db.Model(&domain.Advertisement{}), filter).
+		Select("advertisements.id", "advertisements.user_id", "advertisements.title").
+		Where("advertisements.published_at > ? AND advertisements.published_at <= ?", after, upTo).
+		Order("advertisements.published_at, advertisements.id").
+		Find(&ads).Error
 	return ads, err
-}
\ No newline at end of file
+}
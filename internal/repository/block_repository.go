@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type blockRepository struct {
+	db *gorm.DB
+}
+
+func NewBlockRepository(db *gorm.DB) *blockRepository {
+	return &blockRepository{db: db}
+}
+
+// Block блокирует пользователя; повторная блокировка ничего не меняет
+func (r *blockRepository) Block(blockerID, blockedID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.UserBlock{BlockerID: blockerID, BlockedID: blockedID}).Error
+}
+
+func (r *blockRepository) Unblock(blockerID, blockedID uint) error {
+	return r.db.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+		Delete(&domain.UserBlock{}).Error
+}
+
+// IsBlocked сообщает, заблокировал ли кто-то из двух пользователей другого
+func (r *blockRepository) IsBlocked(userA, userB uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userA, userB, userB, userA).
+		Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,34 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+)
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) *categoryRepository {
+	return &categoryRepository{db: db}
+}
+
+func (r *categoryRepository) GetAll() ([]domain.Category, error) {
+	var categories []domain.Category
+	err := r.db.Order("id").Find(&categories).Error
+	return categories, err
+}
+
+func (r *categoryRepository) GetByID(id uint) (*domain.Category, error) {
+	var category domain.Category
+	err := r.db.First(&category, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
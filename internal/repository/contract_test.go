@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/internal/repository/repotest"
+	"github.com/keenetic29/vk-internship/pkg/database"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newPostgresRepositories создаёт для теста отдельную схему в базе TEST_DATABASE_URL
+// и применяет к ней миграции. Без TEST_DATABASE_URL тест пропускается.
+func newPostgresRepositories(t *testing.T) repotest.Repositories {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	schema := fmt.Sprintf("contract_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	db, err := gorm.Open(postgres.Open(dsn+separator+"search_path="+schema), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect to schema: %v", err)
+	}
+
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := database.RunMigrations(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	return contractRepositories(db)
+}
+
+// contractRepositories собирает репозитории поверх подготовленной базы
+func contractRepositories(db *gorm.DB) repotest.Repositories {
+	return repotest.Repositories{
+		Users:         NewUserRepository(db),
+		Ads:           NewAdvertisementRepository(db),
+		Favorites:     NewFavoriteRepository(db),
+		Offers:        NewOfferRepository(db),
+		Reviews:       NewReviewRepository(db),
+		Conversations: NewConversationRepository(db),
+		Blocks:        NewBlockRepository(db),
+		SavedSearches: NewSavedSearchRepository(db),
+		Notifications: NewNotificationRepository(db),
+		Tokens:        NewTokenRepository(db),
+	}
+}
+
+// newSQLiteDB создаёт базу SQLite во временном каталоге теста и применяет к ней миграции
+func newSQLiteDB(t *testing.T) *gorm.DB {
+	db, err := database.InitDB(database.Options{Driver: database.DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	db.Logger = logger.Discard
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	if _, err := database.RunMigrations(db); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+	return db
+}
+
+func newSQLiteRepositories(t *testing.T) repotest.Repositories {
+	return contractRepositories(newSQLiteDB(t))
+}
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, newPostgresRepositories)
+}
+
+func TestContractSQLite(t *testing.T) {
+	repotest.Run(t, newSQLiteRepositories)
+}
+
+func TestAdvertisementRepository_SQLiteSearch(t *testing.T) {
+	db := newSQLiteDB(t)
+	user := &domain.User{Username: "alice", Password: "hash"}
+	if err := NewUserRepository(db).Create(user); err != nil {
+		t.Fatal(err)
+	}
+	repo := NewAdvertisementRepository(db)
+	for _, ad := range []domain.Advertisement{
+		{Title: "Книжный шкаф", Description: "Гитара в подарок"},
+		{Title: "Гитара Yamaha", Description: "Акустическая гитара, чехол"},
+		{Title: "Гитара детская", Description: "Для начинающих"},
+		{Title: "Велосипеды", Description: "Два взрослых велосипеда"},
+	} {
+		ad.UserID = user.ID
+		if err := repo.Create(&ad); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ads, err := repo.GetAll(domain.AdFilter{Query: "ГИТАРА", SortBy: "relevance", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// регистр не важен, совпадения в заголовке важнее
+	assertAdIDs(t, "relevance", ads, 2, 3, 1)
+
+	tests := []struct {
+		query string
+		want  []uint
+	}{
+		{"велосипед", []uint{4}},    // слово ищется как префикс
+		{"велосипедов", nil},        // морфологии нет
+		{"гитара чехол", []uint{2}}, // все слова обязательны
+		{"шкаф OR чехол", nil},      // операторы FTS5 не действуют
+		{"!!!", nil},
+	}
+	for _, tc := range tests {
+		ads, err := repo.GetAll(domain.AdFilter{Query: tc.query, Limit: 10})
+		if err != nil {
+			t.Fatalf("GetAll(%q) error = %v", tc.query, err)
+		}
+		assertAdIDs(t, tc.query, ads, tc.want...)
+	}
+
+	// индекс следует за изменением заголовка
+	ad, err := repo.GetByID(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ad.Title = "Книжный стеллаж"
+	if err := repo.Update(ad); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := repo.Count(domain.AdFilter{Query: "стеллаж"}); err != nil || count != 1 {
+		t.Errorf("Count(стеллаж) = %d, %v, want 1", count, err)
+	}
+	if count, err := repo.Count(domain.AdFilter{Query: "шкаф"}); err != nil || count != 0 {
+		t.Errorf("Count(шкаф) = %d, %v, want 0", count, err)
+	}
+}
+
+// Водяной знак сохранённого поиска сравнивается на равенство: время должно
+// возвращаться из SQLite ровно таким, каким было записано
+func TestSavedSearchRepository_SQLiteAdvance(t *testing.T) {
+	db := newSQLiteDB(t)
+	repo := NewSavedSearchRepository(db)
+	checkedUntil := time.Date(2025, 1, 10, 12, 0, 0, 123456000, time.Local)
+	search := &domain.SavedSearch{UserID: 1, Name: "Поиск", SortBy: "created_at", Order: "desc", CheckedUntil: checkedUntil}
+	if err := repo.Create(search); err != nil {
+		t.Fatal(err)
+	}
+
+	upTo := checkedUntil.Add(time.Minute)
+	pending, err := repo.ListPending(upTo, 10)
+	if err != nil || len(pending) != 1 {
+		t.Fatalf("ListPending() = %d searches, %v, want 1", len(pending), err)
+	}
+	advanced, err := repo.Advance(search.ID, pending[0].CheckedUntil, upTo, nil)
+	if err != nil || !advanced {
+		t.Fatalf("Advance() = %v, %v, want true", advanced, err)
+	}
+	// второй воркер с устаревшим знаком ничего не сдвигает
+	if advanced, err := repo.Advance(search.ID, pending[0].CheckedUntil, upTo, nil); err != nil || advanced {
+		t.Errorf("stale Advance() = %v, %v, want false", advanced, err)
+	}
+	if pending, err := repo.ListPending(upTo, 10); err != nil || len(pending) != 0 {
+		t.Errorf("ListPending() after Advance = %d searches, %v, want 0", len(pending), err)
+	}
+}
+
+// Параллельные предложения одного покупателя: проверка и вставка атомарны,
+// поэтому ожидающим ответа остаётся только одно
+func TestOfferRepository_SQLiteOneActiveOffer(t *testing.T) {
+	db := newSQLiteDB(t)
+	users := NewUserRepository(db)
+	seller := &domain.User{Username: "seller", Password: "hash"}
+	buyer := &domain.User{Username: "buyer", Password: "hash"}
+	for _, user := range []*domain.User{seller, buyer} {
+		if err := users.Create(user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ad := &domain.Advertisement{Title: "Велосипед", Description: "Горный", UserID: seller.ID, Status: domain.AdStatusPublished}
+	if err := NewAdvertisementRepository(db).Create(ad); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewOfferRepository(db)
+	const attempts = 4
+	errs := make(chan error, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- repo.Create(&domain.Offer{AdID: ad.ID, BuyerID: buyer.ID, SellerID: seller.ID, Amount: 100, Status: domain.OfferStatusPending})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.Is(err, domain.ErrInvalidInput):
+			t.Errorf("Create() error = %v, want ErrInvalidInput", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("created %d active offers, want 1", created)
+	}
+}
+
+func assertAdIDs(t *testing.T, name string, ads []domain.Advertisement, want ...uint) {
+	t.Helper()
+	if len(ads) != len(want) {
+		t.Fatalf("%s: got %d ads, want %v", name, len(ads), want)
+	}
+	for i := range want {
+		if ads[i].ID != want[i] {
+			t.Errorf("%s: ads[%d].ID = %d, want %d", name, i, ads[i].ID, want[i])
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"errors"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type conversationRepository struct {
+	db *gorm.DB
+}
+
+func NewConversationRepository(db *gorm.DB) *conversationRepository {
+	return &conversationRepository{db: db}
+}
+
+// withRelations подгружает объявление (в том числе удалённое) и участников
+func withRelations(db *gorm.DB) *gorm.DB {
+	return db.Preload("Ad", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Buyer").Preload("Seller")
+}
+
+// FindOrCreate возвращает переписку покупателя по объявлению, создавая её при необходимости
+func (r *conversationRepository) FindOrCreate(conv *domain.Conversation) error {
+	err := r.db.Omit(clause.Associations).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "ad_id"}, {Name: "buyer_id"}},
+			DoNothing: true,
+		}).Create(conv).Error
+	if err != nil {
+		return err
+	}
+	return r.db.Where("ad_id = ? AND buyer_id = ?", conv.AdID, conv.BuyerID).First(conv).Error
+}
+
+func (r *conversationRepository) GetByID(id uint) (*domain.Conversation, error) {
+	var conv domain.Conversation
+	err := withRelations(r.db).First(&conv, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &conv, nil
+}
+
+func (r *conversationRepository) userQuery(userID uint) *gorm.DB {
+	return r.db.Model(&domain.Conversation{}).Where("buyer_id = ? OR seller_id = ?", userID, userID)
+}
+
+// ListByUser возвращает переписки пользователя, сначала с последними сообщениями
+func (r *conversationRepository) ListByUser(userID uint, offset, limit int) ([]domain.Conversation, error) {
+	var conversations []domain.Conversation
+	err := withRelations(r.userQuery(userID)).
+		Order("last_message_at DESC, id DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&conversations).Error
+	return conversations, err
+}
+
+func (r *conversationRepository) CountByUser(userID uint) (int64, error) {
+	var count int64
+	err := r.userQuery(userID).Count(&count).Error
+	return count, err
+}
+
+// UnreadTotal возвращает число непрочитанных сообщений во всех переписках пользователя
+func (r *conversationRepository) UnreadTotal(userID uint) (int64, error) {
+	var total int64
+	err := r.userQuery(userID).
+		Select("COALESCE(SUM(CASE WHEN buyer_id = ? THEN buyer_unread ELSE seller_unread END), 0)", userID).
+		Scan(&total).Error
+	return total, err
+}
+
+// AddMessage сохраняет сообщение и в той же транзакции обновляет время последнего
+// сообщения и счётчик непрочитанных у получателя
+func (r *conversationRepository) AddMessage(conv *domain.Conversation, msg *domain.Message) error {
+	unreadColumn := "buyer_unread"
+	if msg.SenderID == conv.BuyerID {
+		unreadColumn = "seller_unread"
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		msg.ConversationID = conv.ID
+		if err := tx.Create(msg).Error; err != nil {
+			return err
+		}
+		return tx.Model(&domain.Conversation{}).
+			Where("id = ?", conv.ID).
+			Updates(map[string]interface{}{
+				"last_message_at": msg.CreatedAt,
+				unreadColumn:      gorm.Expr(unreadColumn + " + 1"),
+			}).Error
+	})
+}
+
+// ListMessages возвращает сообщения переписки с id меньше beforeID (0 - с последнего), новые первыми
+func (r *conversationRepository) ListMessages(conversationID, beforeID uint, limit int) ([]domain.Message, error) {
+	query := r.db.Where("conversation_id = ?", conversationID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	var messages []domain.Message
+	err := query.Order("id DESC").Limit(limit).Find(&messages).Error
+	return messages, err
+}
+
+// MarkRead отмечает прочитанными сообщения собеседника с id не больше upToID: непрочитанными
+// остаются только более новые, поэтому пришедшие после выборки страницы сообщения не теряются.
+// Строка переписки блокируется, чтобы подсчёт не разошёлся с параллельным AddMessage.
+func (r *conversationRepository) MarkRead(conv *domain.Conversation, userID, upToID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var locked domain.Conversation
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, conv.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var newer int64
+		err = tx.Model(&domain.Message{}).
+			Where("conversation_id = ? AND sender_id <> ? AND id > ?", conv.ID, userID, upToID).
+			Count(&newer).Error
+		if err != nil || newer >= int64(locked.UnreadFor(userID)) {
+			return err
+		}
+
+		column := "seller_unread"
+		if userID == locked.BuyerID {
+			column = "buyer_unread"
+		}
+		return tx.Model(&domain.Conversation{}).Where("id = ?", conv.ID).Update(column, newer).Error
+	})
+}
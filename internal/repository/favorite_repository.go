@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type favoriteRepository struct {
+	db *gorm.DB
+}
+
+func NewFavoriteRepository(db *gorm.DB) *favoriteRepository {
+	return &favoriteRepository{db: db}
+}
+
+// Add добавляет объявление в избранное; повторное добавление ничего не меняет
+func (r *favoriteRepository) Add(userID, adID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.Favorite{UserID: userID, AdID: adID}).Error
+}
+
+func (r *favoriteRepository) Remove(userID, adID uint) error {
+	return r.db.Where("user_id = ? AND ad_id = ?", userID, adID).
+		Delete(&domain.Favorite{}).Error
+}
+
+// FavoriteAdIDs возвращает те из adIDs, что есть в избранном пользователя
+func (r *favoriteRepository) FavoriteAdIDs(userID uint, adIDs []uint) ([]uint, error) {
+	var ids []uint
+	if len(adIDs) == 0 {
+		return ids, nil
+	}
+	err := r.db.Model(&domain.Favorite{}).
+		Where("user_id = ? AND ad_id IN ?", userID, adIDs).
+		Pluck("ad_id", &ids).Error
+	return ids, err
+}
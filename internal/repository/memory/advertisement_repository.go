@@ -0,0 +1,391 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+)
+
+type advertisementRepository struct {
+	store *Store
+}
+
+func NewAdvertisementRepository(store *Store) *advertisementRepository {
+	return &advertisementRepository{store: store}
+}
+
+func (r *advertisementRepository) Create(ad *domain.Advertisement) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ad.ID = s.nextID("advertisements")
+	if ad.Status == "" {
+		ad.Status = domain.AdStatusPublished
+	}
+	if ad.CreatedAt.IsZero() {
+		ad.CreatedAt = now()
+	}
+	for i := range ad.Images {
+		ad.Images[i].AdID = ad.ID
+		s.saveImage(&ad.Images[i])
+	}
+	s.saveAd(ad)
+	return nil
+}
+
+// saveAd сохраняет строку объявления без связанных пользователя и изображений
+func (s *Store) saveAd(ad *domain.Advertisement) {
+	stored := *ad
+	stored.User = domain.User{}
+	stored.Images = nil
+	stored.IsOwner = false
+	stored.IsFavorite = false
+	s.ads[ad.ID] = &stored
+}
+
+func (s *Store) saveImage(image *domain.AdImage) {
+	if image.ID == 0 {
+		image.ID = s.nextID("ad_images")
+	}
+	if image.CreatedAt.IsZero() {
+		image.CreatedAt = now()
+	}
+	stored := *image
+	s.images[image.ID] = &stored
+}
+
+// loadAd возвращает копию объявления с автором (как Preload("User"));
+// изображения подгружаются только при withImages
+func (s *Store) loadAd(ad *domain.Advertisement, withImages bool) domain.Advertisement {
+	loaded := *ad
+	if user, ok := s.users[ad.UserID]; ok {
+		loaded.User = *user
+	}
+	if withImages {
+		loaded.Images = s.adImages(ad.ID)
+	}
+	return loaded
+}
+
+// adImages возвращает галерею объявления в порядке position, id
+func (s *Store) adImages(adID uint) []domain.AdImage {
+	images := []domain.AdImage{}
+	for _, image := range s.images {
+		if image.AdID == adID {
+			images = append(images, *image)
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Position != images[j].Position {
+			return images[i].Position < images[j].Position
+		}
+		return images[i].ID < images[j].ID
+	})
+	return images
+}
+
+// liveAd возвращает неудалённое объявление; вызывается под блокировкой
+func (s *Store) liveAd(id uint) *domain.Advertisement {
+	ad, ok := s.ads[id]
+	if !ok || ad.DeletedAt.Valid {
+		return nil
+	}
+	return ad
+}
+
+func (r *advertisementRepository) GetByID(id uint) (*domain.Advertisement, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ad := s.liveAd(id)
+	if ad == nil {
+		return nil, domain.ErrNotFound
+	}
+	loaded := s.loadAd(ad, true)
+	return &loaded, nil
+}
+
+func (r *advertisementRepository) Update(ad *domain.Advertisement) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// как Save в GORM: существующая строка перезаписывается целиком, связи не трогаются;
+	// статус и время публикации меняет только UpdateStatus
+	updated := *ad
+	if stored, ok := s.ads[ad.ID]; ok {
+		updated.Status, updated.PublishedAt = stored.Status, stored.PublishedAt
+	}
+	s.saveAd(&updated)
+	return nil
+}
+
+// UpdateStatus переводит объявление из статуса from в ad.Status вместе со временем публикации.
+// Если статус уже изменился (например, объявление забронировано принятием предложения),
+// возвращает ErrConflict.
+func (r *advertisementRepository) UpdateStatus(ad *domain.Advertisement, from domain.AdStatus) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored := s.liveAd(ad.ID)
+	if stored == nil || stored.Status != from {
+		return fmt.Errorf("%w: advertisement is no longer %s", domain.ErrConflict, from)
+	}
+	stored.Status = ad.Status
+	stored.PublishedAt = copyTime(ad.PublishedAt)
+	return nil
+}
+
+func copyTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	value := *t
+	return &value
+}
+
+// UpdateImages сохраняет галерею целиком: изображения, которых нет в images, удаляются,
+// новые (ID == 0) добавляются, а image_url объявления заменяется на URL обложки
+func (r *advertisementRepository) UpdateImages(adID uint, images []domain.AdImage, coverURL string) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	keep := make(map[uint]bool)
+	for _, image := range images {
+		if image.ID != 0 {
+			keep[image.ID] = true
+		}
+	}
+	for id, image := range s.images {
+		if image.AdID == adID && !keep[id] {
+			delete(s.images, id)
+		}
+	}
+
+	for i := range images {
+		images[i].AdID = adID
+		s.saveImage(&images[i])
+	}
+
+	if ad := s.liveAd(adID); ad != nil {
+		ad.ImageURL = coverURL
+	}
+	return nil
+}
+
+// Delete удаляет объявление мягко: оно остаётся в избранном, но пропадает из выборок
+func (r *advertisementRepository) Delete(id uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ad := s.liveAd(id)
+	if ad == nil {
+		return domain.ErrNotFound
+	}
+	ad.DeletedAt = gorm.DeletedAt{Time: now(), Valid: true}
+	return nil
+}
+
+// matchAd проверяет условия фильтра без сортировки и пагинации; вызывается под блокировкой
+func (s *Store) matchAd(ad *domain.Advertisement, filter domain.AdFilter, terms []string) bool {
+	if filter.FavoritesOf != 0 {
+		// удалённые объявления остаются в избранном
+		if _, ok := s.favorites[favoriteKey{userID: filter.FavoritesOf, adID: ad.ID}]; !ok {
+			return false
+		}
+	} else if ad.DeletedAt.Valid {
+		return false
+	}
+	if filter.Status != "" && ad.Status != filter.Status {
+		return false
+	}
+	if filter.UserID != 0 && ad.UserID != filter.UserID {
+		return false
+	}
+	if filter.Author != "" {
+		author := s.findUser(filter.Author)
+		if author == nil || ad.UserID != author.ID {
+			return false
+		}
+	}
+	if filter.MinPrice > 0 && ad.Price < filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice > 0 && ad.Price > filter.MaxPrice {
+		return false
+	}
+	if len(filter.CategoryIDs) > 0 && !containsID(filter.CategoryIDs, ad.CategoryID) {
+		return false
+	}
+	if filter.Query != "" && searchRank(ad, terms) == 0 {
+		return false
+	}
+	return true
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// searchTerms разбивает строку поиска на слова в нижнем регистре
+func searchTerms(query string) []string {
+	return strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// searchRank заменяет полнотекстовый поиск PostgreSQL: объявление подходит, если каждое
+// слово запроса встречается в заголовке или описании как подстрока (без морфологии),
+// а релевантность - число вхождений слов, причём вхождения в заголовок весят больше
+func searchRank(ad *domain.Advertisement, terms []string) int {
+	if len(terms) == 0 {
+		return 0
+	}
+	title := strings.ToLower(ad.Title)
+	description := strings.ToLower(ad.Description)
+
+	rank := 0
+	for _, term := range terms {
+		inTitle := strings.Count(title, term)
+		inDescription := strings.Count(description, term)
+		if inTitle+inDescription == 0 {
+			return 0
+		}
+		rank += 2*inTitle + inDescription
+	}
+	return rank
+}
+
+// selectAds возвращает подходящие под фильтр объявления в порядке id; вызывается под блокировкой
+func (s *Store) selectAds(filter domain.AdFilter) ([]*domain.Advertisement, []string) {
+	terms := searchTerms(filter.Query)
+	var ads []*domain.Advertisement
+	for _, id := range sortedIDs(s.ads) {
+		if ad := s.ads[id]; s.matchAd(ad, filter, terms) {
+			ads = append(ads, ad)
+		}
+	}
+	return ads, terms
+}
+
+func (r *advertisementRepository) GetAll(filter domain.AdFilter) ([]domain.Advertisement, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ads, terms := s.selectAds(filter)
+	asc := filter.Order == "asc"
+
+	if filter.SortBy == "relevance" && filter.Query != "" {
+		ranks := make(map[uint]int, len(ads))
+		for _, ad := range ads {
+			ranks[ad.ID] = searchRank(ad, terms)
+		}
+		sort.SliceStable(ads, func(i, j int) bool {
+			if ranks[ads[i].ID] != ranks[ads[j].ID] {
+				return ranks[ads[i].ID] > ranks[ads[j].ID]
+			}
+			return ads[i].ID > ads[j].ID
+		})
+	} else {
+		byPrice := filter.SortBy == "price"
+		// compare сравнивает ключи сортировки (created_at или price, затем id) как кортежи
+		compare := func(a *domain.Advertisement, createdAt time.Time, price float64, id uint) int {
+			switch {
+			case byPrice && a.Price != price:
+				return cmpFloat(a.Price, price)
+			case !byPrice && !a.CreatedAt.Equal(createdAt):
+				return a.CreatedAt.Compare(createdAt)
+			}
+			return cmpFloat(float64(a.ID), float64(id))
+		}
+
+		// keyset: продолжаем строго после последней отданной строки
+		if filter.After != nil {
+			after := ads[:0:0]
+			for _, ad := range ads {
+				c := compare(ad, filter.After.CreatedAt, filter.After.Price, filter.After.ID)
+				if (asc && c > 0) || (!asc && c < 0) {
+					after = append(after, ad)
+				}
+			}
+			ads = after
+		}
+
+		sort.Slice(ads, func(i, j int) bool {
+			c := compare(ads[i], ads[j].CreatedAt, ads[j].Price, ads[j].ID)
+			if asc {
+				return c < 0
+			}
+			return c > 0
+		})
+	}
+
+	result := []domain.Advertisement{}
+	for _, ad := range page(ads, filter.Offset, filter.Limit) {
+		result = append(result, s.loadAd(ad, false))
+	}
+	return result, nil
+}
+
+func cmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
+
+func (r *advertisementRepository) Count(filter domain.AdFilter) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ads, _ := s.selectAds(filter)
+	return int64(len(ads)), nil
+}
+
+// MatchNew выбирает объявления, опубликованные в (after, upTo], подходящие под фильтр.
+// Заполнены только id, user_id и title.
+func (r *advertisementRepository) MatchNew(filter domain.AdFilter, after, upTo time.Time) ([]domain.Advertisement, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ads, _ := s.selectAds(filter)
+	var matched []*domain.Advertisement
+	for _, ad := range ads {
+		if ad.PublishedAt != nil && ad.PublishedAt.After(after) && !ad.PublishedAt.After(upTo) {
+			matched = append(matched, ad)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if !matched[i].PublishedAt.Equal(*matched[j].PublishedAt) {
+			return matched[i].PublishedAt.Before(*matched[j].PublishedAt)
+		}
+		return matched[i].ID < matched[j].ID
+	})
+
+	result := []domain.Advertisement{}
+	for _, ad := range matched {
+		result = append(result, domain.Advertisement{ID: ad.ID, UserID: ad.UserID, Title: ad.Title})
+	}
+	return result, nil
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type blockRepository struct {
+	store *Store
+}
+
+func NewBlockRepository(store *Store) *blockRepository {
+	return &blockRepository{store: store}
+}
+
+// Block блокирует пользователя; повторная блокировка ничего не меняет
+func (r *blockRepository) Block(blockerID, blockedID uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := blockKey{blockerID: blockerID, blockedID: blockedID}
+	if _, ok := s.blocks[key]; !ok {
+		s.blocks[key] = &domain.UserBlock{BlockerID: blockerID, BlockedID: blockedID, CreatedAt: now()}
+	}
+	return nil
+}
+
+func (r *blockRepository) Unblock(blockerID, blockedID uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.blocks, blockKey{blockerID: blockerID, blockedID: blockedID})
+	return nil
+}
+
+// IsBlocked сообщает, заблокировал ли кто-то из двух пользователей другого
+func (r *blockRepository) IsBlocked(userA, userB uint) (bool, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ab := s.blocks[blockKey{blockerID: userA, blockedID: userB}]
+	_, ba := s.blocks[blockKey{blockerID: userB, blockedID: userA}]
+	return ab || ba, nil
+}
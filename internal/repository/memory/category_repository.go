@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type categoryRepository struct {
+	store *Store
+}
+
+func NewCategoryRepository(store *Store) *categoryRepository {
+	return &categoryRepository{store: store}
+}
+
+func (r *categoryRepository) GetAll() ([]domain.Category, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	categories := []domain.Category{}
+	for _, id := range sortedIDs(s.categories) {
+		categories = append(categories, *s.categories[id])
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) GetByID(id uint) (*domain.Category, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	category, ok := s.categories[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := *category
+	return &found, nil
+}
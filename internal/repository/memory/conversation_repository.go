@@ -0,0 +1,192 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type conversationRepository struct {
+	store *Store
+}
+
+func NewConversationRepository(store *Store) *conversationRepository {
+	return &conversationRepository{store: store}
+}
+
+// loadConversation возвращает копию переписки с объявлением (в том числе удалённым)
+// и участниками; вызывается под блокировкой
+func (s *Store) loadConversation(conv *domain.Conversation) domain.Conversation {
+	loaded := *conv
+	if ad, ok := s.ads[conv.AdID]; ok {
+		loaded.Ad = *ad
+	}
+	if buyer, ok := s.users[conv.BuyerID]; ok {
+		loaded.Buyer = *buyer
+	}
+	if seller, ok := s.users[conv.SellerID]; ok {
+		loaded.Seller = *seller
+	}
+	return loaded
+}
+
+// FindOrCreate возвращает переписку покупателя по объявлению, создавая её при необходимости
+func (r *conversationRepository) FindOrCreate(conv *domain.Conversation) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.conversations {
+		if existing.AdID == conv.AdID && existing.BuyerID == conv.BuyerID {
+			*conv = *existing
+			return nil
+		}
+	}
+
+	conv.ID = s.nextID("conversations")
+	if conv.CreatedAt.IsZero() {
+		conv.CreatedAt = now()
+	}
+	stored := *conv
+	stored.Ad, stored.Buyer, stored.Seller = domain.Advertisement{}, domain.User{}, domain.User{}
+	s.conversations[conv.ID] = &stored
+	*conv = stored
+	return nil
+}
+
+func (r *conversationRepository) GetByID(id uint) (*domain.Conversation, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conv, ok := s.conversations[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	loaded := s.loadConversation(conv)
+	return &loaded, nil
+}
+
+// userConversations возвращает переписки пользователя, сначала с последними сообщениями
+func (s *Store) userConversations(userID uint) []*domain.Conversation {
+	var conversations []*domain.Conversation
+	for _, conv := range s.conversations {
+		if conv.BuyerID == userID || conv.SellerID == userID {
+			conversations = append(conversations, conv)
+		}
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		a, b := conversations[i], conversations[j]
+		if !a.LastMessageAt.Equal(b.LastMessageAt) {
+			return a.LastMessageAt.After(b.LastMessageAt)
+		}
+		return a.ID > b.ID
+	})
+	return conversations
+}
+
+// ListByUser возвращает переписки пользователя, сначала с последними сообщениями
+func (r *conversationRepository) ListByUser(userID uint, offset, limit int) ([]domain.Conversation, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	conversations := []domain.Conversation{}
+	for _, conv := range page(s.userConversations(userID), offset, limit) {
+		conversations = append(conversations, s.loadConversation(conv))
+	}
+	return conversations, nil
+}
+
+func (r *conversationRepository) CountByUser(userID uint) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.userConversations(userID))), nil
+}
+
+// UnreadTotal возвращает число непрочитанных сообщений во всех переписках пользователя
+func (r *conversationRepository) UnreadTotal(userID uint) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var total int64
+	for _, conv := range s.userConversations(userID) {
+		if conv.BuyerID == userID {
+			total += int64(conv.BuyerUnread)
+		} else {
+			total += int64(conv.SellerUnread)
+		}
+	}
+	return total, nil
+}
+
+// AddMessage сохраняет сообщение и вместе с ним обновляет время последнего
+// сообщения и счётчик непрочитанных у получателя
+func (r *conversationRepository) AddMessage(conv *domain.Conversation, msg *domain.Message) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	msg.ConversationID = conv.ID
+	msg.ID = s.nextID("messages")
+	if msg.CreatedAt.IsZero() {
+		msg.CreatedAt = now()
+	}
+	stored := *msg
+	s.messages[msg.ID] = &stored
+
+	if target, ok := s.conversations[conv.ID]; ok {
+		target.LastMessageAt = msg.CreatedAt
+		if msg.SenderID == conv.BuyerID {
+			target.SellerUnread++
+		} else {
+			target.BuyerUnread++
+		}
+	}
+	return nil
+}
+
+// ListMessages возвращает сообщения переписки с id меньше beforeID (0 - с последнего), новые первыми
+func (r *conversationRepository) ListMessages(conversationID, beforeID uint, limit int) ([]domain.Message, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	messages := []domain.Message{}
+	ids := sortedIDs(s.messages)
+	for i := len(ids) - 1; i >= 0; i-- {
+		msg := s.messages[ids[i]]
+		if msg.ConversationID == conversationID && (beforeID == 0 || msg.ID < beforeID) {
+			messages = append(messages, *msg)
+		}
+	}
+	return page(messages, 0, limit), nil
+}
+
+// MarkRead отмечает прочитанными сообщения собеседника с id не больше upToID: непрочитанными
+// остаются только более новые, поэтому пришедшие после выборки страницы сообщения не теряются
+func (r *conversationRepository) MarkRead(conv *domain.Conversation, userID, upToID uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	target, ok := s.conversations[conv.ID]
+	if !ok {
+		return nil
+	}
+	newer := 0
+	for _, msg := range s.messages {
+		if msg.ConversationID == conv.ID && msg.SenderID != userID && msg.ID > upToID {
+			newer++
+		}
+	}
+	if userID == target.BuyerID {
+		target.BuyerUnread = min(target.BuyerUnread, newer)
+	} else {
+		target.SellerUnread = min(target.SellerUnread, newer)
+	}
+	return nil
+}
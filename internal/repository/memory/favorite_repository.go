@@ -0,0 +1,50 @@
+package memory
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type favoriteRepository struct {
+	store *Store
+}
+
+func NewFavoriteRepository(store *Store) *favoriteRepository {
+	return &favoriteRepository{store: store}
+}
+
+// Add добавляет объявление в избранное; повторное добавление ничего не меняет
+func (r *favoriteRepository) Add(userID, adID uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	key := favoriteKey{userID: userID, adID: adID}
+	if _, ok := s.favorites[key]; !ok {
+		s.favorites[key] = &domain.Favorite{UserID: userID, AdID: adID, CreatedAt: now()}
+	}
+	return nil
+}
+
+func (r *favoriteRepository) Remove(userID, adID uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.favorites, favoriteKey{userID: userID, adID: adID})
+	return nil
+}
+
+// FavoriteAdIDs возвращает те из adIDs, что есть в избранном пользователя
+func (r *favoriteRepository) FavoriteAdIDs(userID uint, adIDs []uint) ([]uint, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var ids []uint
+	for _, adID := range adIDs {
+		if _, ok := s.favorites[favoriteKey{userID: userID, adID: adID}]; ok {
+			ids = append(ids, adID)
+		}
+	}
+	return ids, nil
+}
@@ -0,0 +1,84 @@
+package memory
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/internal/repository/repotest"
+)
+
+func newRepositories(t *testing.T) repotest.Repositories {
+	store := NewStore()
+	return repotest.Repositories{
+		Users:         NewUserRepository(store),
+		Ads:           NewAdvertisementRepository(store),
+		Favorites:     NewFavoriteRepository(store),
+		Offers:        NewOfferRepository(store),
+		Reviews:       NewReviewRepository(store),
+		Conversations: NewConversationRepository(store),
+		Blocks:        NewBlockRepository(store),
+		SavedSearches: NewSavedSearchRepository(store),
+		Notifications: NewNotificationRepository(store),
+		Tokens:        NewTokenRepository(store),
+	}
+}
+
+func TestContract(t *testing.T) {
+	repotest.Run(t, newRepositories)
+}
+
+func TestUserRepository_ConcurrentCreate(t *testing.T) {
+	repo := NewUserRepository(NewStore())
+
+	var wg sync.WaitGroup
+	var created atomic.Int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if repo.Create(&domain.User{Username: "alice", Password: "hash"}) == nil {
+				created.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if created.Load() != 1 {
+		t.Errorf("%d concurrent creates of the same username succeeded, want 1", created.Load())
+	}
+}
+
+func TestAdvertisementRepository_Relevance(t *testing.T) {
+	store := NewStore()
+	repo := NewAdvertisementRepository(store)
+	for _, ad := range []domain.Advertisement{
+		{Title: "Книжный шкаф", Description: "Гитара в подарок"},
+		{Title: "Гитара Yamaha", Description: "Акустическая гитара, чехол"},
+		{Title: "Гитара детская", Description: "Для начинающих"},
+	} {
+		if err := repo.Create(&ad); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ads, err := repo.GetAll(domain.AdFilter{Query: "ГИТАРА", SortBy: "relevance", Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// вхождения в заголовок важнее, при равной релевантности новые первыми
+	assertAdIDs(t, "relevance", ads, 2, 3, 1)
+}
+
+func assertAdIDs(t *testing.T, name string, ads []domain.Advertisement, want ...uint) {
+	t.Helper()
+	if len(ads) != len(want) {
+		t.Fatalf("%s: got %d ads, want %v", name, len(ads), want)
+	}
+	for i := range want {
+		if ads[i].ID != want[i] {
+			t.Errorf("%s: ads[%d].ID = %d, want %d", name, i, ads[i].ID, want[i])
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type notificationRepository struct {
+	store *Store
+}
+
+func NewNotificationRepository(store *Store) *notificationRepository {
+	return &notificationRepository{store: store}
+}
+
+// userNotifications возвращает уведомления пользователя, новые первыми; вызывается под блокировкой
+func (s *Store) userNotifications(userID uint, unreadOnly bool) []*domain.Notification {
+	var notifications []*domain.Notification
+	ids := sortedIDs(s.notifications)
+	for i := len(ids) - 1; i >= 0; i-- {
+		notification := s.notifications[ids[i]]
+		if notification.UserID == userID && (!unreadOnly || notification.ReadAt == nil) {
+			notifications = append(notifications, notification)
+		}
+	}
+	return notifications
+}
+
+// List возвращает уведомления пользователя, новые первыми
+func (r *notificationRepository) List(userID uint, filter domain.NotificationFilter) ([]domain.Notification, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	notifications := []domain.Notification{}
+	for _, notification := range page(s.userNotifications(userID, filter.UnreadOnly), filter.Offset, filter.Limit) {
+		notifications = append(notifications, *notification)
+	}
+	return notifications, nil
+}
+
+func (r *notificationRepository) Count(userID uint, unreadOnly bool) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.userNotifications(userID, unreadOnly))), nil
+}
+
+// MarkRead помечает прочитанными уведомления ids, а при пустом ids - все уведомления пользователя
+func (r *notificationRepository) MarkRead(userID uint, ids []uint) (int64, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var marked int64
+	readAt := now()
+	for _, notification := range s.userNotifications(userID, true) {
+		if len(ids) == 0 || containsID(ids, notification.ID) {
+			notification.ReadAt = &readAt
+			marked++
+		}
+	}
+	return marked, nil
+}
+
+// insertNotifications добавляет уведомления, пропуская повторы по DedupKey; вызывается под блокировкой
+func (s *Store) insertNotifications(notifications []domain.Notification) {
+	for i := range notifications {
+		duplicate := false
+		for _, existing := range s.notifications {
+			if existing.DedupKey == notifications[i].DedupKey {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		notifications[i].ID = s.nextID("notifications")
+		if notifications[i].CreatedAt.IsZero() {
+			notifications[i].CreatedAt = now()
+		}
+		stored := notifications[i]
+		s.notifications[stored.ID] = &stored
+	}
+}
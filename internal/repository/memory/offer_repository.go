@@ -0,0 +1,186 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type offerRepository struct {
+	store *Store
+}
+
+func NewOfferRepository(store *Store) *offerRepository {
+	return &offerRepository{store: store}
+}
+
+var (
+	errAdNotPublished = fmt.Errorf("%w: advertisement is no longer published", domain.ErrInvalidStatusTransition)
+	errActiveOffer    = fmt.Errorf("%w: you already have an active offer on this advertisement", domain.ErrInvalidInput)
+)
+
+// Create сохраняет предложение, если объявление всё ещё опубликовано и у покупателя нет
+// другого предложения по нему, ожидающего ответа
+func (r *offerRepository) Create(offer *domain.Offer) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ad := s.liveAd(offer.AdID)
+	if ad == nil {
+		return domain.ErrNotFound
+	}
+	if ad.Status != domain.AdStatusPublished {
+		return errAdNotPublished
+	}
+	for _, existing := range s.offers {
+		if existing.AdID == offer.AdID && existing.BuyerID == offer.BuyerID && isActiveOffer(existing) {
+			return errActiveOffer
+		}
+	}
+
+	offer.ID = s.nextID("offers")
+	if offer.Status == "" {
+		offer.Status = domain.OfferStatusPending
+	}
+	created := now()
+	if offer.CreatedAt.IsZero() {
+		offer.CreatedAt = created
+	}
+	if offer.UpdatedAt.IsZero() {
+		offer.UpdatedAt = created
+	}
+	stored := *offer
+	stored.Ad, stored.Buyer = domain.Advertisement{}, domain.User{}
+	s.offers[offer.ID] = &stored
+	return nil
+}
+
+// loadOffer возвращает копию предложения с объявлением (в том числе удалённым) и покупателем
+func (s *Store) loadOffer(offer *domain.Offer) domain.Offer {
+	loaded := *offer
+	if ad, ok := s.ads[offer.AdID]; ok {
+		loaded.Ad = *ad
+	}
+	if buyer, ok := s.users[offer.BuyerID]; ok {
+		loaded.Buyer = *buyer
+	}
+	return loaded
+}
+
+func (r *offerRepository) GetByID(id uint) (*domain.Offer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	offer, ok := s.offers[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	loaded := s.loadOffer(offer)
+	return &loaded, nil
+}
+
+// selectOffers возвращает предложения под фильтр, новые первыми; вызывается под блокировкой
+func (s *Store) selectOffers(filter domain.OfferFilter) []*domain.Offer {
+	var offers []*domain.Offer
+	ids := sortedIDs(s.offers)
+	for i := len(ids) - 1; i >= 0; i-- {
+		offer := s.offers[ids[i]]
+		if (filter.AdID == 0 || offer.AdID == filter.AdID) &&
+			(filter.BuyerID == 0 || offer.BuyerID == filter.BuyerID) &&
+			(filter.SellerID == 0 || offer.SellerID == filter.SellerID) {
+			offers = append(offers, offer)
+		}
+	}
+	return offers
+}
+
+// List возвращает предложения, новые первыми
+func (r *offerRepository) List(filter domain.OfferFilter) ([]domain.Offer, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	offers := []domain.Offer{}
+	for _, offer := range page(s.selectOffers(filter), filter.Offset, filter.Limit) {
+		offers = append(offers, s.loadOffer(offer))
+	}
+	return offers, nil
+}
+
+func (r *offerRepository) Count(filter domain.OfferFilter) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return int64(len(s.selectOffers(filter))), nil
+}
+
+func isActiveOffer(offer *domain.Offer) bool {
+	return offer.Status == domain.OfferStatusPending || offer.Status == domain.OfferStatusCountered
+}
+
+// UpdateStatus переводит предложение из статуса from в offer.Status вместе со встречной ценой.
+// Если статус уже изменился, возвращает ErrInvalidStatusTransition.
+func (r *offerRepository) UpdateStatus(offer *domain.Offer, from domain.OfferStatus) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.offers[offer.ID]
+	if !ok || stored.Status != from {
+		return fmt.Errorf("%w: offer is no longer %s", domain.ErrInvalidStatusTransition, from)
+	}
+
+	offer.UpdatedAt = now()
+	stored.Status = offer.Status
+	stored.CounterAmount = copyAmount(offer.CounterAmount)
+	stored.UpdatedAt = offer.UpdatedAt
+	return nil
+}
+
+func copyAmount(amount *float64) *float64 {
+	if amount == nil {
+		return nil
+	}
+	value := *amount
+	return &value
+}
+
+// Accept атомарно принимает предложение, бронирует объявление
+// и отклоняет остальные активные предложения по нему. Возвращает отклонённые.
+func (r *offerRepository) Accept(offer *domain.Offer, from domain.OfferStatus) ([]domain.Offer, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ad := s.liveAd(offer.AdID)
+	if ad == nil || ad.Status != domain.AdStatusPublished {
+		return nil, errAdNotPublished
+	}
+	stored, ok := s.offers[offer.ID]
+	if !ok || stored.Status != from {
+		return nil, fmt.Errorf("%w: offer is no longer %s", domain.ErrInvalidStatusTransition, from)
+	}
+
+	updatedAt := now()
+	ad.Status = domain.AdStatusReserved
+	stored.Status = domain.OfferStatusAccepted
+	stored.AcceptedAmount = copyAmount(offer.AcceptedAmount)
+	stored.UpdatedAt = updatedAt
+
+	declined := []domain.Offer{}
+	for _, id := range sortedIDs(s.offers) {
+		other := s.offers[id]
+		if other.AdID == offer.AdID && other.ID != offer.ID && isActiveOffer(other) {
+			other.Status = domain.OfferStatusDeclined
+			other.UpdatedAt = updatedAt
+			declined = append(declined, *other)
+		}
+	}
+
+	offer.Status = domain.OfferStatusAccepted
+	offer.UpdatedAt = updatedAt
+	return declined, nil
+}
@@ -0,0 +1,67 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type reviewRepository struct {
+	store *Store
+}
+
+func NewReviewRepository(store *Store) *reviewRepository {
+	return &reviewRepository{store: store}
+}
+
+// Create сохраняет отзыв и вместе с ним добавляет оценку к рейтингу продавца.
+// Повторный отзыв по той же сделке возвращает ErrConflict.
+func (r *reviewRepository) Create(review *domain.Review) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.reviews {
+		if existing.OfferID == review.OfferID {
+			return fmt.Errorf("%w: the deal has already been reviewed", domain.ErrConflict)
+		}
+	}
+
+	review.ID = s.nextID("reviews")
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = now()
+	}
+	stored := *review
+	stored.Ad, stored.Buyer = domain.Advertisement{}, domain.User{}
+	s.reviews[review.ID] = &stored
+
+	if seller, ok := s.users[review.SellerID]; ok {
+		seller.RatingSum += review.Rating
+		seller.RatingCount++
+	}
+	return nil
+}
+
+// ListBySeller возвращает последние отзывы о продавце, новые первыми
+func (r *reviewRepository) ListBySeller(sellerID uint, limit int) ([]domain.Review, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reviews := []domain.Review{}
+	ids := sortedIDs(s.reviews)
+	for i := len(ids) - 1; i >= 0; i-- {
+		review := *s.reviews[ids[i]]
+		if review.SellerID != sellerID {
+			continue
+		}
+		if ad, ok := s.ads[review.AdID]; ok {
+			review.Ad = *ad
+		}
+		if buyer, ok := s.users[review.BuyerID]; ok {
+			review.Buyer = *buyer
+		}
+		reviews = append(reviews, review)
+	}
+	return page(reviews, 0, limit), nil
+}
@@ -0,0 +1,104 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type savedSearchRepository struct {
+	store *Store
+}
+
+func NewSavedSearchRepository(store *Store) *savedSearchRepository {
+	return &savedSearchRepository{store: store}
+}
+
+func (r *savedSearchRepository) Create(search *domain.SavedSearch) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search.ID = s.nextID("saved_searches")
+	if search.CreatedAt.IsZero() {
+		search.CreatedAt = now()
+	}
+	stored := *search
+	s.savedSearches[search.ID] = &stored
+	return nil
+}
+
+func (r *savedSearchRepository) GetByID(id uint) (*domain.SavedSearch, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	search, ok := s.savedSearches[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := *search
+	return &found, nil
+}
+
+// selectSearches возвращает поиски, подходящие под match, в порядке id; вызывается под блокировкой
+func (s *Store) selectSearches(match func(search *domain.SavedSearch) bool) []domain.SavedSearch {
+	searches := []domain.SavedSearch{}
+	for _, id := range sortedIDs(s.savedSearches) {
+		if search := s.savedSearches[id]; match(search) {
+			searches = append(searches, *search)
+		}
+	}
+	return searches
+}
+
+func (r *savedSearchRepository) ListByUser(userID uint) ([]domain.SavedSearch, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.selectSearches(func(search *domain.SavedSearch) bool { return search.UserID == userID }), nil
+}
+
+func (r *savedSearchRepository) CountByUser(userID uint) (int64, error) {
+	searches, err := r.ListByUser(userID)
+	return int64(len(searches)), err
+}
+
+func (r *savedSearchRepository) Delete(id uint) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.savedSearches[id]; !ok {
+		return domain.ErrNotFound
+	}
+	delete(s.savedSearches, id)
+	return nil
+}
+
+// ListPending возвращает поиски, водяной знак которых раньше upTo
+func (r *savedSearchRepository) ListPending(upTo time.Time, limit int) ([]domain.SavedSearch, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pending := s.selectSearches(func(search *domain.SavedSearch) bool { return search.CheckedUntil.Before(upTo) })
+	return page(pending, 0, limit), nil
+}
+
+// Advance атомарно сдвигает водяной знак с from на to и записывает уведомления.
+// Если знак уже сдвинут (параллельным воркером), ничего не делает и возвращает false.
+func (r *savedSearchRepository) Advance(searchID uint, from, to time.Time, notifications []domain.Notification) (bool, error) {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	search, ok := s.savedSearches[searchID]
+	if !ok || !search.CheckedUntil.Equal(from) {
+		return false, nil
+	}
+	search.CheckedUntil = to
+	s.insertNotifications(notifications)
+	return true, nil
+}
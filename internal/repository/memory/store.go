@@ -0,0 +1,146 @@
+// Package memory хранит данные маркетплейса в памяти процесса. Репозитории повторяют
+// поведение реализаций из пакета repository (это проверяют контрактные тесты repotest)
+// и используются в режиме STORAGE=memory и в тестах; данные теряются при перезапуске.
+package memory
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// Store - общее хранилище всех таблиц. Один мьютекс на всё хранилище делает
+// операции, которые в PostgreSQL выполняются в транзакции, атомарными.
+type Store struct {
+	mu sync.RWMutex
+
+	lastID map[string]uint
+
+	users         map[uint]*domain.User
+	usernames     map[string]uint // уникальный индекс users.username
+	ads           map[uint]*domain.Advertisement
+	images        map[uint]*domain.AdImage
+	categories    map[uint]*domain.Category
+	favorites     map[favoriteKey]*domain.Favorite
+	refreshTokens map[uint]*domain.RefreshToken
+	revokedTokens map[string]*domain.RevokedToken
+	savedSearches map[uint]*domain.SavedSearch
+	notifications map[uint]*domain.Notification
+	conversations map[uint]*domain.Conversation
+	messages      map[uint]*domain.Message
+	offers        map[uint]*domain.Offer
+	reviews       map[uint]*domain.Review
+	blocks        map[blockKey]*domain.UserBlock
+}
+
+type favoriteKey struct {
+	userID uint
+	adID   uint
+}
+
+type blockKey struct {
+	blockerID uint
+	blockedID uint
+}
+
+// NewStore создаёт пустое хранилище с базовым справочником категорий
+func NewStore() *Store {
+	s := &Store{
+		lastID:        make(map[string]uint),
+		users:         make(map[uint]*domain.User),
+		usernames:     make(map[string]uint),
+		ads:           make(map[uint]*domain.Advertisement),
+		images:        make(map[uint]*domain.AdImage),
+		categories:    make(map[uint]*domain.Category),
+		favorites:     make(map[favoriteKey]*domain.Favorite),
+		refreshTokens: make(map[uint]*domain.RefreshToken),
+		revokedTokens: make(map[string]*domain.RevokedToken),
+		savedSearches: make(map[uint]*domain.SavedSearch),
+		notifications: make(map[uint]*domain.Notification),
+		conversations: make(map[uint]*domain.Conversation),
+		messages:      make(map[uint]*domain.Message),
+		offers:        make(map[uint]*domain.Offer),
+		reviews:       make(map[uint]*domain.Review),
+		blocks:        make(map[blockKey]*domain.UserBlock),
+	}
+	s.seedCategories()
+	return s
+}
+
+// nextID выдаёт следующий id таблицы, как последовательность в PostgreSQL
+func (s *Store) nextID(table string) uint {
+	s.lastID[table]++
+	return s.lastID[table]
+}
+
+// now возвращает текущее время с точностью PostgreSQL timestamptz
+func now() time.Time {
+	return time.Now().Round(time.Microsecond)
+}
+
+// sortedIDs возвращает ключи таблицы по возрастанию
+func sortedIDs[T any](table map[uint]*T) []uint {
+	ids := make([]uint, 0, len(table))
+	for id := range table {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// page применяет OFFSET и LIMIT так же, как GORM: отрицательный limit означает "без ограничения"
+func page[T any](items []T, offset, limit int) []T {
+	if offset > 0 {
+		if offset >= len(items) {
+			return items[:0]
+		}
+		items = items[offset:]
+	}
+	if limit >= 0 && limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// defaultCategories повторяет справочник из миграции 0004_default_categories
+var defaultCategories = []struct {
+	name, slug, parent string
+}{
+	{"Электроника", "electronics", ""},
+	{"Мебель и интерьер", "furniture-interior", ""},
+	{"Транспорт", "transport", ""},
+	{"Одежда и обувь", "clothing", ""},
+	{"Хобби и отдых", "hobby", ""},
+	{"Другое", "other", ""},
+	{"Телефоны", "phones", "electronics"},
+	{"Ноутбуки и компьютеры", "computers", "electronics"},
+	{"Фото и видео", "photo-video", "electronics"},
+	{"Аудио", "audio", "electronics"},
+	{"Мебель", "furniture", "furniture-interior"},
+	{"Освещение", "lighting", "furniture-interior"},
+	{"Декор", "decor", "furniture-interior"},
+	{"Автомобили", "cars", "transport"},
+	{"Велосипеды", "bicycles", "transport"},
+	{"Запчасти", "parts", "transport"},
+	{"Мужская одежда", "mens-clothing", "clothing"},
+	{"Женская одежда", "womens-clothing", "clothing"},
+	{"Детская одежда", "kids-clothing", "clothing"},
+	{"Спорт", "sport", "hobby"},
+	{"Книги", "books", "hobby"},
+	{"Музыкальные инструменты", "music-instruments", "hobby"},
+}
+
+func (s *Store) seedCategories() {
+	ids := make(map[string]uint)
+	for _, seed := range defaultCategories {
+		category := &domain.Category{ID: s.nextID("categories"), Name: seed.name, Slug: seed.slug}
+		if seed.parent != "" {
+			parentID := ids[seed.parent]
+			category.ParentID = &parentID
+		}
+		ids[seed.slug] = category.ID
+		s.categories[category.ID] = category
+	}
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type tokenRepository struct {
+	store *Store
+}
+
+func NewTokenRepository(store *Store) *tokenRepository {
+	return &tokenRepository{store: store}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *domain.RefreshToken) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, existing := range s.refreshTokens {
+		if existing.TokenHash == token.TokenHash {
+			return fmt.Errorf("%w: refresh token already exists", domain.ErrConflict)
+		}
+	}
+
+	token.ID = s.nextID("refresh_tokens")
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = now()
+	}
+	stored := *token
+	s.refreshTokens[token.ID] = &stored
+	return nil
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, token := range s.refreshTokens {
+		if token.TokenHash == hash {
+			found := *token
+			return &found, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+// revoke помечает отозванными ещё действующие токены, подходящие под match
+func (r *tokenRepository) revoke(match func(token *domain.RefreshToken) bool) int {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	revoked := 0
+	revokedAt := now()
+	for _, token := range s.refreshTokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &revokedAt
+			revoked++
+		}
+	}
+	return revoked
+}
+
+// RevokeRefreshToken атомарно помечает токен отозванным.
+// Возвращает false, если токен уже был отозван (например, параллельным запросом).
+func (r *tokenRepository) RevokeRefreshToken(id uint) (bool, error) {
+	return r.revoke(func(token *domain.RefreshToken) bool { return token.ID == id }) > 0, nil
+}
+
+func (r *tokenRepository) RevokeRefreshFamily(familyID string) error {
+	r.revoke(func(token *domain.RefreshToken) bool { return token.FamilyID == familyID })
+	return nil
+}
+
+// RevokeUserRefreshTokens завершает все сессии пользователя
+func (r *tokenRepository) RevokeUserRefreshTokens(userID uint) error {
+	r.revoke(func(token *domain.RefreshToken) bool { return token.UserID == userID })
+	return nil
+}
+
+func (r *tokenRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// заодно чистим записи, срок действия которых уже истёк
+	current := time.Now()
+	for key, token := range s.revokedTokens {
+		if token.ExpiresAt.Before(current) {
+			delete(s.revokedTokens, key)
+		}
+	}
+
+	if _, ok := s.revokedTokens[jti]; !ok {
+		s.revokedTokens[jti] = &domain.RevokedToken{JTI: jti, ExpiresAt: expiresAt}
+	}
+	return nil
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	_, ok := s.revokedTokens[jti]
+	return ok, nil
+}
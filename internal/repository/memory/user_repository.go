@@ -0,0 +1,152 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type userRepository struct {
+	store *Store
+}
+
+func NewUserRepository(store *Store) *userRepository {
+	return &userRepository{store: store}
+}
+
+// Create сохраняет пользователя; имя пользователя уникально, как и в PostgreSQL
+func (r *userRepository) Create(user *domain.User) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.findUser(user.Username) != nil {
+		return fmt.Errorf("%w: username %q already exists", domain.ErrConflict, user.Username)
+	}
+
+	user.ID = s.nextID("users")
+	if user.Role == "" {
+		user.Role = domain.RoleUser
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = now()
+	}
+	stored := *user
+	s.users[user.ID] = &stored
+	s.usernames[user.Username] = user.ID
+	return nil
+}
+
+// findUser ищет пользователя по имени; вызывается под блокировкой
+func (s *Store) findUser(username string) *domain.User {
+	return s.users[s.usernames[username]]
+}
+
+func (r *userRepository) GetByUsername(username string) (*domain.User, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user := s.findUser(username)
+	if user == nil {
+		return nil, domain.ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func (r *userRepository) Exists(username string) (bool, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.findUser(username) != nil, nil
+}
+
+func (r *userRepository) GetByID(id uint) (*domain.User, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return nil, domain.ErrNotFound
+	}
+	found := *user
+	return &found, nil
+}
+
+func matchUser(user *domain.User, filter domain.UserFilter) bool {
+	if filter.Role != "" && user.Role != filter.Role {
+		return false
+	}
+	if filter.Banned != nil && user.Banned != *filter.Banned {
+		return false
+	}
+	return true
+}
+
+func (r *userRepository) List(filter domain.UserFilter) ([]domain.User, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var users []domain.User
+	for _, id := range sortedIDs(s.users) {
+		if user := s.users[id]; matchUser(user, filter) {
+			users = append(users, *user)
+		}
+	}
+	return page(users, filter.Offset, filter.Limit), nil
+}
+
+func (r *userRepository) Count(filter domain.UserFilter) (int64, error) {
+	s := r.store
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var count int64
+	for _, user := range s.users {
+		if matchUser(user, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// update применяет change к пользователю id или возвращает ErrNotFound
+func (r *userRepository) update(id uint, change func(user *domain.User)) error {
+	s := r.store
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	user, ok := s.users[id]
+	if !ok {
+		return domain.ErrNotFound
+	}
+	change(user)
+	return nil
+}
+
+func (r *userRepository) SetBanned(id uint, banned bool) error {
+	return r.update(id, func(user *domain.User) { user.Banned = banned })
+}
+
+func (r *userRepository) SetRole(id uint, role domain.Role) error {
+	return r.update(id, func(user *domain.User) { user.Role = role })
+}
+
+// SetPassword сохраняет новый хэш пароля
+func (r *userRepository) SetPassword(id uint, hash string) error {
+	return r.update(id, func(user *domain.User) { user.Password = hash })
+}
+
+// UpdateProfile сохраняет публичные поля профиля
+func (r *userRepository) UpdateProfile(profile *domain.User) error {
+	return r.update(profile.ID, func(user *domain.User) {
+		user.DisplayName = profile.DisplayName
+		user.AvatarURL = profile.AvatarURL
+		user.City = profile.City
+		user.About = profile.About
+	})
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+)
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) *notificationRepository {
+	return &notificationRepository{db: db}
+}
+
+func (r *notificationRepository) userQuery(userID uint, unreadOnly bool) *gorm.DB {
+	query := r.db.Model(&domain.Notification{}).Where("user_id = ?", userID)
+	if unreadOnly {
+		query = query.Where("read_at IS NULL")
+	}
+	return query
+}
+
+// List возвращает уведомления пользователя, новые первыми
+func (r *notificationRepository) List(userID uint, filter domain.NotificationFilter) ([]domain.Notification, error) {
+	var notifications []domain.Notification
+	err := r.userQuery(userID, filter.UnreadOnly).
+		Order("id DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&notifications).Error
+	return notifications, err
+}
+
+func (r *notificationRepository) Count(userID uint, unreadOnly bool) (int64, error) {
+	var count int64
+	err := r.userQuery(userID, unreadOnly).Count(&count).Error
+	return count, err
+}
+
+// MarkRead помечает прочитанными уведомления ids, а при пустом ids - все уведомления пользователя
+func (r *notificationRepository) MarkRead(userID uint, ids []uint) (int64, error) {
+	query := r.userQuery(userID, true)
+	if len(ids) > 0 {
+		query = query.Where("id IN ?", ids)
+	}
+	result := query.Update("read_at", time.Now())
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type offerRepository struct {
+	db *gorm.DB
+}
+
+func NewOfferRepository(db *gorm.DB) *offerRepository {
+	return &offerRepository{db: db}
+}
+
+var (
+	errAdNotPublished = fmt.Errorf("%w: advertisement is no longer published", domain.ErrInvalidStatusTransition)
+	errActiveOffer    = fmt.Errorf("%w: you already have an active offer on this advertisement", domain.ErrInvalidInput)
+)
+
+// activeOfferStatuses - статусы предложений, ожидающих ответа
+var activeOfferStatuses = []domain.OfferStatus{domain.OfferStatusPending, domain.OfferStatusCountered}
+
+// Create сохраняет предложение, если объявление всё ещё опубликовано и у покупателя нет
+// другого предложения по нему, ожидающего ответа. Строка объявления блокируется, поэтому
+// предложение не проскочит мимо параллельного Accept, а два параллельных Create одного
+// покупателя выполнятся по очереди и второй увидит первое предложение.
+func (r *offerRepository) Create(offer *domain.Offer) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var ad domain.Advertisement
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "status").
+			First(&ad, offer.AdID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return domain.ErrNotFound
+		}
+		if err != nil {
+			return err
+		}
+		if ad.Status != domain.AdStatusPublished {
+			return errAdNotPublished
+		}
+
+		var active int64
+		err = tx.Model(&domain.Offer{}).
+			Where("ad_id = ? AND buyer_id = ? AND status IN ?", offer.AdID, offer.BuyerID, activeOfferStatuses).
+			Count(&active).Error
+		if err != nil {
+			return err
+		}
+		if active > 0 {
+			return errActiveOffer
+		}
+
+		return tx.Omit(clause.Associations).Create(offer).Error
+	})
+}
+
+func (r *offerRepository) GetByID(id uint) (*domain.Offer, error) {
+	var offer domain.Offer
+	err := r.db.Preload("Ad", func(db *gorm.DB) *gorm.DB {
+		return db.Unscoped()
+	}).Preload("Buyer").First(&offer, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &offer, nil
+}
+
+func applyOfferFilter(query *gorm.DB, filter domain.OfferFilter) *gorm.DB {
+	if filter.AdID != 0 {
+		query = query.Where("ad_id = ?", filter.AdID)
+	}
+	if filter.BuyerID != 0 {
+		query = query.Where("buyer_id = ?", filter.BuyerID)
+	}
+	if filter.SellerID != 0 {
+		query = query.Where("seller_id = ?", filter.SellerID)
+	}
+	return query
+}
+
+// List возвращает предложения, новые первыми
+func (r *offerRepository) List(filter domain.OfferFilter) ([]domain.Offer, error) {
+	var offers []domain.Offer
+	err := applyOfferFilter(r.db.Model(&domain.Offer{}), filter).
+		Preload("Ad", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Buyer").
+		Order("id DESC").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&offers).Error
+	return offers, err
+}
+
+func (r *offerRepository) Count(filter domain.OfferFilter) (int64, error) {
+	var count int64
+	err := applyOfferFilter(r.db.Model(&domain.Offer{}), filter).Count(&count).Error
+	return count, err
+}
+
+// UpdateStatus переводит предложение из статуса from в offer.Status вместе со встречной ценой.
+// Если статус уже изменился, возвращает ErrInvalidStatusTransition.
+func (r *offerRepository) UpdateStatus(offer *domain.Offer, from domain.OfferStatus) error {
+	offer.UpdatedAt = time.Now()
+	result := r.db.Model(&domain.Offer{}).
+		Where("id = ? AND status = ?", offer.ID, from).
+		Updates(map[string]interface{}{
+			"status":         offer.Status,
+			"counter_amount": offer.CounterAmount,
+			"updated_at":     offer.UpdatedAt,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("%w: offer is no longer %s", domain.ErrInvalidStatusTransition, from)
+	}
+	return nil
+}
+
+// Accept в одной транзакции принимает предложение, бронирует объявление
+// и отклоняет остальные активные предложения по нему. Возвращает отклонённые.
+func (r *offerRepository) Accept(offer *domain.Offer, from domain.OfferStatus) ([]domain.Offer, error) {
+	var declined []domain.Offer
+	now := time.Now()
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.Advertisement{}).
+			Where("id = ? AND status = ?", offer.AdID, domain.AdStatusPublished).
+			Update("status", domain.AdStatusReserved)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errAdNotPublished
+		}
+
+		result = tx.Model(&domain.Offer{}).
+			Where("id = ? AND status = ?", offer.ID, from).
+			Updates(map[string]interface{}{
+				"status":          domain.OfferStatusAccepted,
+				"accepted_amount": offer.AcceptedAmount,
+				"updated_at":      now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: offer is no longer %s", domain.ErrInvalidStatusTransition, from)
+		}
+
+		if err := tx.Where("ad_id = ? AND id <> ? AND status IN ?", offer.AdID, offer.ID, activeOfferStatuses).
+			Find(&declined).Error; err != nil {
+			return err
+		}
+		if len(declined) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(declined))
+		for i := range declined {
+			ids[i] = declined[i].ID
+			declined[i].Status = domain.OfferStatusDeclined
+			declined[i].UpdatedAt = now
+		}
+		return tx.Model(&domain.Offer{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"status":     domain.OfferStatusDeclined,
+				"updated_at": now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	offer.Status = domain.OfferStatusAccepted
+	offer.UpdatedAt = now
+	return declined, nil
+}
@@ -0,0 +1,416 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// base - момент создания тестовых объявлений; с точностью до секунды, чтобы
+// время одинаково хранилось во всех реализациях
+var base = time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+type adFixture struct {
+	title    string
+	price    float64
+	age      time.Duration
+	status   domain.AdStatus
+	category uint
+}
+
+// createAds создаёт объявления пользователя и возвращает их id в порядке fixtures
+func createAds(t *testing.T, repo AdvertisementRepository, userID uint, fixtures ...adFixture) []uint {
+	t.Helper()
+	ids := make([]uint, len(fixtures))
+	for i, f := range fixtures {
+		status := f.status
+		if status == "" {
+			status = domain.AdStatusPublished
+		}
+		category := f.category
+		if category == 0 {
+			category = 1
+		}
+		ad := &domain.Advertisement{
+			Title:       f.title,
+			Description: "Description of " + f.title,
+			Price:       f.price,
+			Status:      status,
+			CategoryID:  category,
+			UserID:      userID,
+			CreatedAt:   base.Add(-f.age),
+		}
+		if status != domain.AdStatusDraft {
+			publishedAt := ad.CreatedAt
+			ad.PublishedAt = &publishedAt
+		}
+		if err := repo.Create(ad); err != nil {
+			t.Fatalf("Create(%q) error = %v", f.title, err)
+		}
+		ids[i] = ad.ID
+	}
+	return ids
+}
+
+func adIDs(ads []domain.Advertisement) []uint {
+	ids := make([]uint, len(ads))
+	for i, ad := range ads {
+		ids[i] = ad.ID
+	}
+	return ids
+}
+
+func assertAdIDs(t *testing.T, name string, ads []domain.Advertisement, want ...uint) {
+	t.Helper()
+	got := adIDs(ads)
+	if len(got) != len(want) {
+		t.Fatalf("%s: got ids %v, want %v", name, got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("%s: got ids %v, want %v", name, got, want)
+		}
+	}
+}
+
+func RunAdvertisementTests(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+
+		ad := &domain.Advertisement{
+			Title:       "Acoustic guitar",
+			Description: "Six strings, barely used",
+			ImageURL:    "https://example.com/cover.jpg",
+			Price:       9000,
+			CategoryID:  1,
+			UserID:      user.ID,
+			Images: []domain.AdImage{
+				{URL: "https://example.com/second.jpg", Position: 1},
+				{URL: "https://example.com/cover.jpg", Position: 0, IsCover: true},
+			},
+		}
+		if err := repos.Ads.Create(ad); err != nil {
+			t.Fatal(err)
+		}
+		if ad.ID == 0 || ad.Status != domain.AdStatusPublished || ad.CreatedAt.IsZero() {
+			t.Fatalf("Create() did not fill defaults: %+v", ad)
+		}
+		for _, image := range ad.Images {
+			if image.ID == 0 || image.AdID != ad.ID {
+				t.Fatalf("Create() did not save image: %+v", image)
+			}
+		}
+
+		got, err := repos.Ads.GetByID(ad.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != ad.Title || got.Price != ad.Price || got.User.Username != "seller" {
+			t.Errorf("GetByID() = %+v", got)
+		}
+		if len(got.Images) != 2 || got.Images[0].URL != "https://example.com/cover.jpg" || !got.Images[0].IsCover {
+			t.Errorf("GetByID() images = %+v, want ordered by position", got.Images)
+		}
+	})
+
+	t.Run("DeleteIsSoft", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+		ids := createAds(t, repos.Ads, user.ID, adFixture{title: "Old sofa", price: 100})
+
+		if err := repos.Favorites.Add(user.ID, ids[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Ads.Delete(ids[0]); err != nil {
+			t.Fatal(err)
+		}
+		if err := repos.Ads.Delete(ids[0]); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+		}
+		if _, err := repos.Ads.GetByID(ids[0]); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID(deleted) error = %v, want ErrNotFound", err)
+		}
+		if count, err := repos.Ads.Count(domain.AdFilter{}); err != nil || count != 0 {
+			t.Errorf("Count() = %d, %v, want 0", count, err)
+		}
+
+		// удалённое объявление остаётся в избранном
+		favorites, err := repos.Ads.GetAll(domain.AdFilter{FavoritesOf: user.ID, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertAdIDs(t, "favorites", favorites, ids[0])
+		if !favorites[0].DeletedAt.Valid {
+			t.Error("deleted favorite is not marked as deleted")
+		}
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+		ids := createAds(t, repos.Ads, user.ID, adFixture{title: "Road bike", price: 30000})
+
+		ad, err := repos.Ads.GetByID(ids[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		ad.Title = "Road bike, size M"
+		ad.Price = 28000
+		ad.Status = domain.AdStatusArchived
+		if err := repos.Ads.Update(ad); err != nil {
+			t.Fatal(err)
+		}
+
+		// статус меняет только UpdateStatus: устаревшая копия не затирает его
+		got, err := repos.Ads.GetByID(ids[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Title != "Road bike, size M" || got.Price != 28000 || got.Status != domain.AdStatusPublished {
+			t.Errorf("Update() was not saved: %+v", got)
+		}
+		if got.User.Username != "seller" {
+			t.Errorf("Update() changed the author: %+v", got.User)
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+		ids := createAds(t, repos.Ads, user.ID, adFixture{title: "Road bike", price: 30000, status: domain.AdStatusDraft})
+
+		publishedAt := base.Add(time.Hour)
+		ad := &domain.Advertisement{ID: ids[0], Status: domain.AdStatusPublished, PublishedAt: &publishedAt}
+		if err := repos.Ads.UpdateStatus(ad, domain.AdStatusDraft); err != nil {
+			t.Fatal(err)
+		}
+		got, err := repos.Ads.GetByID(ids[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != domain.AdStatusPublished || got.PublishedAt == nil || !got.PublishedAt.Equal(publishedAt) || got.Title != "Road bike" {
+			t.Errorf("UpdateStatus() = %+v", got)
+		}
+
+		// статус уже сменился: переход из устаревшего статуса не проходит
+		stale := &domain.Advertisement{ID: ids[0], Status: domain.AdStatusArchived}
+		if err := repos.Ads.UpdateStatus(stale, domain.AdStatusDraft); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("stale UpdateStatus() error = %v, want ErrConflict", err)
+		}
+		if err := repos.Ads.UpdateStatus(&domain.Advertisement{ID: 999, Status: domain.AdStatusArchived}, domain.AdStatusPublished); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("UpdateStatus(missing) error = %v, want ErrConflict", err)
+		}
+		if got, _ := repos.Ads.GetByID(ids[0]); got == nil || got.Status != domain.AdStatusPublished {
+			t.Errorf("status after stale UpdateStatus = %+v, want published", got)
+		}
+	})
+
+	t.Run("UpdateImages", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+		ad := &domain.Advertisement{
+			Title: "Desk lamp", Description: "Warm light", Price: 800, CategoryID: 1, UserID: user.ID,
+			ImageURL: "https://example.com/a.jpg",
+			Images: []domain.AdImage{
+				{URL: "https://example.com/a.jpg", Position: 0, IsCover: true},
+				{URL: "https://example.com/b.jpg", Position: 1},
+			},
+		}
+		if err := repos.Ads.Create(ad); err != nil {
+			t.Fatal(err)
+		}
+
+		// первое изображение удаляется, второе становится обложкой, третье добавляется
+		images := []domain.AdImage{
+			{ID: ad.Images[1].ID, URL: "https://example.com/b.jpg", Position: 0, IsCover: true},
+			{URL: "https://example.com/c.jpg", Position: 1},
+		}
+		if err := repos.Ads.UpdateImages(ad.ID, images, "https://example.com/b.jpg"); err != nil {
+			t.Fatal(err)
+		}
+		if images[1].ID == 0 {
+			t.Error("UpdateImages() did not assign an id to the new image")
+		}
+
+		got, err := repos.Ads.GetByID(ad.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.ImageURL != "https://example.com/b.jpg" {
+			t.Errorf("ImageURL = %q, want the new cover", got.ImageURL)
+		}
+		if len(got.Images) != 2 || got.Images[0].ID != ad.Images[1].ID || got.Images[1].URL != "https://example.com/c.jpg" {
+			t.Errorf("Images = %+v", got.Images)
+		}
+	})
+
+	t.Run("Filters", func(t *testing.T) {
+		repos := newRepos(t)
+		alice := createUser(t, repos.Users, "alice")
+		bob := createUser(t, repos.Users, "bob")
+		a := createAds(t, repos.Ads, alice.ID,
+			adFixture{title: "Cheap phone", price: 1000, age: 4 * time.Hour, category: 7},
+			adFixture{title: "Middle phone", price: 5000, age: 3 * time.Hour, category: 7},
+			adFixture{title: "Draft phone", price: 5000, age: 2 * time.Hour, category: 7, status: domain.AdStatusDraft},
+		)
+		b := createAds(t, repos.Ads, bob.ID,
+			adFixture{title: "Expensive laptop", price: 90000, age: time.Hour, category: 8},
+		)
+
+		cases := []struct {
+			name   string
+			filter domain.AdFilter
+			want   []uint
+		}{
+			{"min price inclusive", domain.AdFilter{MinPrice: 5000}, []uint{b[0], a[2], a[1]}},
+			{"max price inclusive", domain.AdFilter{MaxPrice: 5000}, []uint{a[2], a[1], a[0]}},
+			{"price range", domain.AdFilter{MinPrice: 2000, MaxPrice: 10000, Status: domain.AdStatusPublished}, []uint{a[1]}},
+			{"status", domain.AdFilter{Status: domain.AdStatusDraft}, []uint{a[2]}},
+			{"user", domain.AdFilter{UserID: bob.ID}, []uint{b[0]}},
+			{"author", domain.AdFilter{Author: "alice", Status: domain.AdStatusPublished}, []uint{a[1], a[0]}},
+			{"unknown author", domain.AdFilter{Author: "ghost"}, nil},
+			{"categories", domain.AdFilter{CategoryIDs: []uint{8, 9}}, []uint{b[0]}},
+			{"search", domain.AdFilter{Query: "laptop"}, []uint{b[0]}},
+			{"search all words", domain.AdFilter{Query: "cheap phone"}, []uint{a[0]}},
+			{"search without match", domain.AdFilter{Query: "guitar"}, nil},
+		}
+		for _, tc := range cases {
+			tc.filter.Limit = 10
+			ads, err := repos.Ads.GetAll(tc.filter)
+			if err != nil {
+				t.Fatalf("%s: GetAll() error = %v", tc.name, err)
+			}
+			assertAdIDs(t, tc.name, ads, tc.want...)
+
+			count, err := repos.Ads.Count(tc.filter)
+			if err != nil || count != int64(len(tc.want)) {
+				t.Errorf("%s: Count() = %d, %v, want %d", tc.name, count, err, len(tc.want))
+			}
+		}
+
+		ads, err := repos.Ads.GetAll(domain.AdFilter{UserID: bob.ID, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ads[0].User.Username != "bob" {
+			t.Errorf("GetAll() did not load the author: %+v", ads[0].User)
+		}
+	})
+
+	t.Run("SortingAndPagination", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+		ids := createAds(t, repos.Ads, user.ID,
+			adFixture{title: "Ad one", price: 300, age: 1 * time.Hour},
+			adFixture{title: "Ad two", price: 100, age: 3 * time.Hour},
+			adFixture{title: "Ad three", price: 200, age: 2 * time.Hour},
+			adFixture{title: "Ad four", price: 200, age: 4 * time.Hour},
+		)
+
+		cases := []struct {
+			name   string
+			filter domain.AdFilter
+			want   []uint
+		}{
+			{"newest first by default", domain.AdFilter{Limit: 10}, []uint{ids[0], ids[2], ids[1], ids[3]}},
+			{"oldest first", domain.AdFilter{SortBy: "created_at", Order: "asc", Limit: 10}, []uint{ids[3], ids[1], ids[2], ids[0]}},
+			{"price asc, id breaks ties", domain.AdFilter{SortBy: "price", Order: "asc", Limit: 10}, []uint{ids[1], ids[2], ids[3], ids[0]}},
+			{"price desc, id breaks ties", domain.AdFilter{SortBy: "price", Order: "desc", Limit: 10}, []uint{ids[0], ids[3], ids[2], ids[1]}},
+			{"offset and limit", domain.AdFilter{SortBy: "price", Order: "asc", Offset: 1, Limit: 2}, []uint{ids[2], ids[3]}},
+			{"offset past the end", domain.AdFilter{Offset: 10, Limit: 10}, nil},
+		}
+		for _, tc := range cases {
+			ads, err := repos.Ads.GetAll(tc.filter)
+			if err != nil {
+				t.Fatalf("%s: GetAll() error = %v", tc.name, err)
+			}
+			assertAdIDs(t, tc.name, ads, tc.want...)
+		}
+
+		if count, err := repos.Ads.Count(domain.AdFilter{Offset: 1, Limit: 1}); err != nil || count != 4 {
+			t.Errorf("Count() = %d, %v, want 4 regardless of pagination", count, err)
+		}
+	})
+
+	t.Run("KeysetPagination", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+		ids := createAds(t, repos.Ads, user.ID,
+			adFixture{title: "Ad one", price: 100, age: time.Hour},
+			adFixture{title: "Ad two", price: 200, age: time.Hour},
+			adFixture{title: "Ad three", price: 200, age: 2 * time.Hour},
+			adFixture{title: "Ad four", price: 300, age: 3 * time.Hour},
+		)
+
+		for _, order := range []struct {
+			sortBy, order string
+			want          []uint
+		}{
+			{"created_at", "desc", []uint{ids[1], ids[0], ids[2], ids[3]}},
+			{"price", "asc", []uint{ids[0], ids[1], ids[2], ids[3]}},
+			{"price", "desc", []uint{ids[3], ids[2], ids[1], ids[0]}},
+		} {
+			filter := domain.AdFilter{SortBy: order.sortBy, Order: order.order, Limit: 2}
+			var got []uint
+			for page := 0; page < 3; page++ {
+				ads, err := repos.Ads.GetAll(filter)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = append(got, adIDs(ads)...)
+				if len(ads) < filter.Limit {
+					break
+				}
+				last := ads[len(ads)-1]
+				filter.After = &domain.AdCursor{SortBy: order.sortBy, Order: order.order, CreatedAt: last.CreatedAt, Price: last.Price, ID: last.ID}
+			}
+			assertAdIDs(t, order.sortBy+" "+order.order, toAds(got), order.want...)
+		}
+	})
+
+	t.Run("MatchNew", func(t *testing.T) {
+		repos := newRepos(t)
+		user := createUser(t, repos.Users, "seller")
+		ids := createAds(t, repos.Ads, user.ID,
+			adFixture{title: "Old bike", price: 200, age: 2 * time.Hour},
+			adFixture{title: "New bike", price: 200, age: time.Hour},
+			adFixture{title: "Future bike", price: 300, age: -time.Hour},
+			adFixture{title: "Cheap bike", price: 100, age: time.Hour},
+			adFixture{title: "Late draft", price: 250, age: 3 * time.Hour, status: domain.AdStatusDraft},
+			adFixture{title: "Still draft", price: 250, age: time.Hour, status: domain.AdStatusDraft},
+		)
+
+		// черновик, созданный раньше водяного знака, но опубликованный после него
+		draft, err := repos.Ads.GetByID(ids[4])
+		if err != nil {
+			t.Fatal(err)
+		}
+		publishedAt := base.Add(-30 * time.Minute)
+		draft.Status = domain.AdStatusPublished
+		draft.PublishedAt = &publishedAt
+		if err := repos.Ads.UpdateStatus(draft, domain.AdStatusDraft); err != nil {
+			t.Fatal(err)
+		}
+
+		filter := domain.AdFilter{MinPrice: 150, Status: domain.AdStatusPublished}
+		matched, err := repos.Ads.MatchNew(filter, base.Add(-90*time.Minute), base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertAdIDs(t, "MatchNew", matched, ids[1], ids[4])
+		if matched[0].Title != "New bike" || matched[0].UserID != user.ID {
+			t.Errorf("MatchNew() = %+v", matched[0])
+		}
+	})
+}
+
+func toAds(ids []uint) []domain.Advertisement {
+	ads := make([]domain.Advertisement, len(ids))
+	for i, id := range ids {
+		ads[i] = domain.Advertisement{ID: id}
+	}
+	return ads
+}
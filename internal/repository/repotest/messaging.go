@@ -0,0 +1,194 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+func openConversation(t *testing.T, repo ConversationRepository, d deal, buyerID uint) *domain.Conversation {
+	t.Helper()
+	conv := &domain.Conversation{AdID: d.adID, BuyerID: buyerID, SellerID: d.seller.ID, LastMessageAt: base}
+	if err := repo.FindOrCreate(conv); err != nil {
+		t.Fatalf("FindOrCreate() error = %v", err)
+	}
+	return conv
+}
+
+// addMessages добавляет по сообщению от каждого отправителя с шагом в минуту и возвращает их id
+func addMessages(t *testing.T, repo ConversationRepository, conv *domain.Conversation, start time.Time, senders ...uint) []uint {
+	t.Helper()
+	ids := make([]uint, len(senders))
+	for i, senderID := range senders {
+		msg := &domain.Message{SenderID: senderID, Body: "Message", CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+		if err := repo.AddMessage(conv, msg); err != nil {
+			t.Fatalf("AddMessage() error = %v", err)
+		}
+		ids[i] = msg.ID
+	}
+	return ids
+}
+
+func RunConversationTests(t *testing.T, newRepos Factory) {
+	t.Run("FindOrCreate", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		other := createUser(t, repos.Users, "other")
+
+		first := openConversation(t, repos.Conversations, d, d.buyer.ID)
+		again := openConversation(t, repos.Conversations, d, d.buyer.ID)
+		if first.ID == 0 || again.ID != first.ID {
+			t.Errorf("FindOrCreate() ids = %d and %d, want the same conversation", first.ID, again.ID)
+		}
+		if another := openConversation(t, repos.Conversations, d, other.ID); another.ID == first.ID {
+			t.Error("FindOrCreate() reused a conversation of another buyer")
+		}
+
+		got, err := repos.Conversations.GetByID(first.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Ad.Title != "Road bike" || got.Buyer.Username != "buyer" || got.Seller.Username != "seller" {
+			t.Errorf("GetByID() did not load relations: %+v", got)
+		}
+		if _, err := repos.Conversations.GetByID(999); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID(999) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("Messages", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		conv := openConversation(t, repos.Conversations, d, d.buyer.ID)
+		ids := addMessages(t, repos.Conversations, conv, base, d.buyer.ID, d.seller.ID, d.buyer.ID, d.buyer.ID)
+
+		messages, err := repos.Conversations.ListMessages(conv.ID, 0, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(messages) != 2 || messages[0].ID != ids[3] || messages[1].ID != ids[2] {
+			t.Fatalf("ListMessages() = %+v, want messages %d and %d", messages, ids[3], ids[2])
+		}
+		older, err := repos.Conversations.ListMessages(conv.ID, ids[2], 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(older) != 2 || older[0].ID != ids[1] || older[1].ID != ids[0] {
+			t.Fatalf("ListMessages(before %d) = %+v", ids[2], older)
+		}
+
+		got, err := repos.Conversations.GetByID(conv.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.SellerUnread != 3 || got.BuyerUnread != 1 {
+			t.Errorf("unread = buyer %d, seller %d, want 1 and 3", got.BuyerUnread, got.SellerUnread)
+		}
+		if !got.LastMessageAt.Equal(base.Add(3 * time.Minute)) {
+			t.Errorf("LastMessageAt = %v, want %v", got.LastMessageAt, base.Add(3*time.Minute))
+		}
+	})
+
+	t.Run("ListByUser", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		other := createUser(t, repos.Users, "other")
+		first := openConversation(t, repos.Conversations, d, d.buyer.ID)
+		second := openConversation(t, repos.Conversations, d, other.ID)
+
+		// переписка с последним сообщением поднимается наверх
+		addMessages(t, repos.Conversations, second, base.Add(time.Hour), other.ID)
+		addMessages(t, repos.Conversations, first, base.Add(2*time.Hour), d.buyer.ID, d.buyer.ID)
+
+		conversations, err := repos.Conversations.ListByUser(d.seller.ID, 0, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(conversations) != 2 || conversations[0].ID != first.ID || conversations[1].ID != second.ID {
+			t.Fatalf("ListByUser(seller) = %+v, want %d then %d", conversations, first.ID, second.ID)
+		}
+		if conversations[0].Buyer.Username != "buyer" {
+			t.Errorf("ListByUser() did not load relations: %+v", conversations[0])
+		}
+		if page, err := repos.Conversations.ListByUser(d.seller.ID, 1, 1); err != nil || len(page) != 1 || page[0].ID != second.ID {
+			t.Errorf("ListByUser(offset 1) = %+v, %v", page, err)
+		}
+
+		for _, tc := range []struct {
+			userID        uint
+			count, unread int64
+		}{{d.seller.ID, 2, 3}, {d.buyer.ID, 1, 0}, {other.ID, 1, 0}} {
+			if count, err := repos.Conversations.CountByUser(tc.userID); err != nil || count != tc.count {
+				t.Errorf("CountByUser(%d) = %d, %v, want %d", tc.userID, count, err, tc.count)
+			}
+			if unread, err := repos.Conversations.UnreadTotal(tc.userID); err != nil || unread != tc.unread {
+				t.Errorf("UnreadTotal(%d) = %d, %v, want %d", tc.userID, unread, err, tc.unread)
+			}
+		}
+	})
+
+	t.Run("MarkRead", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		conv := openConversation(t, repos.Conversations, d, d.buyer.ID)
+		ids := addMessages(t, repos.Conversations, conv, base, d.buyer.ID, d.seller.ID, d.buyer.ID, d.buyer.ID)
+
+		// продавец видел страницу до третьего сообщения: четвёртое остаётся непрочитанным
+		if err := repos.Conversations.MarkRead(conv, d.seller.ID, ids[2]); err != nil {
+			t.Fatal(err)
+		}
+		if unread, err := repos.Conversations.UnreadTotal(d.seller.ID); err != nil || unread != 1 {
+			t.Errorf("UnreadTotal(seller) = %d, %v, want 1", unread, err)
+		}
+		// чтение более старой страницы не возвращает прочитанное в непрочитанные
+		if err := repos.Conversations.MarkRead(conv, d.seller.ID, ids[0]); err != nil {
+			t.Fatal(err)
+		}
+		if unread, err := repos.Conversations.UnreadTotal(d.seller.ID); err != nil || unread != 1 {
+			t.Errorf("UnreadTotal(seller) after older page = %d, %v, want 1", unread, err)
+		}
+		if err := repos.Conversations.MarkRead(conv, d.buyer.ID, ids[3]); err != nil {
+			t.Fatal(err)
+		}
+		if unread, err := repos.Conversations.UnreadTotal(d.buyer.ID); err != nil || unread != 0 {
+			t.Errorf("UnreadTotal(buyer) = %d, %v, want 0", unread, err)
+		}
+	})
+}
+
+func RunBlockTests(t *testing.T, newRepos Factory) {
+	t.Run("BlockAndUnblock", func(t *testing.T) {
+		repo := newRepos(t).Blocks
+		for i := 0; i < 2; i++ {
+			if err := repo.Block(1, 2); err != nil {
+				t.Fatalf("Block() attempt %d error = %v", i+1, err)
+			}
+		}
+
+		// блокировка действует в обе стороны и только на эту пару
+		for _, pair := range [][2]uint{{1, 2}, {2, 1}} {
+			if blocked, err := repo.IsBlocked(pair[0], pair[1]); err != nil || !blocked {
+				t.Errorf("IsBlocked(%d, %d) = %v, %v, want true", pair[0], pair[1], blocked, err)
+			}
+		}
+		if blocked, err := repo.IsBlocked(1, 3); err != nil || blocked {
+			t.Errorf("IsBlocked(1, 3) = %v, %v, want false", blocked, err)
+		}
+
+		// снять блокировку может только тот, кто её поставил
+		if err := repo.Unblock(2, 1); err != nil {
+			t.Fatal(err)
+		}
+		if blocked, err := repo.IsBlocked(1, 2); err != nil || !blocked {
+			t.Errorf("IsBlocked(1, 2) after Unblock(2, 1) = %v, %v, want true", blocked, err)
+		}
+		if err := repo.Unblock(1, 2); err != nil {
+			t.Fatal(err)
+		}
+		if blocked, err := repo.IsBlocked(2, 1); err != nil || blocked {
+			t.Errorf("IsBlocked(2, 1) after Unblock = %v, %v, want false", blocked, err)
+		}
+	})
+}
@@ -0,0 +1,173 @@
+package repotest
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+func createSearch(t *testing.T, repo SavedSearchRepository, userID uint, name string, checkedUntil time.Time) *domain.SavedSearch {
+	t.Helper()
+	search := &domain.SavedSearch{UserID: userID, Name: name, SortBy: "created_at", Order: "desc", CheckedUntil: checkedUntil}
+	if err := repo.Create(search); err != nil {
+		t.Fatalf("Create(%q) error = %v", name, err)
+	}
+	return search
+}
+
+func searchNotification(search *domain.SavedSearch, adID uint) domain.Notification {
+	return domain.Notification{
+		UserID:        search.UserID,
+		Type:          domain.NotificationSavedSearchMatch,
+		Title:         "New ad",
+		AdID:          &adID,
+		SavedSearchID: &search.ID,
+		DedupKey:      fmt.Sprintf("search:%d:ad:%d", search.ID, adID),
+	}
+}
+
+func assertSearchIDs(t *testing.T, name string, searches []domain.SavedSearch, want ...uint) {
+	t.Helper()
+	if len(searches) != len(want) {
+		t.Fatalf("%s: got %d searches, want %v", name, len(searches), want)
+	}
+	for i := range want {
+		if searches[i].ID != want[i] {
+			t.Errorf("%s: searches[%d].ID = %d, want %d", name, i, searches[i].ID, want[i])
+		}
+	}
+}
+
+func RunSavedSearchTests(t *testing.T, newRepos Factory) {
+	t.Run("CRUD", func(t *testing.T) {
+		repo := newRepos(t).SavedSearches
+		first := createSearch(t, repo, 1, "Bikes", base)
+		second := createSearch(t, repo, 1, "Helmets", base)
+		createSearch(t, repo, 2, "Other", base)
+		if first.ID == 0 || first.CreatedAt.IsZero() {
+			t.Fatalf("Create() did not fill defaults: %+v", first)
+		}
+
+		got, err := repo.GetByID(first.ID)
+		if err != nil || got.Name != "Bikes" || got.UserID != 1 || !got.CheckedUntil.Equal(base) {
+			t.Errorf("GetByID() = %+v, %v", got, err)
+		}
+		searches, err := repo.ListByUser(1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertSearchIDs(t, "user searches", searches, first.ID, second.ID)
+
+		if err := repo.Delete(first.ID); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.Delete(first.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("second Delete() error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetByID(first.ID); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID(deleted) error = %v, want ErrNotFound", err)
+		}
+		if count, err := repo.CountByUser(1); err != nil || count != 1 {
+			t.Errorf("CountByUser() = %d, %v, want 1", count, err)
+		}
+	})
+
+	t.Run("Advance", func(t *testing.T) {
+		repos := newRepos(t)
+		repo := repos.SavedSearches
+		early := createSearch(t, repo, 1, "Early", base.Add(-time.Hour))
+		late := createSearch(t, repo, 1, "Late", base.Add(-time.Minute))
+		createSearch(t, repo, 1, "Checked", base)
+
+		pending, err := repo.ListPending(base, 10)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertSearchIDs(t, "pending", pending, early.ID, late.ID)
+		if pending, err := repo.ListPending(base, 1); err != nil || len(pending) != 1 {
+			t.Errorf("ListPending(limit 1) = %d searches, %v", len(pending), err)
+		}
+
+		notifications := []domain.Notification{searchNotification(early, 10), searchNotification(early, 11)}
+		advanced, err := repo.Advance(early.ID, early.CheckedUntil, base, notifications)
+		if err != nil || !advanced {
+			t.Fatalf("Advance() = %v, %v, want true", advanced, err)
+		}
+		// второй воркер с устаревшим знаком ничего не сдвигает и не пишет
+		stale := []domain.Notification{searchNotification(early, 12)}
+		if advanced, err := repo.Advance(early.ID, early.CheckedUntil, base, stale); err != nil || advanced {
+			t.Errorf("stale Advance() = %v, %v, want false", advanced, err)
+		}
+		// повтор уже записанного уведомления пропускается
+		repeated := []domain.Notification{searchNotification(late, 20), searchNotification(early, 10)}
+		if advanced, err := repo.Advance(late.ID, late.CheckedUntil, base, repeated); err != nil || !advanced {
+			t.Errorf("Advance(late) = %v, %v, want true", advanced, err)
+		}
+
+		if pending, err := repo.ListPending(base, 10); err != nil || len(pending) != 0 {
+			t.Errorf("ListPending() after Advance = %d searches, %v, want 0", len(pending), err)
+		}
+		if count, err := repos.Notifications.Count(1, false); err != nil || count != 3 {
+			t.Errorf("notifications = %d, %v, want 3", count, err)
+		}
+	})
+}
+
+func RunNotificationTests(t *testing.T, newRepos Factory) {
+	t.Run("ListAndMarkRead", func(t *testing.T) {
+		repos := newRepos(t)
+		mine := createSearch(t, repos.SavedSearches, 1, "Mine", base.Add(-time.Hour))
+		theirs := createSearch(t, repos.SavedSearches, 2, "Theirs", base.Add(-time.Hour))
+		for _, search := range []*domain.SavedSearch{mine, theirs} {
+			notifications := []domain.Notification{searchNotification(search, 10), searchNotification(search, 11), searchNotification(search, 12)}
+			if _, err := repos.SavedSearches.Advance(search.ID, search.CheckedUntil, base, notifications); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		all, err := repos.Notifications.List(1, domain.NotificationFilter{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(all) != 3 || *all[0].AdID != 12 || *all[2].AdID != 10 {
+			t.Fatalf("List() = %+v, want 3 notifications, newest first", all)
+		}
+		if page, err := repos.Notifications.List(1, domain.NotificationFilter{Offset: 1, Limit: 1}); err != nil || len(page) != 1 || page[0].ID != all[1].ID {
+			t.Errorf("List(offset 1) = %+v, %v", page, err)
+		}
+
+		// чужие уведомления не отмечаются
+		marked, err := repos.Notifications.MarkRead(1, []uint{all[0].ID, all[1].ID})
+		if err != nil || marked != 2 {
+			t.Errorf("MarkRead(ids) = %d, %v, want 2", marked, err)
+		}
+		theirsList, err := repos.Notifications.List(2, domain.NotificationFilter{Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if marked, err := repos.Notifications.MarkRead(1, []uint{theirsList[0].ID}); err != nil || marked != 0 {
+			t.Errorf("MarkRead(foreign id) = %d, %v, want 0", marked, err)
+		}
+
+		unread, err := repos.Notifications.List(1, domain.NotificationFilter{UnreadOnly: true, Limit: 10})
+		if err != nil || len(unread) != 1 || unread[0].ID != all[2].ID {
+			t.Errorf("List(unread) = %+v, %v, want %d", unread, err, all[2].ID)
+		}
+		if read, _ := repos.Notifications.List(1, domain.NotificationFilter{Limit: 1}); len(read) == 1 && read[0].ReadAt == nil {
+			t.Error("MarkRead() did not set ReadAt")
+		}
+
+		if marked, err := repos.Notifications.MarkRead(1, nil); err != nil || marked != 1 {
+			t.Errorf("MarkRead(all) = %d, %v, want 1", marked, err)
+		}
+		if count, err := repos.Notifications.Count(1, true); err != nil || count != 0 {
+			t.Errorf("Count(unread) = %d, %v, want 0", count, err)
+		}
+		if count, err := repos.Notifications.Count(2, true); err != nil || count != 3 {
+			t.Errorf("Count(other user unread) = %d, %v, want 3", count, err)
+		}
+	})
+}
@@ -0,0 +1,274 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// deal - продавец с опубликованным объявлением и покупатель
+type deal struct {
+	seller *domain.User
+	buyer  *domain.User
+	adID   uint
+}
+
+func newDeal(t *testing.T, repos Repositories) deal {
+	t.Helper()
+	seller := createUser(t, repos.Users, "seller")
+	buyer := createUser(t, repos.Users, "buyer")
+	ids := createAds(t, repos.Ads, seller.ID, adFixture{title: "Road bike", price: 30000})
+	return deal{seller: seller, buyer: buyer, adID: ids[0]}
+}
+
+func createOffer(t *testing.T, repo OfferRepository, adID, buyerID, sellerID uint, amount float64) *domain.Offer {
+	t.Helper()
+	offer := &domain.Offer{AdID: adID, BuyerID: buyerID, SellerID: sellerID, Amount: amount, Status: domain.OfferStatusPending}
+	if err := repo.Create(offer); err != nil {
+		t.Fatalf("Create(offer %v) error = %v", amount, err)
+	}
+	return offer
+}
+
+func assertOfferIDs(t *testing.T, name string, offers []domain.Offer, want ...uint) {
+	t.Helper()
+	if len(offers) != len(want) {
+		t.Fatalf("%s: got %d offers, want %v", name, len(offers), want)
+	}
+	for i := range want {
+		if offers[i].ID != want[i] {
+			t.Errorf("%s: offers[%d].ID = %d, want %d", name, i, offers[i].ID, want[i])
+		}
+	}
+}
+
+func RunOfferTests(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		offer := createOffer(t, repos.Offers, d.adID, d.buyer.ID, d.seller.ID, 25000)
+		if offer.ID == 0 || offer.CreatedAt.IsZero() {
+			t.Fatalf("Create() did not fill defaults: %+v", offer)
+		}
+
+		got, err := repos.Offers.GetByID(offer.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Amount != 25000 || got.Status != domain.OfferStatusPending || got.Ad.Title != "Road bike" || got.Buyer.Username != "buyer" {
+			t.Errorf("GetByID() = %+v", got)
+		}
+		if _, err := repos.Offers.GetByID(999); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID(999) error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("CreateRules", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		first := createOffer(t, repos.Offers, d.adID, d.buyer.ID, d.seller.ID, 25000)
+
+		// у покупателя может быть только одно предложение, ожидающее ответа
+		second := &domain.Offer{AdID: d.adID, BuyerID: d.buyer.ID, SellerID: d.seller.ID, Amount: 26000, Status: domain.OfferStatusPending}
+		if err := repos.Offers.Create(second); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("second active Create() error = %v, want ErrInvalidInput", err)
+		}
+		other := createUser(t, repos.Users, "other")
+		createOffer(t, repos.Offers, d.adID, other.ID, d.seller.ID, 24000)
+
+		first.Status = domain.OfferStatusDeclined
+		if err := repos.Offers.UpdateStatus(first, domain.OfferStatusPending); err != nil {
+			t.Fatal(err)
+		}
+		createOffer(t, repos.Offers, d.adID, d.buyer.ID, d.seller.ID, 27000)
+
+		missing := &domain.Offer{AdID: 999, BuyerID: d.buyer.ID, SellerID: d.seller.ID, Amount: 1, Status: domain.OfferStatusPending}
+		if err := repos.Offers.Create(missing); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("Create(missing ad) error = %v, want ErrNotFound", err)
+		}
+		drafts := createAds(t, repos.Ads, d.seller.ID, adFixture{title: "Draft", price: 1, status: domain.AdStatusDraft})
+		draft := &domain.Offer{AdID: drafts[0], BuyerID: d.buyer.ID, SellerID: d.seller.ID, Amount: 1, Status: domain.OfferStatusPending}
+		if err := repos.Offers.Create(draft); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+			t.Errorf("Create(draft ad) error = %v, want ErrInvalidStatusTransition", err)
+		}
+	})
+
+	t.Run("ListAndCount", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		other := createUser(t, repos.Users, "other")
+		second := createAds(t, repos.Ads, d.seller.ID, adFixture{title: "Helmet", price: 2000})
+
+		o1 := createOffer(t, repos.Offers, d.adID, d.buyer.ID, d.seller.ID, 25000)
+		o2 := createOffer(t, repos.Offers, d.adID, other.ID, d.seller.ID, 26000)
+		o3 := createOffer(t, repos.Offers, second[0], d.buyer.ID, d.seller.ID, 1500)
+
+		for name, tc := range map[string]struct {
+			filter domain.OfferFilter
+			want   []uint
+		}{
+			"by ad":     {domain.OfferFilter{AdID: d.adID, Limit: 10}, []uint{o2.ID, o1.ID}},
+			"by buyer":  {domain.OfferFilter{BuyerID: d.buyer.ID, Limit: 10}, []uint{o3.ID, o1.ID}},
+			"by seller": {domain.OfferFilter{SellerID: d.seller.ID, Limit: 10}, []uint{o3.ID, o2.ID, o1.ID}},
+			"page":      {domain.OfferFilter{SellerID: d.seller.ID, Offset: 1, Limit: 1}, []uint{o2.ID}},
+		} {
+			offers, err := repos.Offers.List(tc.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			assertOfferIDs(t, name, offers, tc.want...)
+		}
+		if offers, _ := repos.Offers.List(domain.OfferFilter{AdID: second[0], Limit: 10}); len(offers) == 1 && offers[0].Ad.Title != "Helmet" {
+			t.Errorf("List() did not load the advertisement: %+v", offers[0].Ad)
+		}
+
+		if count, err := repos.Offers.Count(domain.OfferFilter{BuyerID: d.buyer.ID}); err != nil || count != 2 {
+			t.Errorf("Count(buyer) = %d, %v, want 2", count, err)
+		}
+	})
+
+	t.Run("UpdateStatus", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		offer := createOffer(t, repos.Offers, d.adID, d.buyer.ID, d.seller.ID, 25000)
+
+		counter := 28000.0
+		offer.Status = domain.OfferStatusCountered
+		offer.CounterAmount = &counter
+		if err := repos.Offers.UpdateStatus(offer, domain.OfferStatusPending); err != nil {
+			t.Fatal(err)
+		}
+		// переход из устаревшего статуса не проходит
+		offer.Status = domain.OfferStatusWithdrawn
+		if err := repos.Offers.UpdateStatus(offer, domain.OfferStatusPending); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+			t.Errorf("stale UpdateStatus() error = %v, want ErrInvalidStatusTransition", err)
+		}
+
+		got, err := repos.Offers.GetByID(offer.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != domain.OfferStatusCountered || got.CounterAmount == nil || *got.CounterAmount != counter {
+			t.Errorf("GetByID() = %+v, want countered at %v", got, counter)
+		}
+	})
+
+	t.Run("Accept", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		other := createUser(t, repos.Users, "other")
+		offer := createOffer(t, repos.Offers, d.adID, d.buyer.ID, d.seller.ID, 25000)
+		rival := createOffer(t, repos.Offers, d.adID, other.ID, d.seller.ID, 24000)
+
+		amount := offer.Amount
+		offer.AcceptedAmount = &amount
+		declined, err := repos.Offers.Accept(offer, domain.OfferStatusPending)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertOfferIDs(t, "declined", declined, rival.ID)
+		if declined[0].Status != domain.OfferStatusDeclined || offer.Status != domain.OfferStatusAccepted {
+			t.Errorf("Accept() statuses: offer %s, declined %s", offer.Status, declined[0].Status)
+		}
+
+		got, err := repos.Offers.GetByID(offer.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Status != domain.OfferStatusAccepted || got.AcceptedAmount == nil || *got.AcceptedAmount != amount {
+			t.Errorf("GetByID() = %+v, want accepted at %v", got, amount)
+		}
+		if got.Ad.Status != domain.AdStatusReserved {
+			t.Errorf("ad status = %s, want reserved", got.Ad.Status)
+		}
+		if got, _ := repos.Offers.GetByID(rival.ID); got == nil || got.Status != domain.OfferStatusDeclined {
+			t.Errorf("rival offer = %+v, want declined", got)
+		}
+
+		// объявление уже забронировано: новые предложения и второе принятие не проходят
+		late := &domain.Offer{AdID: d.adID, BuyerID: other.ID, SellerID: d.seller.ID, Amount: 30000, Status: domain.OfferStatusPending}
+		if err := repos.Offers.Create(late); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+			t.Errorf("Create() after Accept error = %v, want ErrInvalidStatusTransition", err)
+		}
+		if _, err := repos.Offers.Accept(rival, domain.OfferStatusDeclined); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+			t.Errorf("second Accept() error = %v, want ErrInvalidStatusTransition", err)
+		}
+	})
+
+	t.Run("AcceptStaleOffer", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		offer := createOffer(t, repos.Offers, d.adID, d.buyer.ID, d.seller.ID, 25000)
+
+		// предложение уже отозвано: объявление остаётся опубликованным
+		if _, err := repos.Offers.Accept(offer, domain.OfferStatusCountered); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+			t.Errorf("Accept(stale) error = %v, want ErrInvalidStatusTransition", err)
+		}
+		ad, err := repos.Ads.GetByID(d.adID)
+		if err != nil || ad.Status != domain.AdStatusPublished {
+			t.Errorf("ad after failed Accept = %+v, %v, want published", ad, err)
+		}
+		if got, _ := repos.Offers.GetByID(offer.ID); got == nil || got.Status != domain.OfferStatusPending {
+			t.Errorf("offer after failed Accept = %+v, want pending", got)
+		}
+	})
+}
+
+func RunReviewTests(t *testing.T, newRepos Factory) {
+	t.Run("CreateUpdatesRating", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+
+		review := &domain.Review{OfferID: 1, AdID: d.adID, SellerID: d.seller.ID, BuyerID: d.buyer.ID, Rating: 4, Text: "Всё честно"}
+		if err := repos.Reviews.Create(review); err != nil {
+			t.Fatal(err)
+		}
+		if review.ID == 0 || review.CreatedAt.IsZero() {
+			t.Fatalf("Create() did not fill defaults: %+v", review)
+		}
+
+		// повторный отзыв по той же сделке не меняет рейтинг
+		duplicate := &domain.Review{OfferID: 1, AdID: d.adID, SellerID: d.seller.ID, BuyerID: d.buyer.ID, Rating: 1}
+		if err := repos.Reviews.Create(duplicate); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("duplicate Create() error = %v, want ErrConflict", err)
+		}
+
+		seller, err := repos.Users.GetByID(d.seller.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if seller.RatingSum != 4 || seller.RatingCount != 1 {
+			t.Errorf("seller rating = %d/%d, want 4/1", seller.RatingSum, seller.RatingCount)
+		}
+	})
+
+	t.Run("ListBySeller", func(t *testing.T) {
+		repos := newRepos(t)
+		d := newDeal(t, repos)
+		other := createUser(t, repos.Users, "other")
+
+		var ids []uint
+		for i, fixture := range []struct {
+			sellerID uint
+			rating   int
+		}{{d.seller.ID, 5}, {other.ID, 3}, {d.seller.ID, 4}, {d.seller.ID, 2}} {
+			review := &domain.Review{OfferID: uint(i + 1), AdID: d.adID, SellerID: fixture.sellerID, BuyerID: d.buyer.ID, Rating: fixture.rating}
+			if err := repos.Reviews.Create(review); err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, review.ID)
+		}
+
+		reviews, err := repos.Reviews.ListBySeller(d.seller.ID, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(reviews) != 2 || reviews[0].ID != ids[3] || reviews[1].ID != ids[2] {
+			t.Fatalf("ListBySeller() = %+v, want reviews %d and %d", reviews, ids[3], ids[2])
+		}
+		if reviews[0].Ad.Title != "Road bike" || reviews[0].Buyer.Username != "buyer" {
+			t.Errorf("ListBySeller() did not load relations: %+v", reviews[0])
+		}
+	})
+}
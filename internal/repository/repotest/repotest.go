@@ -0,0 +1,273 @@
+// Package repotest содержит контрактные тесты репозиториев. Их запускает каждая
+// реализация хранилища (PostgreSQL, память), чтобы поведение реализаций не расходилось.
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type UserRepository interface {
+	Create(user *domain.User) error
+	GetByUsername(username string) (*domain.User, error)
+	Exists(username string) (bool, error)
+	GetByID(id uint) (*domain.User, error)
+	List(filter domain.UserFilter) ([]domain.User, error)
+	Count(filter domain.UserFilter) (int64, error)
+	SetBanned(id uint, banned bool) error
+	SetRole(id uint, role domain.Role) error
+	SetPassword(id uint, hash string) error
+	UpdateProfile(user *domain.User) error
+}
+
+type AdvertisementRepository interface {
+	Create(ad *domain.Advertisement) error
+	GetByID(id uint) (*domain.Advertisement, error)
+	Update(ad *domain.Advertisement) error
+	UpdateStatus(ad *domain.Advertisement, from domain.AdStatus) error
+	UpdateImages(adID uint, images []domain.AdImage, coverURL string) error
+	Delete(id uint) error
+	GetAll(filter domain.AdFilter) ([]domain.Advertisement, error)
+	Count(filter domain.AdFilter) (int64, error)
+	MatchNew(filter domain.AdFilter, after, upTo time.Time) ([]domain.Advertisement, error)
+}
+
+type FavoriteRepository interface {
+	Add(userID, adID uint) error
+}
+
+type OfferRepository interface {
+	Create(offer *domain.Offer) error
+	GetByID(id uint) (*domain.Offer, error)
+	List(filter domain.OfferFilter) ([]domain.Offer, error)
+	Count(filter domain.OfferFilter) (int64, error)
+	UpdateStatus(offer *domain.Offer, from domain.OfferStatus) error
+	Accept(offer *domain.Offer, from domain.OfferStatus) ([]domain.Offer, error)
+}
+
+type ReviewRepository interface {
+	Create(review *domain.Review) error
+	ListBySeller(sellerID uint, limit int) ([]domain.Review, error)
+}
+
+type ConversationRepository interface {
+	FindOrCreate(conv *domain.Conversation) error
+	GetByID(id uint) (*domain.Conversation, error)
+	ListByUser(userID uint, offset, limit int) ([]domain.Conversation, error)
+	CountByUser(userID uint) (int64, error)
+	UnreadTotal(userID uint) (int64, error)
+	AddMessage(conv *domain.Conversation, msg *domain.Message) error
+	ListMessages(conversationID, beforeID uint, limit int) ([]domain.Message, error)
+	MarkRead(conv *domain.Conversation, userID, upToID uint) error
+}
+
+type BlockRepository interface {
+	Block(blockerID, blockedID uint) error
+	Unblock(blockerID, blockedID uint) error
+	IsBlocked(userA, userB uint) (bool, error)
+}
+
+type SavedSearchRepository interface {
+	Create(search *domain.SavedSearch) error
+	GetByID(id uint) (*domain.SavedSearch, error)
+	ListByUser(userID uint) ([]domain.SavedSearch, error)
+	CountByUser(userID uint) (int64, error)
+	Delete(id uint) error
+	ListPending(upTo time.Time, limit int) ([]domain.SavedSearch, error)
+	Advance(searchID uint, from, to time.Time, notifications []domain.Notification) (bool, error)
+}
+
+type NotificationRepository interface {
+	List(userID uint, filter domain.NotificationFilter) ([]domain.Notification, error)
+	Count(userID uint, unreadOnly bool) (int64, error)
+	MarkRead(userID uint, ids []uint) (int64, error)
+}
+
+type TokenRepository interface {
+	CreateRefreshToken(token *domain.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*domain.RefreshToken, error)
+	RevokeRefreshToken(id uint) (bool, error)
+	RevokeRefreshFamily(familyID string) error
+	RevokeUserRefreshTokens(userID uint) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+}
+
+// Repositories - репозитории одного пустого хранилища
+type Repositories struct {
+	Users         UserRepository
+	Ads           AdvertisementRepository
+	Favorites     FavoriteRepository
+	Offers        OfferRepository
+	Reviews       ReviewRepository
+	Conversations ConversationRepository
+	Blocks        BlockRepository
+	SavedSearches SavedSearchRepository
+	Notifications NotificationRepository
+	Tokens        TokenRepository
+}
+
+// Factory создаёт репозитории поверх нового пустого хранилища для каждого теста
+type Factory func(t *testing.T) Repositories
+
+// Run запускает все контрактные тесты
+func Run(t *testing.T, newRepos Factory) {
+	t.Run("Users", func(t *testing.T) { RunUserTests(t, newRepos) })
+	t.Run("Advertisements", func(t *testing.T) { RunAdvertisementTests(t, newRepos) })
+	t.Run("Offers", func(t *testing.T) { RunOfferTests(t, newRepos) })
+	t.Run("Reviews", func(t *testing.T) { RunReviewTests(t, newRepos) })
+	t.Run("Conversations", func(t *testing.T) { RunConversationTests(t, newRepos) })
+	t.Run("Blocks", func(t *testing.T) { RunBlockTests(t, newRepos) })
+	t.Run("SavedSearches", func(t *testing.T) { RunSavedSearchTests(t, newRepos) })
+	t.Run("Notifications", func(t *testing.T) { RunNotificationTests(t, newRepos) })
+	t.Run("Tokens", func(t *testing.T) { RunTokenTests(t, newRepos) })
+}
+
+func createUser(t *testing.T, repo UserRepository, username string) *domain.User {
+	t.Helper()
+	user := &domain.User{Username: username, Password: "hash"}
+	if err := repo.Create(user); err != nil {
+		t.Fatalf("Create(%q) error = %v", username, err)
+	}
+	return user
+}
+
+func RunUserTests(t *testing.T, newRepos Factory) {
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepos(t).Users
+		user := createUser(t, repo, "alice")
+		if user.ID == 0 || user.Role != domain.RoleUser || user.CreatedAt.IsZero() {
+			t.Fatalf("Create() did not fill defaults: %+v", user)
+		}
+
+		byName, err := repo.GetByUsername("alice")
+		if err != nil || byName.ID != user.ID || byName.Role != domain.RoleUser {
+			t.Fatalf("GetByUsername() = %+v, %v", byName, err)
+		}
+		byID, err := repo.GetByID(user.ID)
+		if err != nil || byID.Username != "alice" {
+			t.Fatalf("GetByID() = %+v, %v", byID, err)
+		}
+		if exists, err := repo.Exists("alice"); err != nil || !exists {
+			t.Errorf("Exists(alice) = %v, %v", exists, err)
+		}
+		if exists, err := repo.Exists("bob"); err != nil || exists {
+			t.Errorf("Exists(bob) = %v, %v", exists, err)
+		}
+	})
+
+	t.Run("UniqueUsername", func(t *testing.T) {
+		repo := newRepos(t).Users
+		first := createUser(t, repo, "alice")
+		if err := repo.Create(&domain.User{Username: "alice", Password: "other"}); err == nil {
+			t.Fatal("Create() with a taken username succeeded")
+		}
+		user, err := repo.GetByUsername("alice")
+		if err != nil || user.ID != first.ID || user.Password != "hash" {
+			t.Errorf("existing user changed: %+v, %v", user, err)
+		}
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		repo := newRepos(t).Users
+		if _, err := repo.GetByUsername("ghost"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByUsername() error = %v, want ErrNotFound", err)
+		}
+		if _, err := repo.GetByID(999); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetByID() error = %v, want ErrNotFound", err)
+		}
+		for name, err := range map[string]error{
+			"SetBanned":     repo.SetBanned(999, true),
+			"SetRole":       repo.SetRole(999, domain.RoleAdmin),
+			"SetPassword":   repo.SetPassword(999, "hash"),
+			"UpdateProfile": repo.UpdateProfile(&domain.User{ID: 999, City: "Kazan"}),
+		} {
+			if !errors.Is(err, domain.ErrNotFound) {
+				t.Errorf("%s() error = %v, want ErrNotFound", name, err)
+			}
+		}
+	})
+
+	t.Run("Updates", func(t *testing.T) {
+		repo := newRepos(t).Users
+		user := createUser(t, repo, "alice")
+
+		if err := repo.SetBanned(user.ID, true); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.SetRole(user.ID, domain.RoleModerator); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.SetPassword(user.ID, "new-hash"); err != nil {
+			t.Fatal(err)
+		}
+		profile := &domain.User{ID: user.ID, DisplayName: "Alice", AvatarURL: "https://example.com/a.png", City: "Kazan", About: "hi"}
+		if err := repo.UpdateProfile(profile); err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := repo.GetByID(user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.Banned || got.Role != domain.RoleModerator || got.Password != "new-hash" ||
+			got.DisplayName != "Alice" || got.AvatarURL != profile.AvatarURL || got.City != "Kazan" || got.About != "hi" {
+			t.Errorf("updates were not saved: %+v", got)
+		}
+		if got.Username != "alice" {
+			t.Errorf("UpdateProfile() changed username to %q", got.Username)
+		}
+	})
+
+	t.Run("ListAndCount", func(t *testing.T) {
+		repo := newRepos(t).Users
+		var ids []uint
+		for _, name := range []string{"u1", "u2", "u3", "u4", "u5"} {
+			ids = append(ids, createUser(t, repo, name).ID)
+		}
+		if err := repo.SetBanned(ids[1], true); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.SetBanned(ids[3], true); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.SetRole(ids[3], domain.RoleModerator); err != nil {
+			t.Fatal(err)
+		}
+
+		users, err := repo.List(domain.UserFilter{Limit: 2, Offset: 1})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertUserIDs(t, "page", users, ids[1], ids[2])
+
+		banned := true
+		users, err = repo.List(domain.UserFilter{Banned: &banned, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assertUserIDs(t, "banned", users, ids[1], ids[3])
+
+		if count, err := repo.Count(domain.UserFilter{Banned: &banned, Role: domain.RoleUser}); err != nil || count != 1 {
+			t.Errorf("Count(banned users) = %d, %v, want 1", count, err)
+		}
+		if count, err := repo.Count(domain.UserFilter{}); err != nil || count != 5 {
+			t.Errorf("Count() = %d, %v, want 5", count, err)
+		}
+	})
+}
+
+func assertUserIDs(t *testing.T, name string, users []domain.User, want ...uint) {
+	t.Helper()
+	if len(users) != len(want) {
+		t.Fatalf("%s: got %d users, want %d", name, len(users), len(want))
+	}
+	for i := range want {
+		if users[i].ID != want[i] {
+			t.Errorf("%s: users[%d].ID = %d, want %d", name, i, users[i].ID, want[i])
+		}
+	}
+}
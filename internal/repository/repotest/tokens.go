@@ -0,0 +1,110 @@
+package repotest
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+func createRefreshToken(t *testing.T, repo TokenRepository, userID uint, hash, familyID string) *domain.RefreshToken {
+	t.Helper()
+	token := &domain.RefreshToken{UserID: userID, TokenHash: hash, FamilyID: familyID, ExpiresAt: time.Now().Add(time.Hour)}
+	if err := repo.CreateRefreshToken(token); err != nil {
+		t.Fatalf("CreateRefreshToken(%q) error = %v", hash, err)
+	}
+	return token
+}
+
+func RunTokenTests(t *testing.T, newRepos Factory) {
+	t.Run("RefreshTokens", func(t *testing.T) {
+		repo := newRepos(t).Tokens
+		token := createRefreshToken(t, repo, 1, "hash-1", "family-1")
+		if token.ID == 0 || token.CreatedAt.IsZero() {
+			t.Fatalf("CreateRefreshToken() did not fill defaults: %+v", token)
+		}
+		if err := repo.CreateRefreshToken(&domain.RefreshToken{UserID: 2, TokenHash: "hash-1", FamilyID: "family-2", ExpiresAt: time.Now()}); err == nil {
+			t.Error("CreateRefreshToken() with a taken hash succeeded")
+		}
+
+		got, err := repo.GetRefreshTokenByHash("hash-1")
+		if err != nil || got.ID != token.ID || got.UserID != 1 || got.FamilyID != "family-1" || got.RevokedAt != nil {
+			t.Errorf("GetRefreshTokenByHash() = %+v, %v", got, err)
+		}
+		if _, err := repo.GetRefreshTokenByHash("missing"); !errors.Is(err, domain.ErrNotFound) {
+			t.Errorf("GetRefreshTokenByHash(missing) error = %v, want ErrNotFound", err)
+		}
+
+		// токен отзывается один раз: второй параллельный refresh проигрывает
+		if revoked, err := repo.RevokeRefreshToken(token.ID); err != nil || !revoked {
+			t.Errorf("RevokeRefreshToken() = %v, %v, want true", revoked, err)
+		}
+		if revoked, err := repo.RevokeRefreshToken(token.ID); err != nil || revoked {
+			t.Errorf("second RevokeRefreshToken() = %v, %v, want false", revoked, err)
+		}
+		if got, err := repo.GetRefreshTokenByHash("hash-1"); err != nil || got.RevokedAt == nil {
+			t.Errorf("revoked token = %+v, %v, want RevokedAt", got, err)
+		}
+	})
+
+	t.Run("RevokeGroups", func(t *testing.T) {
+		repo := newRepos(t).Tokens
+		createRefreshToken(t, repo, 1, "a1", "family-a")
+		createRefreshToken(t, repo, 1, "a2", "family-a")
+		createRefreshToken(t, repo, 1, "b1", "family-b")
+		createRefreshToken(t, repo, 2, "c1", "family-c")
+
+		if err := repo.RevokeRefreshFamily("family-a"); err != nil {
+			t.Fatal(err)
+		}
+		assertRevoked(t, repo, map[string]bool{"a1": true, "a2": true, "b1": false, "c1": false})
+
+		if err := repo.RevokeUserRefreshTokens(1); err != nil {
+			t.Fatal(err)
+		}
+		assertRevoked(t, repo, map[string]bool{"a1": true, "a2": true, "b1": true, "c1": false})
+	})
+
+	t.Run("AccessTokens", func(t *testing.T) {
+		repo := newRepos(t).Tokens
+		if revoked, err := repo.IsAccessTokenRevoked("jti-1"); err != nil || revoked {
+			t.Errorf("IsAccessTokenRevoked() before revoke = %v, %v, want false", revoked, err)
+		}
+		for i := 0; i < 2; i++ {
+			if err := repo.RevokeAccessToken("jti-1", time.Now().Add(time.Hour)); err != nil {
+				t.Fatalf("RevokeAccessToken() attempt %d error = %v", i+1, err)
+			}
+		}
+		if revoked, err := repo.IsAccessTokenRevoked("jti-1"); err != nil || !revoked {
+			t.Errorf("IsAccessTokenRevoked() = %v, %v, want true", revoked, err)
+		}
+
+		// записи об истёкших токенах удаляются при следующем отзыве
+		if err := repo.RevokeAccessToken("jti-expired", time.Now().Add(-time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if err := repo.RevokeAccessToken("jti-2", time.Now().Add(time.Hour)); err != nil {
+			t.Fatal(err)
+		}
+		if revoked, err := repo.IsAccessTokenRevoked("jti-expired"); err != nil || revoked {
+			t.Errorf("IsAccessTokenRevoked(expired) = %v, %v, want false after cleanup", revoked, err)
+		}
+		if revoked, err := repo.IsAccessTokenRevoked("jti-1"); err != nil || !revoked {
+			t.Errorf("IsAccessTokenRevoked() after cleanup = %v, %v, want true", revoked, err)
+		}
+	})
+}
+
+func assertRevoked(t *testing.T, repo TokenRepository, want map[string]bool) {
+	t.Helper()
+	for hash, revoked := range want {
+		token, err := repo.GetRefreshTokenByHash(hash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (token.RevokedAt != nil) != revoked {
+			t.Errorf("token %s revoked = %v, want %v", hash, token.RevokedAt != nil, revoked)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package repository
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type reviewRepository struct {
+	db *gorm.DB
+}
+
+func NewReviewRepository(db *gorm.DB) *reviewRepository {
+	return &reviewRepository{db: db}
+}
+
+// Create сохраняет отзыв и в той же транзакции добавляет оценку к рейтингу продавца.
+// Повторный отзыв по той же сделке возвращает ErrConflict.
+func (r *reviewRepository) Create(review *domain.Review) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Omit(clause.Associations).
+			Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "offer_id"}}, DoNothing: true}).
+			Create(review)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return fmt.Errorf("%w: the deal has already been reviewed", domain.ErrConflict)
+		}
+
+		return tx.Model(&domain.User{}).
+			Where("id = ?", review.SellerID).
+			Updates(map[string]interface{}{
+				"rating_sum":   gorm.Expr("rating_sum + ?", review.Rating),
+				"rating_count": gorm.Expr("rating_count + 1"),
+			}).Error
+	})
+}
+
+// ListBySeller возвращает последние отзывы о продавце, новые первыми
+func (r *reviewRepository) ListBySeller(sellerID uint, limit int) ([]domain.Review, error) {
+	var reviews []domain.Review
+	err := r.db.Where("seller_id = ?", sellerID).
+		Preload("Ad", func(db *gorm.DB) *gorm.DB {
+			return db.Unscoped()
+		}).
+		Preload("Buyer").
+		Order("id DESC").
+		Limit(limit).
+		Find(&reviews).Error
+	return reviews, err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type savedSearchRepository struct {
+	db *gorm.DB
+}
+
+func NewSavedSearchRepository(db *gorm.DB) *savedSearchRepository {
+	return &savedSearchRepository{db: db}
+}
+
+func (r *savedSearchRepository) Create(search *domain.SavedSearch) error {
+	return r.db.Create(search).Error
+}
+
+func (r *savedSearchRepository) GetByID(id uint) (*domain.SavedSearch, error) {
+	var search domain.SavedSearch
+	err := r.db.First(&search, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (r *savedSearchRepository) ListByUser(userID uint) ([]domain.SavedSearch, error) {
+	var searches []domain.SavedSearch
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&searches).Error
+	return searches, err
+}
+
+func (r *savedSearchRepository) CountByUser(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&domain.SavedSearch{}).Where("user_id = ?", userID).Count(&count).Error
+	return count, err
+}
+
+func (r *savedSearchRepository) Delete(id uint) error {
+	result := r.db.Delete(&domain.SavedSearch{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// ListPending возвращает поиски, водяной знак которых раньше upTo
+func (r *savedSearchRepository) ListPending(upTo time.Time, limit int) ([]domain.SavedSearch, error) {
+	var searches []domain.SavedSearch
+	err := r.db.Where("checked_until < ?", upTo).Order("id").Limit(limit).Find(&searches).Error
+	return searches, err
+}
+
+// Advance в одной транзакции сдвигает водяной знак с from на to и записывает уведомления.
+// Если знак уже сдвинут (параллельным воркером), ничего не делает и возвращает false.
+func (r *savedSearchRepository) Advance(searchID uint, from, to time.Time, notifications []domain.Notification) (bool, error) {
+	advanced := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&domain.SavedSearch{}).
+			Where("id = ? AND checked_until = ?", searchID, from).
+			Update("checked_until", to)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		advanced = true
+
+		if len(notifications) == 0 {
+			return nil
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "dedup_key"}},
+			DoNothing: true,
+		}).Create(&notifications).Error
+	})
+	return advanced, err
+}
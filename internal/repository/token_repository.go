@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type tokenRepository struct {
+	db *gorm.DB
+}
+
+func NewTokenRepository(db *gorm.DB) *tokenRepository {
+	return &tokenRepository{db: db}
+}
+
+func (r *tokenRepository) CreateRefreshToken(token *domain.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *tokenRepository) GetRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	var token domain.RefreshToken
+	err := r.db.Where("token_hash = ?", hash).First(&token).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// RevokeRefreshToken атомарно помечает токен отозванным.
+// Возвращает false, если токен уже был отозван (например, параллельным запросом).
+func (r *tokenRepository) RevokeRefreshToken(id uint) (bool, error) {
+	result := r.db.Model(&domain.RefreshToken{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *tokenRepository) RevokeRefreshFamily(familyID string) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeUserRefreshTokens завершает все сессии пользователя
+func (r *tokenRepository) RevokeUserRefreshTokens(userID uint) error {
+	return r.db.Model(&domain.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func (r *tokenRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	// заодно чистим записи, срок действия которых уже истёк
+	if err := r.db.Where("expires_at < ?", time.Now()).Delete(&domain.RevokedToken{}).Error; err != nil {
+		return err
+	}
+
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&domain.RevokedToken{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+func (r *tokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	var count int64
+	err := r.db.Model(&domain.RevokedToken{}).Where("jti = ?", jti).Count(&count).Error
+	return count > 0, err
+}
@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"errors"
+
 	"github.com/keenetic29/vk-internship/internal/domain"
 	"gorm.io/gorm"
 )
@@ -20,11 +22,105 @@ func (r *userRepository) Create(user *domain.User) error {
 func (r *userRepository) GetByUsername(username string) (*domain.User, error) {
 	var user domain.User
 	err := r.db.Where("username = ?", username).First(&user).Error
-	return &user, err
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, domain.ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
 }
 
 func (r *userRepository) Exists(username string) (bool, error) {
 	var count int64
 	err := r.db.Model(&domain.User{}).Where("username = ?", username).Count(&count).Error
 	return count > 0, err
-}
\ No newline at end of file
+}
+
+func (r *userRepository) GetByID(id uint) (*domain.User, error) {
+	var user domain.User
+	if err := r.db.First(&user, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domain.ErrNotFound
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+func applyUserFilter(query *gorm.DB, filter domain.UserFilter) *gorm.DB {
+	if filter.Role != "" {
+		query = query.Where("role = ?", filter.Role)
+	}
+	if filter.Banned != nil {
+		query = query.Where("banned = ?", *filter.Banned)
+	}
+	return query
+}
+
+func (r *userRepository) List(filter domain.UserFilter) ([]domain.User, error) {
+	var users []domain.User
+	err := applyUserFilter(r.db.Model(&domain.User{}), filter).
+		Order("id").
+		Offset(filter.Offset).
+		Limit(filter.Limit).
+		Find(&users).Error
+	return users, err
+}
+
+func (r *userRepository) Count(filter domain.UserFilter) (int64, error) {
+	var count int64
+	err := applyUserFilter(r.db.Model(&domain.User{}), filter).Count(&count).Error
+	return count, err
+}
+
+func (r *userRepository) SetBanned(id uint, banned bool) error {
+	result := r.db.Model(&domain.User{}).Where("id = ?", id).Update("banned", banned)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+func (r *userRepository) SetRole(id uint, role domain.Role) error {
+	result := r.db.Model(&domain.User{}).Where("id = ?", id).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// SetPassword сохраняет новый хэш пароля
+func (r *userRepository) SetPassword(id uint, hash string) error {
+	result := r.db.Model(&domain.User{}).Where("id = ?", id).Update("password", hash)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
+
+// UpdateProfile сохраняет публичные поля профиля
+func (r *userRepository) UpdateProfile(user *domain.User) error {
+	result := r.db.Model(&domain.User{}).Where("id = ?", user.ID).Updates(map[string]interface{}{
+		"display_name": user.DisplayName,
+		"avatar_url":   user.AvatarURL,
+		"city":         user.City,
+		"about":        user.About,
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return domain.ErrNotFound
+	}
+	return nil
+}
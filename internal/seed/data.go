@@ -0,0 +1,193 @@
+package seed
+
+// Словари для генерации демо-данных. Порядок элементов важен: от него зависит,
+// какие данные получаются из конкретного значения seed.
+
+// имена латиницей - из них составляются имена пользователей
+var firstNames = []string{
+	"ivan", "anna", "dmitry", "elena", "sergey", "olga", "alexey", "maria",
+	"pavel", "natalia", "mikhail", "irina", "nikita", "daria", "artem", "polina",
+	"john", "emily", "david", "sarah", "michael", "laura", "james", "kate",
+}
+
+type city struct {
+	ru, en string
+}
+
+var cities = []city{
+	{"Москве", "Moscow"},
+	{"Санкт-Петербурге", "Saint Petersburg"},
+	{"Казани", "Kazan"},
+	{"Новосибирске", "Novosibirsk"},
+	{"Екатеринбурге", "Yekaterinburg"},
+	{"Нижнем Новгороде", "Nizhny Novgorod"},
+	{"Самаре", "Samara"},
+	{"Краснодаре", "Krasnodar"},
+}
+
+// item - товар из категории с правдоподобным диапазоном цен в рублях
+type item struct {
+	ru, en   string
+	min, max float64
+}
+
+// itemsByCategory сопоставляет товары категориям из миграции 0004_default_categories по slug
+var itemsByCategory = map[string][]item{
+	"phones": {
+		{"iPhone 13 128 ГБ", "iPhone 13 128GB", 35000, 52000},
+		{"Samsung Galaxy S21", "Samsung Galaxy S21", 22000, 38000},
+		{"Xiaomi Redmi Note 12", "Xiaomi Redmi Note 12", 9000, 16000},
+		{"Google Pixel 7", "Google Pixel 7", 25000, 40000},
+		{"Nokia 3310", "Nokia 3310", 1500, 4000},
+	},
+	"computers": {
+		{"MacBook Air M1", "MacBook Air M1", 55000, 75000},
+		{"Ноутбук Lenovo ThinkPad T480", "Lenovo ThinkPad T480 laptop", 18000, 30000},
+		{"Игровой ПК RTX 3060", "Gaming PC with RTX 3060", 60000, 95000},
+		{"Монитор Dell 27\"", "Dell 27\" monitor", 12000, 25000},
+		{"Механическая клавиатура", "Mechanical keyboard", 2500, 9000},
+	},
+	"photo-video": {
+		{"Фотоаппарат Canon EOS 250D", "Canon EOS 250D camera", 35000, 50000},
+		{"Объектив Sony 50mm f/1.8", "Sony 50mm f/1.8 lens", 9000, 16000},
+		{"Экшн-камера GoPro Hero 9", "GoPro Hero 9 action camera", 18000, 28000},
+		{"Штатив Manfrotto", "Manfrotto tripod", 4000, 12000},
+	},
+	"audio": {
+		{"Наушники Sony WH-1000XM4", "Sony WH-1000XM4 headphones", 15000, 24000},
+		{"Колонка JBL Charge 5", "JBL Charge 5 speaker", 8000, 13000},
+		{"Проигрыватель винила Audio-Technica", "Audio-Technica turntable", 14000, 30000},
+		{"AirPods Pro", "AirPods Pro", 9000, 16000},
+	},
+	"furniture": {
+		{"Угловой диван", "Corner sofa", 15000, 60000},
+		{"Обеденный стол из дуба", "Oak dining table", 8000, 35000},
+		{"Офисное кресло", "Office chair", 3000, 18000},
+		{"Книжный шкаф", "Bookcase", 3500, 15000},
+		{"Комод IKEA Malm", "IKEA Malm chest of drawers", 4000, 9000},
+	},
+	"lighting": {
+		{"Торшер с тканевым абажуром", "Floor lamp with fabric shade", 2000, 8000},
+		{"Настольная лампа", "Desk lamp", 800, 4000},
+		{"Люстра на 5 плафонов", "Five-arm chandelier", 3000, 15000},
+	},
+	"decor": {
+		{"Зеркало в деревянной раме", "Wooden frame mirror", 1500, 7000},
+		{"Набор ваз из стекла", "Set of glass vases", 700, 3500},
+		{"Картина маслом, пейзаж", "Oil painting, landscape", 3000, 20000},
+		{"Ковёр 2x3 м", "Rug 2x3 m", 4000, 18000},
+	},
+	"cars": {
+		{"Toyota Camry 2017", "Toyota Camry 2017", 1700000, 2400000},
+		{"Kia Rio 2019", "Kia Rio 2019", 1000000, 1450000},
+		{"Lada Vesta 2020", "Lada Vesta 2020", 850000, 1150000},
+		{"Volkswagen Polo 2016", "Volkswagen Polo 2016", 750000, 1050000},
+	},
+	"bicycles": {
+		{"Горный велосипед Merida", "Merida mountain bike", 20000, 55000},
+		{"Шоссейный велосипед Giant", "Giant road bike", 35000, 90000},
+		{"Детский велосипед 16\"", "Kids bike 16\"", 3000, 8000},
+		{"Электросамокат Xiaomi", "Xiaomi electric scooter", 12000, 25000},
+	},
+	"parts": {
+		{"Комплект зимних шин R16", "Winter tyres R16, set of 4", 12000, 30000},
+		{"Литые диски R17", "Alloy wheels R17", 15000, 40000},
+		{"Аккумулятор 60 Ач", "Car battery 60Ah", 3500, 8000},
+		{"Багажник на крышу", "Roof rack", 3000, 9000},
+	},
+	"mens-clothing": {
+		{"Пуховик мужской, размер 50", "Men's down jacket, size L", 4000, 15000},
+		{"Костюм классический", "Classic suit", 5000, 20000},
+		{"Кроссовки Nike Air Max, 43", "Nike Air Max sneakers, EU 43", 4000, 11000},
+		{"Кожаный ремень", "Leather belt", 800, 3500},
+	},
+	"womens-clothing": {
+		{"Пальто шерстяное, размер 44", "Wool coat, size S", 5000, 18000},
+		{"Платье вечернее", "Evening dress", 2500, 12000},
+		{"Сапоги кожаные, 38", "Leather boots, EU 38", 3500, 12000},
+		{"Сумка через плечо", "Crossbody bag", 1500, 9000},
+	},
+	"kids-clothing": {
+		{"Зимний комбинезон, 98 см", "Winter snowsuit, 3T", 2000, 6000},
+		{"Пакет вещей на девочку 2-3 года", "Bundle of girls' clothes, 2-3 years", 1000, 3500},
+		{"Школьная форма, рост 134", "School uniform, height 134 cm", 1500, 4500},
+		{"Кеды детские, 30", "Kids sneakers, EU 30", 700, 2500},
+	},
+	"sport": {
+		{"Гантели разборные 2x20 кг", "Adjustable dumbbells 2x20 kg", 4000, 9000},
+		{"Беговая дорожка", "Treadmill", 20000, 60000},
+		{"Горные лыжи Rossignol", "Rossignol skis", 9000, 25000},
+		{"Коврик для йоги", "Yoga mat", 600, 2500},
+		{"Палатка 3-местная", "Three-person tent", 4000, 14000},
+	},
+	"books": {
+		{"Собрание сочинений Чехова", "Chekhov collected works", 2000, 7000},
+		{"Учебники для 5 класса", "Grade 5 school textbooks", 500, 2000},
+		{"\"Чистый код\", Роберт Мартин", "\"Clean Code\" by Robert Martin", 700, 1800},
+		{"Комиксы Marvel, 10 выпусков", "Marvel comics, 10 issues", 1000, 4000},
+	},
+	"music-instruments": {
+		{"Акустическая гитара Yamaha F310", "Yamaha F310 acoustic guitar", 7000, 13000},
+		{"Цифровое пианино Casio", "Casio digital piano", 20000, 45000},
+		{"Электрогитара Fender Squier", "Fender Squier electric guitar", 15000, 30000},
+		{"Укулеле", "Ukulele", 2000, 6000},
+	},
+	"other": {
+		{"Переноска для кошки", "Cat carrier", 800, 2500},
+		{"Набор инструментов", "Tool kit", 2000, 9000},
+		{"Комнатное растение монстера", "Monstera houseplant", 700, 3000},
+		{"Настольная игра \"Каркассон\"", "\"Carcassonne\" board game", 1200, 3000},
+	},
+}
+
+// Части заголовков и описаний; русские фразы подобраны так, чтобы не зависеть от рода товара
+var (
+	titleSuffixesRU = []string{"", "", "в отличном состоянии", "в хорошем состоянии", "в упаковке", "срочно", "торг"}
+	titleSuffixesEN = []string{"", "", "excellent condition", "good condition", "brand new, sealed", "urgent", "negotiable"}
+
+	conditionsRU = []string{
+		"Состояние отличное, без царапин и сколов.",
+		"Есть небольшие следы использования, на работу не влияют.",
+		"Куплено в прошлом году, пользовались аккуратно.",
+		"В оригинальной упаковке, все документы сохранены.",
+		"Пользовались пару раз, всё работает.",
+	}
+	conditionsEN = []string{
+		"Excellent condition, no scratches or dents.",
+		"Some minor signs of use that do not affect anything.",
+		"Bought last year and handled with care.",
+		"Comes in the original box with all paperwork.",
+		"Used only a couple of times, works perfectly.",
+	}
+
+	reasonsRU = []string{
+		"Продаю в связи с переездом.",
+		"Продаю, так как купили новое.",
+		"Освобождаю место в квартире.",
+		"Не подошло, вернуть в магазин уже нельзя.",
+		"",
+	}
+	reasonsEN = []string{
+		"Selling because I am moving.",
+		"Selling since we upgraded.",
+		"Clearing out some space at home.",
+		"Did not suit me and it is past the return window.",
+		"",
+	}
+
+	dealsRU = []string{
+		"Самовывоз в %s, возможна доставка.",
+		"Встреча у метро в %s.",
+		"Отправлю в другой город, оплата при получении. Сейчас в %s.",
+		"Смотреть можно вечером, нахожусь в %s.",
+	}
+	dealsEN = []string{
+		"Pickup in %s, delivery possible.",
+		"Can meet near a metro station in %s.",
+		"Can ship to other cities, currently in %s.",
+		"Viewing in the evenings, located in %s.",
+	}
+
+	closingsRU = []string{"Торг уместен.", "Без торга.", "Пишите в сообщения, отвечу быстро.", "Обмен не предлагать.", ""}
+	closingsEN = []string{"Open to offers.", "Price is firm.", "Message me, I reply quickly.", "No trades, please.", ""}
+)
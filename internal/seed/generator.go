@@ -0,0 +1,135 @@
+package seed
+
+import (
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxUsers ограничивает число пользователей, чтобы имя с номером укладывалось в 20 символов
+	MaxUsers = 9999
+	// MaxAge - насколько далеко в прошлое разносятся даты создания объявлений
+	MaxAge = 90 * 24 * time.Hour
+)
+
+// Независимые потоки случайных чисел: у каждого пользователя и объявления свой
+const (
+	streamTag uint64 = iota
+	streamUser
+	streamAd
+)
+
+// Generator детерминированно строит демо-данные по значению seed. Пользователь и объявление
+// зависят только от seed и своего номера, поэтому при увеличении количества уже
+// сгенерированные данные не меняются, а только дописываются новые.
+type Generator struct {
+	seed uint64
+	tag  string
+}
+
+func NewGenerator(seed int64) *Generator {
+	g := &Generator{seed: uint64(seed)}
+	// короткая метка seed в именах пользователей разводит наборы данных разных seed
+	tag := strconv.FormatUint(uint64(g.rand(streamTag, 0).IntN(36*36*36)), 36)
+	g.tag = strings.Repeat("0", 3-len(tag)) + tag
+	return g
+}
+
+func (g *Generator) rand(stream uint64, index int) *rand.Rand {
+	return rand.New(rand.NewPCG(g.seed, stream<<32|uint64(index)))
+}
+
+// Username возвращает имя i-го пользователя (нумерация с нуля)
+func (g *Generator) Username(i int) string {
+	r := g.rand(streamUser, i)
+	return fmt.Sprintf("%s_%s%d", firstNames[r.IntN(len(firstNames))], g.tag, i+1)
+}
+
+// Ad - сгенерированное объявление
+type Ad struct {
+	Author       int // номер пользователя-автора
+	CategorySlug string
+	Title        string
+	Description  string
+	Price        float64
+	Age          time.Duration // на сколько раньше момента заполнения создано объявление
+}
+
+// Ad возвращает j-е объявление (нумерация с нуля) для users пользователей.
+// categories - slug категорий, в которых можно размещать объявления, в постоянном порядке.
+func (g *Generator) Ad(j, users int, categories []string) Ad {
+	r := g.rand(streamAd, j)
+
+	ad := Ad{
+		Author:       r.IntN(users),
+		CategorySlug: categories[r.IntN(len(categories))],
+		Age:          time.Duration(r.Int64N(int64(MaxAge))),
+	}
+
+	items := itemsByCategory[ad.CategorySlug]
+	it := items[r.IntN(len(items))]
+	ad.Price = price(r, it.min, it.max)
+
+	place := cities[r.IntN(len(cities))]
+	if r.IntN(10) < 7 {
+		ad.Title = title(it.ru, pick(r, titleSuffixesRU))
+		ad.Description = sentences(pick(r, conditionsRU), pick(r, reasonsRU), fmt.Sprintf(pick(r, dealsRU), place.ru), pick(r, closingsRU))
+	} else {
+		ad.Title = title(it.en, pick(r, titleSuffixesEN))
+		ad.Description = sentences(pick(r, conditionsEN), pick(r, reasonsEN), fmt.Sprintf(pick(r, dealsEN), place.en), pick(r, closingsEN))
+	}
+
+	return ad
+}
+
+// KnownCategory сообщает, есть ли у генератора товары для категории
+func KnownCategory(slug string) bool {
+	return len(itemsByCategory[slug]) > 0
+}
+
+func pick(r *rand.Rand, values []string) string {
+	return values[r.IntN(len(values))]
+}
+
+func title(name, suffix string) string {
+	if suffix == "" {
+		return name
+	}
+	return name + ", " + suffix
+}
+
+func sentences(parts ...string) string {
+	nonEmpty := parts[:0]
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, " ")
+}
+
+// price выбирает цену из диапазона и округляет её так, как обычно пишут в объявлениях
+func price(r *rand.Rand, min, max float64) float64 {
+	p := min + r.Float64()*(max-min)
+
+	step := 50.0
+	switch {
+	case p >= 100000:
+		step = 10000
+	case p >= 10000:
+		step = 500
+	case p >= 1000:
+		step = 100
+	}
+	p = math.Max(step, math.Round(p/step)*step)
+
+	// часть цен в духе «4 990»
+	if p >= 1000 && r.IntN(3) == 0 {
+		p -= 10
+	}
+	return p
+}
@@ -0,0 +1,112 @@
+package seed
+
+import (
+	"sort"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func allCategories() []string {
+	slugs := make([]string, 0, len(itemsByCategory))
+	for slug := range itemsByCategory {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs
+}
+
+func TestGenerator_Deterministic(t *testing.T) {
+	categories := allCategories()
+	a, b := NewGenerator(42), NewGenerator(42)
+
+	for i := 0; i < 50; i++ {
+		if a.Username(i) != b.Username(i) {
+			t.Fatalf("Username(%d) differs for the same seed", i)
+		}
+		if a.Ad(i, 10, categories) != b.Ad(i, 10, categories) {
+			t.Fatalf("Ad(%d) differs for the same seed", i)
+		}
+	}
+
+	other := NewGenerator(43)
+	if other.Username(0) == a.Username(0) {
+		t.Errorf("different seeds produced the same username %q", a.Username(0))
+	}
+}
+
+// объявления и пользователи не зависят от того, сколько их генерируется
+func TestGenerator_StablePrefix(t *testing.T) {
+	categories := allCategories()
+	g := NewGenerator(7)
+
+	first := g.Ad(3, 5, categories)
+	for i := 0; i < 10; i++ {
+		g.Ad(i, 5, categories)
+	}
+	if again := g.Ad(3, 5, categories); again != first {
+		t.Errorf("Ad(3) changed between calls: %+v != %+v", again, first)
+	}
+}
+
+func TestGenerator_ValidData(t *testing.T) {
+	categories := allCategories()
+	g := NewGenerator(1)
+
+	usernames := make(map[string]bool)
+	for i := 0; i < MaxUsers; i += 7 {
+		name := g.Username(i)
+		if len(name) < 3 || len(name) > 20 {
+			t.Fatalf("username %q has invalid length", name)
+		}
+		if usernames[name] {
+			t.Fatalf("duplicate username %q", name)
+		}
+		usernames[name] = true
+	}
+
+	var ru, en int
+	var oldest, newest time.Duration = MaxAge, 0
+	for j := 0; j < 2000; j++ {
+		ad := g.Ad(j, 20, categories)
+		if len(ad.Title) < 5 || len(ad.Title) > 100 {
+			t.Fatalf("ad %d: title %q has invalid length", j, ad.Title)
+		}
+		if len(ad.Description) < 10 || len(ad.Description) > 1000 {
+			t.Fatalf("ad %d: description %q has invalid length", j, ad.Description)
+		}
+		if ad.Price <= 0 {
+			t.Fatalf("ad %d: price %v is not positive", j, ad.Price)
+		}
+		if ad.Author < 0 || ad.Author >= 20 {
+			t.Fatalf("ad %d: author %d out of range", j, ad.Author)
+		}
+		if ad.Age < 0 || ad.Age >= MaxAge {
+			t.Fatalf("ad %d: age %v out of range", j, ad.Age)
+		}
+		oldest, newest = min(oldest, ad.Age), max(newest, ad.Age)
+
+		if utf8.RuneCountInString(ad.Title) == len(ad.Title) {
+			en++
+		} else {
+			ru++
+		}
+	}
+
+	if ru == 0 || en == 0 {
+		t.Errorf("expected both Russian and English ads, got ru=%d en=%d", ru, en)
+	}
+	if newest-oldest < MaxAge/2 {
+		t.Errorf("ads are not spread in time: ages from %v to %v", oldest, newest)
+	}
+}
+
+func TestPrice_Rounded(t *testing.T) {
+	g := NewGenerator(3)
+	for j := 0; j < 500; j++ {
+		ad := g.Ad(j, 1, allCategories())
+		if ad.Price != float64(int64(ad.Price)) || int64(ad.Price)%10 != 0 {
+			t.Fatalf("price %v is not rounded", ad.Price)
+		}
+	}
+}
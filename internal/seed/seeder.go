@@ -0,0 +1,183 @@
+package seed
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type AuthService interface {
+	Register(username, password string) (*domain.User, error)
+}
+
+type AdvertisementService interface {
+	CreateAd(userID uint, title, description string, imageURLs []string, price float64, categoryID uint, status domain.AdStatus) (*domain.Advertisement, error)
+}
+
+type UserRepository interface {
+	GetByUsername(username string) (*domain.User, error)
+}
+
+type AdvertisementRepository interface {
+	Count(filter domain.AdFilter) (int64, error)
+}
+
+type CategoryRepository interface {
+	GetAll() ([]domain.Category, error)
+}
+
+// Clock - часы, которые Seeder переводит перед созданием каждого объявления.
+// Их Now передаётся сервису объявлений, чтобы CreatedAt были разнесены во времени.
+type Clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// Now возвращает установленное время, а до первой установки - текущее
+func (c *Clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.now.IsZero() {
+		return time.Now()
+	}
+	return c.now
+}
+
+func (c *Clock) Set(t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = t
+}
+
+type Options struct {
+	Seed     int64
+	Users    int
+	Ads      int
+	Password string
+}
+
+type Result struct {
+	UsersCreated  int
+	UsersExisting int
+	AdsCreated    int
+	AdsExisting   int
+}
+
+// Seeder заполняет базу через сервисы, поэтому к демо-данным применяются
+// те же проверки, что и к данным из API
+type Seeder struct {
+	auth       AuthService
+	ads        AdvertisementService
+	users      UserRepository
+	adRepo     AdvertisementRepository
+	categories CategoryRepository
+	clock      *Clock
+	now        func() time.Time
+}
+
+func NewSeeder(auth AuthService, ads AdvertisementService, users UserRepository, adRepo AdvertisementRepository, categories CategoryRepository, clock *Clock) *Seeder {
+	return &Seeder{
+		auth:       auth,
+		ads:        ads,
+		users:      users,
+		adRepo:     adRepo,
+		categories: categories,
+		clock:      clock,
+		now:        time.Now,
+	}
+}
+
+// Run создаёт opts.Users пользователей и opts.Ads объявлений для opts.Seed. Повторный запуск
+// с тем же seed ничего не дублирует: существующие пользователи не создаются заново, а у
+// каждого из них пропускается столько первых объявлений, сколько у него уже есть.
+func (s *Seeder) Run(opts Options) (*Result, error) {
+	if opts.Users < 0 || opts.Users > MaxUsers {
+		return nil, fmt.Errorf("%w: users must be between 0 and %d", domain.ErrInvalidInput, MaxUsers)
+	}
+	if opts.Ads < 0 {
+		return nil, fmt.Errorf("%w: ads must not be negative", domain.ErrInvalidInput)
+	}
+	if opts.Ads > 0 && opts.Users == 0 {
+		return nil, fmt.Errorf("%w: ads need at least one user", domain.ErrInvalidInput)
+	}
+
+	slugs, categoryIDs, err := s.loadCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	gen := NewGenerator(opts.Seed)
+	result := &Result{}
+
+	userIDs := make([]uint, opts.Users)
+	existingAds := make([]int, opts.Users)
+	for i := range userIDs {
+		username := gen.Username(i)
+		user, err := s.users.GetByUsername(username)
+		switch {
+		case errors.Is(err, domain.ErrNotFound):
+			if user, err = s.auth.Register(username, opts.Password); err != nil {
+				return result, fmt.Errorf("register %s: %w", username, err)
+			}
+			result.UsersCreated++
+		case err != nil:
+			return result, err
+		default:
+			count, err := s.adRepo.Count(domain.AdFilter{Author: username})
+			if err != nil {
+				return result, err
+			}
+			existingAds[i] = int(count)
+			result.UsersExisting++
+		}
+		userIDs[i] = user.ID
+	}
+
+	base := s.now()
+	for j := 0; j < opts.Ads; j++ {
+		ad := gen.Ad(j, opts.Users, slugs)
+		if existingAds[ad.Author] > 0 {
+			existingAds[ad.Author]--
+			result.AdsExisting++
+			continue
+		}
+
+		s.clock.Set(base.Add(-ad.Age))
+		_, err := s.ads.CreateAd(userIDs[ad.Author], ad.Title, ad.Description, nil, ad.Price, categoryIDs[ad.CategorySlug], domain.AdStatusPublished)
+		if err != nil {
+			return result, fmt.Errorf("create ad %d: %w", j+1, err)
+		}
+		result.AdsCreated++
+	}
+
+	return result, nil
+}
+
+// loadCategories возвращает отсортированные slug категорий, для которых есть товары, и их ID
+func (s *Seeder) loadCategories() ([]string, map[string]uint, error) {
+	categories, err := s.categories.GetAll()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make(map[string]uint)
+	for _, category := range categories {
+		if KnownCategory(category.Slug) {
+			ids[category.Slug] = category.ID
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil, errors.New("no default categories found, apply migrations first")
+	}
+
+	slugs := make([]string, 0, len(ids))
+	for slug := range ids {
+		slugs = append(slugs, slug)
+	}
+	sort.Strings(slugs)
+	return slugs, ids, nil
+}
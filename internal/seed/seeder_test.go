@@ -0,0 +1,177 @@
+package seed
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// fakeStore хранит пользователей и объявления в памяти и реализует все зависимости Seeder
+type fakeStore struct {
+	clock *Clock
+	users []domain.User
+	ads   []domain.Advertisement
+}
+
+func (f *fakeStore) Register(username, password string) (*domain.User, error) {
+	if _, err := f.GetByUsername(username); err == nil {
+		return nil, errors.New("username already exists")
+	}
+	f.users = append(f.users, domain.User{ID: uint(len(f.users) + 1), Username: username, Password: password})
+	return &f.users[len(f.users)-1], nil
+}
+
+func (f *fakeStore) GetByUsername(username string) (*domain.User, error) {
+	for i := range f.users {
+		if f.users[i].Username == username {
+			return &f.users[i], nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (f *fakeStore) CreateAd(userID uint, title, description string, imageURLs []string, price float64, categoryID uint, status domain.AdStatus) (*domain.Advertisement, error) {
+	ad := domain.Advertisement{
+		ID:          uint(len(f.ads) + 1),
+		Title:       title,
+		Description: description,
+		Price:       price,
+		CategoryID:  categoryID,
+		UserID:      userID,
+		Status:      status,
+		CreatedAt:   f.clock.Now(),
+	}
+	f.ads = append(f.ads, ad)
+	return &ad, nil
+}
+
+func (f *fakeStore) Count(filter domain.AdFilter) (int64, error) {
+	user, err := f.GetByUsername(filter.Author)
+	if err != nil {
+		return 0, nil
+	}
+	var count int64
+	for _, ad := range f.ads {
+		if ad.UserID == user.ID {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (f *fakeStore) GetAll() ([]domain.Category, error) {
+	return []domain.Category{
+		{ID: 1, Name: "Электроника", Slug: "electronics"},
+		{ID: 2, Name: "Телефоны", Slug: "phones"},
+		{ID: 3, Name: "Книги", Slug: "books"},
+		{ID: 4, Name: "Самодельная", Slug: "custom"},
+	}, nil
+}
+
+func newTestSeeder(now time.Time) (*Seeder, *fakeStore) {
+	store := &fakeStore{clock: &Clock{}}
+	seeder := NewSeeder(store, store, store, store, store, store.clock)
+	seeder.now = func() time.Time { return now }
+	return seeder, store
+}
+
+func TestSeeder_Run(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	seeder, store := newTestSeeder(now)
+
+	result, err := seeder.Run(Options{Seed: 42, Users: 5, Ads: 40, Password: "demo-password"})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if *result != (Result{UsersCreated: 5, AdsCreated: 40}) {
+		t.Errorf("Run() result = %+v", *result)
+	}
+
+	distinct := make(map[time.Time]bool)
+	for _, ad := range store.ads {
+		if ad.CategoryID != 2 && ad.CategoryID != 3 {
+			t.Fatalf("ad %d placed in category %d without generated items", ad.ID, ad.CategoryID)
+		}
+		if ad.CreatedAt.After(now) || ad.CreatedAt.Before(now.Add(-MaxAge)) {
+			t.Fatalf("ad %d created at %v, outside of the seeding window", ad.ID, ad.CreatedAt)
+		}
+		distinct[ad.CreatedAt] = true
+	}
+	if len(distinct) < len(store.ads)/2 {
+		t.Errorf("CreatedAt values are not spread: %d distinct of %d", len(distinct), len(store.ads))
+	}
+}
+
+func TestSeeder_Idempotent(t *testing.T) {
+	seeder, store := newTestSeeder(time.Now())
+	opts := Options{Seed: 42, Users: 5, Ads: 40, Password: "demo-password"}
+
+	if _, err := seeder.Run(opts); err != nil {
+		t.Fatalf("first Run() error = %v", err)
+	}
+	firstAds := append([]domain.Advertisement(nil), store.ads...)
+
+	result, err := seeder.Run(opts)
+	if err != nil {
+		t.Fatalf("second Run() error = %v", err)
+	}
+	if *result != (Result{UsersExisting: 5, AdsExisting: 40}) {
+		t.Errorf("second Run() result = %+v", *result)
+	}
+	if len(store.users) != 5 || len(store.ads) != 40 {
+		t.Fatalf("second Run() created data: %d users, %d ads", len(store.users), len(store.ads))
+	}
+
+	// увеличение количества дописывает только недостающее
+	opts.Users, opts.Ads = 5, 60
+	result, err = seeder.Run(opts)
+	if err != nil {
+		t.Fatalf("third Run() error = %v", err)
+	}
+	if result.AdsCreated != 20 || result.AdsExisting != 40 || len(store.ads) != 60 {
+		t.Errorf("third Run() result = %+v, ads = %d", *result, len(store.ads))
+	}
+	for i, ad := range firstAds {
+		if store.ads[i].Title != ad.Title {
+			t.Fatalf("existing ad %d changed", ad.ID)
+		}
+	}
+}
+
+func TestSeeder_SameSeedSameData(t *testing.T) {
+	opts := Options{Seed: 9, Users: 3, Ads: 15, Password: "demo-password"}
+	now := time.Now()
+
+	first, firstStore := newTestSeeder(now)
+	second, secondStore := newTestSeeder(now)
+	if _, err := first.Run(opts); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := second.Run(opts); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := range firstStore.ads {
+		a, b := firstStore.ads[i], secondStore.ads[i]
+		if a.Title != b.Title || a.Price != b.Price || a.UserID != b.UserID || !a.CreatedAt.Equal(b.CreatedAt) {
+			t.Fatalf("ad %d differs between runs: %+v != %+v", i, a, b)
+		}
+	}
+}
+
+func TestSeeder_InvalidOptions(t *testing.T) {
+	seeder, _ := newTestSeeder(time.Now())
+
+	for _, opts := range []Options{
+		{Users: -1},
+		{Users: MaxUsers + 1},
+		{Users: 1, Ads: -1},
+		{Users: 0, Ads: 5},
+	} {
+		if _, err := seeder.Run(opts); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("Run(%+v) error = %v, want ErrInvalidInput", opts, err)
+		}
+	}
+}
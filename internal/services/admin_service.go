@@ -0,0 +1,173 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type AdminUserRepository interface {
+	GetByID(id uint) (*domain.User, error)
+	List(filter domain.UserFilter) ([]domain.User, error)
+	Count(filter domain.UserFilter) (int64, error)
+	SetBanned(id uint, banned bool) error
+	SetRole(id uint, role domain.Role) error
+}
+
+// SessionRevoker завершает все сессии пользователя (отзывает refresh-токены)
+type SessionRevoker interface {
+	RevokeUserRefreshTokens(userID uint) error
+}
+
+type adminService struct {
+	userRepo AdminUserRepository
+	sessions SessionRevoker
+	adRepo   AdvertisementRepository
+}
+
+func NewAdminService(userRepo AdminUserRepository, sessions SessionRevoker, adRepo AdvertisementRepository) *adminService {
+	return &adminService{
+		userRepo: userRepo,
+		sessions: sessions,
+		adRepo:   adRepo,
+	}
+}
+
+func (s *adminService) ListUsers(filter domain.UserFilter) (*domain.UserPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	if filter.Role != "" && !filter.Role.Valid() {
+		return nil, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidInput, filter.Role)
+	}
+
+	filter.Offset = (filter.Page - 1) * filter.Limit
+
+	users, err := s.userRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.userRepo.Count(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.UserPage{
+		Items: users,
+		Total: total,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}, nil
+}
+
+// SetBanned блокирует или разблокирует пользователя. Модерировать можно только
+// пользователей с ролью ниже своей; при блокировке все сессии завершаются.
+func (s *adminService) SetBanned(actorID uint, actorRole domain.Role, userID uint, banned bool) (*domain.User, error) {
+	if actorID == userID {
+		return nil, fmt.Errorf("%w: cannot change own ban status", domain.ErrForbidden)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Role.AtLeast(actorRole) {
+		return nil, fmt.Errorf("%w: insufficient role to moderate this user", domain.ErrForbidden)
+	}
+
+	if err := s.userRepo.SetBanned(userID, banned); err != nil {
+		return nil, err
+	}
+
+	if banned {
+		if err := s.sessions.RevokeUserRefreshTokens(userID); err != nil {
+			return nil, err
+		}
+	}
+
+	user.Banned = banned
+	return user, nil
+}
+
+// ArchiveAd снимает с публикации любое объявление независимо от владельца
+func (s *adminService) ArchiveAd(adID uint) (*domain.Advertisement, error) {
+	ad, err := s.adRepo.GetByID(adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransition(ad.Status, domain.AdStatusArchived) {
+		return nil, fmt.Errorf("%w: %s -> %s", domain.ErrInvalidStatusTransition, ad.Status, domain.AdStatusArchived)
+	}
+
+	from := ad.Status
+	ad.Status = domain.AdStatusArchived
+	if err := s.adRepo.UpdateStatus(ad, from); err != nil {
+		return nil, err
+	}
+
+	return ad, nil
+}
+
+// SetRole назначает пользователю роль. Используется из командной строки оператором,
+// поэтому ограничений по роли того, кто назначает, нет.
+func (s *adminService) SetRole(userID uint, role domain.Role) (*domain.User, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidInput, role)
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.userRepo.SetRole(userID, role); err != nil {
+		return nil, err
+	}
+
+	user.Role = role
+	return user, nil
+}
+
+// Размер пачки, которой ExportAds читает объявления
+const exportBatchSize = 500
+
+// ExportAds передаёт в each все неудалённые объявления в статусе status (пустой - в любом),
+// от старых к новым. Объявления читаются пачками по ключу, так что выгрузка не держит
+// в памяти всю таблицу и не пропускает строки при вставках во время выгрузки.
+func (s *adminService) ExportAds(status domain.AdStatus, each func(ad domain.Advertisement) error) error {
+	if status != "" && !status.Valid() {
+		return fmt.Errorf("%w: unknown status %q", domain.ErrInvalidInput, status)
+	}
+
+	filter := domain.AdFilter{
+		Status: status,
+		SortBy: "created_at",
+		Order:  "asc",
+		Limit:  exportBatchSize,
+	}
+	for {
+		ads, err := s.adRepo.GetAll(filter)
+		if err != nil {
+			return err
+		}
+		for _, ad := range ads {
+			if err := each(ad); err != nil {
+				return err
+			}
+		}
+		if len(ads) < filter.Limit {
+			return nil
+		}
+
+		last := ads[len(ads)-1]
+		filter.After = &domain.AdCursor{SortBy: filter.SortBy, Order: filter.Order, CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+}
@@ -0,0 +1,242 @@
+package services
+
+import (
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+func (m *MockUserRepository) filtered(filter domain.UserFilter) []domain.User {
+	var users []domain.User
+	for _, user := range m.users {
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.Banned != nil && user.Banned != *filter.Banned {
+			continue
+		}
+		users = append(users, *user)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+	return users
+}
+
+func (m *MockUserRepository) List(filter domain.UserFilter) ([]domain.User, error) {
+	users := m.filtered(filter)
+	if filter.Offset >= len(users) {
+		return nil, nil
+	}
+	users = users[filter.Offset:]
+	if len(users) > filter.Limit {
+		users = users[:filter.Limit]
+	}
+	return users, nil
+}
+
+func (m *MockUserRepository) Count(filter domain.UserFilter) (int64, error) {
+	return int64(len(m.filtered(filter))), nil
+}
+
+func (m *MockUserRepository) SetBanned(id uint, banned bool) error {
+	user, err := m.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.Banned = banned
+	return nil
+}
+
+func (m *MockUserRepository) SetRole(id uint, role domain.Role) error {
+	user, err := m.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.Role = role
+	return nil
+}
+
+func (m *MockTokenRepository) RevokeUserRefreshTokens(userID uint) error {
+	for _, token := range m.refreshTokens {
+		if token.UserID == userID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func newAdminTestUsers() *MockUserRepository {
+	repo := &MockUserRepository{users: make(map[string]*domain.User)}
+	for _, user := range []*domain.User{
+		{Username: "admin", Role: domain.RoleAdmin},
+		{Username: "moderator", Role: domain.RoleModerator},
+		{Username: "user1", Role: domain.RoleUser},
+		{Username: "user2", Role: domain.RoleUser, Banned: true},
+	} {
+		_ = repo.Create(user)
+	}
+	return repo
+}
+
+func TestAdminService_ListUsers(t *testing.T) {
+	service := NewAdminService(newAdminTestUsers(), newMockTokenRepository(), &MockAdRepository{})
+
+	page, err := service.ListUsers(domain.UserFilter{Page: 1, Limit: 2})
+	if err != nil {
+		t.Fatalf("ListUsers failed: %v", err)
+	}
+	if page.Total != 4 || len(page.Items) != 2 || page.Items[0].Username != "admin" {
+		t.Errorf("Unexpected page: total=%d items=%d", page.Total, len(page.Items))
+	}
+
+	// Фильтр по блокировке
+	banned := true
+	page, _ = service.ListUsers(domain.UserFilter{Banned: &banned})
+	if page.Total != 1 || page.Items[0].Username != "user2" {
+		t.Errorf("Expected only banned user, got %+v", page.Items)
+	}
+
+	if _, err := service.ListUsers(domain.UserFilter{Role: "root"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for unknown role, got %v", err)
+	}
+}
+
+func TestAdminService_SetBanned(t *testing.T) {
+	users := newAdminTestUsers()
+	tokens := newMockTokenRepository()
+	service := NewAdminService(users, tokens, &MockAdRepository{})
+
+	target := users.users["user1"]
+	_ = tokens.CreateRefreshToken(&domain.RefreshToken{UserID: target.ID, FamilyID: "f1", ExpiresAt: time.Now().Add(time.Hour)})
+
+	// Модератор блокирует пользователя, сессии пользователя отзываются
+	user, err := service.SetBanned(users.users["moderator"].ID, domain.RoleModerator, target.ID, true)
+	if err != nil || !user.Banned || !target.Banned {
+		t.Fatalf("Ban failed: %v", err)
+	}
+	if tokens.refreshTokens[0].RevokedAt == nil {
+		t.Error("Refresh tokens should be revoked after ban")
+	}
+
+	// Разблокировка
+	if _, err := service.SetBanned(users.users["moderator"].ID, domain.RoleModerator, target.ID, false); err != nil || target.Banned {
+		t.Errorf("Unban failed: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		actor   string
+		target  string
+		wantErr error
+	}{
+		{"Moderator cannot ban moderator", "moderator", "moderator", domain.ErrForbidden},
+		{"Moderator cannot ban admin", "moderator", "admin", domain.ErrForbidden},
+		{"Admin cannot ban admin", "admin", "admin", domain.ErrForbidden},
+		{"Admin bans moderator", "admin", "moderator", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor := users.users[tt.actor]
+			_, err := service.SetBanned(actor.ID, actor.Role, users.users[tt.target].ID, true)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	if _, err := service.SetBanned(1, domain.RoleAdmin, 100, true); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAdminService_ArchiveAd(t *testing.T) {
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, UserID: 5, Status: domain.AdStatusPublished},
+		{ID: 2, UserID: 5, Status: domain.AdStatusArchived},
+	}}
+	service := NewAdminService(newAdminTestUsers(), newMockTokenRepository(), repo)
+
+	// Архивировать можно чужое объявление
+	ad, err := service.ArchiveAd(1)
+	if err != nil || ad.Status != domain.AdStatusArchived {
+		t.Errorf("ArchiveAd failed: %v", err)
+	}
+
+	if _, err := service.ArchiveAd(2); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Errorf("Expected ErrInvalidStatusTransition, got %v", err)
+	}
+
+	if _, err := service.ArchiveAd(3); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAdminService_SetRole(t *testing.T) {
+	users := newAdminTestUsers()
+	service := NewAdminService(users, newMockTokenRepository(), &MockAdRepository{})
+
+	target := users.users["user1"]
+	user, err := service.SetRole(target.ID, domain.RoleAdmin)
+	if err != nil || user.Role != domain.RoleAdmin || target.Role != domain.RoleAdmin {
+		t.Fatalf("SetRole failed: %v", err)
+	}
+
+	if _, err := service.SetRole(target.ID, "root"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for unknown role, got %v", err)
+	}
+	if _, err := service.SetRole(42, domain.RoleAdmin); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestAdminService_ExportAds(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &MockAdRepository{}
+	for i := 1; i <= exportBatchSize+3; i++ {
+		status := domain.AdStatusPublished
+		if i%2 == 0 {
+			status = domain.AdStatusSold
+		}
+		repo.ads = append(repo.ads, &domain.Advertisement{ID: uint(i), Status: status, CreatedAt: start.Add(time.Duration(i) * time.Minute)})
+	}
+	service := NewAdminService(newAdminTestUsers(), newMockTokenRepository(), repo)
+
+	var ids []uint
+	if err := service.ExportAds("", func(ad domain.Advertisement) error {
+		ids = append(ids, ad.ID)
+		return nil
+	}); err != nil {
+		t.Fatalf("ExportAds failed: %v", err)
+	}
+	if len(ids) != exportBatchSize+3 {
+		t.Fatalf("Expected all %d ads, got %d", exportBatchSize+3, len(ids))
+	}
+	for i, id := range ids {
+		if id != uint(i+1) {
+			t.Fatalf("Expected ads from oldest to newest without duplicates, got %d at %d", id, i)
+		}
+	}
+
+	count := 0
+	_ = service.ExportAds(domain.AdStatusSold, func(ad domain.Advertisement) error {
+		if ad.Status != domain.AdStatusSold {
+			t.Errorf("Unexpected status %s", ad.Status)
+		}
+		count++
+		return nil
+	})
+	if count != (exportBatchSize+3)/2 {
+		t.Errorf("Expected %d sold ads, got %d", (exportBatchSize+3)/2, count)
+	}
+
+	stop := errors.New("stop")
+	if err := service.ExportAds("", func(domain.Advertisement) error { return stop }); !errors.Is(err, stop) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+	if err := service.ExportAds("deleted", func(domain.Advertisement) error { return nil }); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for unknown status, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type FavoriteRepository interface {
+	Add(userID, adID uint) error
+	Remove(userID, adID uint) error
+	FavoriteAdIDs(userID uint, adIDs []uint) ([]uint, error)
+}
+
+// AddFavorite добавляет в избранное объявление, которое пользователь может видеть
+func (s *advertisementService) AddFavorite(userID, adID uint) error {
+	ad, err := s.GetAd(adID, userID)
+	if err != nil {
+		return err
+	}
+
+	if ad.UserID == userID {
+		return fmt.Errorf("%w: cannot add own advertisement to favorites", domain.ErrInvalidInput)
+	}
+
+	return s.favoriteRepo.Add(userID, adID)
+}
+
+// RemoveFavorite убирает объявление из избранного, в том числе удалённое
+func (s *advertisementService) RemoveFavorite(userID, adID uint) error {
+	return s.favoriteRepo.Remove(userID, adID)
+}
+
+// GetFavorites возвращает избранное пользователя с той же пагинацией и сортировкой, что и GetAds.
+// Удалённые и архивные объявления остаются в списке (см. Advertisement.UnavailableReason).
+func (s *advertisementService) GetFavorites(userID uint, filter domain.AdFilter) (*domain.AdPage, error) {
+	filter.FavoritesOf = userID
+	filter.ViewerID = userID
+	return s.GetAds(filter)
+}
+
+// markFavorites проставляет IsFavorite объявлениям из избранного viewerID
+func (s *advertisementService) markFavorites(viewerID uint, ads []domain.Advertisement) error {
+	if viewerID == 0 || len(ads) == 0 {
+		return nil
+	}
+
+	ids := make([]uint, 0, len(ads))
+	for _, ad := range ads {
+		ids = append(ids, ad.ID)
+	}
+
+	favorites, err := s.favoriteRepo.FavoriteAdIDs(viewerID, ids)
+	if err != nil {
+		return err
+	}
+
+	favorite := make(map[uint]bool, len(favorites))
+	for _, id := range favorites {
+		favorite[id] = true
+	}
+	for i := range ads {
+		ads[i].IsFavorite = favorite[ads[i].ID]
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// normalizeImages проставляет позиции по порядку в срезе и гарантирует ровно одну обложку
+// (по умолчанию - первое изображение). Возвращает URL обложки.
+func normalizeImages(images []domain.AdImage) string {
+	cover := -1
+	for i := range images {
+		images[i].Position = i
+		if images[i].IsCover {
+			if cover >= 0 {
+				images[i].IsCover = false
+			} else {
+				cover = i
+			}
+		}
+	}
+
+	if len(images) == 0 {
+		return ""
+	}
+	if cover < 0 {
+		cover = 0
+		images[0].IsCover = true
+	}
+	return images[cover].URL
+}
+
+func newAdImages(urls []string) ([]domain.AdImage, error) {
+	if len(urls) > domain.MaxAdImages {
+		return nil, fmt.Errorf("%w: at most %d images per advertisement", domain.ErrInvalidInput, domain.MaxAdImages)
+	}
+
+	images := make([]domain.AdImage, 0, len(urls))
+	for _, url := range urls {
+		images = append(images, domain.AdImage{URL: url})
+	}
+	return images, nil
+}
+
+// saveImages сохраняет галерею и синхронизирует ad.ImageURL с обложкой
+func (s *advertisementService) saveImages(ad *domain.Advertisement, images []domain.AdImage) error {
+	coverURL := normalizeImages(images)
+	if err := s.adRepo.UpdateImages(ad.ID, images, coverURL); err != nil {
+		return err
+	}
+
+	ad.Images = images
+	ad.ImageURL = coverURL
+	return nil
+}
+
+func findImage(images []domain.AdImage, imageID uint) int {
+	for i, image := range images {
+		if image.ID == imageID {
+			return i
+		}
+	}
+	return -1
+}
+
+// copyImages - галерея меняется на копии, чтобы не портить загруженную модель при ошибке
+func copyImages(images []domain.AdImage) []domain.AdImage {
+	return append(make([]domain.AdImage, 0, len(images)+1), images...)
+}
+
+// AddImage добавляет изображение в конец галереи (первое изображение становится обложкой)
+func (s *advertisementService) AddImage(userID, adID uint, url string) (*domain.Advertisement, error) {
+	ad, err := s.getOwnAd(userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ad.Images) >= domain.MaxAdImages {
+		return nil, fmt.Errorf("%w: at most %d images per advertisement", domain.ErrInvalidInput, domain.MaxAdImages)
+	}
+
+	images := append(copyImages(ad.Images), domain.AdImage{URL: url})
+	if err := s.saveImages(ad, images); err != nil {
+		return nil, err
+	}
+
+	return ad, nil
+}
+
+// RemoveImage удаляет изображение из галереи и возвращает его, чтобы можно было удалить файл.
+// Если удалена обложка, обложкой становится первое оставшееся изображение.
+func (s *advertisementService) RemoveImage(userID, adID, imageID uint) (*domain.Advertisement, *domain.AdImage, error) {
+	ad, err := s.getOwnAd(userID, adID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	idx := findImage(ad.Images, imageID)
+	if idx < 0 {
+		return nil, nil, domain.ErrNotFound
+	}
+	removed := ad.Images[idx]
+
+	images := copyImages(ad.Images[:idx])
+	images = append(images, ad.Images[idx+1:]...)
+	if err := s.saveImages(ad, images); err != nil {
+		return nil, nil, err
+	}
+
+	return ad, &removed, nil
+}
+
+// ReorderImages задаёт новый порядок галереи; imageIDs должен содержать все изображения ровно по разу
+func (s *advertisementService) ReorderImages(userID, adID uint, imageIDs []uint) (*domain.Advertisement, error) {
+	ad, err := s.getOwnAd(userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(imageIDs) != len(ad.Images) {
+		return nil, fmt.Errorf("%w: image_ids must list every image of the advertisement", domain.ErrInvalidInput)
+	}
+
+	images := make([]domain.AdImage, 0, len(imageIDs))
+	seen := make(map[uint]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		idx := findImage(ad.Images, id)
+		if idx < 0 || seen[id] {
+			return nil, fmt.Errorf("%w: image_ids must list every image of the advertisement", domain.ErrInvalidInput)
+		}
+		seen[id] = true
+		images = append(images, ad.Images[idx])
+	}
+
+	if err := s.saveImages(ad, images); err != nil {
+		return nil, err
+	}
+
+	return ad, nil
+}
+
+func (s *advertisementService) SetCoverImage(userID, adID, imageID uint) (*domain.Advertisement, error) {
+	ad, err := s.getOwnAd(userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if findImage(ad.Images, imageID) < 0 {
+		return nil, domain.ErrNotFound
+	}
+
+	images := copyImages(ad.Images)
+	for i := range images {
+		images[i].IsCover = images[i].ID == imageID
+	}
+
+	if err := s.saveImages(ad, images); err != nil {
+		return nil, err
+	}
+
+	return ad, nil
+}
+
+// withCoverURL возвращает галерею, в которой url - обложка: существующее изображение
+// с этим URL становится обложкой, иначе добавляется новое
+func withCoverURL(current []domain.AdImage, url string) ([]domain.AdImage, error) {
+	images := copyImages(current)
+
+	found := false
+	for i := range images {
+		images[i].IsCover = !found && images[i].URL == url
+		found = found || images[i].IsCover
+	}
+
+	if !found {
+		if len(images) >= domain.MaxAdImages {
+			return nil, fmt.Errorf("%w: at most %d images per advertisement", domain.ErrInvalidInput, domain.MaxAdImages)
+		}
+		images = append(images, domain.AdImage{URL: url, IsCover: true})
+	}
+
+	return images, nil
+}
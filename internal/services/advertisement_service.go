@@ -2,66 +2,396 @@ package services
 
 import (
 	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/events"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
+const maxSearchQueryLength = 200
+
 type AdvertisementRepository interface {
 	Create(ad *domain.Advertisement) error
-	GetAll(page, limit int, sortBy, order string, minPrice, maxPrice float64) ([]domain.Advertisement, error)
+	GetByID(id uint) (*domain.Advertisement, error)
+	Update(ad *domain.Advertisement) error
+	UpdateStatus(ad *domain.Advertisement, from domain.AdStatus) error
+	UpdateImages(adID uint, images []domain.AdImage, coverURL string) error
+	Delete(id uint) error
+	GetAll(filter domain.AdFilter) ([]domain.Advertisement, error)
+	Count(filter domain.AdFilter) (int64, error)
+}
+
+// Разрешённые переходы между статусами. В archived можно перейти из любого статуса,
+// а из reserved - вернуться в published, если сделка сорвалась.
+var adStatusTransitions = map[domain.AdStatus][]domain.AdStatus{
+	domain.AdStatusDraft:     {domain.AdStatusPublished},
+	domain.AdStatusPublished: {domain.AdStatusReserved, domain.AdStatusSold},
+	domain.AdStatusReserved:  {domain.AdStatusPublished, domain.AdStatusSold},
+}
+
+func canTransition(from, to domain.AdStatus) bool {
+	if to == domain.AdStatusArchived {
+		return from != domain.AdStatusArchived
+	}
+	for _, allowed := range adStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
 }
 
 type advertisementService struct {
-	adRepo AdvertisementRepository
+	adRepo       AdvertisementRepository
+	categoryRepo CategoryRepository
+	favoriteRepo FavoriteRepository
+	events       EventPublisher
+	now          func() time.Time
 }
 
-func NewAdvertisementService(adRepo AdvertisementRepository) *advertisementService {
-	return &advertisementService{adRepo: adRepo}
+func NewAdvertisementService(adRepo AdvertisementRepository, categoryRepo CategoryRepository, favoriteRepo FavoriteRepository) *advertisementService {
+	return &advertisementService{
+		adRepo:       adRepo,
+		categoryRepo: categoryRepo,
+		favoriteRepo: favoriteRepo,
+		events:       noopPublisher{},
+		now:          time.Now,
+	}
 }
 
-func (s *advertisementService) CreateAd(userID uint, title, description, imageURL string, price float64) (*domain.Advertisement, error) {
+// SetEventPublisher включает отправку событий о новых объявлениях
+func (s *advertisementService) SetEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+}
+
+// SetClock подменяет источник времени для CreatedAt и PublishedAt объявлений (нужно генератору демо-данных)
+func (s *advertisementService) SetClock(now func() time.Time) {
+	s.now = now
+}
+
+// publishAdCreated сообщает подписчикам, что объявление стало доступно всем
+func (s *advertisementService) publishAdCreated(ad *domain.Advertisement) {
+	publish(s.events, events.New(domain.EventAdCreated, 0, domain.AdCreatedEvent{
+		ID:         ad.ID,
+		Title:      ad.Title,
+		Price:      ad.Price,
+		CategoryID: ad.CategoryID,
+		ImageURL:   ad.ImageURL,
+		CreatedAt:  ad.CreatedAt,
+	}))
+}
+
+func validateAd(title, description string, price float64) error {
 	if len(title) < 5 || len(title) > 100 {
-		return nil, errors.New("title must be between 5 and 100 characters")
+		return fmt.Errorf("%w: title must be between 5 and 100 characters", domain.ErrInvalidInput)
 	}
 
 	if len(description) < 10 || len(description) > 1000 {
-		return nil, errors.New("description must be between 10 and 1000 characters")
+		return fmt.Errorf("%w: description must be between 10 and 1000 characters", domain.ErrInvalidInput)
 	}
 
 	if price <= 0 {
-		return nil, errors.New("price must be positive")
+		return fmt.Errorf("%w: price must be positive", domain.ErrInvalidInput)
+	}
+
+	return nil
+}
+
+func (s *advertisementService) checkCategory(categoryID uint) error {
+	if categoryID == 0 {
+		return fmt.Errorf("%w: category is required", domain.ErrInvalidInput)
+	}
+
+	if _, err := s.categoryRepo.GetByID(categoryID); err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return fmt.Errorf("%w: category not found", domain.ErrInvalidInput)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// CreateAd создаёт опубликованное объявление, либо черновик при status == draft.
+// Первое изображение из imageURLs становится обложкой.
+func (s *advertisementService) CreateAd(userID uint, title, description string, imageURLs []string, price float64, categoryID uint, status domain.AdStatus) (*domain.Advertisement, error) {
+	if err := validateAd(title, description, price); err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCategory(categoryID); err != nil {
+		return nil, err
+	}
+
+	if status == "" {
+		status = domain.AdStatusPublished
+	}
+	if status != domain.AdStatusDraft && status != domain.AdStatusPublished {
+		return nil, fmt.Errorf("%w: new advertisement can only be a draft or published", domain.ErrInvalidInput)
+	}
+
+	images, err := newAdImages(imageURLs)
+	if err != nil {
+		return nil, err
 	}
 
 	ad := &domain.Advertisement{
 		Title:       title,
 		Description: description,
-		ImageURL:    imageURL,
+		ImageURL:    normalizeImages(images),
 		Price:       price,
+		Status:      status,
+		CategoryID:  categoryID,
 		UserID:      userID,
+		Images:      images,
+		CreatedAt:   s.now(),
+	}
+	if status == domain.AdStatusPublished {
+		publishedAt := ad.CreatedAt
+		ad.PublishedAt = &publishedAt
 	}
 
 	if err := s.adRepo.Create(ad); err != nil {
 		return nil, err
 	}
 
+	if ad.Status == domain.AdStatusPublished {
+		s.publishAdCreated(ad)
+	}
+
+	return ad, nil
+}
+
+// GetAd возвращает объявление; черновики видны только владельцу
+func (s *advertisementService) GetAd(id, viewerID uint) (*domain.Advertisement, error) {
+	ad, err := s.adRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ad.Status == domain.AdStatusDraft && ad.UserID != viewerID {
+		return nil, domain.ErrNotFound
+	}
+
+	if viewerID != 0 {
+		ads := []domain.Advertisement{*ad}
+		if err := s.markFavorites(viewerID, ads); err != nil {
+			return nil, err
+		}
+		ad.IsFavorite = ads[0].IsFavorite
+	}
+
+	return ad, nil
+}
+
+// getOwnAd возвращает объявление, только если им владеет userID
+func (s *advertisementService) getOwnAd(userID, adID uint) (*domain.Advertisement, error) {
+	ad, err := s.adRepo.GetByID(adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if ad.UserID != userID {
+		return nil, domain.ErrForbidden
+	}
+
 	return ad, nil
 }
 
-func (s *advertisementService) GetAds(page, limit int, sortBy, order string, minPrice, maxPrice float64) ([]domain.Advertisement, error) {
-	if page < 1 {
-		page = 1
+func (s *advertisementService) UpdateAd(userID, adID uint, update domain.AdvertisementUpdate) (*domain.Advertisement, error) {
+	ad, err := s.getOwnAd(userID, adID)
+	if err != nil {
+		return nil, err
 	}
 
-	if limit < 1 || limit > 100 {
-		limit = 10
+	if update.Title != nil {
+		ad.Title = *update.Title
+	}
+	if update.Description != nil {
+		ad.Description = *update.Description
+	}
+	// image_url в запросе на изменение означает новую обложку галереи
+	var images []domain.AdImage
+	if update.ImageURL != nil {
+		if *update.ImageURL == "" {
+			return nil, fmt.Errorf("%w: image_url must not be empty", domain.ErrInvalidInput)
+		}
+		if images, err = withCoverURL(ad.Images, *update.ImageURL); err != nil {
+			return nil, err
+		}
+	}
+	if update.Price != nil {
+		ad.Price = *update.Price
 	}
 
-	if sortBy != "" && sortBy != "price" && sortBy != "created_at" {
-		sortBy = "created_at"
+	if err := validateAd(ad.Title, ad.Description, ad.Price); err != nil {
+		return nil, err
 	}
 
-	if order != "" && order != "asc" && order != "desc" {
-		order = "desc"
+	if update.CategoryID != nil {
+		if err := s.checkCategory(*update.CategoryID); err != nil {
+			return nil, err
+		}
+		ad.CategoryID = *update.CategoryID
 	}
 
-	return s.adRepo.GetAll(page, limit, sortBy, order, minPrice, maxPrice)
-}
\ No newline at end of file
+	if err := s.adRepo.Update(ad); err != nil {
+		return nil, err
+	}
+
+	if images != nil {
+		if err := s.saveImages(ad, images); err != nil {
+			return nil, err
+		}
+	}
+
+	return ad, nil
+}
+
+func (s *advertisementService) DeleteAd(userID, adID uint) error {
+	if _, err := s.getOwnAd(userID, adID); err != nil {
+		return err
+	}
+
+	return s.adRepo.Delete(adID)
+}
+
+func (s *advertisementService) ChangeStatus(userID, adID uint, status domain.AdStatus) (*domain.Advertisement, error) {
+	ad, err := s.getOwnAd(userID, adID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !canTransition(ad.Status, status) {
+		return nil, fmt.Errorf("%w: %s -> %s", domain.ErrInvalidStatusTransition, ad.Status, status)
+	}
+
+	from := ad.Status
+	ad.Status = status
+	if status == domain.AdStatusPublished && ad.PublishedAt == nil {
+		// по времени публикации сохранённые поиски находят и черновики, созданные давно
+		publishedAt := s.now()
+		ad.PublishedAt = &publishedAt
+	}
+	// статус меняется, только если его не изменили с момента чтения (например, Accept)
+	if err := s.adRepo.UpdateStatus(ad, from); err != nil {
+		return nil, err
+	}
+
+	if from == domain.AdStatusDraft && status == domain.AdStatusPublished {
+		s.publishAdCreated(ad)
+	}
+
+	return ad, nil
+}
+
+// GetAds по умолчанию отдаёт только опубликованные объявления.
+// Фильтр по другому статусу допустим лишь для собственных объявлений (filter.UserID).
+// При заданном filter.Cursor выборка идёт по ключу (keyset) и page игнорируется.
+func (s *advertisementService) GetAds(filter domain.AdFilter) (*domain.AdPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 10
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: search query must be at most %d characters", domain.ErrInvalidInput, maxSearchQueryLength)
+	}
+	filter.Author = strings.TrimSpace(filter.Author)
+
+	if filter.SortBy != "price" && filter.SortBy != "created_at" && filter.SortBy != "relevance" {
+		filter.SortBy = "created_at"
+	}
+	// без строки поиска сортировать по релевантности нечего
+	if filter.SortBy == "relevance" && filter.Query == "" {
+		filter.SortBy = "created_at"
+	}
+
+	if filter.Order != "asc" && filter.Order != "desc" {
+		filter.Order = "desc"
+	}
+
+	if filter.FavoritesOf != 0 {
+		// в избранном объявления любого статуса
+		filter.Status = ""
+		filter.UserID = 0
+	} else {
+		if filter.Status == "" {
+			filter.Status = domain.AdStatusPublished
+		}
+		if !filter.Status.Valid() {
+			return nil, fmt.Errorf("%w: unknown status %q", domain.ErrInvalidInput, filter.Status)
+		}
+		if filter.Status != domain.AdStatusPublished && filter.UserID == 0 {
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	categoryIDs, err := expandCategory(s.categoryRepo, filter.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+	filter.CategoryIDs = categoryIDs
+
+	// keyset-пагинация устойчива к вставкам между запросами страниц, но для релевантности не поддерживается
+	keyset := filter.SortBy != "relevance"
+	filter.After = nil
+	filter.Offset = (filter.Page - 1) * filter.Limit
+	if filter.Cursor != "" {
+		if !keyset {
+			return nil, fmt.Errorf("%w: cursor is not supported for relevance sort", domain.ErrInvalidInput)
+		}
+		after, err := decodeAdCursor(filter.Cursor, filter.SortBy, filter.Order)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+		filter.Offset = 0
+	}
+
+	total, err := s.adRepo.Count(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	// запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	pageFilter := filter
+	pageFilter.Limit = filter.Limit + 1
+	ads, err := s.adRepo.GetAll(pageFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.AdPage{
+		Items: ads,
+		Total: total,
+		Page:  filter.Page,
+		Limit: filter.Limit,
+	}
+	if filter.After != nil {
+		page.Page = 0
+	}
+
+	if len(ads) > filter.Limit {
+		page.Items = ads[:filter.Limit]
+		if keyset {
+			page.NextCursor = encodeAdCursor(page.Items[len(page.Items)-1], filter.SortBy, filter.Order)
+		}
+	}
+
+	if filter.FavoritesOf != 0 && filter.FavoritesOf == filter.ViewerID {
+		for i := range page.Items {
+			page.Items[i].IsFavorite = true
+		}
+	} else if err := s.markFavorites(filter.ViewerID, page.Items); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
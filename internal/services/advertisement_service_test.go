@@ -2,34 +2,267 @@ package services
 
 import (
 	"github.com/keenetic29/vk-internship/internal/domain"
+	"errors"
+	"sort"
+	"strings"
 	"testing"
+	"time"
+
+	"gorm.io/gorm"
 )
 
+// MockAdRepository заодно хранит избранное (FavoriteRepository): выборка FavoritesOf опирается на него
 type MockAdRepository struct {
-	ads []*domain.Advertisement
+	ads         []*domain.Advertisement
+	lastFilter  domain.AdFilter
+	lastImageID uint
+	favorites   map[uint][]uint // userID -> id объявлений
+	// beforeUpdateStatus вызывается перед сменой статуса: имитирует параллельный запрос
+	beforeUpdateStatus func()
 }
 
 func (m *MockAdRepository) Create(ad *domain.Advertisement) error {
+	for i := range ad.Images {
+		m.lastImageID++
+		ad.Images[i].ID = m.lastImageID
+	}
 	m.ads = append(m.ads, ad)
 	return nil
 }
 
-func (m *MockAdRepository) GetAll(page, limit int, sortBy, order string, minPrice, maxPrice float64) ([]domain.Advertisement, error) {
+func (m *MockAdRepository) GetByID(id uint) (*domain.Advertisement, error) {
+	for _, ad := range m.ads {
+		if ad.ID == id && !ad.DeletedAt.Valid {
+			copied := *ad
+			copied.Images = append([]domain.AdImage(nil), ad.Images...)
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockAdRepository) Update(ad *domain.Advertisement) error {
+	for i, existing := range m.ads {
+		if existing.ID == ad.ID {
+			copied := *ad
+			copied.Status, copied.PublishedAt = existing.Status, existing.PublishedAt
+			m.ads[i] = &copied
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *MockAdRepository) UpdateStatus(ad *domain.Advertisement, from domain.AdStatus) error {
+	if m.beforeUpdateStatus != nil {
+		m.beforeUpdateStatus()
+	}
+	for _, existing := range m.ads {
+		if existing.ID == ad.ID && !existing.DeletedAt.Valid && existing.Status == from {
+			existing.Status = ad.Status
+			existing.PublishedAt = ad.PublishedAt
+			return nil
+		}
+	}
+	return domain.ErrConflict
+}
+
+func (m *MockAdRepository) UpdateImages(adID uint, images []domain.AdImage, coverURL string) error {
+	for _, ad := range m.ads {
+		if ad.ID == adID {
+			for i := range images {
+				if images[i].ID == 0 {
+					m.lastImageID++
+					images[i].ID = m.lastImageID
+				}
+				images[i].AdID = adID
+			}
+			ad.Images = append([]domain.AdImage(nil), images...)
+			ad.ImageURL = coverURL
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *MockAdRepository) Delete(id uint) error {
+	for _, ad := range m.ads {
+		if ad.ID == id && !ad.DeletedAt.Valid {
+			ad.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *MockAdRepository) Add(userID, adID uint) error {
+	if !containsID(m.favorites[userID], adID) {
+		if m.favorites == nil {
+			m.favorites = make(map[uint][]uint)
+		}
+		m.favorites[userID] = append(m.favorites[userID], adID)
+	}
+	return nil
+}
+
+func (m *MockAdRepository) Remove(userID, adID uint) error {
+	var rest []uint
+	for _, id := range m.favorites[userID] {
+		if id != adID {
+			rest = append(rest, id)
+		}
+	}
+	m.favorites[userID] = rest
+	return nil
+}
+
+func (m *MockAdRepository) FavoriteAdIDs(userID uint, adIDs []uint) ([]uint, error) {
+	var result []uint
+	for _, id := range adIDs {
+		if containsID(m.favorites[userID], id) {
+			result = append(result, id)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockAdRepository) matches(ad *domain.Advertisement, filter domain.AdFilter) bool {
+	if filter.FavoritesOf != 0 {
+		if !containsID(m.favorites[filter.FavoritesOf], ad.ID) {
+			return false
+		}
+	} else if ad.DeletedAt.Valid {
+		return false
+	}
+	if filter.Status != "" && ad.Status != filter.Status {
+		return false
+	}
+	if filter.UserID != 0 && ad.UserID != filter.UserID {
+		return false
+	}
+	if filter.Author != "" && ad.User.Username != filter.Author {
+		return false
+	}
+	if len(filter.CategoryIDs) > 0 && !containsID(filter.CategoryIDs, ad.CategoryID) {
+		return false
+	}
+	if filter.Query != "" && !strings.Contains(strings.ToLower(ad.Title+" "+ad.Description), strings.ToLower(filter.Query)) {
+		return false
+	}
+	return (filter.MinPrice == 0 || ad.Price >= filter.MinPrice) && (filter.MaxPrice == 0 || ad.Price <= filter.MaxPrice)
+}
+
+// compareAds сравнивает объявления по ключу сортировки и id
+func compareAds(a, b domain.Advertisement, sortBy string) int {
+	switch {
+	case sortBy == "price" && a.Price != b.Price:
+		if a.Price < b.Price {
+			return -1
+		}
+		return 1
+	case sortBy != "price" && !a.CreatedAt.Equal(b.CreatedAt):
+		if a.CreatedAt.Before(b.CreatedAt) {
+			return -1
+		}
+		return 1
+	case a.ID != b.ID:
+		if a.ID < b.ID {
+			return -1
+		}
+		return 1
+	}
+	return 0
+}
+
+func (m *MockAdRepository) GetAll(filter domain.AdFilter) ([]domain.Advertisement, error) {
+	m.lastFilter = filter
 	var result []domain.Advertisement
 	for _, ad := range m.ads {
-		if (minPrice == 0 || ad.Price >= minPrice) && (maxPrice == 0 || ad.Price <= maxPrice) {
+		if m.matches(ad, filter) {
 			result = append(result, *ad)
 		}
 	}
+
+	sign := -1
+	if filter.Order == "asc" {
+		sign = 1
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return sign*compareAds(result[i], result[j], filter.SortBy) < 0
+	})
+
+	if filter.After != nil {
+		last := domain.Advertisement{ID: filter.After.ID, Price: filter.After.Price, CreatedAt: filter.After.CreatedAt}
+		var rest []domain.Advertisement
+		for _, ad := range result {
+			if sign*compareAds(ad, last, filter.SortBy) > 0 {
+				rest = append(rest, ad)
+			}
+		}
+		result = rest
+	}
+
+	if filter.Offset >= len(result) {
+		return nil, nil
+	}
+	result = result[filter.Offset:]
+	if filter.Limit > 0 && len(result) > filter.Limit {
+		result = result[:filter.Limit]
+	}
 	return result, nil
 }
 
+func (m *MockAdRepository) Count(filter domain.AdFilter) (int64, error) {
+	var count int64
+	for _, ad := range m.ads {
+		if m.matches(ad, filter) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func containsID(ids []uint, id uint) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+func TestAdvertisementService_CreateAd_Clock(t *testing.T) {
+	repo := &MockAdRepository{}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+	createdAt := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	service.SetClock(func() time.Time { return createdAt })
+
+	ad, err := service.CreateAd(1, "Title", "Description", nil, 100, 2, "")
+	if err != nil {
+		t.Fatalf("CreateAd failed: %v", err)
+	}
+	if !ad.CreatedAt.Equal(createdAt) {
+		t.Errorf("CreatedAt = %v, want %v", ad.CreatedAt, createdAt)
+	}
+	if ad.PublishedAt == nil || !ad.PublishedAt.Equal(createdAt) {
+		t.Errorf("PublishedAt = %v, want %v", ad.PublishedAt, createdAt)
+	}
+
+	draft, err := service.CreateAd(1, "Draft", "Description", nil, 100, 2, domain.AdStatusDraft)
+	if err != nil {
+		t.Fatalf("CreateAd failed: %v", err)
+	}
+	if draft.PublishedAt != nil {
+		t.Errorf("Draft must not have PublishedAt, got %v", draft.PublishedAt)
+	}
+}
+
 func TestAdvertisementService_CreateAd(t *testing.T) {
 	repo := &MockAdRepository{}
-	service := NewAdvertisementService(repo)
+	service := NewAdvertisementService(repo, testCategories(), repo)
 
 	// Успешное создание
-	ad, err := service.CreateAd(1, "Title", "Description", "http://example.com/image.jpg", 100)
+	ad, err := service.CreateAd(1, "Title", "Description", []string{"http://example.com/image.jpg"}, 100, 2, "")
 	if err != nil {
 		t.Fatalf("CreateAd failed: %v", err)
 	}
@@ -38,6 +271,33 @@ func TestAdvertisementService_CreateAd(t *testing.T) {
 		t.Error("Ad title mismatch")
 	}
 
+	if ad.Status != domain.AdStatusPublished {
+		t.Errorf("New ad should be published by default, got %q", ad.Status)
+	}
+
+	// Черновик
+	draft, err := service.CreateAd(1, "Draft title", "Description", []string{"http://example.com/image.jpg"}, 100, 2, domain.AdStatusDraft)
+	if err != nil || draft.Status != domain.AdStatusDraft {
+		t.Errorf("Draft creation failed: %v", err)
+	}
+
+	// Сразу проданным объявление создать нельзя
+	if _, err := service.CreateAd(1, "Title", "Description", []string{"http://example.com/image.jpg"}, 100, 2, domain.AdStatusSold); err == nil {
+		t.Error("Expected error for creating sold ad")
+	}
+
+	if ad.CategoryID != 2 {
+		t.Errorf("Expected category 2, got %d", ad.CategoryID)
+	}
+
+	// Категория обязательна и должна существовать
+	if _, err := service.CreateAd(1, "Title", "Description", []string{"http://example.com/image.jpg"}, 100, 0, ""); err == nil {
+		t.Error("Expected error for missing category")
+	}
+	if _, err := service.CreateAd(1, "Title", "Description", []string{"http://example.com/image.jpg"}, 100, 42, ""); err == nil {
+		t.Error("Expected error for unknown category")
+	}
+
 	// Невалидные данные
 	testCases := []struct {
 		title       string
@@ -52,13 +312,314 @@ func TestAdvertisementService_CreateAd(t *testing.T) {
 	}
 
 	for _, tc := range testCases {
-		_, err := service.CreateAd(1, tc.title, tc.description, "http://valid.url", tc.price)
+		_, err := service.CreateAd(1, tc.title, tc.description, []string{"http://valid.url"}, tc.price, 2, "")
 		if err == nil {
 			t.Errorf("Expected error for title=%q, desc=%q, price=%f", tc.title, tc.description, tc.price)
 		}
 	}
 }
 
+func TestAdvertisementService_UpdateAd(t *testing.T) {
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, Title: "Old title", Description: "Old description", Price: 100, UserID: 1},
+	}}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	newTitle := "New title"
+	newPrice := 250.0
+
+	// Владелец меняет только переданные поля
+	ad, err := service.UpdateAd(1, 1, domain.AdvertisementUpdate{Title: &newTitle, Price: &newPrice})
+	if err != nil {
+		t.Fatalf("UpdateAd failed: %v", err)
+	}
+	if ad.Title != newTitle || ad.Price != newPrice || ad.Description != "Old description" {
+		t.Errorf("Unexpected ad after update: %+v", ad)
+	}
+
+	// Чужое объявление
+	if _, err := service.UpdateAd(2, 1, domain.AdvertisementUpdate{Title: &newTitle}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+
+	// Несуществующее объявление
+	if _, err := service.UpdateAd(1, 42, domain.AdvertisementUpdate{Title: &newTitle}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Те же правила валидации, что и при создании
+	shortTitle := "T"
+	if _, err := service.UpdateAd(1, 1, domain.AdvertisementUpdate{Title: &shortTitle}); err == nil {
+		t.Error("Expected validation error for short title")
+	}
+	negativePrice := -1.0
+	if _, err := service.UpdateAd(1, 1, domain.AdvertisementUpdate{Price: &negativePrice}); err == nil {
+		t.Error("Expected validation error for negative price")
+	}
+
+	stored, _ := repo.GetByID(1)
+	if stored.Title != newTitle || stored.Price != newPrice {
+		t.Errorf("Invalid update must not be persisted: %+v", stored)
+	}
+}
+
+func TestAdvertisementService_DeleteAd(t *testing.T) {
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, Title: "Title", Description: "Description", Price: 100, UserID: 1},
+	}}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	if err := service.DeleteAd(2, 1); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+
+	if err := service.DeleteAd(1, 1); err != nil {
+		t.Fatalf("DeleteAd failed: %v", err)
+	}
+
+	if _, err := service.GetAd(1, 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound after delete, got %v", err)
+	}
+}
+
+func TestAdvertisementService_ChangeStatus(t *testing.T) {
+	tests := []struct {
+		from    domain.AdStatus
+		to      domain.AdStatus
+		allowed bool
+	}{
+		{domain.AdStatusDraft, domain.AdStatusPublished, true},
+		{domain.AdStatusPublished, domain.AdStatusReserved, true},
+		{domain.AdStatusPublished, domain.AdStatusSold, true},
+		{domain.AdStatusDraft, domain.AdStatusArchived, true},
+		{domain.AdStatusSold, domain.AdStatusArchived, true},
+		{domain.AdStatusReserved, domain.AdStatusArchived, true},
+		{domain.AdStatusReserved, domain.AdStatusSold, true},
+		{domain.AdStatusReserved, domain.AdStatusPublished, true},
+		{domain.AdStatusDraft, domain.AdStatusSold, false},
+		{domain.AdStatusSold, domain.AdStatusPublished, false},
+		{domain.AdStatusArchived, domain.AdStatusPublished, false},
+		{domain.AdStatusArchived, domain.AdStatusArchived, false},
+	}
+
+	for _, tc := range tests {
+		repo := &MockAdRepository{ads: []*domain.Advertisement{
+			{ID: 1, Title: "Title", Description: "Description", Price: 100, UserID: 1, Status: tc.from},
+		}}
+		service := NewAdvertisementService(repo, testCategories(), repo)
+
+		ad, err := service.ChangeStatus(1, 1, tc.to)
+		if tc.allowed {
+			if err != nil || ad.Status != tc.to {
+				t.Errorf("%s -> %s should be allowed, got %v", tc.from, tc.to, err)
+			}
+			// черновик считается опубликованным в момент смены статуса, а не создания
+			if err == nil && tc.to == domain.AdStatusPublished && ad.PublishedAt == nil {
+				t.Errorf("%s -> %s must set PublishedAt", tc.from, tc.to)
+			}
+		} else if !errors.Is(err, domain.ErrInvalidStatusTransition) {
+			t.Errorf("%s -> %s should be rejected, got %v", tc.from, tc.to, err)
+		}
+	}
+
+	// Менять статус может только владелец
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, UserID: 1, Status: domain.AdStatusPublished},
+	}}
+	if _, err := NewAdvertisementService(repo, testCategories(), repo).ChangeStatus(2, 1, domain.AdStatusSold); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+
+	// Принятие предложения забронировало объявление между чтением и записью: бронь не затирается
+	repo.beforeUpdateStatus = func() { repo.ads[0].Status = domain.AdStatusReserved }
+	if _, err := NewAdvertisementService(repo, testCategories(), repo).ChangeStatus(1, 1, domain.AdStatusArchived); !errors.Is(err, domain.ErrConflict) {
+		t.Errorf("Expected ErrConflict, got %v", err)
+	}
+	if repo.ads[0].Status != domain.AdStatusReserved {
+		t.Errorf("Concurrent reservation was overwritten with %s", repo.ads[0].Status)
+	}
+}
+
+func TestAdvertisementService_GetAds(t *testing.T) {
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, UserID: 1, User: domain.User{ID: 1, Username: "alice"}, Price: 100, Status: domain.AdStatusPublished},
+		{ID: 2, UserID: 1, User: domain.User{ID: 1, Username: "alice"}, Price: 100, Status: domain.AdStatusSold},
+		{ID: 3, UserID: 2, User: domain.User{ID: 2, Username: "bob"}, Price: 100, Status: domain.AdStatusSold},
+		{ID: 4, UserID: 2, User: domain.User{ID: 2, Username: "bob"}, Price: 100, Status: domain.AdStatusDraft},
+		{ID: 5, UserID: 2, User: domain.User{ID: 2, Username: "bob"}, Price: 100, Status: domain.AdStatusPublished},
+	}}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	// По умолчанию только опубликованные
+	page, err := service.GetAds(domain.AdFilter{})
+	if err != nil || len(page.Items) != 2 {
+		t.Errorf("Expected only published ads, got %v (%v)", page, err)
+	}
+
+	// Объявления одного автора
+	page, err = service.GetAds(domain.AdFilter{Author: " bob "})
+	if err != nil || len(page.Items) != 1 || page.Items[0].ID != 5 {
+		t.Errorf("Expected published ad of bob, got %v (%v)", page, err)
+	}
+	if repo.lastFilter.Author != "bob" {
+		t.Errorf("Expected trimmed author, got %q", repo.lastFilter.Author)
+	}
+
+	// Фильтр по статусу среди своих объявлений
+	page, err = service.GetAds(domain.AdFilter{Status: domain.AdStatusSold, UserID: 1})
+	if err != nil || len(page.Items) != 1 || page.Items[0].ID != 2 {
+		t.Errorf("Expected own sold ad, got %v (%v)", page, err)
+	}
+
+	// Без владельца фильтровать по статусу нельзя
+	if _, err := service.GetAds(domain.AdFilter{Status: domain.AdStatusSold}); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+
+	// Черновик виден только владельцу
+	if _, err := service.GetAd(4, 1); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for foreign draft, got %v", err)
+	}
+	if _, err := service.GetAd(4, 2); err != nil {
+		t.Errorf("Owner should see own draft, got %v", err)
+	}
+}
+
+func TestAdvertisementService_GetAdsSearch(t *testing.T) {
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, Title: "Горный велосипед", Description: "Почти новый", Status: domain.AdStatusPublished},
+		{ID: 2, Title: "iPhone 13", Description: "Без царапин", Status: domain.AdStatusPublished},
+	}}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	page, err := service.GetAds(domain.AdFilter{Query: "  велосипед ", SortBy: "relevance"})
+	if err != nil || len(page.Items) != 1 || page.Items[0].ID != 1 {
+		t.Errorf("Expected one matching ad, got %v (%v)", page, err)
+	}
+	if repo.lastFilter.Query != "велосипед" || repo.lastFilter.SortBy != "relevance" {
+		t.Errorf("Unexpected filter passed to repository: %+v", repo.lastFilter)
+	}
+
+	// Без строки поиска релевантность заменяется сортировкой по дате
+	if _, err := service.GetAds(domain.AdFilter{SortBy: "relevance"}); err != nil {
+		t.Fatalf("GetAds failed: %v", err)
+	}
+	if repo.lastFilter.SortBy != "created_at" {
+		t.Errorf("Expected created_at sort without query, got %q", repo.lastFilter.SortBy)
+	}
+
+	if _, err := service.GetAds(domain.AdFilter{Query: makeString(201)}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for long query, got %v", err)
+	}
+}
+
+func TestAdvertisementService_GetAdsByCategory(t *testing.T) {
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, CategoryID: 1, Status: domain.AdStatusPublished},
+		{ID: 2, CategoryID: 4, Status: domain.AdStatusPublished},
+		{ID: 3, CategoryID: 3, Status: domain.AdStatusPublished},
+		{ID: 4, CategoryID: 5, Status: domain.AdStatusPublished},
+	}}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	// Телефоны включают вложенные смартфоны
+	page, err := service.GetAds(domain.AdFilter{CategoryID: 2})
+	if err != nil || len(page.Items) != 1 || page.Items[0].ID != 2 {
+		t.Errorf("Expected ad from descendant category, got %v (%v)", page, err)
+	}
+
+	// Электроника включает всё дерево
+	page, err = service.GetAds(domain.AdFilter{CategoryID: 1})
+	if err != nil || len(page.Items) != 3 {
+		t.Errorf("Expected 3 ads in electronics tree, got %v (%v)", page, err)
+	}
+
+	if _, err := service.GetAds(domain.AdFilter{CategoryID: 42}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for unknown category, got %v", err)
+	}
+}
+
+func TestAdvertisementService_GetAdsPagination(t *testing.T) {
+	base := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo := &MockAdRepository{}
+	for i := 1; i <= 5; i++ {
+		repo.ads = append(repo.ads, &domain.Advertisement{
+			ID:        uint(i),
+			Price:     float64(100 * ((i + 1) / 2)), // цены повторяются: 100, 100, 200, 200, 300
+			CreatedAt: base.Add(time.Duration(i) * time.Hour),
+			Status:    domain.AdStatusPublished,
+		})
+	}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	// Офсетная пагинация с метаданными
+	page, err := service.GetAds(domain.AdFilter{Page: 2, Limit: 2})
+	if err != nil {
+		t.Fatalf("GetAds failed: %v", err)
+	}
+	if page.Total != 5 || page.Page != 2 || page.Limit != 2 || len(page.Items) != 2 || page.NextCursor == "" {
+		t.Errorf("Unexpected page metadata: %+v", page)
+	}
+	if page.Items[0].ID != 3 || page.Items[1].ID != 2 {
+		t.Errorf("Unexpected page items: %v, %v", page.Items[0].ID, page.Items[1].ID)
+	}
+
+	last, _ := service.GetAds(domain.AdFilter{Page: 3, Limit: 2})
+	if len(last.Items) != 1 || last.NextCursor != "" {
+		t.Errorf("Last page should have no next cursor: %+v", last)
+	}
+
+	for _, sortBy := range []string{"created_at", "price"} {
+		for _, order := range []string{"asc", "desc"} {
+			seen := make(map[uint]bool)
+			filter := domain.AdFilter{Limit: 2, SortBy: sortBy, Order: order}
+			inserted := false
+
+			for {
+				page, err := service.GetAds(filter)
+				if err != nil {
+					t.Fatalf("%s %s: GetAds failed: %v", sortBy, order, err)
+				}
+				for _, ad := range page.Items {
+					if seen[ad.ID] {
+						t.Errorf("%s %s: duplicate ad %d", sortBy, order, ad.ID)
+					}
+					seen[ad.ID] = true
+				}
+
+				// Новое объявление между запросами страниц не должно сдвигать выдачу
+				if !inserted {
+					repo.ads = append(repo.ads, &domain.Advertisement{
+						ID: 100, Price: 50, CreatedAt: base.Add(100 * time.Hour), Status: domain.AdStatusPublished,
+					})
+					inserted = true
+				}
+
+				if page.NextCursor == "" {
+					break
+				}
+				filter.Cursor = page.NextCursor
+			}
+
+			for i := uint(1); i <= 5; i++ {
+				if !seen[i] {
+					t.Errorf("%s %s: ad %d was skipped", sortBy, order, i)
+				}
+			}
+			repo.ads = repo.ads[:5]
+		}
+	}
+
+	// Курсор привязан к сортировке
+	page, _ = service.GetAds(domain.AdFilter{Limit: 2, SortBy: "price", Order: "asc"})
+	if _, err := service.GetAds(domain.AdFilter{Limit: 2, SortBy: "created_at", Cursor: page.NextCursor}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for cursor from another sort, got %v", err)
+	}
+	if _, err := service.GetAds(domain.AdFilter{Cursor: "not-a-cursor"}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for malformed cursor, got %v", err)
+	}
+}
+
 // Вспомогательная функция для генерации длинных строк
 func makeString(length int) string {
 	b := make([]byte, length)
@@ -66,4 +627,167 @@ func makeString(length int) string {
 		b[i] = 'a'
 	}
 	return string(b)
-}
\ No newline at end of file
+}
+func TestAdvertisementService_Images(t *testing.T) {
+	repo := &MockAdRepository{}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	// При создании первое изображение становится обложкой
+	ad, err := service.CreateAd(1, "Title", "Description", []string{"http://a.jpg", "http://b.jpg", "http://c.jpg"}, 100, 2, "")
+	if err != nil {
+		t.Fatalf("CreateAd failed: %v", err)
+	}
+	ad.ID = 1
+	if ad.ImageURL != "http://a.jpg" || !ad.Images[0].IsCover || ad.Images[2].Position != 2 {
+		t.Fatalf("Unexpected gallery: %+v", ad.Images)
+	}
+
+	tooMany := make([]string, domain.MaxAdImages+1)
+	if _, err := service.CreateAd(1, "Title", "Description", tooMany, 100, 2, ""); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for too many images, got %v", err)
+	}
+
+	ids := func(images []domain.AdImage) []uint {
+		var result []uint
+		for _, image := range images {
+			result = append(result, image.ID)
+		}
+		return result
+	}
+	a, b, c := ad.Images[0].ID, ad.Images[1].ID, ad.Images[2].ID
+
+	// Новый порядок; обложка остаётся прежней
+	ad, err = service.ReorderImages(1, 1, []uint{c, a, b})
+	if err != nil {
+		t.Fatalf("ReorderImages failed: %v", err)
+	}
+	if got := ids(ad.Images); got[0] != c || got[1] != a || got[2] != b || ad.ImageURL != "http://a.jpg" {
+		t.Errorf("Unexpected order %v, cover %s", got, ad.ImageURL)
+	}
+	if _, err := service.ReorderImages(1, 1, []uint{c, a}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for incomplete order, got %v", err)
+	}
+	if _, err := service.ReorderImages(1, 1, []uint{c, c, a}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for duplicate ids, got %v", err)
+	}
+
+	// Смена обложки
+	ad, err = service.SetCoverImage(1, 1, b)
+	if err != nil || ad.ImageURL != "http://b.jpg" {
+		t.Errorf("SetCoverImage failed: %v (%s)", err, ad.ImageURL)
+	}
+
+	// Удаление обложки переносит её на первое оставшееся изображение
+	ad, removed, err := service.RemoveImage(1, 1, b)
+	if err != nil || removed.URL != "http://b.jpg" {
+		t.Fatalf("RemoveImage failed: %v", err)
+	}
+	if len(ad.Images) != 2 || ad.ImageURL != "http://c.jpg" || ad.Images[1].Position != 1 {
+		t.Errorf("Unexpected gallery after removal: %+v", ad.Images)
+	}
+	if _, _, err := service.RemoveImage(1, 1, b); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// Добавление и изменение обложки через image_url
+	ad, err = service.AddImage(1, 1, "http://d.jpg")
+	if err != nil || len(ad.Images) != 3 || ad.ImageURL != "http://c.jpg" {
+		t.Errorf("AddImage failed: %v", err)
+	}
+	newCover := "http://a.jpg"
+	ad, err = service.UpdateAd(1, 1, domain.AdvertisementUpdate{ImageURL: &newCover})
+	if err != nil || ad.ImageURL != newCover || len(ad.Images) != 3 {
+		t.Errorf("UpdateAd with existing image_url failed: %v", err)
+	}
+
+	// Галерею меняет только владелец
+	if _, err := service.AddImage(2, 1, "http://e.jpg"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestAdvertisementService_Favorites(t *testing.T) {
+	now := time.Now()
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, UserID: 1, Price: 100, Status: domain.AdStatusPublished, CreatedAt: now.Add(-3 * time.Hour)},
+		{ID: 2, UserID: 1, Price: 200, Status: domain.AdStatusPublished, CreatedAt: now.Add(-2 * time.Hour)},
+		{ID: 3, UserID: 1, Price: 300, Status: domain.AdStatusPublished, CreatedAt: now.Add(-time.Hour)},
+		{ID: 4, UserID: 1, Price: 400, Status: domain.AdStatusDraft, CreatedAt: now},
+		{ID: 5, UserID: 2, Price: 500, Status: domain.AdStatusPublished, CreatedAt: now},
+	}}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+
+	for _, id := range []uint{1, 2, 3} {
+		if err := service.AddFavorite(2, id); err != nil {
+			t.Fatalf("AddFavorite(%d) failed: %v", id, err)
+		}
+	}
+	// повторное добавление ничего не ломает
+	if err := service.AddFavorite(2, 1); err != nil {
+		t.Errorf("Repeated AddFavorite failed: %v", err)
+	}
+
+	if err := service.AddFavorite(2, 4); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Foreign draft must not be added, got %v", err)
+	}
+	if err := service.AddFavorite(2, 5); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Own ad must not be added, got %v", err)
+	}
+	if err := service.AddFavorite(2, 42); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+
+	// is_favorite в общей выборке и в карточке
+	page, err := service.GetAds(domain.AdFilter{ViewerID: 2})
+	if err != nil {
+		t.Fatalf("GetAds failed: %v", err)
+	}
+	for _, ad := range page.Items {
+		if ad.IsFavorite != (ad.ID <= 3) {
+			t.Errorf("ad %d: IsFavorite = %v", ad.ID, ad.IsFavorite)
+		}
+	}
+	if ad, _ := service.GetAd(1, 2); !ad.IsFavorite {
+		t.Error("GetAd should mark favorite ad")
+	}
+	if ad, _ := service.GetAd(1, 3); ad.IsFavorite {
+		t.Error("Favorites of another user must not be marked")
+	}
+
+	// удалённое и архивное объявления остаются в избранном
+	if _, err := service.ChangeStatus(1, 2, domain.AdStatusArchived); err != nil {
+		t.Fatal(err)
+	}
+	if err := service.DeleteAd(1, 3); err != nil {
+		t.Fatal(err)
+	}
+
+	page, err = service.GetFavorites(2, domain.AdFilter{SortBy: "price", Order: "asc"})
+	if err != nil {
+		t.Fatalf("GetFavorites failed: %v", err)
+	}
+	if page.Total != 3 || len(page.Items) != 3 {
+		t.Fatalf("Expected 3 favorites, got %d (total %d)", len(page.Items), page.Total)
+	}
+	reasons := []string{"", domain.UnavailableArchived, domain.UnavailableDeleted}
+	for i, ad := range page.Items {
+		if ad.ID != uint(i+1) || !ad.IsFavorite || ad.UnavailableReason() != reasons[i] {
+			t.Errorf("item %d: id %d, favorite %v, unavailable %q", i, ad.ID, ad.IsFavorite, ad.UnavailableReason())
+		}
+	}
+
+	// пагинация как в GetAds
+	page, err = service.GetFavorites(2, domain.AdFilter{Limit: 2})
+	if err != nil || len(page.Items) != 2 || page.NextCursor == "" || page.Items[0].ID != 3 {
+		t.Errorf("Expected first page of 2 newest favorites with cursor, got %+v (%v)", page, err)
+	}
+
+	// удалённое объявление можно убрать из избранного
+	if err := service.RemoveFavorite(2, 3); err != nil {
+		t.Fatal(err)
+	}
+	page, _ = service.GetFavorites(2, domain.AdFilter{})
+	if page.Total != 2 {
+		t.Errorf("Expected 2 favorites after removal, got %d", page.Total)
+	}
+}
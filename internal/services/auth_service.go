@@ -4,29 +4,70 @@ import (
 	"github.com/keenetic29/vk-internship/internal/domain"
 	pass "github.com/keenetic29/vk-internship/pkg/password"
 	"github.com/keenetic29/vk-internship/pkg/jwt"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 )
 
+const (
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	errInvalidRefreshToken = errors.New("invalid refresh token")
+	errTokenRevoked        = errors.New("token revoked")
+)
+
 type UserRepository interface {
 	Create(user *domain.User) error
 	GetByUsername(username string) (*domain.User, error)
+	GetByID(id uint) (*domain.User, error)
 	Exists(username string) (bool, error)
+	SetPassword(id uint, hash string) error
+}
+
+type TokenRepository interface {
+	CreateRefreshToken(token *domain.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*domain.RefreshToken, error)
+	RevokeRefreshToken(id uint) (bool, error)
+	RevokeRefreshFamily(familyID string) error
+	RevokeAccessToken(jti string, expiresAt time.Time) error
+	IsAccessTokenRevoked(jti string) (bool, error)
+	RevokeUserRefreshTokens(userID uint) error
 }
 
 type authService struct {
 	userRepo UserRepository
-	jwtSecret string
+	tokenRepo TokenRepository
+	keyring   *jwt.Keyring
 }
 
-func NewAuthService(userRepo UserRepository, jwtSecret string) *authService {
+// NewAuthService создаёт сервис авторизации. keyring может быть nil, если сервис только
+// управляет пользователями (CreateUser, ResetPassword): выдавать и проверять токены он тогда не может
+func NewAuthService(userRepo UserRepository, tokenRepo TokenRepository, keyring *jwt.Keyring) *authService {
 	return &authService{
 		userRepo: userRepo,
-		jwtSecret: jwtSecret,
+		tokenRepo: tokenRepo,
+		keyring:   keyring,
 	}
 }
 
 func (s *authService) Register(username, password string) (*domain.User, error) {
+	return s.CreateUser(username, password, domain.RoleUser)
+}
+
+// CreateUser регистрирует пользователя сразу с ролью role: роль записывается
+// той же вставкой, что и сам пользователь (нужно команде create-admin)
+func (s *authService) CreateUser(username, password string, role domain.Role) (*domain.User, error) {
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: unknown role %q", domain.ErrInvalidInput, role)
+	}
+
 	exists, err := s.userRepo.Exists(username)
 	if err != nil {
 		return nil, err
@@ -39,8 +80,8 @@ func (s *authService) Register(username, password string) (*domain.User, error)
 		return nil, errors.New("username must be between 3 and 20 characters")
 	}
 
-	if len(password) < 6 {
-		return nil, errors.New("password must be at least 6 characters")
+	if err := validatePassword(password); err != nil {
+		return nil, err
 	}
 
 	hashedPassword, err := pass.HashPassword(password)
@@ -51,6 +92,7 @@ func (s *authService) Register(username, password string) (*domain.User, error)
 	user := &domain.User{
 		Username: username,
 		Password: hashedPassword, 
+		Role:     role,
 	}
 
 	if err := s.userRepo.Create(user); err != nil {
@@ -60,29 +102,221 @@ func (s *authService) Register(username, password string) (*domain.User, error)
 	return user, nil
 }
 
-func (s *authService) Login(username, password string) (string, error) {
+func validatePassword(password string) error {
+	if len(password) < 6 {
+		return errors.New("password must be at least 6 characters")
+	}
+	return nil
+}
+
+// ResetPassword задаёт пользователю новый пароль и отзывает его refresh-токены.
+// Уже выданные access-токены действуют до истечения (AccessTokenTTL)
+func (s *authService) ResetPassword(userID uint, password string) error {
+	if err := validatePassword(password); err != nil {
+		return err
+	}
+
+	hashedPassword, err := pass.HashPassword(password)
+	if err != nil {
+		return errors.New("failed to hash password")
+	}
+
+	if err := s.userRepo.SetPassword(userID, hashedPassword); err != nil {
+		return err
+	}
+
+	return s.tokenRepo.RevokeUserRefreshTokens(userID)
+}
+
+func (s *authService) Login(username, password string) (*domain.TokenPair, error) {
 	user, err := s.userRepo.GetByUsername(username)
 	if err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
 	}
 
 	if err := pass.CheckPassword(password, user.Password); err != nil {
-		return "", errors.New("invalid credentials")
+		return nil, errors.New("invalid credentials")
+	}
+
+	// статус блокировки сообщаем только после проверки пароля
+	if user.Banned {
+		return nil, domain.ErrUserBanned
 	}
 
-	token, err := jwt.GenerateToken(user.ID, s.jwtSecret, 1*time.Hour)
+	return s.issueTokens(user, "")
+}
+
+func hashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// issueTokens выдаёт access-токен и новый refresh-токен в рамках цепочки familyID
+// (пустой familyID начинает новую сессию)
+func (s *authService) issueTokens(user *domain.User, familyID string) (*domain.TokenPair, error) {
+	accessToken, err := s.keyring.GenerateToken(user.ID, string(userRole(user)), AccessTokenTTL)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
 	}
+	refreshToken := base64.RawURLEncoding.EncodeToString(raw)
 
-	return token, nil
+	if familyID == "" {
+		if familyID, err = jwt.NewTokenID(); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.tokenRepo.CreateRefreshToken(&domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: hashRefreshToken(refreshToken),
+		FamilyID:  familyID,
+		ExpiresAt: time.Now().Add(RefreshTokenTTL),
+	}); err != nil {
+		return nil, err
+	}
+
+	return &domain.TokenPair{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    AccessTokenTTL,
+	}, nil
 }
 
-func (s *authService) ValidateToken(token string) (uint, error) {
-	claims, err := jwt.ParseToken(token, s.jwtSecret)
+// Refresh обменивает refresh-токен на новую пару (ротация: старый токен больше не действует)
+func (s *authService) Refresh(refreshToken string) (*domain.TokenPair, error) {
+	stored, err := s.tokenRepo.GetRefreshTokenByHash(hashRefreshToken(refreshToken))
 	if err != nil {
-		return 0, err
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, errInvalidRefreshToken
+		}
+		return nil, err
 	}
 
-	return claims.UserID, nil
+	// повторное использование уже заменённого токена - признак утечки, отзываем всю сессию
+	if stored.RevokedAt != nil {
+		if err := s.tokenRepo.RevokeRefreshFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidRefreshToken
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		return nil, errors.New("refresh token expired")
+	}
+
+	revoked, err := s.tokenRepo.RevokeRefreshToken(stored.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !revoked {
+		// токен успели использовать параллельно
+		if err := s.tokenRepo.RevokeRefreshFamily(stored.FamilyID); err != nil {
+			return nil, err
+		}
+		return nil, errInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetByID(stored.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Banned {
+		return nil, domain.ErrUserBanned
+	}
+
+	return s.issueTokens(user, stored.FamilyID)
+}
+
+// Logout отзывает access-токен и, если передан, всю цепочку refresh-токена
+func (s *authService) Logout(accessToken, refreshToken string) error {
+	claims, err := s.keyring.ParseToken(accessToken)
+	if err != nil {
+		return err
+	}
+
+	if claims.ID != "" && claims.ExpiresAt != nil {
+		if err := s.tokenRepo.RevokeAccessToken(claims.ID, claims.ExpiresAt.Time); err != nil {
+			return err
+		}
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := s.tokenRepo.GetRefreshTokenByHash(hashRefreshToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	// чужой refresh-токен не трогаем
+	if stored.UserID != claims.UserID {
+		return nil
+	}
+
+	return s.tokenRepo.RevokeRefreshFamily(stored.FamilyID)
+}
+
+// ValidateToken возвращает пользователя и его роль из токена.
+// Заблокированные пользователи отсекаются сразу, не дожидаясь истечения токена.
+func (s *authService) ValidateToken(token string) (uint, domain.Role, error) {
+	claims, err := s.keyring.ParseToken(token)
+	if err != nil {
+		return 0, "", err
+	}
+
+	// токены без jti невозможно отозвать, поэтому не принимаем их
+	if claims.ID == "" {
+		return 0, "", jwt.ErrInvalidToken
+	}
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(claims.ID)
+	if err != nil {
+		return 0, "", err
+	}
+	if revoked {
+		return 0, "", errTokenRevoked
+	}
+
+	user, err := s.userRepo.GetByID(claims.UserID)
+	if err != nil {
+		if errors.Is(err, domain.ErrNotFound) {
+			return 0, "", jwt.ErrInvalidToken
+		}
+		return 0, "", err
+	}
+	if user.Banned {
+		return 0, "", domain.ErrUserBanned
+	}
+
+	// роль берём из базы, а не из токена: понижение в правах действует сразу
+	return claims.UserID, userRole(user), nil
+}
+
+// TokenExpiresAt возвращает момент истечения access-токена; подпись проверяется,
+// отзыв и блокировку проверяет ValidateToken
+func (s *authService) TokenExpiresAt(token string) (time.Time, error) {
+	claims, err := s.keyring.ParseToken(token)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if claims.ExpiresAt == nil {
+		return time.Time{}, jwt.ErrInvalidToken
+	}
+	return claims.ExpiresAt.Time, nil
+}
+
+func userRole(user *domain.User) domain.Role {
+	if user.Role.Valid() {
+		return user.Role
+	}
+	return domain.RoleUser
 }
\ No newline at end of file
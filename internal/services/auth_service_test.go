@@ -2,8 +2,10 @@ package services
 
 import (
 	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/jwt"
 	"errors"
 	"testing"
+	"time"
 )
 
 type MockUserRepository struct {
@@ -14,6 +16,7 @@ func (m *MockUserRepository) Create(user *domain.User) error {
 	if _, exists := m.users[user.Username]; exists {
 		return errors.New("user already exists")
 	}
+	user.ID = uint(len(m.users) + 1)
 	m.users[user.Username] = user
 	return nil
 }
@@ -22,7 +25,16 @@ func (m *MockUserRepository) GetByUsername(username string) (*domain.User, error
 	if user, exists := m.users[username]; exists {
 		return user, nil
 	}
-	return nil, errors.New("user not found")
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockUserRepository) GetByID(id uint) (*domain.User, error) {
+	for _, user := range m.users {
+		if user.ID == id {
+			return user, nil
+		}
+	}
+	return nil, domain.ErrNotFound
 }
 
 func (m *MockUserRepository) Exists(username string) (bool, error) {
@@ -30,9 +42,83 @@ func (m *MockUserRepository) Exists(username string) (bool, error) {
 	return exists, nil
 }
 
+func (m *MockUserRepository) SetPassword(id uint, hash string) error {
+	user, err := m.GetByID(id)
+	if err != nil {
+		return err
+	}
+	user.Password = hash
+	return nil
+}
+
+type MockTokenRepository struct {
+	refreshTokens []*domain.RefreshToken
+	revoked       map[string]time.Time
+}
+
+func newMockTokenRepository() *MockTokenRepository {
+	return &MockTokenRepository{revoked: make(map[string]time.Time)}
+}
+
+func (m *MockTokenRepository) CreateRefreshToken(token *domain.RefreshToken) error {
+	token.ID = uint(len(m.refreshTokens) + 1)
+	m.refreshTokens = append(m.refreshTokens, token)
+	return nil
+}
+
+func (m *MockTokenRepository) GetRefreshTokenByHash(hash string) (*domain.RefreshToken, error) {
+	for _, token := range m.refreshTokens {
+		if token.TokenHash == hash {
+			copied := *token
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockTokenRepository) RevokeRefreshToken(id uint) (bool, error) {
+	for _, token := range m.refreshTokens {
+		if token.ID == id && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (m *MockTokenRepository) RevokeRefreshFamily(familyID string) error {
+	for _, token := range m.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			now := time.Now()
+			token.RevokedAt = &now
+		}
+	}
+	return nil
+}
+
+func (m *MockTokenRepository) RevokeAccessToken(jti string, expiresAt time.Time) error {
+	m.revoked[jti] = expiresAt
+	return nil
+}
+
+func (m *MockTokenRepository) IsAccessTokenRevoked(jti string) (bool, error) {
+	_, revoked := m.revoked[jti]
+	return revoked, nil
+}
+
+func testKeyring(t *testing.T) *jwt.Keyring {
+	t.Helper()
+	keyring, err := jwt.NewKeyring(jwt.DefaultHMACKeyID, jwt.NewHMACKey(jwt.DefaultHMACKeyID, []byte("test-secret")))
+	if err != nil {
+		t.Fatalf("NewKeyring failed: %v", err)
+	}
+	return keyring
+}
+
 func TestAuthService_Register(t *testing.T) {
 	repo := &MockUserRepository{users: make(map[string]*domain.User)}
-	service := NewAuthService(repo, "test-secret")
+	service := NewAuthService(repo, newMockTokenRepository(), testKeyring(t))
 
 	// Успешная регистрация
 	user, err := service.Register("testuser", "password123")
@@ -52,16 +138,37 @@ func TestAuthService_Register(t *testing.T) {
 	}
 }
 
+func TestAuthService_CreateUser(t *testing.T) {
+	repo := &MockUserRepository{users: make(map[string]*domain.User)}
+	service := NewAuthService(repo, newMockTokenRepository(), testKeyring(t))
+
+	// Неизвестная роль отклоняется до создания пользователя
+	if _, err := service.CreateUser("root", "password123", "superuser"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for unknown role, got %v", err)
+	}
+	if len(repo.users) != 0 {
+		t.Errorf("User must not be created with an unknown role, got %d users", len(repo.users))
+	}
+
+	user, err := service.CreateUser("root", "password123", domain.RoleAdmin)
+	if err != nil {
+		t.Fatalf("CreateUser failed: %v", err)
+	}
+	if user.Role != domain.RoleAdmin || repo.users["root"].Role != domain.RoleAdmin {
+		t.Errorf("User must be stored with role admin, got %q", repo.users["root"].Role)
+	}
+}
+
 func TestAuthService_Login(t *testing.T) {
 	repo := &MockUserRepository{users: make(map[string]*domain.User)}
-	service := NewAuthService(repo, "test-secret")
+	service := NewAuthService(repo, newMockTokenRepository(), testKeyring(t))
 
 	// Предварительно регистрируем пользователя
 	_, _ = service.Register("testuser", "password123")
 
 	// Успешный логин
-	token, err := service.Login("testuser", "password123")
-	if err != nil || token == "" {
+	tokens, err := service.Login("testuser", "password123")
+	if err != nil || tokens.AccessToken == "" || tokens.RefreshToken == "" {
 		t.Error("Valid login should succeed")
 	}
 
@@ -76,4 +183,140 @@ func TestAuthService_Login(t *testing.T) {
 	if err == nil {
 		t.Error("Non-existent user should fail")
 	}
-}
\ No newline at end of file
+
+	// Роль попадает в токен
+	_, role, err := service.ValidateToken(tokens.AccessToken)
+	if err != nil || role != domain.RoleUser {
+		t.Errorf("Expected role %q, got %q (%v)", domain.RoleUser, role, err)
+	}
+
+	// Срок действия токена нужен потоку событий, чтобы закрыть соединение вовремя
+	expiresAt, err := service.TokenExpiresAt(tokens.AccessToken)
+	if err != nil || expiresAt.Sub(time.Now().Add(AccessTokenTTL)).Abs() > time.Minute {
+		t.Errorf("Expected expiry in %v, got %v (%v)", AccessTokenTTL, expiresAt, err)
+	}
+	if _, err := service.TokenExpiresAt("garbage"); err == nil {
+		t.Error("Malformed token should have no expiry")
+	}
+
+	// Смена роли действует на уже выданные токены
+	repo.users["testuser"].Role = domain.RoleAdmin
+	if _, role, _ := service.ValidateToken(tokens.AccessToken); role != domain.RoleAdmin {
+		t.Errorf("Expected promoted role %q, got %q", domain.RoleAdmin, role)
+	}
+	repo.users["testuser"].Role = domain.RoleUser
+	if _, role, _ := service.ValidateToken(tokens.AccessToken); role != domain.RoleUser {
+		t.Errorf("Demoted user kept role %q", role)
+	}
+
+	// Заблокированный пользователь не может войти, а выданные токены перестают действовать
+	repo.users["testuser"].Banned = true
+	if _, err := service.Login("testuser", "password123"); !errors.Is(err, domain.ErrUserBanned) {
+		t.Errorf("Expected ErrUserBanned, got %v", err)
+	}
+	if _, _, err := service.ValidateToken(tokens.AccessToken); !errors.Is(err, domain.ErrUserBanned) {
+		t.Errorf("Banned user's token should be rejected, got %v", err)
+	}
+	if _, err := service.Refresh(tokens.RefreshToken); !errors.Is(err, domain.ErrUserBanned) {
+		t.Errorf("Banned user should not refresh tokens, got %v", err)
+	}
+}
+
+func TestAuthService_Refresh(t *testing.T) {
+	repo := &MockUserRepository{users: make(map[string]*domain.User)}
+	tokenRepo := newMockTokenRepository()
+	service := NewAuthService(repo, tokenRepo, testKeyring(t))
+
+	_, _ = service.Register("testuser", "password123")
+	tokens, err := service.Login("testuser", "password123")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+
+	// Ротация: выдаётся новая пара, старый refresh-токен больше не действует
+	rotated, err := service.Refresh(tokens.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if rotated.RefreshToken == tokens.RefreshToken {
+		t.Error("Refresh token should be rotated")
+	}
+	if _, _, err := service.ValidateToken(rotated.AccessToken); err != nil {
+		t.Errorf("New access token should be valid: %v", err)
+	}
+
+	// Повторное использование старого токена отзывает всю цепочку
+	if _, err := service.Refresh(tokens.RefreshToken); err == nil {
+		t.Error("Reused refresh token should fail")
+	}
+	if _, err := service.Refresh(rotated.RefreshToken); err == nil {
+		t.Error("Whole token family should be revoked after reuse")
+	}
+
+	if _, err := service.Refresh("unknown"); err == nil {
+		t.Error("Unknown refresh token should fail")
+	}
+
+	// Истёкший токен
+	expired, _ := service.Login("testuser", "password123")
+	for _, token := range tokenRepo.refreshTokens {
+		if token.TokenHash == hashRefreshToken(expired.RefreshToken) {
+			token.ExpiresAt = time.Now().Add(-time.Minute)
+		}
+	}
+	if _, err := service.Refresh(expired.RefreshToken); err == nil {
+		t.Error("Expired refresh token should fail")
+	}
+}
+
+func TestAuthService_Logout(t *testing.T) {
+	repo := &MockUserRepository{users: make(map[string]*domain.User)}
+	service := NewAuthService(repo, newMockTokenRepository(), testKeyring(t))
+
+	_, _ = service.Register("testuser", "password123")
+	tokens, _ := service.Login("testuser", "password123")
+
+	if _, _, err := service.ValidateToken(tokens.AccessToken); err != nil {
+		t.Fatalf("Token should be valid before logout: %v", err)
+	}
+
+	if err := service.Logout(tokens.AccessToken, tokens.RefreshToken); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+
+	if _, _, err := service.ValidateToken(tokens.AccessToken); err == nil {
+		t.Error("Access token should be revoked after logout")
+	}
+	if _, err := service.Refresh(tokens.RefreshToken); err == nil {
+		t.Error("Refresh token should be revoked after logout")
+	}
+}
+
+func TestAuthService_ResetPassword(t *testing.T) {
+	repo := &MockUserRepository{users: make(map[string]*domain.User)}
+	service := NewAuthService(repo, newMockTokenRepository(), testKeyring(t))
+
+	user, _ := service.Register("testuser", "password123")
+	tokens, _ := service.Login("testuser", "password123")
+
+	if err := service.ResetPassword(user.ID, "123"); err == nil {
+		t.Error("Short password should be rejected")
+	}
+
+	if err := service.ResetPassword(user.ID, "newpassword"); err != nil {
+		t.Fatalf("ResetPassword failed: %v", err)
+	}
+	if _, err := service.Login("testuser", "password123"); err == nil {
+		t.Error("Old password should not work after reset")
+	}
+	if _, err := service.Login("testuser", "newpassword"); err != nil {
+		t.Errorf("New password should work: %v", err)
+	}
+	if _, err := service.Refresh(tokens.RefreshToken); err == nil {
+		t.Error("Sessions should be revoked after password reset")
+	}
+
+	if err := service.ResetPassword(42, "newpassword"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"fmt"
+)
+
+type CategoryRepository interface {
+	GetAll() ([]domain.Category, error)
+	GetByID(id uint) (*domain.Category, error)
+}
+
+type categoryService struct {
+	categoryRepo CategoryRepository
+}
+
+func NewCategoryService(categoryRepo CategoryRepository) *categoryService {
+	return &categoryService{categoryRepo: categoryRepo}
+}
+
+// GetTree возвращает корневые категории с вложенными подкатегориями
+func (s *categoryService) GetTree() ([]domain.Category, error) {
+	categories, err := s.categoryRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCategoryTree(categories), nil
+}
+
+func buildCategoryTree(categories []domain.Category) []domain.Category {
+	children := make(map[uint][]domain.Category)
+	var roots []domain.Category
+
+	for _, category := range categories {
+		category.Children = nil
+		if category.ParentID == nil {
+			roots = append(roots, category)
+		} else {
+			children[*category.ParentID] = append(children[*category.ParentID], category)
+		}
+	}
+
+	var attach func(nodes []domain.Category, visited map[uint]bool) []domain.Category
+	attach = func(nodes []domain.Category, visited map[uint]bool) []domain.Category {
+		for i := range nodes {
+			// защита от циклов в некорректных данных
+			if visited[nodes[i].ID] {
+				continue
+			}
+			visited[nodes[i].ID] = true
+			nodes[i].Children = attach(children[nodes[i].ID], visited)
+		}
+		return nodes
+	}
+
+	return attach(roots, make(map[uint]bool))
+}
+
+// expandCategory возвращает категорию categoryID вместе с подкатегориями для фильтра выборки
+// (nil, если категория не задана)
+func expandCategory(repo CategoryRepository, categoryID uint) ([]uint, error) {
+	if categoryID == 0 {
+		return nil, nil
+	}
+
+	categories, err := repo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		if category.ID == categoryID {
+			return descendantIDs(categories, categoryID), nil
+		}
+	}
+	return nil, fmt.Errorf("%w: category not found", domain.ErrInvalidInput)
+}
+
+// descendantIDs возвращает rootID и ID всех его потомков
+func descendantIDs(categories []domain.Category, rootID uint) []uint {
+	children := make(map[uint][]uint)
+	for _, category := range categories {
+		if category.ParentID != nil {
+			children[*category.ParentID] = append(children[*category.ParentID], category.ID)
+		}
+	}
+
+	ids := []uint{rootID}
+	visited := map[uint]bool{rootID: true}
+	for i := 0; i < len(ids); i++ {
+		for _, child := range children[ids[i]] {
+			if !visited[child] {
+				visited[child] = true
+				ids = append(ids, child)
+			}
+		}
+	}
+
+	return ids
+}
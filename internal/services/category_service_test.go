@@ -0,0 +1,83 @@
+package services
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"sort"
+	"testing"
+)
+
+type MockCategoryRepository struct {
+	categories []domain.Category
+}
+
+func (m *MockCategoryRepository) GetAll() ([]domain.Category, error) {
+	return m.categories, nil
+}
+
+func (m *MockCategoryRepository) GetByID(id uint) (*domain.Category, error) {
+	for _, category := range m.categories {
+		if category.ID == id {
+			return &category, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func uintPtr(v uint) *uint {
+	return &v
+}
+
+// Электроника(1) -> Телефоны(2) -> Смартфоны(4); Электроника(1) -> Ноутбуки(3); Мебель(5)
+func testCategories() *MockCategoryRepository {
+	return &MockCategoryRepository{categories: []domain.Category{
+		{ID: 1, Name: "Электроника", Slug: "electronics"},
+		{ID: 2, Name: "Телефоны", Slug: "phones", ParentID: uintPtr(1)},
+		{ID: 3, Name: "Ноутбуки", Slug: "laptops", ParentID: uintPtr(1)},
+		{ID: 4, Name: "Смартфоны", Slug: "smartphones", ParentID: uintPtr(2)},
+		{ID: 5, Name: "Мебель", Slug: "furniture"},
+	}}
+}
+
+func TestCategoryService_GetTree(t *testing.T) {
+	service := NewCategoryService(testCategories())
+
+	tree, err := service.GetTree()
+	if err != nil {
+		t.Fatalf("GetTree failed: %v", err)
+	}
+
+	if len(tree) != 2 || tree[0].Slug != "electronics" || tree[1].Slug != "furniture" {
+		t.Fatalf("Unexpected roots: %+v", tree)
+	}
+
+	electronics := tree[0]
+	if len(electronics.Children) != 2 {
+		t.Fatalf("Expected 2 children of electronics, got %d", len(electronics.Children))
+	}
+
+	phones := electronics.Children[0]
+	if phones.Slug != "phones" || len(phones.Children) != 1 || phones.Children[0].Slug != "smartphones" {
+		t.Errorf("Unexpected phones subtree: %+v", phones)
+	}
+}
+
+func TestDescendantIDs(t *testing.T) {
+	categories := testCategories().categories
+
+	ids := descendantIDs(categories, 1)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	expected := []uint{1, 2, 3, 4}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, ids)
+		}
+	}
+
+	if ids := descendantIDs(categories, 5); len(ids) != 1 || ids[0] != 5 {
+		t.Errorf("Leaf category should contain only itself, got %v", ids)
+	}
+}
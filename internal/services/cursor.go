@@ -0,0 +1,63 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// Курсор - base64 от JSON с ключом сортировки и ID последнего объявления страницы.
+// Для клиента он непрозрачен и действителен только для той сортировки, в которой выдан.
+
+func encodeAdCursor(ad domain.Advertisement, sortBy, order string) string {
+	cursor := domain.AdCursor{SortBy: sortBy, Order: order, ID: ad.ID}
+	switch sortBy {
+	case "price":
+		cursor.Price = ad.Price
+	default:
+		cursor.CreatedAt = ad.CreatedAt
+	}
+
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeAdCursor(raw, sortBy, order string) (*domain.AdCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidInput)
+	}
+
+	var cursor domain.AdCursor
+	if err := json.Unmarshal(data, &cursor); err != nil || cursor.ID == 0 {
+		return nil, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidInput)
+	}
+
+	if cursor.SortBy != sortBy || cursor.Order != order {
+		return nil, fmt.Errorf("%w: cursor was issued for a different sort order", domain.ErrInvalidInput)
+	}
+
+	return &cursor, nil
+}
+
+// Курсор сообщений - base64 от ID самого старого сообщения страницы
+
+func encodeMessageCursor(msg domain.Message) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatUint(uint64(msg.ID), 10)))
+}
+
+func decodeMessageCursor(raw string) (uint, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidInput)
+	}
+
+	id, err := strconv.ParseUint(string(data), 10, 64)
+	if err != nil || id == 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", domain.ErrInvalidInput)
+	}
+	return uint(id), nil
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"github.com/keenetic29/vk-internship/pkg/events"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+// EventPublisher доставляет события подписчикам GET /stream
+type EventPublisher interface {
+	Publish(event events.Event) error
+}
+
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(events.Event) error { return nil }
+
+// publish отправляет событие после успешной операции. Доставка не гарантируется,
+// поэтому ошибка только логируется и не влияет на результат запроса.
+func publish(publisher EventPublisher, event events.Event) {
+	if err := publisher.Publish(event); err != nil {
+		logger.Log.Warn("Failed to publish event",
+			"type", event.Type,
+			"user_id", event.UserID,
+			"error", err,
+		)
+	}
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/events"
+)
+
+type MockEventPublisher struct {
+	events []events.Event
+}
+
+func (m *MockEventPublisher) Publish(event events.Event) error {
+	m.events = append(m.events, event)
+	return nil
+}
+
+func (m *MockEventPublisher) types() []string {
+	var types []string
+	for _, event := range m.events {
+		types = append(types, event.Type)
+	}
+	return types
+}
+
+func TestAdvertisementService_PublishesAdCreated(t *testing.T) {
+	repo := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 5, UserID: 1, Title: "Самокат", Price: 50, CategoryID: 2, Status: domain.AdStatusDraft},
+	}}
+	service := NewAdvertisementService(repo, testCategories(), repo)
+	publisher := &MockEventPublisher{}
+	service.SetEventPublisher(publisher)
+
+	if _, err := service.CreateAd(1, "Велосипед", "Горный велосипед", nil, 100, 2, ""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.CreateAd(1, "Ролики", "Детские ролики", nil, 50, 2, domain.AdStatusDraft); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.events) != 1 {
+		t.Fatalf("Draft must not be announced, got %v", publisher.types())
+	}
+
+	if _, err := service.ChangeStatus(1, 5, domain.AdStatusPublished); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ChangeStatus(1, 5, domain.AdStatusReserved); err != nil {
+		t.Fatal(err)
+	}
+	if len(publisher.events) != 2 {
+		t.Fatalf("Expected events for creation and publication only, got %v", publisher.types())
+	}
+
+	event := publisher.events[1]
+	var data domain.AdCreatedEvent
+	if err := json.Unmarshal(event.Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if event.Type != domain.EventAdCreated || event.UserID != 0 || data.ID != 5 || data.Title != "Самокат" {
+		t.Errorf("Unexpected event %+v with data %+v", event, data)
+	}
+}
+
+func TestMessagingService_PublishesToRecipient(t *testing.T) {
+	service, _, _, _ := newTestMessagingService()
+	publisher := &MockEventPublisher{}
+	service.SetEventPublisher(publisher)
+
+	msg, err := service.SendToAd(2, 1, strings.Repeat("я", domain.MessageEventPreviewLength+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Reply(1, msg.ConversationID, "Да"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(publisher.events) != 2 || publisher.events[0].UserID != 1 || publisher.events[1].UserID != 2 {
+		t.Fatalf("Each message must be delivered to the other participant, got %+v", publisher.events)
+	}
+
+	var data domain.MessageEvent
+	if err := json.Unmarshal(publisher.events[0].Data, &data); err != nil {
+		t.Fatal(err)
+	}
+	if !data.Truncated || len([]rune(data.Text)) != domain.MessageEventPreviewLength || data.AdID != 1 {
+		t.Errorf("Long message must be truncated in the event, got %d runes", len([]rune(data.Text)))
+	}
+}
+
+func TestSavedSearchService_PublishesNotifications(t *testing.T) {
+	now := time.Now()
+	ads := &MockAdRepository{}
+	repo := &MockSavedSearchRepository{}
+	service := NewSavedSearchService(repo, ads, testCategories())
+	service.now = func() time.Time { return now }
+	publisher := &MockEventPublisher{}
+	service.SetEventPublisher(publisher)
+
+	if _, err := service.CreateSavedSearch(1, "Всё", domain.AdFilter{}); err != nil {
+		t.Fatal(err)
+	}
+	publishedAt := now.Add(time.Minute)
+	ads.ads = append(ads.ads, &domain.Advertisement{ID: 1, UserID: 2, Title: "Диван", Price: 10, CategoryID: 5,
+		Status: domain.AdStatusPublished, CreatedAt: publishedAt, PublishedAt: &publishedAt})
+	now = now.Add(time.Hour)
+
+	service.EvaluateSavedSearches()
+	service.EvaluateSavedSearches()
+
+	if len(publisher.events) != 1 || publisher.events[0].UserID != 1 || publisher.events[0].Type != domain.EventNotification {
+		t.Errorf("Expected a single notification event for user 1, got %+v", publisher.events)
+	}
+}
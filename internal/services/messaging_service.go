@@ -0,0 +1,296 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/events"
+)
+
+type ConversationRepository interface {
+	FindOrCreate(conv *domain.Conversation) error
+	GetByID(id uint) (*domain.Conversation, error)
+	ListByUser(userID uint, offset, limit int) ([]domain.Conversation, error)
+	CountByUser(userID uint) (int64, error)
+	UnreadTotal(userID uint) (int64, error)
+	AddMessage(conv *domain.Conversation, msg *domain.Message) error
+	ListMessages(conversationID, beforeID uint, limit int) ([]domain.Message, error)
+	MarkRead(conv *domain.Conversation, userID, upToID uint) error
+}
+
+// AdLookup - объявление, к которому привязывается переписка
+type AdLookup interface {
+	GetByID(id uint) (*domain.Advertisement, error)
+}
+
+// UserLookup нужен для проверки бана участников и поиска блокируемого пользователя
+type UserLookup interface {
+	GetByID(id uint) (*domain.User, error)
+	GetByUsername(username string) (*domain.User, error)
+}
+
+// BlockRepository - личные блокировки пользователей
+type BlockRepository interface {
+	Block(blockerID, blockedID uint) error
+	Unblock(blockerID, blockedID uint) error
+	IsBlocked(userA, userB uint) (bool, error)
+}
+
+type messagingService struct {
+	convRepo ConversationRepository
+	ads      AdLookup
+	users    UserLookup
+	blocks   BlockRepository
+	events   EventPublisher
+	now      func() time.Time
+}
+
+func NewMessagingService(convRepo ConversationRepository, ads AdLookup, users UserLookup, blocks BlockRepository) *messagingService {
+	return &messagingService{
+		convRepo: convRepo,
+		ads:      ads,
+		users:    users,
+		blocks:   blocks,
+		events:   noopPublisher{},
+		now:      time.Now,
+	}
+}
+
+// SetEventPublisher включает отправку событий о новых сообщениях получателям
+func (s *messagingService) SetEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+}
+
+func normalizeMessage(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", fmt.Errorf("%w: message is empty", domain.ErrInvalidInput)
+	}
+	if utf8.RuneCountInString(body) > domain.MaxMessageLength {
+		return "", fmt.Errorf("%w: message must be at most %d characters", domain.ErrInvalidInput, domain.MaxMessageLength)
+	}
+	return body, nil
+}
+
+// checkCanMessage запрещает переписку, если любой из участников забанен администратором
+// или один из них заблокировал другого
+func (s *messagingService) checkCanMessage(senderID, recipientID uint) error {
+	for _, id := range []uint{senderID, recipientID} {
+		user, err := s.users.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if user.Banned {
+			return fmt.Errorf("%w: user is banned", domain.ErrForbidden)
+		}
+	}
+
+	blocked, err := s.blocks.IsBlocked(senderID, recipientID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return fmt.Errorf("%w: user is blocked", domain.ErrForbidden)
+	}
+	return nil
+}
+
+// BlockUser запрещает переписку между userID и пользователем username в обе стороны
+func (s *messagingService) BlockUser(userID uint, username string) error {
+	target, err := s.users.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	if target.ID == userID {
+		return fmt.Errorf("%w: cannot block yourself", domain.ErrInvalidInput)
+	}
+	return s.blocks.Block(userID, target.ID)
+}
+
+// UnblockUser снимает блокировку, поставленную userID; снять чужую нельзя
+func (s *messagingService) UnblockUser(userID uint, username string) error {
+	target, err := s.users.GetByUsername(username)
+	if err != nil {
+		return err
+	}
+	return s.blocks.Unblock(userID, target.ID)
+}
+
+// SendToAd отправляет сообщение автору активного объявления от имени покупателя,
+// начиная переписку, если её ещё нет
+func (s *messagingService) SendToAd(senderID, adID uint, body string) (*domain.Message, error) {
+	body, err := normalizeMessage(body)
+	if err != nil {
+		return nil, err
+	}
+
+	ad, err := s.ads.GetByID(adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.UserID == senderID {
+		return nil, fmt.Errorf("%w: cannot start a conversation on your own advertisement", domain.ErrInvalidInput)
+	}
+	switch ad.Status {
+	case domain.AdStatusDraft:
+		return nil, domain.ErrNotFound
+	case domain.AdStatusPublished, domain.AdStatusReserved:
+	default:
+		// продолжить уже начатую переписку можно через Reply
+		return nil, fmt.Errorf("%w: advertisement is not available", domain.ErrInvalidInput)
+	}
+
+	if err := s.checkCanMessage(senderID, ad.UserID); err != nil {
+		return nil, err
+	}
+
+	conv := &domain.Conversation{
+		AdID:          ad.ID,
+		BuyerID:       senderID,
+		SellerID:      ad.UserID,
+		LastMessageAt: s.now(),
+	}
+	if err := s.convRepo.FindOrCreate(conv); err != nil {
+		return nil, err
+	}
+
+	return s.send(conv, senderID, body)
+}
+
+// Reply отправляет сообщение в существующую переписку от имени любого её участника
+func (s *messagingService) Reply(senderID, conversationID uint, body string) (*domain.Message, error) {
+	body, err := normalizeMessage(body)
+	if err != nil {
+		return nil, err
+	}
+
+	conv, err := s.getConversation(senderID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkCanMessage(senderID, conv.CounterpartID(senderID)); err != nil {
+		return nil, err
+	}
+
+	return s.send(conv, senderID, body)
+}
+
+func (s *messagingService) send(conv *domain.Conversation, senderID uint, body string) (*domain.Message, error) {
+	msg := &domain.Message{
+		SenderID:  senderID,
+		Body:      body,
+		CreatedAt: s.now(),
+	}
+	if err := s.convRepo.AddMessage(conv, msg); err != nil {
+		return nil, err
+	}
+
+	text, truncated := body, false
+	if runes := []rune(body); len(runes) > domain.MessageEventPreviewLength {
+		text, truncated = string(runes[:domain.MessageEventPreviewLength]), true
+	}
+	publish(s.events, events.New(domain.EventMessage, conv.CounterpartID(senderID), domain.MessageEvent{
+		ID:             msg.ID,
+		ConversationID: conv.ID,
+		AdID:           conv.AdID,
+		SenderID:       senderID,
+		Text:           text,
+		Truncated:      truncated,
+		CreatedAt:      msg.CreatedAt,
+	}))
+
+	return msg, nil
+}
+
+// getConversation возвращает переписку, только если userID в ней участвует;
+// чужие переписки неотличимы от несуществующих
+func (s *messagingService) getConversation(userID, conversationID uint) (*domain.Conversation, error) {
+	conv, err := s.convRepo.GetByID(conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !conv.IsParticipant(userID) {
+		return nil, domain.ErrNotFound
+	}
+	return conv, nil
+}
+
+// ListConversations возвращает переписки пользователя, сначала с последними сообщениями
+func (s *messagingService) ListConversations(userID uint, page, limit int) (*domain.ConversationPage, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	items, err := s.convRepo.ListByUser(userID, (page-1)*limit, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.convRepo.CountByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	unread, err := s.convRepo.UnreadTotal(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.ConversationPage{
+		Items:  items,
+		Total:  total,
+		Unread: unread,
+		Page:   page,
+		Limit:  limit,
+	}, nil
+}
+
+// GetMessages возвращает страницу сообщений переписки (новые первыми)
+// и отмечает прочитанными для userID сообщения до самого нового на странице
+func (s *messagingService) GetMessages(userID, conversationID uint, cursor string, limit int) (*domain.MessagePage, error) {
+	if limit < 1 || limit > 100 {
+		limit = 50
+	}
+
+	var beforeID uint
+	if cursor != "" {
+		var err error
+		if beforeID, err = decodeMessageCursor(cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	conv, err := s.getConversation(userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// на одно сообщение больше, чтобы узнать, есть ли следующая страница
+	messages, err := s.convRepo.ListMessages(conv.ID, beforeID, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.MessagePage{Items: messages}
+	if len(messages) > limit {
+		page.Items = messages[:limit]
+		page.NextCursor = encodeMessageCursor(page.Items[limit-1])
+	}
+
+	// прочитанным считается только отданное: сообщения новее первого на странице
+	// (в том числе пришедшие после выборки) остаются непрочитанными
+	if conv.UnreadFor(userID) > 0 && len(page.Items) > 0 {
+		if err := s.convRepo.MarkRead(conv, userID, page.Items[0].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,357 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type MockConversationRepository struct {
+	conversations []*domain.Conversation
+	messages      []domain.Message
+	// afterList вызывается после выборки сообщений, имитируя параллельную отправку
+	afterList func()
+}
+
+func (m *MockConversationRepository) FindOrCreate(conv *domain.Conversation) error {
+	for _, existing := range m.conversations {
+		if existing.AdID == conv.AdID && existing.BuyerID == conv.BuyerID {
+			*conv = *existing
+			return nil
+		}
+	}
+	conv.ID = uint(len(m.conversations) + 1)
+	conv.CreatedAt = conv.LastMessageAt
+	copied := *conv
+	m.conversations = append(m.conversations, &copied)
+	return nil
+}
+
+func (m *MockConversationRepository) GetByID(id uint) (*domain.Conversation, error) {
+	for _, conv := range m.conversations {
+		if conv.ID == id {
+			copied := *conv
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockConversationRepository) ListByUser(userID uint, offset, limit int) ([]domain.Conversation, error) {
+	var result []domain.Conversation
+	for i := len(m.conversations) - 1; i >= 0; i-- {
+		if m.conversations[i].IsParticipant(userID) {
+			result = append(result, *m.conversations[i])
+		}
+	}
+	if offset >= len(result) {
+		return nil, nil
+	}
+	result = result[offset:]
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+func (m *MockConversationRepository) CountByUser(userID uint) (int64, error) {
+	var count int64
+	for _, conv := range m.conversations {
+		if conv.IsParticipant(userID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *MockConversationRepository) UnreadTotal(userID uint) (int64, error) {
+	var total int64
+	for _, conv := range m.conversations {
+		if conv.IsParticipant(userID) {
+			total += int64(conv.UnreadFor(userID))
+		}
+	}
+	return total, nil
+}
+
+func (m *MockConversationRepository) AddMessage(conv *domain.Conversation, msg *domain.Message) error {
+	msg.ID = uint(len(m.messages) + 1)
+	msg.ConversationID = conv.ID
+	m.messages = append(m.messages, *msg)
+
+	stored := m.conversations[conv.ID-1]
+	stored.LastMessageAt = msg.CreatedAt
+	if msg.SenderID == stored.BuyerID {
+		stored.SellerUnread++
+	} else {
+		stored.BuyerUnread++
+	}
+	return nil
+}
+
+func (m *MockConversationRepository) ListMessages(conversationID, beforeID uint, limit int) ([]domain.Message, error) {
+	var result []domain.Message
+	for i := len(m.messages) - 1; i >= 0 && len(result) < limit; i-- {
+		msg := m.messages[i]
+		if msg.ConversationID == conversationID && (beforeID == 0 || msg.ID < beforeID) {
+			result = append(result, msg)
+		}
+	}
+	if m.afterList != nil {
+		m.afterList()
+	}
+	return result, nil
+}
+
+func (m *MockConversationRepository) MarkRead(conv *domain.Conversation, userID, upToID uint) error {
+	newer := 0
+	for _, msg := range m.messages {
+		if msg.ConversationID == conv.ID && msg.SenderID != userID && msg.ID > upToID {
+			newer++
+		}
+	}
+	stored := m.conversations[conv.ID-1]
+	if userID == stored.BuyerID {
+		stored.BuyerUnread = min(stored.BuyerUnread, newer)
+	} else {
+		stored.SellerUnread = min(stored.SellerUnread, newer)
+	}
+	return nil
+}
+
+type blockPair struct {
+	blockerID, blockedID uint
+}
+
+type MockBlockRepository struct {
+	blocks map[blockPair]bool
+}
+
+func (m *MockBlockRepository) Block(blockerID, blockedID uint) error {
+	if m.blocks == nil {
+		m.blocks = make(map[blockPair]bool)
+	}
+	m.blocks[blockPair{blockerID, blockedID}] = true
+	return nil
+}
+
+func (m *MockBlockRepository) Unblock(blockerID, blockedID uint) error {
+	delete(m.blocks, blockPair{blockerID, blockedID})
+	return nil
+}
+
+func (m *MockBlockRepository) IsBlocked(userA, userB uint) (bool, error) {
+	return m.blocks[blockPair{userA, userB}] || m.blocks[blockPair{userB, userA}], nil
+}
+
+func newTestMessagingService() (*messagingService, *MockConversationRepository, *MockAdRepository, *MockUserRepository) {
+	convRepo := &MockConversationRepository{}
+	ads := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, UserID: 1, Title: "Велосипед", Status: domain.AdStatusPublished},
+		{ID: 2, UserID: 1, Title: "Черновик", Status: domain.AdStatusDraft},
+		{ID: 3, UserID: 1, Title: "Продано", Status: domain.AdStatusSold},
+	}}
+	users := &MockUserRepository{users: map[string]*domain.User{
+		"seller":  {ID: 1, Username: "seller"},
+		"buyer":   {ID: 2, Username: "buyer"},
+		"other":   {ID: 3, Username: "other"},
+		"spammer": {ID: 4, Username: "spammer", Banned: true},
+	}}
+
+	service := NewMessagingService(convRepo, ads, users, &MockBlockRepository{})
+	clock := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	service.now = func() time.Time {
+		clock = clock.Add(time.Second)
+		return clock
+	}
+	return service, convRepo, ads, users
+}
+
+func TestMessagingService_Send(t *testing.T) {
+	service, convRepo, _, users := newTestMessagingService()
+
+	first, err := service.SendToAd(2, 1, "  Ещё продаёте?  ")
+	if err != nil {
+		t.Fatalf("SendToAd failed: %v", err)
+	}
+	if first.Body != "Ещё продаёте?" {
+		t.Errorf("Message must be trimmed, got %q", first.Body)
+	}
+
+	// второе сообщение покупателя попадает в ту же переписку
+	second, err := service.SendToAd(2, 1, "Могу забрать сегодня")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.ConversationID != first.ConversationID || len(convRepo.conversations) != 1 {
+		t.Errorf("Expected a single conversation, got %d", len(convRepo.conversations))
+	}
+
+	if _, err := service.Reply(1, first.ConversationID, "Да, приезжайте"); err != nil {
+		t.Fatalf("Seller reply failed: %v", err)
+	}
+
+	conv := convRepo.conversations[0]
+	if conv.SellerUnread != 2 || conv.BuyerUnread != 1 || conv.SellerID != 1 || conv.BuyerID != 2 {
+		t.Errorf("Unexpected conversation state: %+v", conv)
+	}
+
+	tests := []struct {
+		name     string
+		send     func() error
+		expected error
+	}{
+		{"Own ad", func() error { _, err := service.SendToAd(1, 1, "Привет"); return err }, domain.ErrInvalidInput},
+		{"Draft", func() error { _, err := service.SendToAd(2, 2, "Привет"); return err }, domain.ErrNotFound},
+		{"Sold ad", func() error { _, err := service.SendToAd(3, 3, "Привет"); return err }, domain.ErrInvalidInput},
+		{"Unknown ad", func() error { _, err := service.SendToAd(2, 42, "Привет"); return err }, domain.ErrNotFound},
+		{"Empty message", func() error { _, err := service.SendToAd(2, 1, "   "); return err }, domain.ErrInvalidInput},
+		{"Too long", func() error { _, err := service.SendToAd(2, 1, makeString(domain.MaxMessageLength+1)); return err }, domain.ErrInvalidInput},
+		{"Banned sender", func() error { _, err := service.SendToAd(4, 1, "Привет"); return err }, domain.ErrForbidden},
+		{"Foreign conversation", func() error { _, err := service.Reply(3, first.ConversationID, "Привет"); return err }, domain.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.send(); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// после блокировки покупателя продавец не может ему ответить
+	users.users["buyer"].Banned = true
+	if _, err := service.Reply(1, first.ConversationID, "Вы здесь?"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Reply to banned user: expected ErrForbidden, got %v", err)
+	}
+}
+
+func TestMessagingService_Block(t *testing.T) {
+	service, _, _, _ := newTestMessagingService()
+
+	msg, err := service.SendToAd(2, 1, "Ещё продаёте?")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := service.BlockUser(1, "seller"); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Blocking yourself: expected ErrInvalidInput, got %v", err)
+	}
+	if err := service.BlockUser(1, "ghost"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Blocking unknown user: expected ErrNotFound, got %v", err)
+	}
+
+	// продавец блокирует покупателя: писать нельзя ни одному из них
+	if err := service.BlockUser(1, "buyer"); err != nil {
+		t.Fatalf("BlockUser failed: %v", err)
+	}
+	if _, err := service.SendToAd(2, 1, "Ответьте!"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Blocked buyer SendToAd: expected ErrForbidden, got %v", err)
+	}
+	if _, err := service.Reply(2, msg.ConversationID, "Ответьте!"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Blocked buyer Reply: expected ErrForbidden, got %v", err)
+	}
+	if _, err := service.Reply(1, msg.ConversationID, "Нет"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Blocker Reply: expected ErrForbidden, got %v", err)
+	}
+	// блокировка касается только этой пары
+	if _, err := service.SendToAd(3, 1, "Здравствуйте"); err != nil {
+		t.Errorf("Other user must still be able to write, got %v", err)
+	}
+
+	// снять блокировку может только тот, кто её поставил
+	if err := service.UnblockUser(2, "seller"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Reply(2, msg.ConversationID, "Ответьте!"); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Block must survive unblock by the blocked user, got %v", err)
+	}
+	if err := service.UnblockUser(1, "buyer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Reply(2, msg.ConversationID, "Ответьте!"); err != nil {
+		t.Errorf("Reply after unblock failed: %v", err)
+	}
+}
+
+func TestMessagingService_ReadAndPaginate(t *testing.T) {
+	service, convRepo, _, _ := newTestMessagingService()
+
+	var conversationID uint
+	for i := 0; i < 5; i++ {
+		msg, err := service.SendToAd(2, 1, "Сообщение")
+		if err != nil {
+			t.Fatal(err)
+		}
+		conversationID = msg.ConversationID
+	}
+	if _, err := service.SendToAd(3, 1, "Другой покупатель"); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := service.ListConversations(1, 1, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Total != 2 || list.Unread != 6 {
+		t.Errorf("Expected 2 conversations with 6 unread, got %d and %d", list.Total, list.Unread)
+	}
+
+	var ids []uint
+	cursor := ""
+	for {
+		page, err := service.GetMessages(1, conversationID, cursor, 2)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, msg := range page.Items {
+			ids = append(ids, msg.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(ids) != 5 || ids[0] != 5 || ids[4] != 1 {
+		t.Errorf("Expected messages 5..1 newest first, got %v", ids)
+	}
+
+	list, _ = service.ListConversations(1, 1, 10)
+	if list.Unread != 1 {
+		t.Errorf("Reading a conversation must reset only its unread count, got %d", list.Unread)
+	}
+
+	buyerList, _ := service.ListConversations(2, 1, 10)
+	if buyerList.Total != 1 || buyerList.Unread != 0 {
+		t.Errorf("Unexpected buyer conversations: %+v", buyerList)
+	}
+
+	// сообщение, пришедшее между выборкой страницы и отметкой о прочтении, остаётся непрочитанным
+	if _, err := service.SendToAd(2, 1, "Вы здесь?"); err != nil {
+		t.Fatal(err)
+	}
+	convRepo.afterList = func() {
+		convRepo.afterList = nil
+		if _, err := service.SendToAd(2, 1, "Ау"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	page, err := service.GetMessages(1, conversationID, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Items) != 6 {
+		t.Errorf("Expected 6 messages without the concurrent one, got %d", len(page.Items))
+	}
+	if list, _ := service.ListConversations(1, 1, 10); list.Items[0].UnreadFor(1) != 1 {
+		t.Errorf("Concurrent message must stay unread, got %d", list.Items[0].UnreadFor(1))
+	}
+
+	if _, err := service.GetMessages(3, conversationID, "", 10); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Foreign conversation must not be readable, got %v", err)
+	}
+	if _, err := service.GetMessages(1, conversationID, "garbage!", 10); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for malformed cursor, got %v", err)
+	}
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type NotificationRepository interface {
+	List(userID uint, filter domain.NotificationFilter) ([]domain.Notification, error)
+	Count(userID uint, unreadOnly bool) (int64, error)
+	MarkRead(userID uint, ids []uint) (int64, error)
+}
+
+type notificationService struct {
+	repo NotificationRepository
+}
+
+func NewNotificationService(repo NotificationRepository) *notificationService {
+	return &notificationService{repo: repo}
+}
+
+// ListNotifications возвращает страницу уведомлений (новые первыми) и число непрочитанных
+func (s *notificationService) ListNotifications(userID uint, filter domain.NotificationFilter) (*domain.NotificationPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	filter.Offset = (filter.Page - 1) * filter.Limit
+
+	items, err := s.repo.List(userID, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.repo.Count(userID, filter.UnreadOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	unread := total
+	if !filter.UnreadOnly {
+		if unread, err = s.repo.Count(userID, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return &domain.NotificationPage{
+		Items:  items,
+		Total:  total,
+		Unread: unread,
+		Page:   filter.Page,
+		Limit:  filter.Limit,
+	}, nil
+}
+
+// MarkRead помечает прочитанными уведомления ids (все непрочитанные, если ids пуст)
+// и возвращает число изменённых
+func (s *notificationService) MarkRead(userID uint, ids []uint) (int64, error) {
+	return s.repo.MarkRead(userID, ids)
+}
@@ -0,0 +1,50 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type MockNotificationRepository struct {
+	lastFilter domain.NotificationFilter
+	total      int64
+	unread     int64
+}
+
+func (m *MockNotificationRepository) List(userID uint, filter domain.NotificationFilter) ([]domain.Notification, error) {
+	m.lastFilter = filter
+	return nil, nil
+}
+
+func (m *MockNotificationRepository) Count(userID uint, unreadOnly bool) (int64, error) {
+	if unreadOnly {
+		return m.unread, nil
+	}
+	return m.total, nil
+}
+
+func (m *MockNotificationRepository) MarkRead(userID uint, ids []uint) (int64, error) {
+	return int64(len(ids)), nil
+}
+
+func TestNotificationService_ListNotifications(t *testing.T) {
+	repo := &MockNotificationRepository{total: 45, unread: 3}
+	service := NewNotificationService(repo)
+
+	page, err := service.ListNotifications(1, domain.NotificationFilter{Page: 3, Limit: 500})
+	if err != nil {
+		t.Fatalf("ListNotifications failed: %v", err)
+	}
+	if repo.lastFilter.Limit != 20 || repo.lastFilter.Offset != 40 {
+		t.Errorf("Unexpected filter: %+v", repo.lastFilter)
+	}
+	if page.Total != 45 || page.Unread != 3 || page.Page != 3 {
+		t.Errorf("Unexpected page: %+v", page)
+	}
+
+	page, _ = service.ListNotifications(1, domain.NotificationFilter{UnreadOnly: true})
+	if page.Total != 3 || page.Unread != 3 || page.Page != 1 {
+		t.Errorf("Unexpected unread page: %+v", page)
+	}
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/events"
+)
+
+type OfferRepository interface {
+	Create(offer *domain.Offer) error
+	GetByID(id uint) (*domain.Offer, error)
+	List(filter domain.OfferFilter) ([]domain.Offer, error)
+	Count(filter domain.OfferFilter) (int64, error)
+	UpdateStatus(offer *domain.Offer, from domain.OfferStatus) error
+	Accept(offer *domain.Offer, from domain.OfferStatus) ([]domain.Offer, error)
+}
+
+// Стороны сделки для выборки GET /me/offers
+const (
+	OfferRoleBuyer  = "buyer"
+	OfferRoleSeller = "seller"
+)
+
+type offerService struct {
+	offerRepo OfferRepository
+	ads       AdLookup
+	events    EventPublisher
+}
+
+func NewOfferService(offerRepo OfferRepository, ads AdLookup) *offerService {
+	return &offerService{
+		offerRepo: offerRepo,
+		ads:       ads,
+		events:    noopPublisher{},
+	}
+}
+
+// SetEventPublisher включает отправку событий об изменении предложений
+func (s *offerService) SetEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+}
+
+func (s *offerService) publishOffer(offer *domain.Offer, recipientID uint) {
+	publish(s.events, events.New(domain.EventOffer, recipientID, domain.OfferEvent{
+		ID:             offer.ID,
+		AdID:           offer.AdID,
+		Status:         offer.Status,
+		Amount:         offer.Amount,
+		CounterAmount:  offer.CounterAmount,
+		AcceptedAmount: offer.AcceptedAmount,
+	}))
+}
+
+func validateAmount(amount float64) error {
+	if amount <= 0 {
+		return fmt.Errorf("%w: amount must be positive", domain.ErrInvalidInput)
+	}
+	return nil
+}
+
+// MakeOffer создаёт предложение цены по чужому опубликованному объявлению.
+// У покупателя может быть только одно ожидающее ответа предложение по объявлению.
+func (s *offerService) MakeOffer(buyerID, adID uint, amount float64) (*domain.Offer, error) {
+	if err := validateAmount(amount); err != nil {
+		return nil, err
+	}
+
+	ad, err := s.ads.GetByID(adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.UserID == buyerID {
+		return nil, fmt.Errorf("%w: cannot make an offer on your own advertisement", domain.ErrForbidden)
+	}
+	if ad.Status == domain.AdStatusDraft {
+		return nil, domain.ErrNotFound
+	}
+	if ad.Status != domain.AdStatusPublished {
+		return nil, fmt.Errorf("%w: offers are accepted only on published advertisements", domain.ErrInvalidStatusTransition)
+	}
+
+	offer := &domain.Offer{
+		AdID:     ad.ID,
+		BuyerID:  buyerID,
+		SellerID: ad.UserID,
+		Amount:   amount,
+		Status:   domain.OfferStatusPending,
+	}
+	// одно ожидающее ответа предложение на покупателя проверяет Create атомарно со вставкой
+	if err := s.offerRepo.Create(offer); err != nil {
+		return nil, err
+	}
+
+	s.publishOffer(offer, offer.SellerID)
+	return offer, nil
+}
+
+func (s *offerService) list(filter domain.OfferFilter) (*domain.OfferPage, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+	filter.Offset = (filter.Page - 1) * filter.Limit
+
+	items, err := s.offerRepo.List(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	total, err := s.offerRepo.Count(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.OfferPage{Items: items, Total: total, Page: filter.Page, Limit: filter.Limit}, nil
+}
+
+// ListAdOffers возвращает предложения по объявлению; доступно только его автору
+func (s *offerService) ListAdOffers(sellerID, adID uint, page, limit int) (*domain.OfferPage, error) {
+	ad, err := s.ads.GetByID(adID)
+	if err != nil {
+		return nil, err
+	}
+	if ad.UserID != sellerID {
+		return nil, domain.ErrForbidden
+	}
+
+	return s.list(domain.OfferFilter{AdID: adID, Page: page, Limit: limit})
+}
+
+// ListMyOffers возвращает предложения, сделанные пользователем (buyer) или полученные им (seller)
+func (s *offerService) ListMyOffers(userID uint, role string, page, limit int) (*domain.OfferPage, error) {
+	filter := domain.OfferFilter{Page: page, Limit: limit}
+	switch role {
+	case "", OfferRoleBuyer:
+		filter.BuyerID = userID
+	case OfferRoleSeller:
+		filter.SellerID = userID
+	default:
+		return nil, fmt.Errorf("%w: role must be buyer or seller", domain.ErrInvalidInput)
+	}
+
+	return s.list(filter)
+}
+
+// getOffer возвращает предложение, только если userID - одна из сторон сделки
+func (s *offerService) getOffer(userID, offerID uint) (*domain.Offer, error) {
+	offer, err := s.offerRepo.GetByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != offer.BuyerID && userID != offer.SellerID {
+		return nil, domain.ErrNotFound
+	}
+	return offer, nil
+}
+
+// checkTurn проверяет, что предложение в одном из статусов allowed и ответ за userID:
+// на pending отвечает продавец, на countered - покупатель
+func checkTurn(offer *domain.Offer, userID uint, allowed ...domain.OfferStatus) error {
+	turn := offer.SellerID
+	if offer.Status == domain.OfferStatusCountered {
+		turn = offer.BuyerID
+	}
+	for _, status := range allowed {
+		if offer.Status == status && userID == turn {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: offer is %s", domain.ErrInvalidStatusTransition, offer.Status)
+}
+
+// counterpart возвращает вторую сторону сделки
+func counterpart(offer *domain.Offer, userID uint) uint {
+	if userID == offer.BuyerID {
+		return offer.SellerID
+	}
+	return offer.BuyerID
+}
+
+// Accept принимает предложение: продавец - исходную цену покупателя,
+// покупатель - встречную цену продавца. Объявление бронируется,
+// остальные активные предложения по нему отклоняются.
+func (s *offerService) Accept(userID, offerID uint) (*domain.Offer, error) {
+	offer, err := s.getOffer(userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTurn(offer, userID, domain.OfferStatusPending, domain.OfferStatusCountered); err != nil {
+		return nil, err
+	}
+
+	from := offer.Status
+	offer.AcceptedAmount = &offer.Amount
+	if from == domain.OfferStatusCountered {
+		offer.AcceptedAmount = offer.CounterAmount
+	}
+
+	declined, err := s.offerRepo.Accept(offer, from)
+	if err != nil {
+		return nil, err
+	}
+
+	s.publishOffer(offer, counterpart(offer, userID))
+	for i := range declined {
+		s.publishOffer(&declined[i], declined[i].BuyerID)
+	}
+	return offer, nil
+}
+
+// Reject отклоняет предложение (продавец) или встречную цену (покупатель)
+func (s *offerService) Reject(userID, offerID uint) (*domain.Offer, error) {
+	offer, err := s.getOffer(userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkTurn(offer, userID, domain.OfferStatusPending, domain.OfferStatusCountered); err != nil {
+		return nil, err
+	}
+
+	return s.transition(offer, userID, offer.Status, domain.OfferStatusRejected)
+}
+
+// Counter - встречная цена продавца на ожидающее предложение
+func (s *offerService) Counter(userID, offerID uint, amount float64) (*domain.Offer, error) {
+	if err := validateAmount(amount); err != nil {
+		return nil, err
+	}
+
+	offer, err := s.getOffer(userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkTurn(offer, userID, domain.OfferStatusPending); err != nil {
+		return nil, err
+	}
+
+	offer.CounterAmount = &amount
+	return s.transition(offer, userID, domain.OfferStatusPending, domain.OfferStatusCountered)
+}
+
+// Withdraw - покупатель отзывает своё предложение, пока по нему не принято решение
+func (s *offerService) Withdraw(userID, offerID uint) (*domain.Offer, error) {
+	offer, err := s.getOffer(userID, offerID)
+	if err != nil {
+		return nil, err
+	}
+	if userID != offer.BuyerID || !offer.Status.Active() {
+		return nil, fmt.Errorf("%w: offer is %s", domain.ErrInvalidStatusTransition, offer.Status)
+	}
+
+	return s.transition(offer, userID, offer.Status, domain.OfferStatusWithdrawn)
+}
+
+func (s *offerService) transition(offer *domain.Offer, userID uint, from, to domain.OfferStatus) (*domain.Offer, error) {
+	offer.Status = to
+	if err := s.offerRepo.UpdateStatus(offer, from); err != nil {
+		return nil, err
+	}
+
+	s.publishOffer(offer, counterpart(offer, userID))
+	return offer, nil
+}
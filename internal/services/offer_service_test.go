@@ -0,0 +1,222 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// MockOfferRepository хранит предложения в памяти и бронирует объявления в общем MockAdRepository
+type MockOfferRepository struct {
+	ads    *MockAdRepository
+	offers []*domain.Offer
+}
+
+func (m *MockOfferRepository) Create(offer *domain.Offer) error {
+	ad, err := m.ads.GetByID(offer.AdID)
+	if err != nil {
+		return err
+	}
+	if ad.Status != domain.AdStatusPublished {
+		return domain.ErrInvalidStatusTransition
+	}
+	for _, existing := range m.offers {
+		if existing.AdID == offer.AdID && existing.BuyerID == offer.BuyerID && existing.Status.Active() {
+			return domain.ErrInvalidInput
+		}
+	}
+	offer.ID = uint(len(m.offers) + 1)
+	copied := *offer
+	m.offers = append(m.offers, &copied)
+	return nil
+}
+
+func (m *MockOfferRepository) GetByID(id uint) (*domain.Offer, error) {
+	for _, offer := range m.offers {
+		if offer.ID == id {
+			copied := *offer
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockOfferRepository) filtered(filter domain.OfferFilter) []domain.Offer {
+	var result []domain.Offer
+	for i := len(m.offers) - 1; i >= 0; i-- {
+		offer := m.offers[i]
+		if (filter.AdID == 0 || offer.AdID == filter.AdID) &&
+			(filter.BuyerID == 0 || offer.BuyerID == filter.BuyerID) &&
+			(filter.SellerID == 0 || offer.SellerID == filter.SellerID) {
+			result = append(result, *offer)
+		}
+	}
+	return result
+}
+
+func (m *MockOfferRepository) List(filter domain.OfferFilter) ([]domain.Offer, error) {
+	return m.filtered(filter), nil
+}
+
+func (m *MockOfferRepository) Count(filter domain.OfferFilter) (int64, error) {
+	return int64(len(m.filtered(filter))), nil
+}
+
+func (m *MockOfferRepository) UpdateStatus(offer *domain.Offer, from domain.OfferStatus) error {
+	stored := m.offers[offer.ID-1]
+	if stored.Status != from {
+		return domain.ErrInvalidStatusTransition
+	}
+	stored.Status = offer.Status
+	stored.CounterAmount = offer.CounterAmount
+	return nil
+}
+
+func (m *MockOfferRepository) Accept(offer *domain.Offer, from domain.OfferStatus) ([]domain.Offer, error) {
+	ad, _ := m.ads.GetByID(offer.AdID)
+	if ad.Status != domain.AdStatusPublished || m.offers[offer.ID-1].Status != from {
+		return nil, domain.ErrInvalidStatusTransition
+	}
+	ad.Status = domain.AdStatusReserved
+	m.ads.UpdateStatus(ad, domain.AdStatusPublished)
+
+	stored := m.offers[offer.ID-1]
+	stored.Status = domain.OfferStatusAccepted
+	stored.AcceptedAmount = offer.AcceptedAmount
+	offer.Status = domain.OfferStatusAccepted
+
+	var declined []domain.Offer
+	for _, other := range m.offers {
+		if other.AdID == offer.AdID && other.ID != offer.ID && other.Status.Active() {
+			other.Status = domain.OfferStatusDeclined
+			declined = append(declined, *other)
+		}
+	}
+	return declined, nil
+}
+
+func newTestOfferService() (*offerService, *MockOfferRepository, *MockAdRepository) {
+	ads := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, UserID: 1, Title: "Велосипед", Price: 1000, Status: domain.AdStatusPublished},
+		{ID: 2, UserID: 1, Title: "Черновик", Price: 1000, Status: domain.AdStatusDraft},
+		{ID: 3, UserID: 1, Title: "Продано", Price: 1000, Status: domain.AdStatusSold},
+	}}
+	offers := &MockOfferRepository{ads: ads}
+	return NewOfferService(offers, ads), offers, ads
+}
+
+func TestOfferService_MakeOffer(t *testing.T) {
+	service, _, _ := newTestOfferService()
+
+	offer, err := service.MakeOffer(2, 1, 800)
+	if err != nil {
+		t.Fatalf("MakeOffer failed: %v", err)
+	}
+	if offer.Status != domain.OfferStatusPending || offer.SellerID != 1 {
+		t.Errorf("Unexpected offer: %+v", offer)
+	}
+
+	tests := []struct {
+		name     string
+		buyerID  uint
+		adID     uint
+		amount   float64
+		expected error
+	}{
+		{"Own ad", 1, 1, 800, domain.ErrForbidden},
+		{"Draft", 2, 2, 800, domain.ErrNotFound},
+		{"Sold ad", 2, 3, 800, domain.ErrInvalidStatusTransition},
+		{"Zero amount", 3, 1, 0, domain.ErrInvalidInput},
+		{"Second active offer", 2, 1, 900, domain.ErrInvalidInput},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.MakeOffer(tt.buyerID, tt.adID, tt.amount); !errors.Is(err, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+
+	// после отзыва можно предложить другую цену
+	if _, err := service.Withdraw(2, offer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.MakeOffer(2, 1, 900); err != nil {
+		t.Errorf("New offer after withdrawal failed: %v", err)
+	}
+}
+
+func TestOfferService_Negotiation(t *testing.T) {
+	service, offers, ads := newTestOfferService()
+	publisher := &MockEventPublisher{}
+	service.SetEventPublisher(publisher)
+
+	first, _ := service.MakeOffer(2, 1, 700)
+	second, _ := service.MakeOffer(3, 1, 750)
+	third, _ := service.MakeOffer(4, 1, 600)
+
+	if _, err := service.Reject(1, third.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// ход продавца: покупатель не может сам принять своё предложение
+	if _, err := service.Accept(2, first.ID); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Errorf("Buyer must not accept a pending offer, got %v", err)
+	}
+	if _, err := service.Accept(5, first.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Stranger must not see the offer, got %v", err)
+	}
+
+	countered, err := service.Counter(1, first.ID, 900)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if countered.Status != domain.OfferStatusCountered || *countered.CounterAmount != 900 {
+		t.Errorf("Unexpected countered offer: %+v", countered)
+	}
+	// теперь ход покупателя
+	if _, err := service.Counter(1, first.ID, 850); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Errorf("Seller must wait for the buyer, got %v", err)
+	}
+
+	accepted, err := service.Accept(2, first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if accepted.Status != domain.OfferStatusAccepted || *accepted.AcceptedAmount != 900 {
+		t.Errorf("Unexpected accepted offer: %+v", accepted)
+	}
+
+	ad, _ := ads.GetByID(1)
+	if ad.Status != domain.AdStatusReserved {
+		t.Errorf("Accepted offer must reserve the ad, got %s", ad.Status)
+	}
+	if offers.offers[second.ID-1].Status != domain.OfferStatusDeclined {
+		t.Errorf("Competing offer must be declined, got %s", offers.offers[second.ID-1].Status)
+	}
+	if offers.offers[third.ID-1].Status != domain.OfferStatusRejected {
+		t.Errorf("Rejected offer must stay rejected, got %s", offers.offers[third.ID-1].Status)
+	}
+
+	// события: 3 новых предложения продавцу, отказ, встречная цена, принятие продавцу и отказ второму покупателю
+	last := publisher.events[len(publisher.events)-1]
+	if len(publisher.events) != 7 || last.UserID != 3 {
+		t.Errorf("Unexpected events: %+v", publisher.events)
+	}
+
+	if _, err := service.MakeOffer(5, 1, 1000); !errors.Is(err, domain.ErrInvalidStatusTransition) {
+		t.Errorf("Reserved ad must not accept offers, got %v", err)
+	}
+
+	page, _ := service.ListMyOffers(1, OfferRoleSeller, 1, 10)
+	if page.Total != 3 {
+		t.Errorf("Seller must see 3 received offers, got %d", page.Total)
+	}
+	if _, err := service.ListAdOffers(2, 1, 1, 10); !errors.Is(err, domain.ErrForbidden) {
+		t.Errorf("Only the author may list offers on the ad, got %v", err)
+	}
+	if _, err := service.ListMyOffers(1, "owner", 1, 10); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected ErrInvalidInput for unknown role, got %v", err)
+	}
+}
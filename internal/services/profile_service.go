@@ -0,0 +1,74 @@
+package services
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type ProfileRepository interface {
+	GetByID(id uint) (*domain.User, error)
+	UpdateProfile(user *domain.User) error
+}
+
+type profileService struct {
+	userRepo ProfileRepository
+}
+
+func NewProfileService(userRepo ProfileRepository) *profileService {
+	return &profileService{userRepo: userRepo}
+}
+
+// profileField обрезает пробелы и проверяет длину поля профиля
+func profileField(name, value string, max int) (string, error) {
+	value = strings.TrimSpace(value)
+	if utf8.RuneCountInString(value) > max {
+		return "", fmt.Errorf("%w: %s must be at most %d characters", domain.ErrInvalidInput, name, max)
+	}
+	return value, nil
+}
+
+// UpdateProfile меняет переданные поля публичного профиля пользователя
+func (s *profileService) UpdateProfile(userID uint, update domain.ProfileUpdate) (*domain.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := []struct {
+		name   string
+		value  *string
+		max    int
+		target *string
+	}{
+		{"display_name", update.DisplayName, domain.MaxDisplayNameLength, &user.DisplayName},
+		{"avatar_url", update.AvatarURL, domain.MaxAvatarURLLength, &user.AvatarURL},
+		{"city", update.City, domain.MaxCityLength, &user.City},
+		{"about", update.About, domain.MaxAboutLength, &user.About},
+	}
+	for _, field := range fields {
+		if field.value == nil {
+			continue
+		}
+		value, err := profileField(field.name, *field.value, field.max)
+		if err != nil {
+			return nil, err
+		}
+		*field.target = value
+	}
+
+	if user.AvatarURL != "" {
+		parsed, err := url.ParseRequestURI(user.AvatarURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("%w: avatar_url must be an http(s) URL", domain.ErrInvalidInput)
+		}
+	}
+
+	if err := s.userRepo.UpdateProfile(user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
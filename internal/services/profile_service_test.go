@@ -0,0 +1,61 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+func (m *MockUserRepository) UpdateProfile(user *domain.User) error {
+	stored, err := m.GetByID(user.ID)
+	if err != nil {
+		return err
+	}
+	*stored = *user
+	return nil
+}
+
+func TestProfileService_UpdateProfile(t *testing.T) {
+	users := &MockUserRepository{users: map[string]*domain.User{
+		"seller": {ID: 1, Username: "seller", City: "Москва", About: "Продаю велосипеды"},
+	}}
+	service := NewProfileService(users)
+	text := func(value string) *string { return &value }
+
+	user, err := service.UpdateProfile(1, domain.ProfileUpdate{
+		DisplayName: text("  Иван  "),
+		AvatarURL:   text("https://example.com/avatar.png"),
+		City:        text(""),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	stored := users.users["seller"]
+	if stored.DisplayName != "Иван" || stored.AvatarURL != "https://example.com/avatar.png" || stored.City != "" {
+		t.Errorf("profile was not updated: %+v", stored)
+	}
+	if stored.About != "Продаю велосипеды" || user.About != stored.About {
+		t.Errorf("omitted field must stay unchanged: %+v", stored)
+	}
+
+	invalid := []domain.ProfileUpdate{
+		{DisplayName: text(strings.Repeat("я", domain.MaxDisplayNameLength+1))},
+		{About: text(strings.Repeat("я", domain.MaxAboutLength+1))},
+		{AvatarURL: text("ftp://example.com/avatar.png")},
+		{AvatarURL: text("not a url")},
+	}
+	for _, update := range invalid {
+		if _, err := service.UpdateProfile(1, update); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("expected ErrInvalidInput for %+v, got %v", update, err)
+		}
+	}
+	if stored.DisplayName != "Иван" {
+		t.Errorf("rejected update must not change the profile: %+v", stored)
+	}
+
+	if _, err := service.UpdateProfile(42, domain.ProfileUpdate{}); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+type ReviewRepository interface {
+	Create(review *domain.Review) error
+	ListBySeller(sellerID uint, limit int) ([]domain.Review, error)
+}
+
+// OfferLookup - сделка, по которой оставляется отзыв
+type OfferLookup interface {
+	GetByID(id uint) (*domain.Offer, error)
+}
+
+type ProfileLookup interface {
+	GetByUsername(username string) (*domain.User, error)
+}
+
+// Сколько последних отзывов показывается в профиле
+const profileReviewsLimit = 10
+
+type reviewService struct {
+	reviewRepo ReviewRepository
+	offers     OfferLookup
+	users      ProfileLookup
+}
+
+func NewReviewService(reviewRepo ReviewRepository, offers OfferLookup, users ProfileLookup) *reviewService {
+	return &reviewService{
+		reviewRepo: reviewRepo,
+		offers:     offers,
+		users:      users,
+	}
+}
+
+// LeaveReview - отзыв покупателя о продавце. Сделка считается завершённой,
+// когда предложение принято, а объявление продано.
+func (s *reviewService) LeaveReview(buyerID, offerID uint, rating int, text string) (*domain.Review, error) {
+	offer, err := s.offers.GetByID(offerID)
+	if err != nil {
+		return nil, err
+	}
+	if buyerID != offer.BuyerID && buyerID != offer.SellerID {
+		return nil, domain.ErrNotFound
+	}
+	if buyerID != offer.BuyerID {
+		return nil, fmt.Errorf("%w: only the buyer can review the deal", domain.ErrForbidden)
+	}
+	if offer.Status != domain.OfferStatusAccepted || offer.Ad.Status != domain.AdStatusSold {
+		return nil, fmt.Errorf("%w: the deal is not completed", domain.ErrInvalidStatusTransition)
+	}
+
+	if rating < domain.MinRating || rating > domain.MaxRating {
+		return nil, fmt.Errorf("%w: rating must be between %d and %d", domain.ErrInvalidInput, domain.MinRating, domain.MaxRating)
+	}
+	text = strings.TrimSpace(text)
+	if utf8.RuneCountInString(text) > domain.MaxReviewLength {
+		return nil, fmt.Errorf("%w: review must be at most %d characters", domain.ErrInvalidInput, domain.MaxReviewLength)
+	}
+
+	review := &domain.Review{
+		OfferID:  offer.ID,
+		AdID:     offer.AdID,
+		Ad:       offer.Ad,
+		SellerID: offer.SellerID,
+		BuyerID:  buyerID,
+		Buyer:    offer.Buyer,
+		Rating:   rating,
+		Text:     text,
+	}
+	if err := s.reviewRepo.Create(review); err != nil {
+		return nil, err
+	}
+	return review, nil
+}
+
+// GetProfile возвращает пользователя с его рейтингом продавца и последними отзывами
+func (s *reviewService) GetProfile(username string) (*domain.SellerProfile, error) {
+	user, err := s.users.GetByUsername(username)
+	if err != nil {
+		return nil, err
+	}
+
+	reviews, err := s.reviewRepo.ListBySeller(user.ID, profileReviewsLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	return &domain.SellerProfile{User: *user, Reviews: reviews}, nil
+}
@@ -0,0 +1,135 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+// MockReviewRepository хранит отзывы в памяти и обновляет рейтинг продавца в MockUserRepository
+type MockReviewRepository struct {
+	users   *MockUserRepository
+	reviews []domain.Review
+}
+
+func (m *MockReviewRepository) Create(review *domain.Review) error {
+	for _, existing := range m.reviews {
+		if existing.OfferID == review.OfferID {
+			return domain.ErrConflict
+		}
+	}
+	review.ID = uint(len(m.reviews) + 1)
+	m.reviews = append(m.reviews, *review)
+
+	seller, err := m.users.GetByID(review.SellerID)
+	if err != nil {
+		return err
+	}
+	seller.RatingSum += review.Rating
+	seller.RatingCount++
+	return nil
+}
+
+func (m *MockReviewRepository) ListBySeller(sellerID uint, limit int) ([]domain.Review, error) {
+	var result []domain.Review
+	for i := len(m.reviews) - 1; i >= 0 && len(result) < limit; i-- {
+		if m.reviews[i].SellerID == sellerID {
+			result = append(result, m.reviews[i])
+		}
+	}
+	return result, nil
+}
+
+// newTestReviewService: продавец seller (1) продал объявление 1 покупателю buyer (2) по предложению 1,
+// предложение 2 покупателя other (3) по объявлению 2 ещё ждёт ответа
+func newTestReviewService() (*reviewService, *MockUserRepository) {
+	users := &MockUserRepository{users: map[string]*domain.User{
+		"seller": {ID: 1, Username: "seller"},
+		"buyer":  {ID: 2, Username: "buyer"},
+		"other":  {ID: 3, Username: "other"},
+	}}
+	offers := &MockOfferRepository{offers: []*domain.Offer{
+		{ID: 1, AdID: 1, Ad: domain.Advertisement{ID: 1, Status: domain.AdStatusSold}, BuyerID: 2, SellerID: 1, Status: domain.OfferStatusAccepted},
+		{ID: 2, AdID: 2, Ad: domain.Advertisement{ID: 2, Status: domain.AdStatusPublished}, BuyerID: 3, SellerID: 1, Status: domain.OfferStatusPending},
+	}}
+	return NewReviewService(&MockReviewRepository{users: users}, offers, users), users
+}
+
+func TestReviewService_LeaveReview(t *testing.T) {
+	tests := []struct {
+		name    string
+		userID  uint
+		offerID uint
+		rating  int
+		text    string
+		err     error
+	}{
+		{"stranger", 3, 1, 5, "", domain.ErrNotFound},
+		{"seller", 1, 1, 5, "", domain.ErrForbidden},
+		{"deal not completed", 3, 2, 5, "", domain.ErrInvalidStatusTransition},
+		{"rating too low", 2, 1, 0, "", domain.ErrInvalidInput},
+		{"rating too high", 2, 1, 6, "", domain.ErrInvalidInput},
+		{"text too long", 2, 1, 5, strings.Repeat("я", domain.MaxReviewLength+1), domain.ErrInvalidInput},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			service, _ := newTestReviewService()
+			if _, err := service.LeaveReview(tc.userID, tc.offerID, tc.rating, tc.text); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+
+	t.Run("completed deal", func(t *testing.T) {
+		service, users := newTestReviewService()
+
+		review, err := service.LeaveReview(2, 1, 4, "  Всё отлично  ")
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if review.SellerID != 1 || review.Rating != 4 || review.Text != "Всё отлично" {
+			t.Errorf("unexpected review: %+v", review)
+		}
+		if seller := users.users["seller"]; seller.RatingSum != 4 || seller.RatingCount != 1 {
+			t.Errorf("seller rating was not updated: %+v", seller)
+		}
+
+		if _, err := service.LeaveReview(2, 1, 5, ""); !errors.Is(err, domain.ErrConflict) {
+			t.Errorf("expected ErrConflict for a second review, got %v", err)
+		}
+	})
+}
+
+func TestReviewService_GetProfile(t *testing.T) {
+	service, _ := newTestReviewService()
+
+	profile, err := service.GetProfile("seller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if profile.User.Rating() != nil || len(profile.Reviews) != 0 {
+		t.Errorf("expected no rating without reviews, got %+v", profile)
+	}
+
+	if _, err := service.LeaveReview(2, 1, 3, "Нормально"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	profile, err = service.GetProfile("seller")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rating := profile.User.Rating(); rating == nil || *rating != 3 {
+		t.Errorf("expected rating 3, got %v", rating)
+	}
+	if len(profile.Reviews) != 1 || profile.Reviews[0].Text != "Нормально" {
+		t.Errorf("unexpected reviews: %+v", profile.Reviews)
+	}
+
+	if _, err := service.GetProfile("nobody"); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,244 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+	"github.com/keenetic29/vk-internship/pkg/events"
+	"github.com/keenetic29/vk-internship/pkg/logger"
+)
+
+const (
+	// объявления, опубликованные позже чем эта задержка назад, воркер не трогает:
+	// транзакции с более ранним published_at могут ещё не закоммититься, а водяной
+	// знак не должен их перешагнуть
+	savedSearchSettleDelay = 30 * time.Second
+	savedSearchBatchSize   = 100
+)
+
+type SavedSearchRepository interface {
+	Create(search *domain.SavedSearch) error
+	GetByID(id uint) (*domain.SavedSearch, error)
+	ListByUser(userID uint) ([]domain.SavedSearch, error)
+	CountByUser(userID uint) (int64, error)
+	Delete(id uint) error
+	ListPending(upTo time.Time, limit int) ([]domain.SavedSearch, error)
+	Advance(searchID uint, from, to time.Time, notifications []domain.Notification) (bool, error)
+}
+
+// AdMatcher ищет новые объявления для сохранённых поисков
+type AdMatcher interface {
+	MatchNew(filter domain.AdFilter, after, upTo time.Time) ([]domain.Advertisement, error)
+}
+
+type savedSearchService struct {
+	searchRepo   SavedSearchRepository
+	ads          AdMatcher
+	categoryRepo CategoryRepository
+	events       EventPublisher
+	now          func() time.Time
+}
+
+func NewSavedSearchService(searchRepo SavedSearchRepository, ads AdMatcher, categoryRepo CategoryRepository) *savedSearchService {
+	return &savedSearchService{
+		searchRepo:   searchRepo,
+		ads:          ads,
+		categoryRepo: categoryRepo,
+		events:       noopPublisher{},
+		now:          time.Now,
+	}
+}
+
+// SetEventPublisher включает отправку событий о новых уведомлениях
+func (s *savedSearchService) SetEventPublisher(publisher EventPublisher) {
+	s.events = publisher
+}
+
+// CreateSavedSearch сохраняет фильтр GET /ads под именем. Уведомления придут
+// только об объявлениях, созданных после сохранения.
+func (s *savedSearchService) CreateSavedSearch(userID uint, name string, filter domain.AdFilter) (*domain.SavedSearch, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || utf8.RuneCountInString(name) > 100 {
+		return nil, fmt.Errorf("%w: name must be between 1 and 100 characters", domain.ErrInvalidInput)
+	}
+
+	filter.Query = strings.TrimSpace(filter.Query)
+	if utf8.RuneCountInString(filter.Query) > maxSearchQueryLength {
+		return nil, fmt.Errorf("%w: search query must be at most %d characters", domain.ErrInvalidInput, maxSearchQueryLength)
+	}
+	if filter.MinPrice < 0 || filter.MaxPrice < 0 || (filter.MaxPrice > 0 && filter.MinPrice > filter.MaxPrice) {
+		return nil, fmt.Errorf("%w: invalid price range", domain.ErrInvalidInput)
+	}
+	if _, err := expandCategory(s.categoryRepo, filter.CategoryID); err != nil {
+		return nil, err
+	}
+	if filter.SortBy != "price" && filter.SortBy != "created_at" && filter.SortBy != "relevance" {
+		filter.SortBy = "created_at"
+	}
+	if filter.Order != "asc" && filter.Order != "desc" {
+		filter.Order = "desc"
+	}
+
+	count, err := s.searchRepo.CountByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= domain.MaxSavedSearches {
+		return nil, fmt.Errorf("%w: at most %d saved searches", domain.ErrInvalidInput, domain.MaxSavedSearches)
+	}
+
+	search := &domain.SavedSearch{
+		UserID:     userID,
+		Name:       name,
+		Query:      filter.Query,
+		MinPrice:   filter.MinPrice,
+		MaxPrice:   filter.MaxPrice,
+		CategoryID: filter.CategoryID,
+		SortBy:     filter.SortBy,
+		Order:      filter.Order,
+		// уведомления только о тех, что опубликованы после сохранения поиска
+		CheckedUntil: watermark(s.now()),
+	}
+	if err := s.searchRepo.Create(search); err != nil {
+		return nil, err
+	}
+	return search, nil
+}
+
+func (s *savedSearchService) ListSavedSearches(userID uint) ([]domain.SavedSearch, error) {
+	return s.searchRepo.ListByUser(userID)
+}
+
+// DeleteSavedSearch удаляет поиск; чужой поиск для пользователя не существует
+func (s *savedSearchService) DeleteSavedSearch(userID, searchID uint) error {
+	search, err := s.searchRepo.GetByID(searchID)
+	if err != nil {
+		return err
+	}
+	if search.UserID != userID {
+		return domain.ErrNotFound
+	}
+	return s.searchRepo.Delete(searchID)
+}
+
+// EvaluateSavedSearches проверяет сохранённые поиски на новых объявлениях и записывает
+// уведомления о совпадениях. Возвращает число обработанных поисков.
+//
+// Водяной знак поиска сдвигается в одной транзакции с записью уведомлений, а уведомления
+// уникальны по (поиск, объявление), поэтому после перезапуска воркер продолжает с места
+// остановки: объявления, созданные во время простоя, не теряются и не дублируются.
+func (s *savedSearchService) EvaluateSavedSearches() (int, error) {
+	upTo := watermark(s.now().Add(-savedSearchSettleDelay))
+
+	processed := 0
+	for {
+		searches, err := s.searchRepo.ListPending(upTo, savedSearchBatchSize)
+		if err != nil {
+			return processed, err
+		}
+
+		for i := range searches {
+			if err := s.evaluate(&searches[i], upTo); err != nil {
+				return processed, err
+			}
+			processed++
+		}
+
+		if len(searches) < savedSearchBatchSize {
+			return processed, nil
+		}
+	}
+}
+
+func (s *savedSearchService) evaluate(search *domain.SavedSearch, upTo time.Time) error {
+	notifications, err := s.matches(search, upTo)
+	if err != nil {
+		return err
+	}
+
+	// false означает, что поиск уже обработан параллельно или удалён
+	advanced, err := s.searchRepo.Advance(search.ID, search.CheckedUntil, upTo, notifications)
+	if err != nil || !advanced {
+		return err
+	}
+
+	for _, n := range notifications {
+		publish(s.events, events.New(domain.EventNotification, n.UserID, domain.NotificationEvent{
+			Type:          n.Type,
+			Title:         n.Title,
+			AdID:          n.AdID,
+			SavedSearchID: n.SavedSearchID,
+		}))
+	}
+	return nil
+}
+
+// matches собирает уведомления о чужих объявлениях, опубликованных в (CheckedUntil, upTo]
+func (s *savedSearchService) matches(search *domain.SavedSearch, upTo time.Time) ([]domain.Notification, error) {
+	filter := search.Filter()
+	filter.Status = domain.AdStatusPublished
+
+	categoryIDs, err := expandCategory(s.categoryRepo, filter.CategoryID)
+	if errors.Is(err, domain.ErrInvalidInput) {
+		// категорию удалили: поиск больше ничего не находит, но водяной знак сдвигается
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	filter.CategoryIDs = categoryIDs
+
+	ads, err := s.ads.MatchNew(filter, search.CheckedUntil, upTo)
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []domain.Notification
+	for _, ad := range ads {
+		if ad.UserID == search.UserID {
+			continue
+		}
+		adID, searchID := ad.ID, search.ID
+		notifications = append(notifications, domain.Notification{
+			UserID:        search.UserID,
+			Type:          domain.NotificationSavedSearchMatch,
+			Title:         fmt.Sprintf("%s: %s", search.Name, ad.Title),
+			AdID:          &adID,
+			SavedSearchID: &searchID,
+			DedupKey:      fmt.Sprintf("saved_search:%d:ad:%d", search.ID, ad.ID),
+		})
+	}
+	return notifications, nil
+}
+
+// watermark округляет время до микросекунд - точности timestamp в Postgres, чтобы
+// сохранённый водяной знак совпадал с тем, по которому выбирались объявления
+func watermark(t time.Time) time.Time {
+	return t.Truncate(time.Microsecond)
+}
+
+// Run периодически вызывает EvaluateSavedSearches, пока не отменён ctx
+func (s *savedSearchService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		processed, err := s.EvaluateSavedSearches()
+		if err != nil {
+			logger.Log.Error("Failed to evaluate saved searches", "error", err)
+		} else if processed > 0 {
+			logger.Log.Info("Saved searches evaluated", "processed", processed)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/keenetic29/vk-internship/internal/domain"
+)
+
+func (m *MockAdRepository) MatchNew(filter domain.AdFilter, after, upTo time.Time) ([]domain.Advertisement, error) {
+	var result []domain.Advertisement
+	for _, ad := range m.ads {
+		if ad.PublishedAt != nil && ad.PublishedAt.After(after) && !ad.PublishedAt.After(upTo) && m.matches(ad, filter) {
+			result = append(result, domain.Advertisement{ID: ad.ID, UserID: ad.UserID, Title: ad.Title})
+		}
+	}
+	return result, nil
+}
+
+type MockSavedSearchRepository struct {
+	searches      []*domain.SavedSearch
+	notifications []domain.Notification
+	failAdvance   bool
+}
+
+func (m *MockSavedSearchRepository) Create(search *domain.SavedSearch) error {
+	search.ID = uint(len(m.searches) + 1)
+	copied := *search
+	m.searches = append(m.searches, &copied)
+	return nil
+}
+
+func (m *MockSavedSearchRepository) GetByID(id uint) (*domain.SavedSearch, error) {
+	for _, search := range m.searches {
+		if search.ID == id {
+			copied := *search
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrNotFound
+}
+
+func (m *MockSavedSearchRepository) ListByUser(userID uint) ([]domain.SavedSearch, error) {
+	var result []domain.SavedSearch
+	for _, search := range m.searches {
+		if search.UserID == userID {
+			result = append(result, *search)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockSavedSearchRepository) CountByUser(userID uint) (int64, error) {
+	searches, _ := m.ListByUser(userID)
+	return int64(len(searches)), nil
+}
+
+func (m *MockSavedSearchRepository) Delete(id uint) error {
+	for i, search := range m.searches {
+		if search.ID == id {
+			m.searches = append(m.searches[:i], m.searches[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrNotFound
+}
+
+func (m *MockSavedSearchRepository) ListPending(upTo time.Time, limit int) ([]domain.SavedSearch, error) {
+	var result []domain.SavedSearch
+	for _, search := range m.searches {
+		if search.CheckedUntil.Before(upTo) && len(result) < limit {
+			result = append(result, *search)
+		}
+	}
+	return result, nil
+}
+
+func (m *MockSavedSearchRepository) Advance(searchID uint, from, to time.Time, notifications []domain.Notification) (bool, error) {
+	if m.failAdvance {
+		return false, errors.New("connection lost")
+	}
+	for _, search := range m.searches {
+		if search.ID != searchID || !search.CheckedUntil.Equal(from) {
+			continue
+		}
+		search.CheckedUntil = to
+	next:
+		for _, n := range notifications {
+			for _, existing := range m.notifications {
+				if existing.DedupKey == n.DedupKey {
+					continue next
+				}
+			}
+			m.notifications = append(m.notifications, n)
+		}
+		return true, nil
+	}
+	return false, nil
+}
+
+func TestSavedSearchService_Create(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 1500, time.UTC)
+	repo := &MockSavedSearchRepository{}
+	service := NewSavedSearchService(repo, &MockAdRepository{}, testCategories())
+	service.now = func() time.Time { return now }
+
+	search, err := service.CreateSavedSearch(1, "  Дешёвые телефоны ", domain.AdFilter{CategoryID: 2, MaxPrice: 1000})
+	if err != nil {
+		t.Fatalf("CreateSavedSearch failed: %v", err)
+	}
+	// уведомления только о новых объявлениях; знак округлён до точности Postgres
+	if !search.CheckedUntil.Equal(now.Truncate(time.Microsecond)) || search.Name != "Дешёвые телефоны" || search.SortBy != "created_at" || search.Order != "desc" {
+		t.Errorf("Unexpected saved search: %+v", search)
+	}
+
+	invalid := []struct {
+		name   string
+		filter domain.AdFilter
+	}{
+		{"", domain.AdFilter{}},
+		{"Поиск", domain.AdFilter{CategoryID: 42}},
+		{"Поиск", domain.AdFilter{MinPrice: 500, MaxPrice: 100}},
+		{"Поиск", domain.AdFilter{Query: makeString(201)}},
+	}
+	for _, tc := range invalid {
+		if _, err := service.CreateSavedSearch(1, tc.name, tc.filter); !errors.Is(err, domain.ErrInvalidInput) {
+			t.Errorf("CreateSavedSearch(%q, %+v): expected ErrInvalidInput, got %v", tc.name, tc.filter, err)
+		}
+	}
+
+	for i := 1; i < domain.MaxSavedSearches; i++ {
+		if _, err := service.CreateSavedSearch(1, "Поиск", domain.AdFilter{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := service.CreateSavedSearch(1, "Лишний", domain.AdFilter{}); !errors.Is(err, domain.ErrInvalidInput) {
+		t.Errorf("Expected limit error, got %v", err)
+	}
+
+	if err := service.DeleteSavedSearch(2, search.ID); !errors.Is(err, domain.ErrNotFound) {
+		t.Errorf("Foreign saved search must not be deleted, got %v", err)
+	}
+	if err := service.DeleteSavedSearch(1, search.ID); err != nil {
+		t.Errorf("DeleteSavedSearch failed: %v", err)
+	}
+}
+
+func TestSavedSearchService_Evaluate(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	ads := &MockAdRepository{ads: []*domain.Advertisement{
+		{ID: 1, UserID: 2, Title: "Старый телефон", Price: 500, CategoryID: 4, Status: domain.AdStatusPublished, CreatedAt: now.Add(-time.Hour)},
+		{ID: 9, UserID: 3, Title: "Телефон-черновик", Price: 300, CategoryID: 2, Status: domain.AdStatusDraft, CreatedAt: now.Add(-time.Hour)},
+	}}
+	ads.ads[0].PublishedAt = &ads.ads[0].CreatedAt
+	repo := &MockSavedSearchRepository{}
+	service := NewSavedSearchService(repo, ads, testCategories())
+	service.now = func() time.Time { return now }
+
+	if _, err := service.CreateSavedSearch(1, "Телефоны", domain.AdFilter{CategoryID: 2, MaxPrice: 1000}); err != nil {
+		t.Fatal(err)
+	}
+
+	add := func(ad domain.Advertisement) {
+		if ad.Status == domain.AdStatusPublished {
+			ad.PublishedAt = &ad.CreatedAt
+		}
+		ads.ads = append(ads.ads, &ad)
+	}
+	add(domain.Advertisement{ID: 2, UserID: 2, Title: "Смартфон", Price: 900, CategoryID: 4, Status: domain.AdStatusPublished, CreatedAt: now.Add(time.Minute)})
+	add(domain.Advertisement{ID: 3, UserID: 2, Title: "Дорогой", Price: 5000, CategoryID: 4, Status: domain.AdStatusPublished, CreatedAt: now.Add(time.Minute)})
+	add(domain.Advertisement{ID: 4, UserID: 2, Title: "Диван", Price: 900, CategoryID: 5, Status: domain.AdStatusPublished, CreatedAt: now.Add(time.Minute)})
+	add(domain.Advertisement{ID: 5, UserID: 1, Title: "Мой телефон", Price: 900, CategoryID: 2, Status: domain.AdStatusPublished, CreatedAt: now.Add(time.Minute)})
+	add(domain.Advertisement{ID: 6, UserID: 2, Title: "Черновик", Price: 900, CategoryID: 2, Status: domain.AdStatusDraft, CreatedAt: now.Add(time.Minute)})
+
+	// объявления моложе задержки ещё не обрабатываются
+	now = now.Add(time.Minute + savedSearchSettleDelay/2)
+	if _, err := service.EvaluateSavedSearches(); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.notifications) != 0 {
+		t.Fatalf("Fresh ads must wait for the settle delay, got %+v", repo.notifications)
+	}
+
+	now = now.Add(savedSearchSettleDelay)
+	if _, err := service.EvaluateSavedSearches(); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.notifications) != 1 || *repo.notifications[0].AdID != 2 || repo.notifications[0].UserID != 1 {
+		t.Fatalf("Expected a single match for ad 2, got %+v", repo.notifications)
+	}
+	if repo.notifications[0].Title != "Телефоны: Смартфон" {
+		t.Errorf("Unexpected title %q", repo.notifications[0].Title)
+	}
+
+	// повторный проход ничего не добавляет
+	if processed, _ := service.EvaluateSavedSearches(); processed != 0 || len(repo.notifications) != 1 {
+		t.Errorf("Repeated run must be a no-op, processed %d, notifications %d", processed, len(repo.notifications))
+	}
+
+	// сбой записи: водяной знак не сдвигается, объявление не теряется
+	add(domain.Advertisement{ID: 7, UserID: 3, Title: "Телефон", Price: 100, CategoryID: 2, Status: domain.AdStatusPublished, CreatedAt: now})
+	now = now.Add(time.Hour)
+	repo.failAdvance = true
+	if _, err := service.EvaluateSavedSearches(); err == nil {
+		t.Fatal("Expected error from Advance")
+	}
+	repo.failAdvance = false
+
+	// "перезапуск": новый экземпляр сервиса продолжает с водяного знака, включая объявления,
+	// созданные во время простоя
+	add(domain.Advertisement{ID: 8, UserID: 3, Title: "Ещё телефон", Price: 200, CategoryID: 4, Status: domain.AdStatusPublished, CreatedAt: now.Add(-time.Minute)})
+	restarted := NewSavedSearchService(repo, ads, testCategories())
+	restarted.now = func() time.Time { return now }
+	if _, err := restarted.EvaluateSavedSearches(); err != nil {
+		t.Fatal(err)
+	}
+
+	var matched []uint
+	for _, n := range repo.notifications {
+		matched = append(matched, *n.AdID)
+	}
+	if len(matched) != 3 || matched[1] != 7 || matched[2] != 8 {
+		t.Fatalf("Expected matches [2 7 8], got %v", matched)
+	}
+
+	// черновик, созданный до сохранения поиска (и с меньшим id, чем уже найденные),
+	// находится, когда его публикуют
+	adService := NewAdvertisementService(ads, testCategories(), ads)
+	adService.SetClock(func() time.Time { return now })
+	if _, err := adService.ChangeStatus(3, 9, domain.AdStatusPublished); err != nil {
+		t.Fatal(err)
+	}
+	now = now.Add(savedSearchSettleDelay)
+	if _, err := restarted.EvaluateSavedSearches(); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.notifications) != 4 || *repo.notifications[3].AdID != 9 {
+		t.Errorf("Expected the published draft 9 to match, got %+v", repo.notifications)
+	}
+}
@@ -0,0 +1,47 @@
+package database
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// Поддерживаемые СУБД (DB_DRIVER); совпадают с именами диалектов GORM
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Options - параметры подключения к базе выбранной СУБД
+type Options struct {
+	Driver string
+
+	// PostgreSQL: подключение к служебной базе, в которой при необходимости
+	// создаётся база Name, и подключение к самой базе
+	AdminDSN string
+	DSN      string
+	Name     string
+
+	// SQLite: путь к файлу базы
+	Path string
+}
+
+// InitDB подключается к базе выбранной СУБД
+func InitDB(opts Options) (*gorm.DB, error) {
+	switch opts.Driver {
+	case DriverPostgres:
+		return initPostgres(opts.AdminDSN, opts.DSN, opts.Name)
+	case DriverSQLite:
+		return initSQLite(opts.Path)
+	}
+	return nil, fmt.Errorf("unknown database driver %q", opts.Driver)
+}
+
+// RunMigrations применяет встроенные миграции, которые ещё не применены
+func RunMigrations(db *gorm.DB) ([]MigrationStep, error) {
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		return nil, err
+	}
+	return migrator.Up()
+}
@@ -0,0 +1,326 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Для каждой СУБД свой набор миграций в migrations/<драйвер>; номера и имена миграций совпадают
+//
+//go:embed migrations/postgres/*.sql migrations/sqlite/*.sql
+var embeddedMigrations embed.FS
+
+// Ключ pg_advisory_xact_lock: миграции разных экземпляров приложения выполняются по очереди
+const migrationLockID = 7_412_095_321
+
+var createMigrationsTable = map[string]string{
+	DriverPostgres: `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version bigint PRIMARY KEY,
+	name text NOT NULL,
+	applied_at timestamptz NOT NULL DEFAULT now()
+)`,
+	DriverSQLite: `CREATE TABLE IF NOT EXISTS schema_migrations (
+	version integer PRIMARY KEY,
+	name text NOT NULL,
+	applied_at datetime NOT NULL DEFAULT CURRENT_TIMESTAMP
+)`,
+}
+
+var ErrUnknownVersion = errors.New("unknown migration version")
+
+// Миграция из пары файлов NNNN_name.up.sql и NNNN_name.down.sql
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Выполненный шаг: применение (Up) или откат миграции
+type MigrationStep struct {
+	Migration
+	Up bool
+}
+
+func (s MigrationStep) String() string {
+	direction := "down"
+	if s.Up {
+		direction = "up"
+	}
+	return fmt.Sprintf("%04d_%s %s", s.Version, s.Name, direction)
+}
+
+// Состояние миграции для migrate status; AppliedAt == nil - не применена
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// LoadMigrations читает миграции из корня fsys и сортирует их по номеру.
+// У каждой миграции должны быть оба файла, номера не повторяются.
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("unexpected migration file %q", entry.Name())
+		}
+		version, err := strconv.Atoi(match[1])
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has files with different names: %s and %s", version, migration.Name, match[2])
+		}
+		if match[3] == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s must have both up and down files", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+type Migrator struct {
+	db         *gorm.DB
+	driver     string
+	migrations []Migration
+}
+
+// NewMigrator - мигратор со встроенными в бинарник миграциями для СУБД базы db
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	driver := db.Dialector.Name()
+	migrations, err := loadEmbeddedMigrations(driver)
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, driver: driver, migrations: migrations}, nil
+}
+
+func loadEmbeddedMigrations(driver string) ([]Migration, error) {
+	if _, ok := createMigrationsTable[driver]; !ok {
+		return nil, fmt.Errorf("migrations are not available for database driver %q", driver)
+	}
+	sub, err := fs.Sub(embeddedMigrations, "migrations/"+driver)
+	if err != nil {
+		return nil, err
+	}
+	return LoadMigrations(sub)
+}
+
+// Latest - номер последней известной миграции
+func (m *Migrator) Latest() int {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up применяет все ещё не применённые миграции
+func (m *Migrator) Up() ([]MigrationStep, error) {
+	return m.To(m.Latest())
+}
+
+// Down откатывает последнюю применённую миграцию
+func (m *Migrator) Down() ([]MigrationStep, error) {
+	step, err := m.step(func(applied map[int]bool) (*Migration, bool, error) {
+		latest := 0
+		for version := range applied {
+			if version > latest {
+				latest = version
+			}
+		}
+		if latest == 0 {
+			return nil, false, nil
+		}
+		migration, err := m.find(latest)
+		return migration, false, err
+	})
+	if err != nil || step == nil {
+		return nil, err
+	}
+	return []MigrationStep{*step}, nil
+}
+
+// To приводит схему к версии target: применяет недостающие миграции с номерами
+// не больше target и откатывает применённые с большими номерами. 0 - откатить всё.
+func (m *Migrator) To(target int) ([]MigrationStep, error) {
+	if target != 0 {
+		if _, err := m.find(target); err != nil {
+			return nil, err
+		}
+	}
+	var steps []MigrationStep
+	for {
+		step, err := m.step(func(applied map[int]bool) (*Migration, bool, error) {
+			return nextStep(m.migrations, applied, target)
+		})
+		if err != nil || step == nil {
+			return steps, err
+		}
+		steps = append(steps, *step)
+	}
+}
+
+// Status возвращает все известные миграции с отметкой о применении
+func (m *Migrator) Status() ([]MigrationStatus, error) {
+	if err := m.db.Exec(createMigrationsTable[m.driver]).Error; err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		Version   int
+		AppliedAt time.Time
+	}
+	if err := m.db.Raw("SELECT version, applied_at FROM schema_migrations").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	appliedAt := make(map[int]time.Time, len(rows))
+	for _, row := range rows {
+		appliedAt[row.Version] = row.AppliedAt
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+func (m *Migrator) find(version int) (*Migration, error) {
+	for i := range m.migrations {
+		if m.migrations[i].Version == version {
+			return &m.migrations[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+}
+
+// nextStep выбирает следующий шаг к версии target: сначала откат применённых миграций
+// с номерами больше target (с конца), затем применение недостающих (по порядку)
+func nextStep(migrations []Migration, applied map[int]bool, target int) (*Migration, bool, error) {
+	for i := len(migrations) - 1; i >= 0; i-- {
+		if migrations[i].Version > target && applied[migrations[i].Version] {
+			return &migrations[i], false, nil
+		}
+	}
+	for version := range applied {
+		if version > target {
+			return nil, false, fmt.Errorf("%w: database has migration %d, which this binary does not know", ErrUnknownVersion, version)
+		}
+	}
+	for i := range migrations {
+		if migrations[i].Version <= target && !applied[migrations[i].Version] {
+			return &migrations[i], true, nil
+		}
+	}
+	return nil, false, nil
+}
+
+// step выполняет один шаг в отдельной транзакции под блокировкой: advisory-блокировкой в PostgreSQL,
+// а в SQLite - блокировкой записи, которую берёт BEGIN IMMEDIATE. Список применённых миграций
+// читается уже под блокировкой, поэтому параллельно запущенные экземпляры не выполнят одну
+// миграцию дважды. Возвращает nil, если делать больше нечего.
+func (m *Migrator) step(choose func(applied map[int]bool) (*Migration, bool, error)) (*MigrationStep, error) {
+	var step *MigrationStep
+	err := m.db.Transaction(func(tx *gorm.DB) error {
+		if m.driver == DriverPostgres {
+			if err := tx.Exec("SELECT pg_advisory_xact_lock(?)", migrationLockID).Error; err != nil {
+				return fmt.Errorf("failed to acquire migration lock: %w", err)
+			}
+		}
+		if err := tx.Exec(createMigrationsTable[m.driver]).Error; err != nil {
+			return err
+		}
+
+		var versions []int
+		if err := tx.Raw("SELECT version FROM schema_migrations").Scan(&versions).Error; err != nil {
+			return err
+		}
+		applied := make(map[int]bool, len(versions))
+		for _, version := range versions {
+			applied[version] = true
+		}
+
+		migration, up, err := choose(applied)
+		if err != nil {
+			return err
+		}
+		if migration == nil {
+			return nil
+		}
+
+		name := fmt.Sprintf("%04d_%s", migration.Version, migration.Name)
+		script := migration.Down
+		if up {
+			script = migration.Up
+		}
+		if strings.TrimSpace(script) != "" {
+			if err := tx.Exec(script).Error; err != nil {
+				return fmt.Errorf("migration %s failed: %w", name, err)
+			}
+		}
+
+		if up {
+			err = tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)", migration.Version, migration.Name).Error
+		} else {
+			err = tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+		}
+		if err != nil {
+			return err
+		}
+
+		step = &MigrationStep{Migration: *migration, Up: up}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return step, nil
+}
@@ -0,0 +1,261 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := LoadMigrations(fstest.MapFS{
+		"0002_add_index.up.sql":   {Data: []byte("CREATE INDEX a ON t (c);")},
+		"0002_add_index.down.sql": {Data: []byte("DROP INDEX a;")},
+		"0001_init.up.sql":        {Data: []byte("CREATE TABLE t (c int);")},
+		"0001_init.down.sql":      {Data: []byte("DROP TABLE t;")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(migrations) != 2 || migrations[0].Version != 1 || migrations[1].Version != 2 {
+		t.Fatalf("migrations are not sorted by version: %+v", migrations)
+	}
+	if migrations[0].Name != "init" || migrations[0].Up != "CREATE TABLE t (c int);" || migrations[0].Down != "DROP TABLE t;" {
+		t.Errorf("unexpected migration: %+v", migrations[0])
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"missing down": {
+			"0001_init.up.sql": {Data: []byte("SELECT 1;")},
+		},
+		"unexpected file": {
+			"init.sql": {Data: []byte("SELECT 1;")},
+		},
+		"zero version": {
+			"0000_init.up.sql":   {Data: []byte("SELECT 1;")},
+			"0000_init.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"duplicate version": {
+			"0001_init.up.sql":    {Data: []byte("SELECT 1;")},
+			"0001_init.down.sql":  {Data: []byte("SELECT 1;")},
+			"0001_other.up.sql":   {Data: []byte("SELECT 1;")},
+			"0001_other.down.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+	for name, fsys := range invalid {
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	postgres, err := loadEmbeddedMigrations(DriverPostgres)
+	if err != nil {
+		t.Fatalf("embedded PostgreSQL migrations are invalid: %v", err)
+	}
+	for i, migration := range postgres {
+		if migration.Version != i+1 {
+			t.Errorf("expected migration %d, got %d_%s: versions must have no gaps", i+1, migration.Version, migration.Name)
+		}
+	}
+	if latest := (&Migrator{migrations: postgres}).Latest(); latest != len(postgres) {
+		t.Errorf("unexpected latest version %d", latest)
+	}
+
+	// схема в SQLite должна развиваться вместе с PostgreSQL: миграции с теми же номерами и именами
+	sqlite, err := loadEmbeddedMigrations(DriverSQLite)
+	if err != nil {
+		t.Fatalf("embedded SQLite migrations are invalid: %v", err)
+	}
+	if len(sqlite) != len(postgres) {
+		t.Fatalf("expected %d SQLite migrations, got %d", len(postgres), len(sqlite))
+	}
+	for i := range postgres {
+		if sqlite[i].Version != postgres[i].Version || sqlite[i].Name != postgres[i].Name {
+			t.Errorf("SQLite migration %d_%s does not match PostgreSQL migration %d_%s",
+				sqlite[i].Version, sqlite[i].Name, postgres[i].Version, postgres[i].Name)
+		}
+	}
+
+	if _, err := loadEmbeddedMigrations("mysql"); err == nil {
+		t.Error("expected an error for an unknown driver")
+	}
+}
+
+func TestNextStep(t *testing.T) {
+	migrations := []Migration{{Version: 1}, {Version: 2}, {Version: 3}}
+
+	tests := []struct {
+		name    string
+		applied []int
+		target  int
+		version int // 0 - делать нечего
+		up      bool
+		err     error
+	}{
+		{name: "empty database", target: 3, version: 1, up: true},
+		{name: "continue up", applied: []int{1}, target: 3, version: 2, up: true},
+		{name: "fill a gap", applied: []int{1, 3}, target: 3, version: 2, up: true},
+		{name: "up to date", applied: []int{1, 2, 3}, target: 3},
+		{name: "down from the latest", applied: []int{1, 2, 3}, target: 1, version: 3},
+		{name: "down to zero", applied: []int{1}, target: 0, version: 1},
+		{name: "partial target", applied: []int{1}, target: 2, version: 2, up: true},
+		{name: "unknown applied version", applied: []int{1, 2, 3, 4}, target: 3, err: ErrUnknownVersion},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			applied := make(map[int]bool)
+			for _, version := range tc.applied {
+				applied[version] = true
+			}
+
+			migration, up, err := nextStep(migrations, applied, tc.target)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			version := 0
+			if migration != nil {
+				version = migration.Version
+			}
+			if version != tc.version || up != tc.up {
+				t.Errorf("expected step %d (up=%v), got %d (up=%v)", tc.version, tc.up, version, up)
+			}
+		})
+	}
+}
+
+func TestMigratorSQLite(t *testing.T) {
+	db, err := InitDB(Options{Driver: DriverSQLite, Path: filepath.Join(t.TempDir(), "test.db")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps, err := migrator.Up()
+	if err != nil {
+		t.Fatalf("Up() error = %v", err)
+	}
+	if len(steps) != migrator.Latest() {
+		t.Fatalf("Up() applied %d migrations, want %d", len(steps), migrator.Latest())
+	}
+	var categories int64
+	if err := db.Table("categories").Count(&categories).Error; err != nil || categories != 22 {
+		t.Errorf("default categories = %d, %v, want 22", categories, err)
+	}
+
+	statuses, err := migrator.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, status := range statuses {
+		if status.AppliedAt == nil {
+			t.Errorf("migration %d is not marked as applied", status.Version)
+		}
+	}
+
+	// полный откат и повторное применение проверяют down-миграции
+	if _, err := migrator.To(0); err != nil {
+		t.Fatalf("To(0) error = %v", err)
+	}
+	if db.Migrator().HasTable("advertisements") {
+		t.Error("To(0) left the advertisements table")
+	}
+	if steps, err := migrator.Up(); err != nil || len(steps) != migrator.Latest() {
+		t.Fatalf("second Up() = %d steps, %v", len(steps), err)
+	}
+}
+
+// База, созданная AutoMigrate первой версии, после миграций получает все колонки моделей.
+// Нужна PostgreSQL из TEST_DATABASE_URL, без неё тест пропускается.
+func TestMigratorAdoptsAutoMigrateDatabase(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	schema := fmt.Sprintf("adopt_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+	separator := "?"
+	if strings.Contains(dsn, "?") {
+		separator = "&"
+	}
+	db, err := gorm.Open(postgres.Open(dsn+separator+"search_path="+schema), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("connect to schema: %v", err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		admin.Exec("DROP SCHEMA " + schema + " CASCADE")
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	legacy := []string{
+		`CREATE TABLE "users" ("id" bigserial PRIMARY KEY, "username" text NOT NULL UNIQUE,
+			"password" varchar(100) NOT NULL, "created_at" timestamptz)`,
+		`CREATE TABLE "advertisements" ("id" bigserial PRIMARY KEY, "title" varchar(100) NOT NULL,
+			"description" varchar(1000) NOT NULL, "image_url" text NOT NULL, "price" decimal NOT NULL,
+			"user_id" bigint NOT NULL REFERENCES "users"("id"), "created_at" timestamptz)`,
+		`INSERT INTO "users" ("username", "password", "created_at") VALUES ('alice', 'hash', now())`,
+		`INSERT INTO "advertisements" ("title", "description", "image_url", "price", "user_id", "created_at")
+			VALUES ('Велосипед', 'Горный', 'http://example.com/bike.jpg', 100, 1, now())`,
+	}
+	for _, statement := range legacy {
+		if err := db.Exec(statement).Error; err != nil {
+			t.Fatalf("create legacy schema: %v", err)
+		}
+	}
+
+	if _, err := RunMigrations(db); err != nil {
+		t.Fatalf("RunMigrations() error = %v", err)
+	}
+
+	var user struct {
+		Role        string
+		Banned      bool
+		RatingCount int
+	}
+	if err := db.Table("users").Select("role", "banned", "rating_count").Take(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	if user.Role != "user" || user.Banned || user.RatingCount != 0 {
+		t.Errorf("adopted user = %+v", user)
+	}
+
+	var ad struct {
+		Status      string
+		PublishedAt *time.Time
+	}
+	if err := db.Table("advertisements").Select("status", "published_at").Take(&ad).Error; err != nil {
+		t.Fatal(err)
+	}
+	if ad.Status != "published" || ad.PublishedAt == nil {
+		t.Errorf("adopted advertisement = %+v", ad)
+	}
+	var covers int64
+	if err := db.Table("ad_images").Where("is_cover").Count(&covers).Error; err != nil || covers != 1 {
+		t.Errorf("cover images = %d, %v, want 1", covers, err)
+	}
+}
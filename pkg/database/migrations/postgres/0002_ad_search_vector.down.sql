@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_advertisements_search_vector;
+ALTER TABLE advertisements DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,11 @@
+-- Полнотекстовый индекс объявлений. Используются обе конфигурации - russian и english,
+-- чтобы совпадали словоформы на обоих языках.
+ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('russian', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('english', coalesce(title, '')), 'A') ||
+        setweight(to_tsvector('russian', coalesce(description, '')), 'B') ||
+        setweight(to_tsvector('english', coalesce(description, '')), 'B')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_advertisements_search_vector ON advertisements USING GIN (search_vector);
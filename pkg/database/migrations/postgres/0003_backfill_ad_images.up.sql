@@ -0,0 +1,6 @@
+-- Объявления, созданные до появления галереи, получают единственное изображение-обложку из image_url
+INSERT INTO ad_images (ad_id, url, position, is_cover, created_at)
+SELECT a.id, a.image_url, 0, true, a.created_at
+FROM advertisements a
+WHERE a.image_url <> ''
+  AND NOT EXISTS (SELECT 1 FROM ad_images i WHERE i.ad_id = a.id);
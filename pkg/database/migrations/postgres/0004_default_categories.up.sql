@@ -0,0 +1,39 @@
+-- Базовый справочник категорий. Создаётся только на пустой таблице, чтобы не вернуть
+-- категории, которые уже удалили или переименовали.
+WITH parents AS (
+    INSERT INTO categories (name, slug)
+    SELECT name, slug
+    FROM (VALUES
+        (1, 'Электроника', 'electronics'),
+        (2, 'Мебель и интерьер', 'furniture-interior'),
+        (3, 'Транспорт', 'transport'),
+        (4, 'Одежда и обувь', 'clothing'),
+        (5, 'Хобби и отдых', 'hobby'),
+        (6, 'Другое', 'other')
+    ) AS seed (position, name, slug)
+    WHERE NOT EXISTS (SELECT 1 FROM categories)
+    ORDER BY position
+    RETURNING id, slug
+)
+INSERT INTO categories (name, slug, parent_id)
+SELECT seed.name, seed.slug, parents.id
+FROM (VALUES
+    (1, 'Телефоны', 'phones', 'electronics'),
+    (2, 'Ноутбуки и компьютеры', 'computers', 'electronics'),
+    (3, 'Фото и видео', 'photo-video', 'electronics'),
+    (4, 'Аудио', 'audio', 'electronics'),
+    (5, 'Мебель', 'furniture', 'furniture-interior'),
+    (6, 'Освещение', 'lighting', 'furniture-interior'),
+    (7, 'Декор', 'decor', 'furniture-interior'),
+    (8, 'Автомобили', 'cars', 'transport'),
+    (9, 'Велосипеды', 'bicycles', 'transport'),
+    (10, 'Запчасти', 'parts', 'transport'),
+    (11, 'Мужская одежда', 'mens-clothing', 'clothing'),
+    (12, 'Женская одежда', 'womens-clothing', 'clothing'),
+    (13, 'Детская одежда', 'kids-clothing', 'clothing'),
+    (14, 'Спорт', 'sport', 'hobby'),
+    (15, 'Книги', 'books', 'hobby'),
+    (16, 'Музыкальные инструменты', 'music-instruments', 'hobby')
+) AS seed (position, name, slug, parent_slug)
+JOIN parents ON parents.slug = seed.parent_slug
+ORDER BY seed.position;
@@ -0,0 +1,10 @@
+ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS last_ad_id bigint NOT NULL DEFAULT 0;
+UPDATE saved_searches s
+SET last_ad_id = COALESCE(
+    (SELECT MAX(a.id) FROM advertisements a WHERE a.created_at <= s.checked_until),
+    0
+);
+ALTER TABLE saved_searches DROP COLUMN IF EXISTS checked_until;
+
+DROP INDEX IF EXISTS idx_advertisements_published_at;
+ALTER TABLE advertisements DROP COLUMN IF EXISTS published_at;
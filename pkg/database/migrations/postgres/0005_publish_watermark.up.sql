@@ -0,0 +1,19 @@
+-- Сохранённые поиски проверяются по времени публикации, а не по id объявления: черновик,
+-- опубликованный после того, как водяной знак прошёл его id, иначе не нашёлся бы никогда.
+-- Уже опубликованные объявления считаются опубликованными в момент создания.
+ALTER TABLE advertisements ADD COLUMN IF NOT EXISTS published_at timestamptz;
+UPDATE advertisements SET published_at = created_at
+WHERE status <> 'draft' AND published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_advertisements_published_at ON advertisements (published_at);
+
+-- Водяной знак - время создания последнего проверенного объявления (или сохранения поиска).
+-- Повторно найденные объявления отсекаются уникальностью dedup_key уведомлений.
+ALTER TABLE saved_searches ADD COLUMN IF NOT EXISTS checked_until timestamptz;
+UPDATE saved_searches s
+SET checked_until = COALESCE(
+    (SELECT a.created_at FROM advertisements a WHERE a.id = s.last_ad_id),
+    s.created_at,
+    now()
+);
+ALTER TABLE saved_searches ALTER COLUMN checked_until SET NOT NULL;
+ALTER TABLE saved_searches DROP COLUMN IF EXISTS last_ad_id;
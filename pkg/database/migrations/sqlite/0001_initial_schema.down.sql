@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS "revoked_tokens";
+DROP TABLE IF EXISTS "refresh_tokens";
+DROP TABLE IF EXISTS "reviews";
+DROP TABLE IF EXISTS "offers";
+DROP TABLE IF EXISTS "messages";
+DROP TABLE IF EXISTS "conversations";
+DROP TABLE IF EXISTS "notifications";
+DROP TABLE IF EXISTS "saved_searches";
+DROP TABLE IF EXISTS "favorites";
+DROP TABLE IF EXISTS "ad_images";
+DROP TABLE IF EXISTS "advertisements";
+DROP TABLE IF EXISTS "categories";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,175 @@
+-- Схема для SQLite повторяет миграцию PostgreSQL с теми же именами таблиц, столбцов
+-- и индексов. Время хранится в столбцах datetime, по этому типу драйвер читает его как
+-- time.Time. AUTOINCREMENT, как и последовательности PostgreSQL, не выдаёт id повторно.
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "username" text NOT NULL,
+    "password" varchar(100) NOT NULL,
+    "role" varchar(20) NOT NULL DEFAULT 'user',
+    "banned" boolean NOT NULL DEFAULT false,
+    "display_name" varchar(50),
+    "avatar_url" varchar(500),
+    "city" varchar(100),
+    "about" text,
+    "rating_sum" bigint NOT NULL DEFAULT 0,
+    "rating_count" bigint NOT NULL DEFAULT 0,
+    "created_at" datetime,
+    CONSTRAINT "uni_users_username" UNIQUE ("username")
+);
+
+CREATE TABLE IF NOT EXISTS "categories" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "name" varchar(100) NOT NULL,
+    "slug" varchar(100) NOT NULL,
+    "parent_id" bigint,
+    CONSTRAINT "fk_categories_children" FOREIGN KEY ("parent_id") REFERENCES "categories"("id"),
+    CONSTRAINT "uni_categories_slug" UNIQUE ("slug")
+);
+CREATE INDEX IF NOT EXISTS "idx_categories_parent_id" ON "categories" ("parent_id");
+
+CREATE TABLE IF NOT EXISTS "advertisements" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "title" varchar(100) NOT NULL,
+    "description" varchar(1000) NOT NULL,
+    "image_url" text NOT NULL,
+    "price" decimal NOT NULL,
+    "status" varchar(20) NOT NULL DEFAULT 'published',
+    "category_id" bigint,
+    "user_id" bigint NOT NULL,
+    "created_at" datetime,
+    "deleted_at" datetime,
+    CONSTRAINT "fk_advertisements_user" FOREIGN KEY ("user_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_advertisements_deleted_at" ON "advertisements" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_advertisements_category_id" ON "advertisements" ("category_id");
+CREATE INDEX IF NOT EXISTS "idx_advertisements_status" ON "advertisements" ("status");
+
+CREATE TABLE IF NOT EXISTS "ad_images" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "ad_id" bigint NOT NULL,
+    "url" text NOT NULL,
+    "position" bigint NOT NULL,
+    "is_cover" boolean NOT NULL DEFAULT false,
+    "created_at" datetime,
+    CONSTRAINT "fk_advertisements_images" FOREIGN KEY ("ad_id") REFERENCES "advertisements"("id") ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS "idx_ad_images_ad_id" ON "ad_images" ("ad_id");
+
+CREATE TABLE IF NOT EXISTS "favorites" (
+    "user_id" bigint,
+    "ad_id" bigint,
+    "created_at" datetime,
+    PRIMARY KEY ("user_id", "ad_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_favorites_ad_id" ON "favorites" ("ad_id");
+
+CREATE TABLE IF NOT EXISTS "saved_searches" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" bigint NOT NULL,
+    "name" varchar(100) NOT NULL,
+    "query" varchar(200) NOT NULL,
+    "min_price" decimal NOT NULL DEFAULT 0,
+    "max_price" decimal NOT NULL DEFAULT 0,
+    "category_id" bigint NOT NULL DEFAULT 0,
+    "sort_by" varchar(20) NOT NULL,
+    "order" varchar(4) NOT NULL,
+    "last_ad_id" bigint NOT NULL DEFAULT 0,
+    "created_at" datetime
+);
+CREATE INDEX IF NOT EXISTS "idx_saved_searches_user_id" ON "saved_searches" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "notifications" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" bigint NOT NULL,
+    "type" varchar(50) NOT NULL,
+    "title" varchar(200) NOT NULL,
+    "ad_id" bigint,
+    "saved_search_id" bigint,
+    "dedup_key" varchar(100) NOT NULL,
+    "read_at" datetime,
+    "created_at" datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_notifications_dedup_key" ON "notifications" ("dedup_key");
+CREATE INDEX IF NOT EXISTS "idx_notifications_user_id" ON "notifications" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "conversations" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "ad_id" bigint NOT NULL,
+    "buyer_id" bigint NOT NULL,
+    "seller_id" bigint NOT NULL,
+    "buyer_unread" bigint NOT NULL DEFAULT 0,
+    "seller_unread" bigint NOT NULL DEFAULT 0,
+    "last_message_at" datetime,
+    "created_at" datetime,
+    CONSTRAINT "fk_conversations_ad" FOREIGN KEY ("ad_id") REFERENCES "advertisements"("id"),
+    CONSTRAINT "fk_conversations_buyer" FOREIGN KEY ("buyer_id") REFERENCES "users"("id"),
+    CONSTRAINT "fk_conversations_seller" FOREIGN KEY ("seller_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_conversations_last_message_at" ON "conversations" ("last_message_at");
+CREATE INDEX IF NOT EXISTS "idx_conversations_seller_id" ON "conversations" ("seller_id");
+CREATE INDEX IF NOT EXISTS "idx_conversations_buyer_id" ON "conversations" ("buyer_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_conversation_ad_buyer" ON "conversations" ("ad_id", "buyer_id");
+
+CREATE TABLE IF NOT EXISTS "messages" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "conversation_id" bigint NOT NULL,
+    "sender_id" bigint NOT NULL,
+    "body" varchar(2000) NOT NULL,
+    "created_at" datetime
+);
+CREATE INDEX IF NOT EXISTS "idx_messages_conversation_id" ON "messages" ("conversation_id");
+
+CREATE TABLE IF NOT EXISTS "offers" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "ad_id" bigint NOT NULL,
+    "buyer_id" bigint NOT NULL,
+    "seller_id" bigint NOT NULL,
+    "amount" decimal NOT NULL,
+    "counter_amount" decimal,
+    "accepted_amount" decimal,
+    "status" varchar(20) NOT NULL DEFAULT 'pending',
+    "created_at" datetime,
+    "updated_at" datetime,
+    CONSTRAINT "fk_offers_ad" FOREIGN KEY ("ad_id") REFERENCES "advertisements"("id"),
+    CONSTRAINT "fk_offers_buyer" FOREIGN KEY ("buyer_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_offers_status" ON "offers" ("status");
+CREATE INDEX IF NOT EXISTS "idx_offers_seller_id" ON "offers" ("seller_id");
+CREATE INDEX IF NOT EXISTS "idx_offers_buyer_id" ON "offers" ("buyer_id");
+CREATE INDEX IF NOT EXISTS "idx_offers_ad_id" ON "offers" ("ad_id");
+
+CREATE TABLE IF NOT EXISTS "reviews" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "offer_id" bigint NOT NULL,
+    "ad_id" bigint NOT NULL,
+    "seller_id" bigint NOT NULL,
+    "buyer_id" bigint NOT NULL,
+    "rating" bigint NOT NULL,
+    "text" text,
+    "created_at" datetime,
+    CONSTRAINT "fk_reviews_ad" FOREIGN KEY ("ad_id") REFERENCES "advertisements"("id"),
+    CONSTRAINT "fk_reviews_buyer" FOREIGN KEY ("buyer_id") REFERENCES "users"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reviews_seller_id" ON "reviews" ("seller_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reviews_offer_id" ON "reviews" ("offer_id");
+
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "id" integer PRIMARY KEY AUTOINCREMENT,
+    "user_id" bigint NOT NULL,
+    "token_hash" varchar(64) NOT NULL,
+    "family_id" varchar(32) NOT NULL,
+    "expires_at" datetime NOT NULL,
+    "revoked_at" datetime,
+    "created_at" datetime
+);
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_token_hash" ON "refresh_tokens" ("token_hash");
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+    "jti" varchar(64),
+    "expires_at" datetime NOT NULL,
+    PRIMARY KEY ("jti")
+);
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
@@ -0,0 +1,25 @@
+-- В SQLite нет tsvector, поэтому полнотекстовый индекс объявлений - внешняя таблица FTS5
+-- поверх advertisements, которую поддерживают триггеры. Токенизатор unicode61 приводит
+-- к нижнему регистру и кириллицу, но морфологии не знает.
+CREATE VIRTUAL TABLE IF NOT EXISTS advertisements_fts USING fts5(
+    title,
+    description,
+    content = 'advertisements',
+    content_rowid = 'id',
+    tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER IF NOT EXISTS advertisements_fts_insert AFTER INSERT ON advertisements BEGIN
+    INSERT INTO advertisements_fts (rowid, title, description) VALUES (new.id, new.title, new.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS advertisements_fts_delete AFTER DELETE ON advertisements BEGIN
+    INSERT INTO advertisements_fts (advertisements_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+END;
+
+CREATE TRIGGER IF NOT EXISTS advertisements_fts_update AFTER UPDATE OF title, description ON advertisements BEGIN
+    INSERT INTO advertisements_fts (advertisements_fts, rowid, title, description) VALUES ('delete', old.id, old.title, old.description);
+    INSERT INTO advertisements_fts (rowid, title, description) VALUES (new.id, new.title, new.description);
+END;
+
+INSERT INTO advertisements_fts (advertisements_fts) VALUES ('rebuild');
@@ -0,0 +1 @@
+-- Перенесённые обложки не отличить от загруженных позже, поэтому откат ничего не удаляет
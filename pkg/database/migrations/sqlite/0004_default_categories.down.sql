@@ -0,0 +1,12 @@
+-- Удаляются только категории справочника без объявлений; подкатегории раньше родителей
+DELETE FROM categories AS c
+WHERE c.parent_id IS NOT NULL
+  AND c.slug IN ('phones', 'computers', 'photo-video', 'audio', 'furniture', 'lighting', 'decor',
+                 'cars', 'bicycles', 'parts', 'mens-clothing', 'womens-clothing', 'kids-clothing',
+                 'sport', 'books', 'music-instruments')
+  AND NOT EXISTS (SELECT 1 FROM advertisements a WHERE a.category_id = c.id);
+
+DELETE FROM categories AS c
+WHERE c.slug IN ('electronics', 'furniture-interior', 'transport', 'clothing', 'hobby', 'other')
+  AND NOT EXISTS (SELECT 1 FROM categories child WHERE child.parent_id = c.id)
+  AND NOT EXISTS (SELECT 1 FROM advertisements a WHERE a.category_id = c.id);
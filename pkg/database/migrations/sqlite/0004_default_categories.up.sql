@@ -0,0 +1,46 @@
+-- Базовый справочник категорий, как в миграции PostgreSQL. Создаётся только на пустой
+-- таблице; в SQLite нет INSERT ... RETURNING внутри WITH, поэтому признак пустой таблицы
+-- запоминается до вставки родителей во временной таблице.
+CREATE TEMP TABLE default_categories_seed AS
+SELECT NOT EXISTS (SELECT 1 FROM categories) AS empty;
+
+WITH seed (position, name, slug) AS (VALUES
+    (1, 'Электроника', 'electronics'),
+    (2, 'Мебель и интерьер', 'furniture-interior'),
+    (3, 'Транспорт', 'transport'),
+    (4, 'Одежда и обувь', 'clothing'),
+    (5, 'Хобби и отдых', 'hobby'),
+    (6, 'Другое', 'other')
+)
+INSERT INTO categories (name, slug)
+SELECT name, slug
+FROM seed
+WHERE (SELECT empty FROM default_categories_seed)
+ORDER BY position;
+
+WITH seed (position, name, slug, parent_slug) AS (VALUES
+    (1, 'Телефоны', 'phones', 'electronics'),
+    (2, 'Ноутбуки и компьютеры', 'computers', 'electronics'),
+    (3, 'Фото и видео', 'photo-video', 'electronics'),
+    (4, 'Аудио', 'audio', 'electronics'),
+    (5, 'Мебель', 'furniture', 'furniture-interior'),
+    (6, 'Освещение', 'lighting', 'furniture-interior'),
+    (7, 'Декор', 'decor', 'furniture-interior'),
+    (8, 'Автомобили', 'cars', 'transport'),
+    (9, 'Велосипеды', 'bicycles', 'transport'),
+    (10, 'Запчасти', 'parts', 'transport'),
+    (11, 'Мужская одежда', 'mens-clothing', 'clothing'),
+    (12, 'Женская одежда', 'womens-clothing', 'clothing'),
+    (13, 'Детская одежда', 'kids-clothing', 'clothing'),
+    (14, 'Спорт', 'sport', 'hobby'),
+    (15, 'Книги', 'books', 'hobby'),
+    (16, 'Музыкальные инструменты', 'music-instruments', 'hobby')
+)
+INSERT INTO categories (name, slug, parent_id)
+SELECT seed.name, seed.slug, parents.id
+FROM seed
+JOIN categories parents ON parents.slug = seed.parent_slug
+WHERE (SELECT empty FROM default_categories_seed)
+ORDER BY seed.position;
+
+DROP TABLE default_categories_seed;
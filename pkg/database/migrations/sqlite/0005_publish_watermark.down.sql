@@ -0,0 +1,10 @@
+ALTER TABLE saved_searches ADD COLUMN last_ad_id bigint NOT NULL DEFAULT 0;
+UPDATE saved_searches
+SET last_ad_id = COALESCE(
+    (SELECT MAX(a.id) FROM advertisements a WHERE a.created_at <= saved_searches.checked_until),
+    0
+);
+ALTER TABLE saved_searches DROP COLUMN checked_until;
+
+DROP INDEX IF EXISTS idx_advertisements_published_at;
+ALTER TABLE advertisements DROP COLUMN published_at;
@@ -0,0 +1,16 @@
+-- Сохранённые поиски проверяются по времени публикации, а не по id объявления, как в
+-- миграции PostgreSQL. SQLite не умеет менять NOT NULL у существующей колонки, поэтому
+-- checked_until добавляется сразу с заглушкой по умолчанию и тут же заполняется.
+ALTER TABLE advertisements ADD COLUMN published_at datetime;
+UPDATE advertisements SET published_at = created_at
+WHERE status <> 'draft' AND published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_advertisements_published_at ON advertisements (published_at);
+
+ALTER TABLE saved_searches ADD COLUMN checked_until datetime NOT NULL DEFAULT '1970-01-01 00:00:00+00:00';
+UPDATE saved_searches
+SET checked_until = COALESCE(
+    (SELECT a.created_at FROM advertisements a WHERE a.id = saved_searches.last_ad_id),
+    saved_searches.created_at,
+    CURRENT_TIMESTAMP
+);
+ALTER TABLE saved_searches DROP COLUMN last_ad_id;
@@ -0,0 +1,8 @@
+-- Личные блокировки: заблокированный пользователь не может писать заблокировавшему и наоборот
+CREATE TABLE IF NOT EXISTS "user_blocks" (
+    "blocker_id" bigint,
+    "blocked_id" bigint,
+    "created_at" datetime,
+    PRIMARY KEY ("blocker_id", "blocked_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_user_blocks_blocked_id" ON "user_blocks" ("blocked_id");
@@ -1,15 +1,14 @@
 package database
 
 import (
-	"github.com/keenetic29/vk-internship/internal/domain"
 	"fmt"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-
-func InitDB(dsnCreateDB, dsnConnect, dbName string) (*gorm.DB, error) {
+// initPostgres подключается к базе dbName, создав её при необходимости
+func initPostgres(dsnCreateDB, dsnConnect, dbName string) (*gorm.DB, error) {
     // Сначала подключаемся к БД postgres (которая всегда есть)
     adminDB, err := gorm.Open(postgres.Open(dsnCreateDB), &gorm.Config{})
     if err != nil {
@@ -35,10 +34,3 @@ func InitDB(dsnCreateDB, dsnConnect, dbName string) (*gorm.DB, error) {
 
     return db, nil
 }
-
-func RunMigrations(db *gorm.DB) error {
-	return db.AutoMigrate(
-		&domain.User{},
-		&domain.Advertisement{},
-	)
-}
\ No newline at end of file
@@ -0,0 +1,27 @@
+package database
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// initSQLite открывает файл базы SQLite, создавая его при необходимости.
+// SQLite допускает одного писателя, поэтому транзакции начинаются с BEGIN IMMEDIATE
+// и сразу ждут блокировку записи (busy_timeout драйвера - 5 секунд), а не падают
+// с SQLITE_BUSY при первой записи после чтения. В режиме WAL чтение не ждёт записи.
+func initSQLite(path string) (*gorm.DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create database directory: %w", err)
+	}
+
+	dsn := path + "?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_txlock=immediate"
+	db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open SQLite database: %w", err)
+	}
+	return db, nil
+}
@@ -0,0 +1,228 @@
+// Package diskcache - LRU-кэш файлов на диске с ограничением общего размера.
+//
+// Каждая запись хранится в файле "<key>_<etag>", где etag - хэш содержимого, поэтому после
+// перезапуска индекс восстанавливается по именам файлов без их чтения. Порядок вытеснения
+// определяется временем последнего обращения (mtime файла).
+package diskcache
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrInvalidKey = errors.New("invalid cache key")
+
+type entry struct {
+	key  string
+	etag string
+	size int64
+}
+
+type Cache struct {
+	dir      string
+	maxBytes int64
+
+	mu    sync.Mutex
+	order *list.List // от недавно использованных к давно
+	items map[string]*list.Element
+	size  int64
+}
+
+// New открывает кэш в каталоге dir; записи, оставшиеся с прошлого запуска, подхватываются
+func New(dir string, maxBytes int64) (*Cache, error) {
+	if maxBytes <= 0 {
+		return nil, fmt.Errorf("cache size must be positive, got %d", maxBytes)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	c := &Cache{
+		dir:      dir,
+		maxBytes: maxBytes,
+		order:    list.New(),
+		items:    make(map[string]*list.Element),
+	}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// ValidateKey допускает ключи из латиницы, цифр и "-" длиной до 128 символов
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 128 {
+		return ErrInvalidKey
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-':
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	return nil
+}
+
+func (c *Cache) path(e *entry) string {
+	return filepath.Join(c.dir, e.key+"_"+e.etag)
+}
+
+func (c *Cache) load() error {
+	files, err := os.ReadDir(c.dir)
+	if err != nil {
+		return err
+	}
+
+	type found struct {
+		entry   *entry
+		modTime time.Time
+	}
+	var entries []found
+	for _, file := range files {
+		if !file.Type().IsRegular() {
+			continue
+		}
+		name := file.Name()
+		key, etag, ok := strings.Cut(name, "_")
+		if !ok || ValidateKey(key) != nil || etag == "" {
+			// временные файлы незавершённой записи
+			if strings.HasPrefix(name, ".tmp-") {
+				os.Remove(filepath.Join(c.dir, name))
+			}
+			continue
+		}
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		entries = append(entries, found{&entry{key: key, etag: etag, size: info.Size()}, info.ModTime()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].modTime.After(entries[j].modTime) })
+	for _, f := range entries {
+		if _, ok := c.items[f.entry.key]; ok {
+			// дубликат ключа: актуальна более свежая запись
+			os.Remove(c.path(f.entry))
+			continue
+		}
+		c.items[f.entry.key] = c.order.PushBack(f.entry)
+		c.size += f.entry.size
+	}
+	c.evict()
+	return nil
+}
+
+// Get возвращает содержимое записи и её etag
+func (c *Cache) Get(key string) ([]byte, string, bool) {
+	c.mu.Lock()
+	elem, ok := c.items[key]
+	if !ok {
+		c.mu.Unlock()
+		return nil, "", false
+	}
+	c.order.MoveToFront(elem)
+	e := elem.Value.(*entry)
+	c.mu.Unlock()
+
+	name := c.path(e)
+	data, err := os.ReadFile(name)
+	if err != nil {
+		// файл удалили в обход кэша
+		if errors.Is(err, fs.ErrNotExist) {
+			c.mu.Lock()
+			if current, ok := c.items[key]; ok && current == elem {
+				c.remove(elem)
+			}
+			c.mu.Unlock()
+		}
+		return nil, "", false
+	}
+
+	now := time.Now()
+	os.Chtimes(name, now, now)
+	return data, e.etag, true
+}
+
+// Put сохраняет запись и возвращает её etag; при превышении лимита вытесняются давние записи
+func (c *Cache) Put(key string, data []byte) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+	e := &entry{key: key, etag: hex.EncodeToString(sum[:16]), size: int64(len(data))}
+	if e.size > c.maxBytes {
+		return e.etag, nil
+	}
+
+	tmp, err := os.CreateTemp(c.dir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := os.Rename(tmp.Name(), c.path(e)); err != nil {
+		return "", err
+	}
+	if elem, ok := c.items[key]; ok {
+		old := elem.Value.(*entry)
+		if old.etag != e.etag {
+			os.Remove(c.path(old))
+		}
+		c.order.Remove(elem)
+		delete(c.items, key)
+		c.size -= old.size
+	}
+
+	c.items[key] = c.order.PushFront(e)
+	c.size += e.size
+	c.evict()
+	return e.etag, nil
+}
+
+// Size возвращает суммарный размер записей в байтах
+func (c *Cache) Size() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.size
+}
+
+func (c *Cache) evict() {
+	for c.size > c.maxBytes {
+		elem := c.order.Back()
+		if elem == nil {
+			return
+		}
+		os.Remove(c.path(elem.Value.(*entry)))
+		c.remove(elem)
+	}
+}
+
+func (c *Cache) remove(elem *list.Element) {
+	e := elem.Value.(*entry)
+	c.order.Remove(elem)
+	delete(c.items, e.key)
+	c.size -= e.size
+}
@@ -0,0 +1,88 @@
+package diskcache
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCache(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := New(dir, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	etagA, err := cache.Put("a", []byte("aaaa"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Put("b", []byte("bbbb")); err != nil {
+		t.Fatal(err)
+	}
+
+	data, etag, ok := cache.Get("a")
+	if !ok || !bytes.Equal(data, []byte("aaaa")) || etag != etagA {
+		t.Fatalf("Get(a) = %q, %q, %v", data, etag, ok)
+	}
+
+	// "a" только что прочитан, поэтому вытесняется "b"
+	if _, err := cache.Put("c", []byte("cccc")); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := cache.Get("b"); ok {
+		t.Error("b should be evicted")
+	}
+	if _, _, ok := cache.Get("a"); !ok {
+		t.Error("a should stay in cache")
+	}
+	if size := cache.Size(); size != 8 {
+		t.Errorf("size = %d, want 8", size)
+	}
+
+	// новое содержимое - новый etag, старый файл удаляется
+	etagC, _ := cache.Put("c", []byte("CCCC"))
+	if _, etag, _ := cache.Get("c"); etag != etagC {
+		t.Errorf("etag = %q, want %q", etag, etagC)
+	}
+	files, _ := os.ReadDir(dir)
+	if len(files) != 2 {
+		t.Errorf("got %d files, want 2", len(files))
+	}
+
+	if _, err := cache.Put("../x", []byte("x")); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected %v, got %v", ErrInvalidKey, err)
+	}
+}
+
+func TestCacheReload(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := New(dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	etag, _ := cache.Put("old", []byte("1234"))
+	cache.Put("new", []byte("5678"))
+
+	// "old" использовался давно
+	past := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(dir, "old_"+etag), past, past)
+	os.WriteFile(filepath.Join(dir, ".tmp-123"), []byte("partial"), 0o644)
+
+	reopened, err := New(dir, 6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, ok := reopened.Get("old"); ok {
+		t.Error("least recently used entry should be evicted on load")
+	}
+	if data, _, ok := reopened.Get("new"); !ok || string(data) != "5678" {
+		t.Errorf("Get(new) = %q, %v", data, ok)
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".tmp-123")); !os.IsNotExist(err) {
+		t.Error("temporary files should be removed on load")
+	}
+}
@@ -0,0 +1,148 @@
+// Package events рассылает события подписчикам в пределах процесса (Hub)
+// и между репликами через PostgreSQL LISTEN/NOTIFY (PGBroker).
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// Размер очереди подписчика по умолчанию
+const DefaultBuffer = 64
+
+var (
+	ErrClosed = errors.New("event hub is closed")
+	// подписчик не успевал забирать события, и его очередь переполнилась
+	ErrSlowSubscriber = errors.New("subscriber is too slow")
+)
+
+type Event struct {
+	Type string `json:"type"`
+	// получатель события; 0 - все подписчики
+	UserID uint            `json:"user_id,omitempty"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// New собирает событие, сериализуя data в JSON
+func New(eventType string, userID uint, data any) Event {
+	raw, _ := marshal(data)
+	return Event{Type: eventType, UserID: userID, Data: raw}
+}
+
+// marshal - json.Marshal без экранирования <, > и &, которое раздувает NOTIFY
+func marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+type Subscription struct {
+	userID uint
+	ch     chan Event
+	err    error
+}
+
+// Events возвращает канал событий; канал закрывается при отписке, переполнении очереди
+// или закрытии Hub, причину сообщает Err
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+func (s *Subscription) Err() error {
+	return s.err
+}
+
+// Hub раздаёт события подписчикам. Publish никогда не блокируется: подписчик
+// с переполненной очередью отключается и должен переподключиться.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	buffer int
+	closed bool
+}
+
+func NewHub(buffer int) *Hub {
+	if buffer < 1 {
+		buffer = DefaultBuffer
+	}
+	return &Hub{
+		subs:   make(map[*Subscription]struct{}),
+		buffer: buffer,
+	}
+}
+
+// Subscribe подписывает пользователя на его события и события для всех
+func (h *Hub) Subscribe(userID uint) (*Subscription, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil, ErrClosed
+	}
+
+	sub := &Subscription{userID: userID, ch: make(chan Event, h.buffer)}
+	h.subs[sub] = struct{}{}
+	return sub, nil
+}
+
+func (h *Hub) Unsubscribe(sub *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.drop(sub, nil)
+}
+
+// drop отключает подписчика; вызывается под h.mu
+func (h *Hub) drop(sub *Subscription, err error) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	sub.err = err
+	close(sub.ch)
+}
+
+// Publish доставляет событие подписчикам этого процесса
+func (h *Hub) Publish(event Event) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return ErrClosed
+	}
+
+	for sub := range h.subs {
+		if event.UserID != 0 && event.UserID != sub.userID {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			h.drop(sub, ErrSlowSubscriber)
+		}
+	}
+	return nil
+}
+
+// Len возвращает число активных подписчиков
+func (h *Hub) Len() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+// Close отключает всех подписчиков и запрещает новые подписки
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.closed = true
+	for sub := range h.subs {
+		h.drop(sub, ErrClosed)
+	}
+}
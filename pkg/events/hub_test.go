@@ -0,0 +1,84 @@
+package events
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHub_Routing(t *testing.T) {
+	hub := NewHub(4)
+	alice, _ := hub.Subscribe(1)
+	bob, _ := hub.Subscribe(2)
+
+	hub.Publish(New("ad_created", 0, map[string]int{"id": 7}))
+	hub.Publish(New("message", 2, map[string]string{"text": "hi"}))
+
+	if got := len(alice.Events()); got != 1 {
+		t.Errorf("Alice must receive only the broadcast, got %d events", got)
+	}
+	if got := len(bob.Events()); got != 2 {
+		t.Errorf("Bob must receive both events, got %d", got)
+	}
+
+	event := <-alice.Events()
+	if event.Type != "ad_created" || string(event.Data) != `{"id":7}` {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	hub.Unsubscribe(alice)
+	if _, ok := <-alice.Events(); ok {
+		t.Error("Channel must be closed after Unsubscribe")
+	}
+	if alice.Err() != nil {
+		t.Errorf("Unsubscribe is not an error, got %v", alice.Err())
+	}
+	// повторная отписка безопасна
+	hub.Unsubscribe(alice)
+}
+
+func TestHub_SlowSubscriber(t *testing.T) {
+	hub := NewHub(2)
+	slow, _ := hub.Subscribe(1)
+	fast, _ := hub.Subscribe(1)
+
+	for i := 0; i < 3; i++ {
+		hub.Publish(New("ad_created", 0, i))
+		<-fast.Events()
+	}
+
+	for range slow.Events() {
+	}
+	if !errors.Is(slow.Err(), ErrSlowSubscriber) {
+		t.Errorf("Expected ErrSlowSubscriber, got %v", slow.Err())
+	}
+	if hub.Len() != 1 {
+		t.Errorf("Slow subscriber must be dropped, %d left", hub.Len())
+	}
+}
+
+func TestHub_Close(t *testing.T) {
+	hub := NewHub(1)
+	sub, _ := hub.Subscribe(1)
+
+	hub.Close()
+
+	if _, ok := <-sub.Events(); ok || !errors.Is(sub.Err(), ErrClosed) {
+		t.Errorf("Subscription must be closed with ErrClosed, got %v", sub.Err())
+	}
+	if _, err := hub.Subscribe(1); !errors.Is(err, ErrClosed) {
+		t.Errorf("Subscribe after Close: expected ErrClosed, got %v", err)
+	}
+	if err := hub.Publish(New("ad_created", 0, nil)); !errors.Is(err, ErrClosed) {
+		t.Errorf("Publish after Close: expected ErrClosed, got %v", err)
+	}
+}
+
+func TestPGBroker_PayloadLimit(t *testing.T) {
+	broker := NewPGBroker(nil, "", DefaultChannel, NewHub(1))
+
+	err := broker.Publish(New("message", 1, strings.Repeat("я", MaxPayload)))
+	if !errors.Is(err, ErrPayloadTooLarge) {
+		t.Errorf("Expected ErrPayloadTooLarge, got %v", err)
+	}
+}
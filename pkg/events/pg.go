@@ -0,0 +1,99 @@
+package events
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// Канал LISTEN/NOTIFY по умолчанию
+const DefaultChannel = "marketplace_events"
+
+// MaxPayload - предел размера NOTIFY в PostgreSQL (8000 байт) с запасом
+const MaxPayload = 7900
+
+var ErrPayloadTooLarge = errors.New("event payload is too large")
+
+// PGBroker рассылает события всем репликам: Publish отправляет NOTIFY,
+// а Run слушает канал и передаёт полученные события (в том числе свои) в локальный Hub.
+// События, отправленные, пока слушатель переподключается, теряются.
+type PGBroker struct {
+	db      *sql.DB
+	dsn     string
+	channel string
+	hub     *Hub
+}
+
+func NewPGBroker(db *sql.DB, dsn, channel string, hub *Hub) *PGBroker {
+	return &PGBroker{db: db, dsn: dsn, channel: channel, hub: hub}
+}
+
+func (b *PGBroker) Publish(event Event) error {
+	payload, err := marshal(event)
+	if err != nil {
+		return err
+	}
+	if len(payload) > MaxPayload {
+		return fmt.Errorf("%w: %d bytes", ErrPayloadTooLarge, len(payload))
+	}
+
+	_, err = b.db.Exec("SELECT pg_notify($1, $2)", b.channel, string(payload))
+	return err
+}
+
+// Run слушает канал до отмены ctx, переподключаясь при обрыве соединения.
+// Ошибки подключения передаются в onError.
+func (b *PGBroker) Run(ctx context.Context, onError func(error)) {
+	backoff := time.Second
+	for {
+		started := time.Now()
+		err := b.listen(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		onError(err)
+
+		// соединение, проработавшее долго, переподключаем сразу с минимальной задержкой
+		if time.Since(started) > time.Minute {
+			backoff = time.Second
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, 30*time.Second)
+	}
+}
+
+func (b *PGBroker) listen(ctx context.Context) error {
+	conn, err := pgx.Connect(ctx, b.dsn)
+	if err != nil {
+		return err
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{b.channel}.Sanitize()); err != nil {
+		return err
+	}
+
+	for {
+		notification, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+
+		var event Event
+		if err := json.Unmarshal([]byte(notification.Payload), &event); err != nil {
+			continue
+		}
+		if err := b.hub.Publish(event); err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Package imagecheck проверяет изображение по ссылке: тип определяется по сигнатуре файла,
+// а размеры в пикселях - по заголовку изображения, без загрузки всего файла.
+package imagecheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	_ "golang.org/x/image/webp"
+)
+
+// Сколько байт от начала файла читается для определения типа и размеров.
+// У JPEG перед заголовком кадра могут идти крупные EXIF-блоки, поэтому запас большой.
+const HeaderBytes = 256 * 1024
+
+var (
+	ErrUnreachable     = errors.New("image is unreachable")
+	ErrUnsupportedType = errors.New("unsupported image format")
+	ErrTypeMismatch    = errors.New("declared content type does not match image data")
+	ErrTooLarge        = errors.New("image size exceeds maximum limit")
+	ErrDimensions      = errors.New("image dimensions are out of range")
+	ErrMalformed       = errors.New("unable to read image header")
+)
+
+type Doer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+type Limits struct {
+	AllowedTypes []string
+	MaxSize      int64
+	MinWidth     int
+	MinHeight    int
+	MaxWidth     int
+	MaxHeight    int
+}
+
+type Result struct {
+	ContentType string
+	Size        int64 // -1, если сервер не сообщил размер
+	Width       int
+	Height      int
+}
+
+// declaredType нормализует Content-Type; пустой и application/octet-stream считаются незаявленными
+func declaredType(header string) string {
+	if header == "" {
+		return ""
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil {
+		return strings.ToLower(strings.TrimSpace(header))
+	}
+	switch mediaType {
+	case "application/octet-stream", "binary/octet-stream":
+		return ""
+	case "image/jpg", "image/pjpeg":
+		return "image/jpeg"
+	}
+	return mediaType
+}
+
+// totalSize берёт полный размер файла из Content-Range ("bytes 0-99/1234") или Content-Length
+func totalSize(resp *http.Response) int64 {
+	if resp.StatusCode == http.StatusPartialContent {
+		if contentRange := resp.Header.Get("Content-Range"); contentRange != "" {
+			if i := strings.LastIndex(contentRange, "/"); i >= 0 {
+				if size, err := strconv.ParseInt(contentRange[i+1:], 10, 64); err == nil {
+					return size
+				}
+			}
+		}
+		return -1
+	}
+	return resp.ContentLength
+}
+
+func (l Limits) allowed(contentType string) bool {
+	for _, allowed := range l.AllowedTypes {
+		if contentType == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func (l Limits) checkSize(size int64) error {
+	if l.MaxSize > 0 && size > l.MaxSize {
+		return fmt.Errorf("%w: %d bytes", ErrTooLarge, size)
+	}
+	return nil
+}
+
+func (l Limits) checkDeclared(declared string) error {
+	if declared != "" && !l.allowed(declared) {
+		return fmt.Errorf("%w: %s", ErrUnsupportedType, declared)
+	}
+	return nil
+}
+
+// head делает HEAD-запрос. Неудача не считается ошибкой: многие серверы HEAD не поддерживают,
+// тогда всё проверяется по GET. ok == false означает, что ответа нет.
+func head(ctx context.Context, client Doer, url string) (declared string, size int64, ok bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, url, nil)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", 0, false, nil
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", 0, false, nil
+	}
+	return declaredType(resp.Header.Get("Content-Type")), resp.ContentLength, true, nil
+}
+
+// Check проверяет изображение по ссылке:
+//  1. HEAD (если поддерживается) - ранний отказ по заявленному типу или размеру;
+//  2. GET с Range на первые HeaderBytes байт - тип по сигнатуре и размеры по заголовку.
+//
+// Заявленный сервером тип, если он есть, должен совпадать с определённым по содержимому.
+func Check(ctx context.Context, client Doer, url string, limits Limits) (*Result, error) {
+	headType, headSize, headOK, err := head(ctx, client, url)
+	if err != nil {
+		return nil, err
+	}
+	if headOK {
+		if err := limits.checkDeclared(headType); err != nil {
+			return nil, err
+		}
+		if err := limits.checkSize(headSize); err != nil {
+			return nil, err
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", HeaderBytes-1))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		return nil, fmt.Errorf("%w: status %d", ErrUnreachable, resp.StatusCode)
+	}
+
+	// сервер мог проигнорировать Range и отдавать файл целиком - читаем только начало
+	data, err := io.ReadAll(io.LimitReader(resp.Body, HeaderBytes))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnreachable, err)
+	}
+
+	size := totalSize(resp)
+	if size < 0 && headOK {
+		size = headSize
+	}
+	if err := limits.checkSize(size); err != nil {
+		return nil, err
+	}
+
+	detected := http.DetectContentType(data)
+	if !limits.allowed(detected) {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, detected)
+	}
+
+	for _, declared := range []string{headType, declaredType(resp.Header.Get("Content-Type"))} {
+		if declared != "" && declared != detected {
+			return nil, fmt.Errorf("%w: declared %s, detected %s", ErrTypeMismatch, declared, detected)
+		}
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrMalformed, err)
+	}
+
+	if config.Width < limits.MinWidth || config.Height < limits.MinHeight ||
+		(limits.MaxWidth > 0 && config.Width > limits.MaxWidth) ||
+		(limits.MaxHeight > 0 && config.Height > limits.MaxHeight) {
+		return nil, fmt.Errorf("%w: %dx%d", ErrDimensions, config.Width, config.Height)
+	}
+
+	return &Result{
+		ContentType: detected,
+		Size:        size,
+		Width:       config.Width,
+		Height:      config.Height,
+	}, nil
+}
@@ -0,0 +1,174 @@
+package imagecheck
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func encode(t *testing.T, format string, width, height int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	var err error
+	switch format {
+	case "png":
+		err = png.Encode(&buf, img)
+	default:
+		err = jpeg.Encode(&buf, img, nil)
+	}
+	if err != nil {
+		t.Fatalf("encode %s: %v", format, err)
+	}
+	return buf.Bytes()
+}
+
+// file описывает, как тестовый сервер отдаёт изображение
+type file struct {
+	data        []byte
+	contentType string
+	noHead      bool // HEAD отвечает 405
+	ignoreRange bool // Range игнорируется, файл отдаётся целиком
+}
+
+func TestCheck(t *testing.T) {
+	jpegImage := encode(t, "jpeg", 200, 150)
+	pngImage := encode(t, "png", 300, 300)
+
+	files := map[string]file{
+		"/photo.jpg":     {data: jpegImage, contentType: "image/jpeg"},
+		"/photo.png":     {data: pngImage, contentType: "image/png"},
+		"/no-head.jpg":   {data: jpegImage, contentType: "image/jpeg", noHead: true},
+		"/full.jpg":      {data: jpegImage, contentType: "image/jpg", ignoreRange: true},
+		"/untyped.png":   {data: pngImage},
+		"/octet.png":     {data: pngImage, contentType: "application/octet-stream"},
+		"/liar.jpg":      {data: pngImage, contentType: "image/jpeg"},
+		"/html.jpg":      {data: []byte("<!DOCTYPE html><html></html>"), contentType: "image/jpeg"},
+		"/page.html":     {data: []byte("<!DOCTYPE html><html></html>"), contentType: "text/html"},
+		"/gif.gif":       {data: []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00;"), contentType: "image/gif"},
+		"/small.jpg":     {data: encode(t, "jpeg", 50, 50), contentType: "image/jpeg"},
+		"/wide.png":      {data: encode(t, "png", 1200, 100), contentType: "image/png"},
+		"/truncated.png": {data: pngImage[:20], contentType: "image/png"},
+	}
+
+	var gets int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Method == http.MethodHead && f.noHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if r.Method == http.MethodGet {
+			gets++
+		}
+		if f.contentType != "" {
+			w.Header().Set("Content-Type", f.contentType)
+		} else {
+			// без явного заголовка net/http подставит тип сам
+			w.Header()["Content-Type"] = nil
+		}
+		if r.Method == http.MethodGet && r.Header.Get("Range") != "" && !f.ignoreRange {
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(f.data)-1, len(f.data)))
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.Header().Set("Content-Length", fmt.Sprint(len(f.data)))
+		}
+		if r.Method == http.MethodGet {
+			w.Write(f.data)
+		}
+	}))
+	defer server.Close()
+
+	limits := Limits{
+		AllowedTypes: []string{"image/jpeg", "image/png", "image/webp"},
+		MaxSize:      1 << 20,
+		MinWidth:     100,
+		MinHeight:    100,
+		MaxWidth:     1000,
+		MaxHeight:    1000,
+	}
+
+	valid := []struct {
+		path        string
+		contentType string
+		width       int
+		height      int
+	}{
+		{"/photo.jpg", "image/jpeg", 200, 150},
+		{"/photo.png", "image/png", 300, 300},
+		{"/no-head.jpg", "image/jpeg", 200, 150},
+		{"/full.jpg", "image/jpeg", 200, 150},
+		{"/untyped.png", "image/png", 300, 300},
+		{"/octet.png", "image/png", 300, 300},
+	}
+	for _, tt := range valid {
+		t.Run(tt.path, func(t *testing.T) {
+			result, err := Check(context.Background(), server.Client(), server.URL+tt.path, limits)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result.ContentType != tt.contentType || result.Width != tt.width || result.Height != tt.height {
+				t.Errorf("got %s %dx%d, want %s %dx%d",
+					result.ContentType, result.Width, result.Height, tt.contentType, tt.width, tt.height)
+			}
+			if result.Size != int64(len(files[tt.path].data)) {
+				t.Errorf("got size %d, want %d", result.Size, len(files[tt.path].data))
+			}
+		})
+	}
+
+	invalid := []struct {
+		path    string
+		wantErr error
+	}{
+		{"/liar.jpg", ErrTypeMismatch},
+		{"/html.jpg", ErrUnsupportedType},
+		{"/page.html", ErrUnsupportedType},
+		{"/gif.gif", ErrUnsupportedType},
+		{"/small.jpg", ErrDimensions},
+		{"/wide.png", ErrDimensions},
+		{"/truncated.png", ErrMalformed},
+		{"/missing.jpg", ErrUnreachable},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.path, func(t *testing.T) {
+			_, err := Check(context.Background(), server.Client(), server.URL+tt.path, limits)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Run("Declared type rejected before download", func(t *testing.T) {
+		before := gets
+		_, err := Check(context.Background(), server.Client(), server.URL+"/page.html", limits)
+		if !errors.Is(err, ErrUnsupportedType) {
+			t.Fatalf("expected %v, got %v", ErrUnsupportedType, err)
+		}
+		if gets != before {
+			t.Error("GET should not be sent when HEAD declares an unsupported type")
+		}
+	})
+
+	t.Run("Size limit", func(t *testing.T) {
+		small := limits
+		small.MaxSize = 100
+		for _, path := range []string{"/photo.jpg", "/no-head.jpg"} {
+			_, err := Check(context.Background(), server.Client(), server.URL+path, small)
+			if !errors.Is(err, ErrTooLarge) {
+				t.Errorf("%s: expected %v, got %v", path, ErrTooLarge, err)
+			}
+		}
+	})
+}
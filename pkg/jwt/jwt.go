@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 	"errors"
 	"github.com/golang-jwt/jwt/v5"
@@ -11,26 +13,44 @@ var (
 )
 
 type Claims struct {
-	UserID uint `json:"user_id"`
+	UserID uint   `json:"user_id"`
+	Role   string `json:"role,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID uint, secret string, expiresIn time.Duration) (string, error) {
+// NewTokenID генерирует случайный идентификатор для claim jti
+func NewTokenID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateToken подписывает токен активным ключом связки (kid попадает в заголовок)
+func (k *Keyring) GenerateToken(userID uint, role string, expiresIn time.Duration) (string, error) {
+	tokenID, err := NewTokenID()
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	claims := Claims{
 		UserID: userID,
+		Role:   role,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(expiresIn)),
+			ID:        tokenID,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(expiresIn)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(secret))
+	return k.sign(claims)
 }
 
-func ParseToken(tokenString, secret string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(secret), nil
-	})
+// ParseToken проверяет подпись ключом из заголовка kid
+func (k *Keyring) ParseToken(tokenString string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, k.keyFunc)
 
 	if err != nil {
 		return nil, err
@@ -41,4 +61,4 @@ func ParseToken(tokenString, secret string) (*Claims, error) {
 	}
 
 	return nil, ErrInvalidToken
-}
\ No newline at end of file
+}
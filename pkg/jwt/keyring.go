@@ -0,0 +1,158 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Идентификатор HMAC-ключа из JWT_SECRET. Им же проверяются токены без заголовка kid,
+// выпущенные до появления ротации ключей.
+const DefaultHMACKeyID = "default"
+
+const minRSAKeyBits = 2048
+
+var (
+	ErrUnknownKey = errors.New("unknown signing key")
+	ErrCannotSign = errors.New("key cannot be used for signing")
+)
+
+// Key - ключ подписи с идентификатором kid. Ключ, загруженный только из публичной части,
+// годится лишь для проверки подписи.
+type Key struct {
+	ID        string
+	Method    jwt.SigningMethod
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+func NewHMACKey(id string, secret []byte) *Key {
+	return &Key{
+		ID:        id,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// ParseKeyPEM разбирает RSA (RS256) или Ed25519 (EdDSA) ключ.
+// Поддерживаются приватные ключи PKCS#1/PKCS#8 и публичные ключи PKIX.
+func ParseKeyPEM(id string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("key %q: no PEM block found", id)
+	}
+
+	var parsed interface{}
+	var err error
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("key %q: unsupported PEM block %q", id, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	key := &Key{ID: id}
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodRS256, k, &k.PublicKey
+	case *rsa.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodRS256, k
+	case ed25519.PrivateKey:
+		key.Method, key.signKey, key.verifyKey = jwt.SigningMethodEdDSA, k, k.Public()
+	case ed25519.PublicKey:
+		key.Method, key.verifyKey = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("key %q: unsupported key type %T", id, parsed)
+	}
+
+	if pub, ok := key.verifyKey.(*rsa.PublicKey); ok && pub.N.BitLen() < minRSAKeyBits {
+		return nil, fmt.Errorf("key %q: RSA key must be at least %d bits", id, minRSAKeyBits)
+	}
+
+	return key, nil
+}
+
+func LoadKeyFile(id, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+	return ParseKeyPEM(id, data)
+}
+
+// Keyring хранит несколько ключей: активным подписываются новые токены,
+// остальные используются только для проверки ранее выданных.
+type Keyring struct {
+	keys   map[string]*Key
+	order  []string
+	active *Key
+}
+
+func NewKeyring(activeID string, keys ...*Key) (*Keyring, error) {
+	keyring := &Keyring{keys: make(map[string]*Key)}
+	for _, key := range keys {
+		if _, exists := keyring.keys[key.ID]; exists {
+			return nil, fmt.Errorf("duplicate key id %q", key.ID)
+		}
+		keyring.keys[key.ID] = key
+		keyring.order = append(keyring.order, key.ID)
+	}
+
+	active, ok := keyring.keys[activeID]
+	if !ok {
+		return nil, fmt.Errorf("active key %q: %w", activeID, ErrUnknownKey)
+	}
+	if !active.CanSign() {
+		return nil, fmt.Errorf("active key %q: %w", activeID, ErrCannotSign)
+	}
+	keyring.active = active
+
+	return keyring, nil
+}
+
+func (k *Keyring) ActiveKeyID() string {
+	return k.active.ID
+}
+
+func (k *Keyring) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.active.Method, claims)
+	token.Header["kid"] = k.active.ID
+	return token.SignedString(k.active.signKey)
+}
+
+// keyFunc выбирает ключ по kid и не допускает подмены алгоритма
+func (k *Keyring) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		kid = DefaultHMACKeyID
+	}
+
+	key, ok := k.keys[kid]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, ErrInvalidToken
+	}
+
+	return key.verifyKey, nil
+}
@@ -0,0 +1,141 @@
+package jwt
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func rsaKeyPEM(t *testing.T) []byte {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func ed25519KeyPEM(t *testing.T) (private, public []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	privDER, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER})
+}
+
+func TestKeyring(t *testing.T) {
+	hmacKey := NewHMACKey(DefaultHMACKeyID, []byte("secret"))
+	rsaKey, err := ParseKeyPEM("rsa-1", rsaKeyPEM(t))
+	if err != nil {
+		t.Fatalf("ParseKeyPEM(rsa) failed: %v", err)
+	}
+	edPrivPEM, edPubPEM := ed25519KeyPEM(t)
+	edKey, err := ParseKeyPEM("ed-1", edPrivPEM)
+	if err != nil {
+		t.Fatalf("ParseKeyPEM(ed25519) failed: %v", err)
+	}
+
+	t.Run("Sign and parse with each algorithm", func(t *testing.T) {
+		for _, key := range []*Key{hmacKey, rsaKey, edKey} {
+			keyring, err := NewKeyring(key.ID, hmacKey, rsaKey, edKey)
+			if err != nil {
+				t.Fatalf("NewKeyring failed: %v", err)
+			}
+
+			token, err := keyring.GenerateToken(42, "admin", time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateToken(%s) failed: %v", key.ID, err)
+			}
+
+			claims, err := keyring.ParseToken(token)
+			if err != nil {
+				t.Fatalf("ParseToken(%s) failed: %v", key.ID, err)
+			}
+			if claims.UserID != 42 || claims.Role != "admin" || claims.ID == "" {
+				t.Errorf("unexpected claims for %s: %+v", key.ID, claims)
+			}
+		}
+	})
+
+	t.Run("Old key still verifies after rotation", func(t *testing.T) {
+		oldKeyring, _ := NewKeyring("rsa-1", rsaKey)
+		token, _ := oldKeyring.GenerateToken(1, "user", time.Minute)
+
+		rotated, err := NewKeyring("ed-1", edKey, rsaKey)
+		if err != nil {
+			t.Fatalf("NewKeyring failed: %v", err)
+		}
+		if _, err := rotated.ParseToken(token); err != nil {
+			t.Errorf("token signed by retired key should be valid: %v", err)
+		}
+
+		withoutOld, _ := NewKeyring("ed-1", edKey)
+		if _, err := withoutOld.ParseToken(token); err == nil {
+			t.Error("token signed by removed key should be rejected")
+		}
+	})
+
+	t.Run("Token without kid uses default HMAC key", func(t *testing.T) {
+		claims := Claims{UserID: 7, RegisteredClaims: jwt.RegisteredClaims{ID: "legacy"}}
+		token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+
+		keyring, _ := NewKeyring("rsa-1", hmacKey, rsaKey)
+		parsed, err := keyring.ParseToken(token)
+		if err != nil {
+			t.Fatalf("legacy token should be valid: %v", err)
+		}
+		if parsed.UserID != 7 {
+			t.Errorf("expected user 7, got %d", parsed.UserID)
+		}
+	})
+
+	t.Run("Algorithm mismatch is rejected", func(t *testing.T) {
+		// HS256-токен с kid асимметричного ключа не должен проверяться его публичной частью
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{UserID: 1})
+		token.Header["kid"] = "rsa-1"
+		signed, _ := token.SignedString([]byte("secret"))
+
+		keyring, _ := NewKeyring("rsa-1", hmacKey, rsaKey)
+		if _, err := keyring.ParseToken(signed); err == nil {
+			t.Error("token with mismatched algorithm should be rejected")
+		}
+	})
+
+	t.Run("Public key cannot be active", func(t *testing.T) {
+		pubKey, err := ParseKeyPEM("ed-pub", edPubPEM)
+		if err != nil {
+			t.Fatalf("ParseKeyPEM(public) failed: %v", err)
+		}
+		if _, err := NewKeyring("ed-pub", pubKey); err == nil {
+			t.Error("verification-only key should not be accepted as active")
+		}
+		if _, err := NewKeyring("missing", hmacKey); err == nil {
+			t.Error("unknown active key should be rejected")
+		}
+	})
+
+	t.Run("JWKS exposes only public keys", func(t *testing.T) {
+		keyring, _ := NewKeyring(DefaultHMACKeyID, hmacKey, rsaKey, edKey)
+		set := keyring.JWKS()
+
+		if len(set.Keys) != 2 {
+			t.Fatalf("expected 2 public keys, got %d", len(set.Keys))
+		}
+		if set.Keys[0].Kid != "rsa-1" || set.Keys[0].Kty != "RSA" || set.Keys[0].E != "AQAB" || set.Keys[0].N == "" {
+			t.Errorf("unexpected RSA JWK: %+v", set.Keys[0])
+		}
+		if set.Keys[1].Kid != "ed-1" || set.Keys[1].Kty != "OKP" || set.Keys[1].Crv != "Ed25519" || set.Keys[1].X == "" {
+			t.Errorf("unexpected Ed25519 JWK: %+v", set.Keys[1])
+		}
+	})
+}
@@ -15,6 +15,12 @@ var (
 )
 
 func Init(debug string, logDir string, logName string) error {
+	return InitWithConsole(debug, logDir, logName, os.Stdout)
+}
+
+// InitWithConsole - как Init, но дублирует лог в console вместо stdout
+// (служебные команды пишут в stdout свой результат)
+func InitWithConsole(debug string, logDir string, logName string, console io.Writer) error {
 	mu.Lock()
 	defer mu.Unlock()
 
@@ -33,7 +39,7 @@ func Init(debug string, logDir string, logName string) error {
 		level = slog.LevelDebug
 	}
 
-	multiWriter := io.MultiWriter(os.Stdout, file)
+	multiWriter := io.MultiWriter(console, file)
 
 	handler := slog.NewJSONHandler(multiWriter, &slog.HandlerOptions{
 		Level:     level,
@@ -0,0 +1,151 @@
+package safehttp
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+)
+
+type fakeResolver struct {
+	mu      sync.Mutex
+	hosts   map[string][]string
+	lookups map[string]int
+}
+
+func newFakeResolver(hosts map[string][]string) *fakeResolver {
+	return &fakeResolver{hosts: hosts, lookups: make(map[string]int)}
+}
+
+func (r *fakeResolver) LookupIPAddr(ctx context.Context, host string) ([]net.IPAddr, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lookups[host]++
+
+	ips, ok := r.hosts[host]
+	if !ok {
+		return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+	}
+	addrs := make([]net.IPAddr, 0, len(ips))
+	for _, ip := range ips {
+		addrs = append(addrs, net.IPAddr{IP: net.ParseIP(ip)})
+	}
+	return addrs, nil
+}
+
+func TestIsBlocked(t *testing.T) {
+	blocked := []string{
+		"127.0.0.1", "10.1.2.3", "172.16.0.1", "192.168.1.1", "169.254.169.254",
+		"0.0.0.0", "100.64.0.1", "::1", "fe80::1", "fc00::1", "::ffff:127.0.0.1", "64:ff9b::a00:1",
+	}
+	for _, ip := range blocked {
+		if !IsBlocked(net.ParseIP(ip)) {
+			t.Errorf("%s should be blocked", ip)
+		}
+	}
+
+	allowed := []string{"93.184.216.34", "8.8.8.8", "2606:4700:4700::1111"}
+	for _, ip := range allowed {
+		if IsBlocked(net.ParseIP(ip)) {
+			t.Errorf("%s should be allowed", ip)
+		}
+	}
+}
+
+func TestClient(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/to-internal", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://internal.test/secret", http.StatusFound)
+	})
+	mux.HandleFunc("/to-file", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "file:///etc/passwd", http.StatusFound)
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	mux.HandleFunc("/once", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/ok", http.StatusFound)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	serverURL, _ := url.Parse(server.URL)
+	serverIP := net.ParseIP(serverURL.Hostname())
+	port := serverURL.Port()
+
+	resolver := newFakeResolver(map[string][]string{
+		"images.test":   {serverIP.String()},
+		"internal.test": {"10.0.0.5"},
+		"metadata.test": {"169.254.169.254"},
+		"mixed.test":    {serverIP.String(), "192.168.0.10"},
+	})
+
+	// тестовый сервер слушает loopback, поэтому разрешаем только его адрес
+	client := NewClient(Options{
+		Resolver:     resolver,
+		MaxRedirects: 2,
+		AllowIP:      func(ip net.IP) bool { return ip.Equal(serverIP) },
+	})
+
+	hostURL := func(host, path string) string {
+		return "http://" + net.JoinHostPort(host, port) + path
+	}
+
+	t.Run("Allowed host", func(t *testing.T) {
+		resp, err := client.Get(hostURL("images.test", "/ok"))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			t.Errorf("expected 200, got %d", resp.StatusCode)
+		}
+		if resolver.lookups["images.test"] != 1 {
+			t.Errorf("host should be resolved once, got %d lookups", resolver.lookups["images.test"])
+		}
+	})
+
+	t.Run("Redirect within limit", func(t *testing.T) {
+		resp, err := client.Get(hostURL("images.test", "/once"))
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		resp.Body.Close()
+	})
+
+	tests := []struct {
+		name    string
+		url     string
+		wantErr error
+	}{
+		{"Private network", hostURL("internal.test", "/"), ErrBlockedAddress},
+		{"Cloud metadata", hostURL("metadata.test", "/latest/meta-data"), ErrBlockedAddress},
+		{"Mixed DNS records", hostURL("mixed.test", "/ok"), ErrBlockedAddress},
+		{"Loopback literal", "http://127.0.0.2:" + port + "/ok", ErrBlockedAddress},
+		{"IPv6 loopback literal", "http://[::1]:" + port + "/ok", ErrBlockedAddress},
+		{"Redirect to private network", hostURL("images.test", "/to-internal"), ErrBlockedAddress},
+		{"Redirect to file scheme", hostURL("images.test", "/to-file"), ErrSchemeNotAllowed},
+		{"Redirect loop", hostURL("images.test", "/loop"), ErrTooManyRedirects},
+		{"Unsupported scheme", "ftp://images.test/file", ErrSchemeNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Get(tt.url)
+			if err == nil {
+				resp.Body.Close()
+				t.Fatalf("expected %v, request succeeded", tt.wantErr)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrNotFound   = errors.New("object not found")
+	ErrInvalidKey = errors.New("invalid object key")
+)
+
+type ObjectInfo struct {
+	ContentType string
+	Size        int64
+	ModTime     time.Time
+}
+
+// ValidateKey допускает только относительные ключи вида "ads/1/abc.jpg"
+// из латиницы, цифр и символов "-_./", без пустых сегментов и "..".
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 512 {
+		return ErrInvalidKey
+	}
+
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-' || r == '_' || r == '.' || r == '/':
+		default:
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+
+	return nil
+}
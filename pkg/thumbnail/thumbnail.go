@@ -0,0 +1,94 @@
+// Package thumbnail уменьшает изображения до фиксированных размеров и кодирует их в JPEG.
+package thumbnail
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	_ "image/png"
+
+	xdraw "golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+const (
+	ContentType = "image/jpeg"
+	Quality     = 85
+	// ограничение на исходник: декодированное изображение держится в памяти целиком (4 байта на пиксель)
+	MaxSourcePixels = 40_000_000
+)
+
+var (
+	ErrUnknownSize   = errors.New("unknown thumbnail size")
+	ErrInvalidSource = errors.New("unable to decode source image")
+	ErrSourceTooBig  = errors.New("source image is too large")
+)
+
+// Size - прямоугольник, в который вписывается миниатюра с сохранением пропорций
+type Size struct {
+	Width  int
+	Height int
+}
+
+// Sizes - доступные размеры миниатюр
+var Sizes = map[string]Size{
+	"small":  {Width: 160, Height: 160},
+	"medium": {Width: 480, Height: 480},
+	"large":  {Width: 1024, Height: 1024},
+}
+
+func Lookup(name string) (Size, error) {
+	size, ok := Sizes[name]
+	if !ok {
+		return Size{}, fmt.Errorf("%w: %q", ErrUnknownSize, name)
+	}
+	return size, nil
+}
+
+// fit вписывает width x height в size; изображения меньше size не увеличиваются
+func fit(width, height int, size Size) (int, int) {
+	if width <= size.Width && height <= size.Height {
+		return width, height
+	}
+	if width*size.Height > height*size.Width {
+		return size.Width, max(1, height*size.Width/width)
+	}
+	return max(1, width*size.Height/height), size.Height
+}
+
+// Generate строит миниатюру из JPEG, PNG или WEBP. Прозрачные области заливаются белым,
+// метаданные исходника в результат не попадают.
+func Generate(data []byte, size Size) ([]byte, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSource, err)
+	}
+	if config.Width <= 0 || config.Height <= 0 {
+		return nil, ErrInvalidSource
+	}
+	if config.Width*config.Height > MaxSourcePixels {
+		return nil, fmt.Errorf("%w: %dx%d", ErrSourceTooBig, config.Width, config.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSource, err)
+	}
+
+	bounds := src.Bounds()
+	width, height := fit(bounds.Dx(), bounds.Dy(), size)
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+	xdraw.CatmullRom.Scale(dst, dst.Bounds(), src, bounds, xdraw.Over, nil)
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: Quality}); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,89 @@
+package thumbnail
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestFit(t *testing.T) {
+	box := Size{Width: 160, Height: 160}
+	tests := []struct {
+		width, height int
+		wantW, wantH  int
+	}{
+		{1600, 1200, 160, 120},
+		{1200, 1600, 120, 160},
+		{500, 500, 160, 160},
+		{100, 80, 100, 80},  // не увеличиваем
+		{10000, 10, 160, 1}, // не меньше пикселя
+	}
+	for _, tt := range tests {
+		w, h := fit(tt.width, tt.height, box)
+		if w != tt.wantW || h != tt.wantH {
+			t.Errorf("fit(%d, %d) = %dx%d, want %dx%d", tt.width, tt.height, w, h, tt.wantW, tt.wantH)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	src := image.NewNRGBA(image.Rect(0, 0, 800, 400))
+	for x := 0; x < 800; x++ {
+		for y := 0; y < 400; y++ {
+			src.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: 0, A: 255})
+		}
+	}
+	// прозрачный угол должен стать белым
+	src.Set(0, 0, color.NRGBA{})
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, src); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := Generate(buf.Bytes(), Sizes["small"])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	thumb, err := jpeg.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("thumbnail is not a JPEG: %v", err)
+	}
+	if b := thumb.Bounds(); b.Dx() != 160 || b.Dy() != 80 {
+		t.Errorf("got %dx%d, want 160x80", b.Dx(), b.Dy())
+	}
+
+	again, err := Generate(buf.Bytes(), Sizes["small"])
+	if err != nil || !bytes.Equal(data, again) {
+		t.Error("generation should be deterministic")
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	if _, err := Generate([]byte("not an image"), Sizes["small"]); !errors.Is(err, ErrInvalidSource) {
+		t.Errorf("expected %v, got %v", ErrInvalidSource, err)
+	}
+
+	// заголовок PNG с огромными размерами: отказ до декодирования пикселей
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1)))
+	huge := buf.Bytes()
+	// IHDR: ширина и высота по смещениям 16 и 20
+	copy(huge[16:20], []byte{0, 0, 0x27, 0x10}) // 10000
+	copy(huge[20:24], []byte{0, 0, 0x27, 0x10}) // 10000
+	binary.BigEndian.PutUint32(huge[29:33], crc32.ChecksumIEEE(huge[12:29]))
+	if _, err := Generate(huge, Sizes["small"]); !errors.Is(err, ErrSourceTooBig) {
+		t.Errorf("expected %v, got %v", ErrSourceTooBig, err)
+	}
+
+	if _, err := Lookup("huge"); !errors.Is(err, ErrUnknownSize) {
+		t.Errorf("expected %v, got %v", ErrUnknownSize, err)
+	}
+}